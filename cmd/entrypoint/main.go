@@ -17,6 +17,8 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"log"
 	"os"
@@ -25,6 +27,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/credentials"
 	"github.com/tektoncd/pipeline/pkg/credentials/dockercreds"
 	"github.com/tektoncd/pipeline/pkg/credentials/gitcreds"
@@ -39,6 +42,11 @@ var (
 	postFile            = flag.String("post_file", "", "If specified, file to write upon completion")
 	terminationPath     = flag.String("termination_path", "/tekton/termination", "If specified, file to write upon termination")
 	results             = flag.String("results", "", "If specified, list of file names that might contain task results")
+	when                = flag.String("when", "", "If specified, JSON-encoded when expressions which must all evaluate to true for the step to run")
+	onError             = flag.String("on_error", "", "If set to continue, the next steps run even if the command exits with a non-zero exit code")
+	timeout             = flag.Duration("timeout", 0, "If specified, duration after which the command is terminated")
+	breakpointOnFailure = flag.Bool("breakpoint_on_failure", false, "If specified, hold the step after the command fails until it is resumed for debugging")
+	breakpointProbe     = flag.String("breakpoint_probe", "", "If specified, only checks whether this file exists, to report a step held at a breakpoint as ready")
 	waitPollingInterval = time.Second
 )
 
@@ -49,6 +57,14 @@ func main() {
 
 	flag.Parse()
 
+	// The readiness probe of a step held at a breakpoint succeeds once the breakpoint file is written
+	if *breakpointProbe != "" {
+		if _, err := os.Stat(*breakpointProbe); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// Copy creds-init credentials from secret volume mounts to /tekton/creds
 	// This is done to support the expansion of a variable, $(credentials.path), that
 	// resolves to a single place with all the stored credentials.
@@ -59,17 +75,28 @@ func main() {
 		}
 	}
 
+	var whenExpressions v1beta1.WhenExpressions
+	if *when != "" {
+		if err := json.Unmarshal([]byte(*when), &whenExpressions); err != nil {
+			log.Fatalf("Error parsing when expressions: %v", err)
+		}
+	}
+
 	e := entrypoint.Entrypointer{
-		Entrypoint:      *ep,
-		WaitFiles:       strings.Split(*waitFiles, ","),
-		WaitFileContent: *waitFileContent,
-		PostFile:        *postFile,
-		TerminationPath: *terminationPath,
-		Args:            flag.Args(),
-		Waiter:          &realWaiter{},
-		Runner:          &realRunner{},
-		PostWriter:      &realPostWriter{},
-		Results:         strings.Split(*results, ","),
+		Entrypoint:          *ep,
+		WaitFiles:           strings.Split(*waitFiles, ","),
+		WaitFileContent:     *waitFileContent,
+		PostFile:            *postFile,
+		TerminationPath:     *terminationPath,
+		Args:                flag.Args(),
+		Waiter:              &realWaiter{},
+		Runner:              &realRunner{},
+		PostWriter:          &realPostWriter{},
+		Results:             strings.Split(*results, ","),
+		WhenExpressions:     whenExpressions,
+		OnError:             *onError,
+		Timeout:             *timeout,
+		BreakpointOnFailure: *breakpointOnFailure,
 	}
 
 	// Copy any creds injected by the controller into the $HOME directory of the current
@@ -79,6 +106,10 @@ func main() {
 	}
 
 	if err := e.Go(); err != nil {
+		if err == context.DeadlineExceeded {
+			log.Printf("Step timed out after %s", *timeout)
+			os.Exit(entrypoint.TimedOutExitCode)
+		}
 		switch t := err.(type) {
 		case skipError:
 			log.Print("Skipping step because a previous step failed")
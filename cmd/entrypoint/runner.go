@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/tektoncd/pipeline/pkg/entrypoint"
 )
@@ -12,14 +14,19 @@ import (
 // TODO(jasonhall): Test that original exit code is propagated and that
 // stdout/stderr are collected -- needs e2e tests.
 
+// defaultGracePeriod is how long a command which timed out has to exit after
+// receiving SIGTERM before it is killed.
+const defaultGracePeriod = 10 * time.Second
+
 // realRunner actually runs commands.
 type realRunner struct {
-	signals chan os.Signal
+	signals     chan os.Signal
+	gracePeriod time.Duration
 }
 
 var _ entrypoint.Runner = (*realRunner)(nil)
 
-func (rr *realRunner) Run(args ...string) error {
+func (rr *realRunner) Run(ctx context.Context, args ...string) error {
 	if len(args) == 0 {
 		return nil
 	}
@@ -55,10 +62,28 @@ func (rr *realRunner) Run(args ...string) error {
 		}
 	}()
 
-	// Wait for command to exit
-	if err := cmd.Wait(); err != nil {
+	// Wait for command to exit, or terminate it once ctx is done
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	select {
+	case err := <-done:
 		return err
+	case <-ctx.Done():
 	}
 
-	return nil
+	// Give the main process and all children a chance to exit cleanly before killing them
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	gracePeriod := rr.gracePeriod
+	if gracePeriod == 0 {
+		gracePeriod = defaultGracePeriod
+	}
+	select {
+	case <-done:
+	case <-time.After(gracePeriod):
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		<-done
+	}
+	return ctx.Err()
 }
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"os"
 	"syscall"
 	"testing"
+	"time"
 )
 
 // TestRealRunnerSignalForwarding will artificially put an interrupt signal (SIGINT) in the rr.signals chan.
@@ -14,9 +16,35 @@ func TestRealRunnerSignalForwarding(t *testing.T) {
 	rr := realRunner{}
 	rr.signals = make(chan os.Signal, 1)
 	rr.signals <- syscall.SIGINT
-	if err := rr.Run("sleep", "3600"); err.Error() == "signal: interrupt" {
+	if err := rr.Run(context.Background(), "sleep", "3600"); err.Error() == "signal: interrupt" {
 		t.Logf("SIGINT forwarded to Entrypoint")
 	} else {
 		t.Fatalf("Unexpected error received: %v", err)
 	}
 }
+
+// TestRealRunnerTimeout runs a command which ignores SIGTERM with a timeout, which must be
+// killed once the grace period is over.
+func TestRealRunnerTimeout(t *testing.T) {
+	rr := realRunner{gracePeriod: 100 * time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := rr.Run(ctx, "sh", "-c", "trap '' TERM; sleep 3600"); err != context.DeadlineExceeded {
+		t.Fatalf("Expected error %v, got %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Expected the command to be killed after the grace period but it ran for %s", elapsed)
+	}
+}
+
+// TestRealRunnerTimeoutSIGTERM runs a command with a timeout, which must exit when it
+// receives SIGTERM without waiting for the grace period.
+func TestRealRunnerTimeoutSIGTERM(t *testing.T) {
+	rr := realRunner{gracePeriod: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := rr.Run(ctx, "sleep", "3600"); err != context.DeadlineExceeded {
+		t.Fatalf("Expected error %v, got %v", context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tektoncd/pipeline/pkg/lint"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s FILE|DIR|- ...\n\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "Validates the Tekton resources in the given YAML files, and checks them against each other.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	l := lint.NewLinter()
+	for _, path := range flag.Args() {
+		if err := l.AddPath(path); err != nil {
+			fmt.Fprintf(os.Stderr, "error reading %s: %v\n", path, err)
+			os.Exit(2)
+		}
+	}
+	problems := l.Lint()
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+}
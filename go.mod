@@ -6,6 +6,8 @@ require (
 	github.com/GoogleCloudPlatform/cloud-builders/gcs-fetcher v0.0.0-20191203181535-308b93ad1f39
 	github.com/cloudevents/sdk-go/v2 v2.1.0
 	github.com/ghodss/yaml v1.0.0
+	github.com/golang/protobuf v1.4.2
+	github.com/google/cel-go v0.6.0
 	github.com/google/go-cmp v0.5.2
 	github.com/google/go-containerregistry v0.1.2
 	github.com/google/uuid v1.1.1
@@ -19,6 +21,7 @@ require (
 	go.uber.org/zap v1.15.0
 	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d
 	gomodules.xyz/jsonpatch/v2 v2.1.0
+	google.golang.org/genproto v0.0.0-20200828030656-73b5761be4c5
 	k8s.io/api v0.18.7-rc.0
 	k8s.io/apimachinery v0.19.0
 	k8s.io/client-go v11.0.1-0.20190805182717-6502b5e7b1b5+incompatible
@@ -106,11 +106,28 @@ func PipelineDescription(desc string) PipelineSpecOp {
 	}
 }
 
+// PipelineMaxParallel sets the maximum number of TaskRuns and child PipelineRuns of the pipeline which run at once
+func PipelineMaxParallel(maxParallel int) PipelineSpecOp {
+	return func(ps *v1beta1.PipelineSpec) {
+		ps.MaxParallel = maxParallel
+	}
+}
+
 // PipelineRunCancelled sets the status to cancel to the TaskRunSpec.
 func PipelineRunCancelled(spec *v1beta1.PipelineRunSpec) {
 	spec.Status = v1beta1.PipelineRunSpecStatusCancelled
 }
 
+// PipelineRunPaused sets the status to paused to the PipelineRunSpec.
+func PipelineRunPaused(spec *v1beta1.PipelineRunSpec) {
+	spec.Status = v1beta1.PipelineRunSpecStatusPaused
+}
+
+// PipelineRunPending sets the status to pending to the PipelineRunSpec.
+func PipelineRunPending(spec *v1beta1.PipelineRunSpec) {
+	spec.Status = v1beta1.PipelineRunSpecStatusPending
+}
+
 // PipelineDeclaredResource adds a resource declaration to the Pipeline Spec,
 // with the specified name and type.
 func PipelineDeclaredResource(name string, t v1beta1.PipelineResourceType) PipelineSpecOp {
@@ -223,6 +240,28 @@ func Retries(retries int) PipelineTaskOp {
 	}
 }
 
+// PipelineTaskRetryPolicy sets the retry policy of a PipelineTask.
+func PipelineTaskRetryPolicy(policy v1beta1.RetryPolicy) PipelineTaskOp {
+	return func(pt *v1beta1.PipelineTask) {
+		pt.RetryPolicy = &policy
+	}
+}
+
+// PipelineTaskCache sets the cache of the PipelineTask, which reuses the results of previous runs.
+func PipelineTaskCache(cache v1beta1.TaskCache) PipelineTaskOp {
+	return func(pt *v1beta1.PipelineTask) {
+		pt.Cache = &cache
+	}
+}
+
+// PipelineTaskConcurrency sets the concurrency of the PipelineTask, which serializes its TaskRuns
+// with the ones of other PipelineRuns holding the same key.
+func PipelineTaskConcurrency(key string, policy v1beta1.ConcurrencyPolicy) PipelineTaskOp {
+	return func(pt *v1beta1.PipelineTask) {
+		pt.Concurrency = &v1beta1.Concurrency{Key: key, Policy: policy}
+	}
+}
+
 // RunAfter will update the provided Pipeline Task to indicate that it
 // should be run after the provided list of Pipeline Task names.
 func RunAfter(tasks ...string) PipelineTaskOp {
@@ -508,6 +547,37 @@ func PipelineRunNilTimeout(prs *v1beta1.PipelineRunSpec) {
 	prs.Timeout = nil
 }
 
+// PipelineRunTimeouts sets the timeouts of the pipeline, its tasks and its finally tasks
+// to the PipelineRunSpec, in place of its timeout.
+func PipelineRunTimeouts(timeouts v1beta1.TimeoutFields) PipelineRunSpecOp {
+	return func(prs *v1beta1.PipelineRunSpec) {
+		prs.Timeout = nil
+		prs.Timeouts = &timeouts
+	}
+}
+
+// PipelineRunResumeFrom sets the name of the PipelineRun resumed by the PipelineRunSpec.
+func PipelineRunResumeFrom(name string) PipelineRunSpecOp {
+	return func(prs *v1beta1.PipelineRunSpec) {
+		prs.ResumeFrom = name
+	}
+}
+
+// PipelineRunMaxParallel sets the maximum number of TaskRuns and child PipelineRuns of the PipelineRunSpec which run at once.
+func PipelineRunMaxParallel(maxParallel int) PipelineRunSpecOp {
+	return func(prs *v1beta1.PipelineRunSpec) {
+		prs.MaxParallel = maxParallel
+	}
+}
+
+// PipelineRunConcurrency sets the concurrency of the PipelineRun, which serializes it with the
+// other PipelineRuns holding the same key.
+func PipelineRunConcurrency(key string, policy v1beta1.ConcurrencyPolicy) PipelineRunSpecOp {
+	return func(prs *v1beta1.PipelineRunSpec) {
+		prs.Concurrency = &v1beta1.Concurrency{Key: key, Policy: policy}
+	}
+}
+
 // PipelineRunNodeSelector sets the Node selector to the PipelineRunSpec.
 func PipelineRunNodeSelector(values map[string]string) PipelineRunSpecOp {
 	return func(prs *v1beta1.PipelineRunSpec) {
@@ -557,6 +627,13 @@ func PipelineRunStartTime(startTime time.Time) PipelineRunStatusOp {
 	}
 }
 
+// PipelineRunFinallyStartTime sets the start time of the finally tasks to the PipelineRunStatus.
+func PipelineRunFinallyStartTime(t time.Time) PipelineRunStatusOp {
+	return func(s *v1beta1.PipelineRunStatus) {
+		s.FinallyStartTime = &metav1.Time{Time: t}
+	}
+}
+
 // PipelineRunCompletionTime sets the completion time  to the PipelineRunStatus.
 func PipelineRunCompletionTime(t time.Time) PipelineRunStatusOp {
 	return func(s *v1beta1.PipelineRunStatus) {
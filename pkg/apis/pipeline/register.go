@@ -48,6 +48,19 @@ const (
 
 	// RunKey is used as the label identifier for a Run
 	RunKey = "/run"
+
+	// CacheKeyLabelKey is used as the label identifier for the key the results of a TaskRun are cached under
+	CacheKeyLabelKey = "/cacheKey"
+
+	// ConcurrencyKeyLabelKey is used as the label identifier for the hash of the concurrency key of a PipelineRun
+	ConcurrencyKeyLabelKey = "/concurrencyKey"
+
+	// ParentPipelineRefsAnnotationKey is used as the annotation identifier for the references to the Pipelines run by
+	// the PipelineRuns a PipelineRun is nested in
+	ParentPipelineRefsAnnotationKey = "/parentPipelineRefs"
+
+	// RetryPolicyAnnotationKey is used as the annotation identifier for the TaskRuns of a PipelineTask with a retry policy
+	RetryPolicyAnnotationKey = "/retryPolicy"
 )
 
 var (
@@ -38,8 +38,9 @@ const (
 	ParamTypeArray  ParamType = v1beta1.ParamTypeArray
 )
 
-// AllParamTypes can be used for ParamType validation.
-var AllParamTypes = v1beta1.AllParamTypes
+// AllParamTypes can be used for ParamType validation. Object params are only
+// supported in v1beta1.
+var AllParamTypes = []ParamType{ParamTypeString, ParamTypeArray}
 
 // ArrayOrString is modeled after IntOrString in kubernetes/apimachinery:
 
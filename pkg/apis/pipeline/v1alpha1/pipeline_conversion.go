@@ -25,7 +25,18 @@ import (
 	"knative.dev/pkg/apis"
 )
 
-const FinallyFieldName = "finally"
+const (
+	FinallyFieldName      = "finally"
+	PipelineRefFieldName  = "pipelineRef"
+	PipelineSpecFieldName = "pipelineSpec"
+	RetryPolicyFieldName  = "retryPolicy"
+	ResumeFromFieldName   = "resumeFrom"
+	CacheFieldName        = "cache"
+	MaxParallelFieldName  = "maxParallel"
+	ConcurrencyFieldName  = "concurrency"
+	TimeoutsFieldName     = "timeouts"
+	DebugFieldName        = "debug"
+)
 
 var _ apis.Convertible = (*Pipeline)(nil)
 
@@ -104,10 +115,33 @@ func (sink *PipelineSpec) ConvertFrom(ctx context.Context, source v1beta1.Pipeli
 	if len(source.Finally) > 0 {
 		return ConvertErrorf(FinallyFieldName, ConversionErrorFieldNotAvailableMsg)
 	}
+	// the maxParallel limit was introduced in v1beta1 and not available in v1alpha1
+	if source.MaxParallel != 0 {
+		return ConvertErrorf(MaxParallelFieldName, ConversionErrorFieldNotAvailableMsg)
+	}
 	return nil
 }
 
 func (sink *PipelineTask) ConvertFrom(ctx context.Context, source v1beta1.PipelineTask) error {
+	// pipeline tasks running a Pipeline were introduced in v1beta1 and not available in v1alpha1
+	if source.PipelineRef != nil {
+		return ConvertErrorf(PipelineRefFieldName, ConversionErrorFieldNotAvailableMsg)
+	}
+	if source.PipelineSpec != nil {
+		return ConvertErrorf(PipelineSpecFieldName, ConversionErrorFieldNotAvailableMsg)
+	}
+	// retry policies were introduced in v1beta1 and not available in v1alpha1
+	if source.RetryPolicy != nil {
+		return ConvertErrorf(RetryPolicyFieldName, ConversionErrorFieldNotAvailableMsg)
+	}
+	// task caches were introduced in v1beta1 and not available in v1alpha1
+	if source.Cache != nil {
+		return ConvertErrorf(CacheFieldName, ConversionErrorFieldNotAvailableMsg)
+	}
+	// concurrency groups were introduced in v1beta1 and not available in v1alpha1
+	if source.Concurrency != nil {
+		return ConvertErrorf(ConcurrencyFieldName, ConversionErrorFieldNotAvailableMsg)
+	}
 	sink.Name = source.Name
 	sink.TaskRef = source.TaskRef
 	if source.TaskSpec != nil {
@@ -255,3 +255,73 @@ func TestPipelineConversionFromBetaToAlphaWithFinally_Failure(t *testing.T) {
 		}
 	})
 }
+
+func TestPipelineConversionFromWithMaxParallel(t *testing.T) {
+	p := &v1beta1.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "foo",
+			Namespace:  "bar",
+			Generation: 1,
+		},
+		Spec: v1beta1.PipelineSpec{
+			Tasks:       []v1beta1.PipelineTask{{Name: "mytask", TaskRef: &TaskRef{Name: "task"}}},
+			MaxParallel: 2,
+		},
+	}
+	got := &Pipeline{}
+	err := got.ConvertFrom(context.Background(), p)
+	if cce, ok := err.(*CannotConvertError); !ok || cce.Field != MaxParallelFieldName {
+		t.Errorf("ConvertFrom() = %v, wanted a conversion error for field %q", err, MaxParallelFieldName)
+	}
+}
+
+func TestPipelineConversionFromWithPipelineTasks(t *testing.T) {
+	tests := []struct {
+		name  string
+		task  v1beta1.PipelineTask
+		field string
+	}{{
+		name:  "pipelineRef not available in v1alpha1",
+		task:  v1beta1.PipelineTask{Name: "mypipeline", PipelineRef: &v1beta1.PipelineRef{Name: "pipeline"}},
+		field: PipelineRefFieldName,
+	}, {
+		name: "pipelineSpec not available in v1alpha1",
+		task: v1beta1.PipelineTask{Name: "mypipeline", PipelineSpec: &v1beta1.PipelineSpec{
+			Tasks: []v1beta1.PipelineTask{{Name: "mytask", TaskRef: &TaskRef{Name: "task"}}},
+		}},
+		field: PipelineSpecFieldName,
+	}, {
+		name: "retryPolicy not available in v1alpha1",
+		task: v1beta1.PipelineTask{Name: "mytask", TaskRef: &TaskRef{Name: "task"}, Retries: 1, RetryPolicy: &v1beta1.RetryPolicy{
+			On: []v1beta1.RetryReason{v1beta1.RetryReasonEvicted},
+		}},
+		field: RetryPolicyFieldName,
+	}, {
+		name:  "cache not available in v1alpha1",
+		task:  v1beta1.PipelineTask{Name: "mytask", TaskRef: &TaskRef{Name: "task"}, Cache: &v1beta1.TaskCache{}},
+		field: CacheFieldName,
+	}, {
+		name:  "concurrency not available in v1alpha1",
+		task:  v1beta1.PipelineTask{Name: "mytask", TaskRef: &TaskRef{Name: "task"}, Concurrency: &v1beta1.Concurrency{Key: "deploy"}},
+		field: ConcurrencyFieldName,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &v1beta1.Pipeline{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "foo",
+					Namespace:  "bar",
+					Generation: 1,
+				},
+				Spec: v1beta1.PipelineSpec{
+					Tasks: []v1beta1.PipelineTask{test.task},
+				},
+			}
+			got := &Pipeline{}
+			err := got.ConvertFrom(context.Background(), p)
+			if cce, ok := err.(*CannotConvertError); !ok || cce.Field != test.field {
+				t.Errorf("ConvertFrom() = %v, wanted a conversion error for field %q", err, test.field)
+			}
+		})
+	}
+}
@@ -77,6 +77,19 @@ func (sink *PipelineRun) ConvertFrom(ctx context.Context, obj apis.Convertible)
 }
 
 func (sink *PipelineRunSpec) ConvertFrom(ctx context.Context, source *v1beta1.PipelineRunSpec) error {
+	if source.ResumeFrom != "" {
+		return ConvertErrorf(ResumeFromFieldName, ConversionErrorFieldNotAvailableMsg)
+	}
+	if source.MaxParallel != 0 {
+		return ConvertErrorf(MaxParallelFieldName, ConversionErrorFieldNotAvailableMsg)
+	}
+	if source.Concurrency != nil {
+		return ConvertErrorf(ConcurrencyFieldName, ConversionErrorFieldNotAvailableMsg)
+	}
+	// v1alpha1 only has a timeout for the whole pipelinerun
+	if source.Timeouts != nil && (source.Timeouts.Tasks != nil || source.Timeouts.Finally != nil) {
+		return ConvertErrorf(TimeoutsFieldName, ConversionErrorFieldNotAvailableMsg)
+	}
 	sink.PipelineRef = source.PipelineRef
 	if source.PipelineSpec != nil {
 		sink.PipelineSpec = &PipelineSpec{}
@@ -90,6 +103,9 @@ func (sink *PipelineRunSpec) ConvertFrom(ctx context.Context, source *v1beta1.Pi
 	sink.ServiceAccountNames = source.ServiceAccountNames
 	sink.Status = source.Status
 	sink.Timeout = source.Timeout
+	if source.Timeouts != nil {
+		sink.Timeout = source.Timeouts.Pipeline
+	}
 	sink.PodTemplate = source.PodTemplate
 	sink.Workspaces = source.Workspaces
 	return nil
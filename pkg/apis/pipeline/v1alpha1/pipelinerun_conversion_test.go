@@ -180,3 +180,122 @@ func TestPipelineRunConversion(t *testing.T) {
 		}
 	}
 }
+
+func TestPipelineRunConversionFromWithResumeFrom(t *testing.T) {
+	pr := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "foo",
+			Namespace:  "bar",
+			Generation: 1,
+		},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "pipeline"},
+			ResumeFrom:  "failed-run",
+		},
+	}
+	got := &PipelineRun{}
+	err := got.ConvertFrom(context.Background(), pr)
+	if cce, ok := err.(*CannotConvertError); !ok || cce.Field != ResumeFromFieldName {
+		t.Errorf("ConvertFrom() = %v, wanted a conversion error for field %q", err, ResumeFromFieldName)
+	}
+}
+
+func TestPipelineRunConversionFromWithMaxParallel(t *testing.T) {
+	pr := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "foo",
+			Namespace:  "bar",
+			Generation: 1,
+		},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "pipeline"},
+			MaxParallel: 2,
+		},
+	}
+	got := &PipelineRun{}
+	err := got.ConvertFrom(context.Background(), pr)
+	if cce, ok := err.(*CannotConvertError); !ok || cce.Field != MaxParallelFieldName {
+		t.Errorf("ConvertFrom() = %v, wanted a conversion error for field %q", err, MaxParallelFieldName)
+	}
+}
+
+func TestPipelineRunConversionFromWithConcurrency(t *testing.T) {
+	pr := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "foo",
+			Namespace:  "bar",
+			Generation: 1,
+		},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "pipeline"},
+			Concurrency: &v1beta1.Concurrency{Key: "deploy"},
+		},
+	}
+	got := &PipelineRun{}
+	err := got.ConvertFrom(context.Background(), pr)
+	if cce, ok := err.(*CannotConvertError); !ok || cce.Field != ConcurrencyFieldName {
+		t.Errorf("ConvertFrom() = %v, wanted a conversion error for field %q", err, ConcurrencyFieldName)
+	}
+}
+
+func TestPipelineRunConversionFromWithTimeouts(t *testing.T) {
+	tcs := []struct {
+		name     string
+		timeouts *v1beta1.TimeoutFields
+	}{{
+		name: "tasks timeout",
+		timeouts: &v1beta1.TimeoutFields{
+			Pipeline: &metav1.Duration{Duration: time.Hour},
+			Tasks:    &metav1.Duration{Duration: 40 * time.Minute},
+		},
+	}, {
+		name: "finally timeout",
+		timeouts: &v1beta1.TimeoutFields{
+			Pipeline: &metav1.Duration{Duration: time.Hour},
+			Finally:  &metav1.Duration{Duration: 20 * time.Minute},
+		},
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := &v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "foo",
+					Namespace:  "bar",
+					Generation: 1,
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{Name: "pipeline"},
+					Timeouts:    tc.timeouts,
+				},
+			}
+			got := &PipelineRun{}
+			err := got.ConvertFrom(context.Background(), pr)
+			if cce, ok := err.(*CannotConvertError); !ok || cce.Field != TimeoutsFieldName {
+				t.Errorf("ConvertFrom() = %v, wanted a conversion error for field %q", err, TimeoutsFieldName)
+			}
+		})
+	}
+}
+
+func TestPipelineRunConversionFromWithPipelineTimeout(t *testing.T) {
+	pr := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "foo",
+			Namespace:  "bar",
+			Generation: 1,
+		},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "pipeline"},
+			Timeouts: &v1beta1.TimeoutFields{
+				Pipeline: &metav1.Duration{Duration: time.Hour},
+			},
+		},
+	}
+	got := &PipelineRun{}
+	if err := got.ConvertFrom(context.Background(), pr); err != nil {
+		t.Fatalf("ConvertFrom() = %v", err)
+	}
+	if d := cmp.Diff(&metav1.Duration{Duration: time.Hour}, got.Spec.Timeout); d != "" {
+		t.Errorf("ConvertFrom() timeout %s", diff.PrintWantGot(d))
+	}
+}
@@ -103,6 +103,18 @@ const (
 	// PipelineRunSpecStatusCancelled indicates that the user wants to cancel the task,
 	// if not already cancelled or terminated
 	PipelineRunSpecStatusCancelled = v1beta1.PipelineRunSpecStatusCancelled
+
+	// PipelineRunSpecStatusCancelledRunFinally indicates that the user wants to cancel the running
+	// tasks and skip the ones that did not start yet, but still run the final tasks
+	PipelineRunSpecStatusCancelledRunFinally = v1beta1.PipelineRunSpecStatusCancelledRunFinally
+
+	// PipelineRunSpecStatusStoppedRunFinally indicates that the user wants to let the running
+	// tasks finish and skip the ones that did not start yet, but still run the final tasks
+	PipelineRunSpecStatusStoppedRunFinally = v1beta1.PipelineRunSpecStatusStoppedRunFinally
+
+	// PipelineRunSpecStatusPaused indicates that the user wants to stop scheduling new tasks
+	// while the running ones finish, until the spec status is cleared
+	PipelineRunSpecStatusPaused = v1beta1.PipelineRunSpecStatusPaused
 )
 
 // PipelineResourceRef can be used to refer to a specific instance of a Resource
@@ -130,6 +130,12 @@ func (sink *TaskRun) ConvertFrom(ctx context.Context, obj apis.Convertible) erro
 }
 
 func (sink *TaskRunSpec) ConvertFrom(ctx context.Context, source *v1beta1.TaskRunSpec) error {
+	if source.Cache != nil {
+		return ConvertErrorf(CacheFieldName, ConversionErrorFieldNotAvailableMsg)
+	}
+	if source.Debug != nil {
+		return ConvertErrorf(DebugFieldName, ConversionErrorFieldNotAvailableMsg)
+	}
 	sink.ServiceAccountName = source.ServiceAccountName
 	sink.TaskRef = source.TaskRef
 	if source.TaskSpec != nil {
@@ -439,3 +439,41 @@ func TestTaskRunConversionFromDeprecated(t *testing.T) {
 		}
 	}
 }
+
+func TestTaskRunConversionFromWithCache(t *testing.T) {
+	tr := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "foo",
+			Namespace:  "bar",
+			Generation: 1,
+		},
+		Spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "task"},
+			Cache:   &v1beta1.TaskCache{ConfigMap: "task-cache"},
+		},
+	}
+	got := &TaskRun{}
+	err := got.ConvertFrom(context.Background(), tr)
+	if cce, ok := err.(*CannotConvertError); !ok || cce.Field != CacheFieldName {
+		t.Errorf("ConvertFrom() = %v, wanted a conversion error for field %q", err, CacheFieldName)
+	}
+}
+
+func TestTaskRunConversionFromWithDebug(t *testing.T) {
+	tr := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "foo",
+			Namespace:  "bar",
+			Generation: 1,
+		},
+		Spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "task"},
+			Debug:   &v1beta1.TaskRunDebug{Breakpoint: []string{v1beta1.BreakpointOnFailure}},
+		},
+	}
+	got := &TaskRun{}
+	err := got.ConvertFrom(context.Background(), tr)
+	if cce, ok := err.(*CannotConvertError); !ok || cce.Field != DebugFieldName {
+		t.Errorf("ConvertFrom() = %v, wanted a conversion error for field %q", err, DebugFieldName)
+	}
+}
@@ -0,0 +1,27 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+// TaskCache enables the reuse of the results of a previous successful TaskRun of the same
+// Task with the same params, input resources and step images, in place of running it again.
+// A TaskRun with workspaces is never cached.
+type TaskCache struct {
+	// ConfigMap is the name of a ConfigMap in the namespace of the TaskRun where the results
+	// are stored too, so that they can still be reused once the TaskRun is deleted
+	// +optional
+	ConfigMap string `json:"configMap,omitempty"`
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+// validate checks that the ConfigMap of the cache, if any, has a valid name
+func (c *TaskCache) validate() *apis.FieldError {
+	if c.ConfigMap != "" {
+		if errs := validation.IsDNS1123Subdomain(c.ConfigMap); len(errs) > 0 {
+			return apis.ErrInvalidValue(strings.Join(errs, ", "), "configMap")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/test/diff"
+	"knative.dev/pkg/apis"
+)
+
+func TestTaskCache_Valid(t *testing.T) {
+	tests := []struct {
+		name  string
+		cache TaskCache
+	}{{
+		name:  "without configmap",
+		cache: TaskCache{},
+	}, {
+		name:  "with configmap",
+		cache: TaskCache{ConfigMap: "task-cache"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cache.validate(); err != nil {
+				t.Errorf("TaskCache.validate() returned an error for valid cache: %v", err)
+			}
+		})
+	}
+}
+
+func TestTaskCache_Invalid(t *testing.T) {
+	cache := TaskCache{ConfigMap: "Task_Cache"}
+	want := apis.ErrInvalidValue("a DNS-1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')", "configMap")
+	err := cache.validate()
+	if err == nil {
+		t.Fatalf("Expected an error, got nothing for %v", cache)
+	}
+	if d := cmp.Diff(want.Error(), err.Error()); d != "" {
+		t.Errorf("TaskCache.validate() errors diff %s", diff.PrintWantGot(d))
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+// ConcurrencyPolicy is what happens to a run whose concurrency key is held by another run
+type ConcurrencyPolicy string
+
+const (
+	// ConcurrencyPolicyQueue makes the run wait until the run holding the key is done
+	ConcurrencyPolicyQueue ConcurrencyPolicy = "queue"
+	// ConcurrencyPolicyCancelPrevious cancels the older runs holding or waiting for the key
+	ConcurrencyPolicyCancelPrevious ConcurrencyPolicy = "cancel-previous"
+)
+
+// Concurrency declares a concurrency group: at most one PipelineRun, or TaskRun of a PipelineTask,
+// holding the same key runs at once in a namespace
+type Concurrency struct {
+	// Key identifies the concurrency group. It can use the params of the PipelineRun, for
+	// example "deploy-$(params.env)".
+	Key string `json:"key"`
+	// Policy is either "queue", the default, or "cancel-previous"
+	// +optional
+	Policy ConcurrencyPolicy `json:"policy,omitempty"`
+}
+
+// CancelsPrevious returns true if the older runs holding or waiting for the key are cancelled
+func (c *Concurrency) CancelsPrevious() bool {
+	return c.Policy == ConcurrencyPolicyCancelPrevious
+}
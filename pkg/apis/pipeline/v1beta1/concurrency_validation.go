@@ -0,0 +1,36 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	"knative.dev/pkg/apis"
+)
+
+// validate checks that the concurrency has a key and a known policy
+func (c *Concurrency) validate() *apis.FieldError {
+	if c.Key == "" {
+		return apis.ErrMissingField("key")
+	}
+	switch c.Policy {
+	case "", ConcurrencyPolicyQueue, ConcurrencyPolicyCancelPrevious:
+	default:
+		return apis.ErrInvalidValue(fmt.Sprintf("%s should be one of %s, %s", c.Policy, ConcurrencyPolicyQueue, ConcurrencyPolicyCancelPrevious), "policy")
+	}
+	return nil
+}
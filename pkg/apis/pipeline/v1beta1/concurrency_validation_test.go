@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/test/diff"
+	"knative.dev/pkg/apis"
+)
+
+func TestConcurrency_Valid(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency Concurrency
+	}{{
+		name:        "default policy",
+		concurrency: Concurrency{Key: "deploy-$(params.env)"},
+	}, {
+		name:        "queue",
+		concurrency: Concurrency{Key: "deploy", Policy: ConcurrencyPolicyQueue},
+	}, {
+		name:        "cancel previous",
+		concurrency: Concurrency{Key: "deploy", Policy: ConcurrencyPolicyCancelPrevious},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.concurrency.validate(); err != nil {
+				t.Errorf("Concurrency.validate() returned an error for valid concurrency: %v", err)
+			}
+		})
+	}
+}
+
+func TestConcurrency_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency Concurrency
+		want        *apis.FieldError
+	}{{
+		name:        "missing key",
+		concurrency: Concurrency{Policy: ConcurrencyPolicyQueue},
+		want:        apis.ErrMissingField("key"),
+	}, {
+		name:        "unknown policy",
+		concurrency: Concurrency{Key: "deploy", Policy: "cancel-all"},
+		want:        apis.ErrInvalidValue("cancel-all should be one of queue, cancel-previous", "policy"),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.concurrency.validate()
+			if err == nil {
+				t.Fatalf("Expected an error, got nothing for %v", tt.concurrency)
+			}
+			if d := cmp.Diff(tt.want.Error(), err.Error()); d != "" {
+				t.Errorf("Concurrency.validate() errors diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
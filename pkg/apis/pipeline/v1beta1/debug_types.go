@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+// BreakpointOnFailure is the breakpoint which holds the pod of a TaskRun when one of its steps fails
+const BreakpointOnFailure = "onFailure"
+
+// TaskRunDebug configures how the pod of a TaskRun can be debugged
+type TaskRunDebug struct {
+	// Breakpoint lists the events at which the step container is held instead of exiting, so that
+	// it can be inspected with kubectl exec. The only supported breakpoint is "onFailure".
+	// +optional
+	Breakpoint []string `json:"breakpoint,omitempty"`
+}
+
+// NeedsBreakpointOnFailure returns true if the steps of the TaskRun should be held when they fail
+func (d *TaskRunDebug) NeedsBreakpointOnFailure() bool {
+	if d == nil {
+		return false
+	}
+	for _, b := range d.Breakpoint {
+		if b == BreakpointOnFailure {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskRunDebugStatus reports the step container held at a breakpoint, which can be debugged with
+// kubectl exec
+type TaskRunDebugStatus struct {
+	// PodName is the name of the pod of the held step
+	PodName string `json:"podName"`
+	// ContainerName is the name of the container of the held step
+	ContainerName string `json:"containerName"`
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	"knative.dev/pkg/apis"
+)
+
+// validate checks that the breakpoints of the debug configuration are supported
+func (d *TaskRunDebug) validate() *apis.FieldError {
+	for i, b := range d.Breakpoint {
+		if b != BreakpointOnFailure {
+			return apis.ErrInvalidValue(fmt.Sprintf("%s should be %s", b, BreakpointOnFailure), fmt.Sprintf("breakpoint[%d]", i))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/test/diff"
+	"knative.dev/pkg/apis"
+)
+
+func TestTaskRunDebug_Valid(t *testing.T) {
+	tests := []struct {
+		name  string
+		debug TaskRunDebug
+	}{{
+		name:  "without breakpoints",
+		debug: TaskRunDebug{},
+	}, {
+		name:  "with onFailure breakpoint",
+		debug: TaskRunDebug{Breakpoint: []string{"onFailure"}},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.debug.validate(); err != nil {
+				t.Errorf("TaskRunDebug.validate() returned an error for valid debug: %v", err)
+			}
+		})
+	}
+}
+
+func TestTaskRunDebug_Invalid(t *testing.T) {
+	debug := TaskRunDebug{Breakpoint: []string{"onFailure", "onSuccess"}}
+	want := apis.ErrInvalidValue("onSuccess should be onFailure", "breakpoint[1]")
+	err := debug.validate()
+	if err == nil {
+		t.Fatalf("Expected an error, got nothing for %v", debug)
+	}
+	if d := cmp.Diff(want.Error(), err.Error()); d != "" {
+		t.Errorf("TaskRunDebug.validate() errors diff %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestTaskRunDebug_NeedsBreakpointOnFailure(t *testing.T) {
+	tests := []struct {
+		name  string
+		debug *TaskRunDebug
+		want  bool
+	}{{
+		name: "without debug",
+	}, {
+		name:  "without breakpoints",
+		debug: &TaskRunDebug{},
+	}, {
+		name:  "with onFailure breakpoint",
+		debug: &TaskRunDebug{Breakpoint: []string{"onFailure"}},
+		want:  true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.debug.NeedsBreakpointOnFailure(); got != tt.want {
+				t.Errorf("NeedsBreakpointOnFailure() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
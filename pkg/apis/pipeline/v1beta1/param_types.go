@@ -34,7 +34,7 @@ type ParamSpec struct {
 	// Name declares the name by which a parameter is referenced.
 	Name string `json:"name"`
 	// Type is the user-specified type of the parameter. The possible types
-	// are currently "string" and "array", and "string" is the default.
+	// are currently "string", "array" and "object", and "string" is the default.
 	// +optional
 	Type ParamType `json:"type,omitempty"`
 	// Description is a user-facing description of the parameter that may be
@@ -46,6 +46,18 @@ type ParamSpec struct {
 	// parameter.
 	// +optional
 	Default *ArrayOrString `json:"default,omitempty"`
+	// Properties is the JSON Schema properties to support key-value pairs parameter.
+	// It is required for, and only allowed with, parameters of type "object".
+	// +optional
+	Properties map[string]PropertySpec `json:"properties,omitempty"`
+}
+
+// PropertySpec defines the struct for object keys
+type PropertySpec struct {
+	// Type is the type of the value stored under the key. Only "string" is
+	// currently supported, and it is the default.
+	// +optional
+	Type ParamType `json:"type,omitempty"`
 }
 
 // SetDefaults set the default type
@@ -59,6 +71,14 @@ func (pp *ParamSpec) SetDefaults(ctx context.Context) {
 			pp.Type = ParamTypeString
 		}
 	}
+	if pp != nil {
+		for key, property := range pp.Properties {
+			if property.Type == "" {
+				property.Type = ParamTypeString
+				pp.Properties[key] = property
+			}
+		}
+	}
 }
 
 // ResourceParam declares a string value to use for the parameter called Name, and is used in
@@ -79,27 +99,34 @@ type ParamType string
 const (
 	ParamTypeString ParamType = "string"
 	ParamTypeArray  ParamType = "array"
+	ParamTypeObject ParamType = "object"
 )
 
 // AllParamTypes can be used for ParamType validation.
-var AllParamTypes = []ParamType{ParamTypeString, ParamTypeArray}
+var AllParamTypes = []ParamType{ParamTypeString, ParamTypeArray, ParamTypeObject}
 
 // ArrayOrString is modeled after IntOrString in kubernetes/apimachinery:
 
-// ArrayOrString is a type that can hold a single string or string array.
+// ArrayOrString is a type that can hold a single string, a string array or
+// a string-to-string map (an object).
 // Used in JSON unmarshalling so that a single JSON field can accept
-// either an individual string or an array of strings.
+// either an individual string, an array of strings or an object.
 type ArrayOrString struct {
-	Type      ParamType `json:"type"` // Represents the stored type of ArrayOrString.
-	StringVal string    `json:"stringVal"`
-	ArrayVal  []string  `json:"arrayVal"`
+	Type      ParamType         `json:"type"` // Represents the stored type of ArrayOrString.
+	StringVal string            `json:"stringVal"`
+	ArrayVal  []string          `json:"arrayVal"`
+	ObjectVal map[string]string `json:"objectVal"`
 }
 
 // UnmarshalJSON implements the json.Unmarshaller interface.
 func (arrayOrString *ArrayOrString) UnmarshalJSON(value []byte) error {
-	if value[0] == '"' {
+	switch value[0] {
+	case '"':
 		arrayOrString.Type = ParamTypeString
 		return json.Unmarshal(value, &arrayOrString.StringVal)
+	case '{':
+		arrayOrString.Type = ParamTypeObject
+		return json.Unmarshal(value, &arrayOrString.ObjectVal)
 	}
 	arrayOrString.Type = ParamTypeArray
 	return json.Unmarshal(value, &arrayOrString.ArrayVal)
@@ -112,16 +139,35 @@ func (arrayOrString ArrayOrString) MarshalJSON() ([]byte, error) {
 		return json.Marshal(arrayOrString.StringVal)
 	case ParamTypeArray:
 		return json.Marshal(arrayOrString.ArrayVal)
+	case ParamTypeObject:
+		return json.Marshal(arrayOrString.ObjectVal)
 	default:
 		return []byte{}, fmt.Errorf("impossible ArrayOrString.Type: %q", arrayOrString.Type)
 	}
 }
 
-// ApplyReplacements applyes replacements for ArrayOrString type
-func (arrayOrString *ArrayOrString) ApplyReplacements(stringReplacements map[string]string, arrayReplacements map[string][]string) {
-	if arrayOrString.Type == ParamTypeString {
+// ApplyReplacements applyes replacements for ArrayOrString type. A string which consists only of
+// a whole object reference, e.g. "$(params.foo[*])", is replaced by the object from objectReplacements.
+func (arrayOrString *ArrayOrString) ApplyReplacements(stringReplacements map[string]string, arrayReplacements map[string][]string, objectReplacements map[string]map[string]string) {
+	switch arrayOrString.Type {
+	case ParamTypeString:
+		for k, v := range objectReplacements {
+			if arrayOrString.StringVal == fmt.Sprintf("$(%s[*])", k) {
+				arrayOrString.Type = ParamTypeObject
+				arrayOrString.StringVal = ""
+				arrayOrString.ObjectVal = make(map[string]string, len(v))
+				for key, value := range v {
+					arrayOrString.ObjectVal[key] = value
+				}
+				return
+			}
+		}
 		arrayOrString.StringVal = ApplyReplacements(arrayOrString.StringVal, stringReplacements)
-	} else {
+	case ParamTypeObject:
+		for k, v := range arrayOrString.ObjectVal {
+			arrayOrString.ObjectVal[k] = ApplyReplacements(v, stringReplacements)
+		}
+	default:
 		var newArrayVal []string
 		for _, v := range arrayOrString.ArrayVal {
 			newArrayVal = append(newArrayVal, ApplyArrayReplacements(v, stringReplacements, arrayReplacements)...)
@@ -145,15 +191,30 @@ func NewArrayOrString(value string, values ...string) *ArrayOrString {
 	}
 }
 
-func validatePipelineParametersVariablesInTaskParameters(params []Param, prefix string, paramNames sets.String, arrayParamNames sets.String) *apis.FieldError {
+// NewObject creates an ArrayOrString of type ParamTypeObject holding the given key-value pairs.
+func NewObject(pairs map[string]string) *ArrayOrString {
+	return &ArrayOrString{
+		Type:      ParamTypeObject,
+		ObjectVal: pairs,
+	}
+}
+
+func validatePipelineParametersVariablesInTaskParameters(params []Param, prefix string, paramNames sets.String, arrayParamNames sets.String, objectParamKeys map[string]sets.String) *apis.FieldError {
 	for _, param := range params {
-		if param.Value.Type == ParamTypeString {
-			if err := validateStringVariableInTaskParameters(fmt.Sprintf("[%s]", param.Name), param.Value.StringVal, prefix, paramNames, arrayParamNames); err != nil {
+		switch param.Value.Type {
+		case ParamTypeString:
+			if err := validateStringVariableInTaskParameters(fmt.Sprintf("[%s]", param.Name), param.Value.StringVal, prefix, paramNames, arrayParamNames, objectParamKeys); err != nil {
 				return err
 			}
-		} else {
+		case ParamTypeObject:
+			for _, key := range sets.StringKeySet(param.Value.ObjectVal).List() {
+				if err := validateObjectVariableInTaskParameters(fmt.Sprintf("[%s.%s]", param.Name, key), param.Value.ObjectVal[key], prefix, paramNames, arrayParamNames, objectParamKeys); err != nil {
+					return err
+				}
+			}
+		default:
 			for _, arrayElement := range param.Value.ArrayVal {
-				if err := validateArrayVariableInTaskParameters(fmt.Sprintf("[%s]", param.Name), arrayElement, prefix, paramNames, arrayParamNames); err != nil {
+				if err := validateArrayVariableInTaskParameters(fmt.Sprintf("[%s]", param.Name), arrayElement, prefix, paramNames, arrayParamNames, objectParamKeys); err != nil {
 					return err
 				}
 			}
@@ -162,22 +223,41 @@ func validatePipelineParametersVariablesInTaskParameters(params []Param, prefix
 	return nil
 }
 
-func validateStringVariableInTaskParameters(name, value, prefix string, stringVars sets.String, arrayVars sets.String) *apis.FieldError {
+func validateStringVariableInTaskParameters(name, value, prefix string, stringVars sets.String, arrayVars sets.String, objectKeys map[string]sets.String) *apis.FieldError {
 	if err := substitution.ValidateVariable(name, value, prefix, "task parameter", "pipelinespec.params", stringVars); err != nil {
 		return err
 	}
 	if err := substitution.ValidateVariableProhibited(name, value, prefix, "task parameter", "pipelinespec.params", arrayVars); err != nil {
 		return err
 	}
+	if err := substitution.ValidateVariableObjectKeysOrIsolated(name, value, prefix, "task parameter", "pipelinespec.params", objectKeys); err != nil {
+		return err
+	}
 	return nil
 }
 
-func validateArrayVariableInTaskParameters(name, value, prefix string, stringVars sets.String, arrayVars sets.String) *apis.FieldError {
+func validateArrayVariableInTaskParameters(name, value, prefix string, stringVars sets.String, arrayVars sets.String, objectKeys map[string]sets.String) *apis.FieldError {
 	if err := substitution.ValidateVariable(name, value, prefix, "task parameter", "pipelinespec.params", stringVars); err != nil {
 		return err
 	}
 	if err := substitution.ValidateVariableIsolated(name, value, prefix, "task parameter", "pipelinespec.params", arrayVars); err != nil {
 		return err
 	}
+	if err := substitution.ValidateVariableObjectKeys(name, value, prefix, "task parameter", "pipelinespec.params", objectKeys); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateObjectVariableInTaskParameters(name, value, prefix string, stringVars sets.String, arrayVars sets.String, objectKeys map[string]sets.String) *apis.FieldError {
+	if err := substitution.ValidateVariable(name, value, prefix, "task parameter", "pipelinespec.params", stringVars); err != nil {
+		return err
+	}
+	if err := substitution.ValidateVariableProhibited(name, value, prefix, "task parameter", "pipelinespec.params", arrayVars); err != nil {
+		return err
+	}
+	if err := substitution.ValidateVariableObjectKeys(name, value, prefix, "task parameter", "pipelinespec.params", objectKeys); err != nil {
+		return err
+	}
 	return nil
 }
@@ -83,6 +83,7 @@ func TestArrayOrString_ApplyReplacements(t *testing.T) {
 		input              *v1beta1.ArrayOrString
 		stringReplacements map[string]string
 		arrayReplacements  map[string][]string
+		objectReplacements map[string]map[string]string
 	}
 	tests := []struct {
 		name           string
@@ -128,10 +129,33 @@ func TestArrayOrString_ApplyReplacements(t *testing.T) {
 			arrayReplacements:  map[string][]string{"arraykey": {}},
 		},
 		expectedOutput: v1beta1.NewArrayOrString("firstvalue", "lastvalue"),
+	}, {
+		name: "string replacements on object",
+		args: args{
+			input:              v1beta1.NewObject(map[string]string{"url": "$(params.image.registry)/app", "digest": "$(params.digest)"}),
+			stringReplacements: map[string]string{"params.image.registry": "gcr.io", "params.digest": "sha256:1234"},
+		},
+		expectedOutput: v1beta1.NewObject(map[string]string{"url": "gcr.io/app", "digest": "sha256:1234"}),
+	}, {
+		name: "whole object replacement",
+		args: args{
+			input:              v1beta1.NewArrayOrString("$(params.image[*])"),
+			stringReplacements: map[string]string{"params.image.registry": "gcr.io"},
+			objectReplacements: map[string]map[string]string{"params.image": {"registry": "gcr.io", "digest": "sha256:1234"}},
+		},
+		expectedOutput: v1beta1.NewObject(map[string]string{"registry": "gcr.io", "digest": "sha256:1234"}),
+	}, {
+		name: "object key replacement on string",
+		args: args{
+			input:              v1beta1.NewArrayOrString("$(params.image.registry)/$(params.image[*])"),
+			stringReplacements: map[string]string{"params.image.registry": "gcr.io"},
+			objectReplacements: map[string]map[string]string{"params.image": {"registry": "gcr.io"}},
+		},
+		expectedOutput: v1beta1.NewArrayOrString("gcr.io/$(params.image[*])"),
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tt.args.input.ApplyReplacements(tt.args.stringReplacements, tt.args.arrayReplacements)
+			tt.args.input.ApplyReplacements(tt.args.stringReplacements, tt.args.arrayReplacements, tt.args.objectReplacements)
 			if d := cmp.Diff(tt.expectedOutput, tt.args.input); d != "" {
 				t.Errorf("ApplyReplacements() output did not match expected value %s", diff.PrintWantGot(d))
 			}
@@ -153,6 +177,7 @@ func TestArrayOrString_UnmarshalJSON(t *testing.T) {
 		{"{\"val\":[]}", v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{}}},
 		{"{\"val\":[\"oneelement\"]}", v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"oneelement"}}},
 		{"{\"val\":[\"multiple\", \"elements\"]}", *v1beta1.NewArrayOrString("multiple", "elements")},
+		{"{\"val\":{\"key\": \"value\"}}", *v1beta1.NewObject(map[string]string{"key": "value"})},
 	}
 
 	for _, c := range cases {
@@ -174,6 +199,7 @@ func TestArrayOrString_MarshalJSON(t *testing.T) {
 		{*v1beta1.NewArrayOrString("123"), "{\"val\":\"123\"}"},
 		{*v1beta1.NewArrayOrString("123", "1234"), "{\"val\":[\"123\",\"1234\"]}"},
 		{*v1beta1.NewArrayOrString("a", "a", "a"), "{\"val\":[\"a\",\"a\",\"a\"]}"},
+		{*v1beta1.NewObject(map[string]string{"key": "value"}), "{\"val\":{\"key\":\"value\"}}"},
 	}
 
 	for _, c := range cases {
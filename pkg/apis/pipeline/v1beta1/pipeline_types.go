@@ -75,6 +75,10 @@ type PipelineSpec struct {
 	// i.e. either after all Tasks are finished executing successfully
 	// or after a failure which would result in ending the Pipeline
 	Finally []PipelineTask `json:"finally,omitempty"`
+	// MaxParallel is the maximum number of TaskRuns and child PipelineRuns of the Pipeline which
+	// run at once, the others are queued until some are done. There is no limit if it is not set.
+	// +optional
+	MaxParallel int `json:"maxParallel,omitempty"`
 }
 
 // PipelineResult used to describe the results of a pipeline
@@ -123,6 +127,16 @@ type PipelineTask struct {
 	// +optional
 	TaskSpec *EmbeddedTask `json:"taskSpec,inline,omitempty"`
 
+	// PipelineRef is a reference to a pipeline definition, run as a child PipelineRun
+	// instead of a TaskRun.
+	// +optional
+	PipelineRef *PipelineRef `json:"pipelineRef,omitempty"`
+
+	// PipelineSpec is a specification of a pipeline, run as a child PipelineRun
+	// instead of a TaskRun.
+	// +optional
+	PipelineSpec *PipelineSpec `json:"pipelineSpec,omitempty"`
+
 	// Conditions is a list of conditions that need to be true for the task to run
 	// Conditions are deprecated, use WhenExpressions instead
 	// +optional
@@ -136,6 +150,21 @@ type PipelineTask struct {
 	// +optional
 	Retries int `json:"retries,omitempty"`
 
+	// RetryPolicy configures the delay between the retries and the failures the task is
+	// retried for. The task is retried right away on any failure by default.
+	// +optional
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+
+	// Cache reuses the results of a previous successful run of the task with the same
+	// resolved spec, params, input resources and step images instead of running it again
+	// +optional
+	Cache *TaskCache `json:"cache,omitempty"`
+
+	// Concurrency makes the TaskRun of the task wait for, or cancel, the TaskRuns of other
+	// PipelineRuns in the namespace holding the same concurrency key
+	// +optional
+	Concurrency *Concurrency `json:"concurrency,omitempty"`
+
 	// RunAfter is the list of PipelineTask names that should be executed before
 	// this Task executes. (Used to force a specific ordering in graph execution.)
 	// +optional
@@ -149,6 +178,12 @@ type PipelineTask struct {
 	// +optional
 	Params []Param `json:"params,omitempty"`
 
+	// Matrix declares array parameters to fan this task out over. One TaskRun is
+	// created for each combination of the values of the Matrix parameters, each
+	// receiving the combination as string parameters alongside Params.
+	// +optional
+	Matrix []Param `json:"matrix,omitempty"`
+
 	// Workspaces maps workspaces from the pipeline spec to the workspaces
 	// declared in the Task.
 	// +optional
@@ -169,6 +204,39 @@ func (pt PipelineTask) HashKey() string {
 	return pt.Name
 }
 
+// IsPipeline returns true if the PipelineTask runs a Pipeline, through PipelineRef or
+// PipelineSpec, instead of a Task
+func (pt PipelineTask) IsPipeline() bool {
+	return pt.PipelineRef != nil || pt.PipelineSpec != nil
+}
+
+// IsMatrixed returns true if the PipelineTask fans out over a Matrix of parameters
+func (pt PipelineTask) IsMatrixed() bool {
+	return len(pt.Matrix) > 0
+}
+
+// MatrixCombinations returns the combinations of the values of the Matrix parameters, each as a list
+// of string parameters. The combinations are ordered so that the values of the last Matrix parameter
+// vary fastest, which keeps the index of a combination stable for a given Matrix.
+func (pt PipelineTask) MatrixCombinations() [][]Param {
+	if !pt.IsMatrixed() {
+		return nil
+	}
+	combinations := [][]Param{{}}
+	for _, mp := range pt.Matrix {
+		var next [][]Param
+		for _, combination := range combinations {
+			for _, value := range mp.Value.ArrayVal {
+				c := make([]Param, len(combination), len(combination)+1)
+				copy(c, combination)
+				next = append(next, append(c, Param{Name: mp.Name, Value: *NewArrayOrString(value)}))
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
 func (pt PipelineTask) Deps() []string {
 	deps := []string{}
 	deps = append(deps, pt.RunAfter...)
@@ -202,6 +270,16 @@ func (pt PipelineTask) Deps() []string {
 			}
 		}
 	}
+	// Add any dependents from task results used in the matrix
+	for _, param := range pt.Matrix {
+		expressions, ok := GetVarSubstitutionExpressionsForParam(param)
+		if ok {
+			resultRefs := NewResultRefs(expressions)
+			for _, resultRef := range resultRefs {
+				deps = append(deps, resultRef.PipelineTask)
+			}
+		}
+	}
 	// Add any dependents from when expressions
 	for _, whenExpression := range pt.WhenExpressions {
 		expressions, ok := whenExpression.GetVarSubstitutionExpressions()
@@ -212,6 +290,8 @@ func (pt PipelineTask) Deps() []string {
 			}
 		}
 	}
+	// Add any dependents from the statuses read by when expressions
+	deps = append(deps, pt.WhenExpressions.TaskStatusRefs()...)
 	return deps
 }
 
@@ -0,0 +1,117 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/test/diff"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+func TestPipelineTask_MatrixCombinations(t *testing.T) {
+	tests := []struct {
+		name   string
+		matrix []Param
+		want   [][]Param
+	}{{
+		name:   "no matrix",
+		matrix: nil,
+		want:   nil,
+	}, {
+		name: "one parameter",
+		matrix: []Param{{
+			Name: "platform", Value: ArrayOrString{Type: ParamTypeArray, ArrayVal: []string{"linux", "mac"}},
+		}},
+		want: [][]Param{{
+			{Name: "platform", Value: ArrayOrString{Type: ParamTypeString, StringVal: "linux"}},
+		}, {
+			{Name: "platform", Value: ArrayOrString{Type: ParamTypeString, StringVal: "mac"}},
+		}},
+	}, {
+		name: "multiple parameters",
+		matrix: []Param{{
+			Name: "platform", Value: ArrayOrString{Type: ParamTypeArray, ArrayVal: []string{"linux", "mac"}},
+		}, {
+			Name: "browser", Value: ArrayOrString{Type: ParamTypeArray, ArrayVal: []string{"chrome", "safari", "firefox"}},
+		}},
+		want: [][]Param{{
+			{Name: "platform", Value: ArrayOrString{Type: ParamTypeString, StringVal: "linux"}},
+			{Name: "browser", Value: ArrayOrString{Type: ParamTypeString, StringVal: "chrome"}},
+		}, {
+			{Name: "platform", Value: ArrayOrString{Type: ParamTypeString, StringVal: "linux"}},
+			{Name: "browser", Value: ArrayOrString{Type: ParamTypeString, StringVal: "safari"}},
+		}, {
+			{Name: "platform", Value: ArrayOrString{Type: ParamTypeString, StringVal: "linux"}},
+			{Name: "browser", Value: ArrayOrString{Type: ParamTypeString, StringVal: "firefox"}},
+		}, {
+			{Name: "platform", Value: ArrayOrString{Type: ParamTypeString, StringVal: "mac"}},
+			{Name: "browser", Value: ArrayOrString{Type: ParamTypeString, StringVal: "chrome"}},
+		}, {
+			{Name: "platform", Value: ArrayOrString{Type: ParamTypeString, StringVal: "mac"}},
+			{Name: "browser", Value: ArrayOrString{Type: ParamTypeString, StringVal: "safari"}},
+		}, {
+			{Name: "platform", Value: ArrayOrString{Type: ParamTypeString, StringVal: "mac"}},
+			{Name: "browser", Value: ArrayOrString{Type: ParamTypeString, StringVal: "firefox"}},
+		}},
+	}, {
+		name: "empty parameter",
+		matrix: []Param{{
+			Name: "platform", Value: ArrayOrString{Type: ParamTypeArray, ArrayVal: []string{"linux", "mac"}},
+		}, {
+			Name: "browser", Value: ArrayOrString{Type: ParamTypeArray},
+		}},
+		want: nil,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pt := PipelineTask{Name: "task", Matrix: tt.matrix}
+			if d := cmp.Diff(tt.want, pt.MatrixCombinations()); d != "" {
+				t.Errorf("MatrixCombinations() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestPipelineTask_Deps_Matrix(t *testing.T) {
+	pt := PipelineTask{
+		Name: "task",
+		Matrix: []Param{{
+			Name: "platform", Value: ArrayOrString{Type: ParamTypeArray, ArrayVal: []string{"$(tasks.platforms.results.linux)", "mac"}},
+		}},
+	}
+	if d := cmp.Diff([]string{"platforms"}, pt.Deps()); d != "" {
+		t.Errorf("Deps() %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineTask_Deps_TaskStatus(t *testing.T) {
+	pt := PipelineTask{
+		Name: "task",
+		WhenExpressions: WhenExpressions{{
+			Input:    "$(tasks.build.status)",
+			Operator: selection.In,
+			Values:   []string{PipelineTaskStatusFailed},
+		}, {
+			CEL: "tasks['unit-test'].status != 'Failed'",
+		}},
+	}
+	if d := cmp.Diff([]string{"build", "unit-test"}, pt.Deps()); d != "" {
+		t.Errorf("Deps() %s", diff.PrintWantGot(d))
+	}
+}
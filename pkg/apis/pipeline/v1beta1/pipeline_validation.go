@@ -156,7 +156,7 @@ func (ps *PipelineSpec) Validate(ctx context.Context) *apis.FieldError {
 		return apis.ErrGeneric("expected at least one, got none", "spec.description", "spec.params", "spec.resources", "spec.tasks", "spec.workspaces")
 	}
 
-	// PipelineTask must have a valid unique label and at least one of taskRef or taskSpec should be specified
+	// PipelineTask must have a valid unique label and one of taskRef, taskSpec, pipelineRef or pipelineSpec should be specified
 	if err := validatePipelineTasks(ctx, ps.Tasks, ps.Finally); err != nil {
 		return err
 	}
@@ -200,7 +200,7 @@ func (ps *PipelineSpec) Validate(ctx context.Context) *apis.FieldError {
 	}
 
 	// Validate the pipeline's results
-	if err := validatePipelineResults(ps.Results); err != nil {
+	if err := validatePipelineResults(ps.Results, ps.Finally); err != nil {
 		return apis.ErrInvalidValue(err.Error(), "spec.tasks.params.value")
 	}
 
@@ -208,6 +208,10 @@ func (ps *PipelineSpec) Validate(ctx context.Context) *apis.FieldError {
 		return err
 	}
 
+	if ps.MaxParallel < 0 {
+		return apis.ErrInvalidValue(fmt.Sprintf("%d should be >= 0", ps.MaxParallel), "spec.maxParallel")
+	}
+
 	if err := validateFinalTasks(ps.Finally); err != nil {
 		return err
 	}
@@ -216,11 +220,15 @@ func (ps *PipelineSpec) Validate(ctx context.Context) *apis.FieldError {
 		return err
 	}
 
+	if err := validateMatrix(ps); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // validatePipelineTasks ensures that pipeline tasks has unique label, pipeline tasks has specified one of
-// taskRef or taskSpec, and in case of a pipeline task with taskRef, it has a reference to a valid task (task name)
+// taskRef, taskSpec, pipelineRef or pipelineSpec, and in case of a pipeline task with taskRef, it has a reference to a valid task (task name)
 func validatePipelineTasks(ctx context.Context, tasks []PipelineTask, finalTasks []PipelineTask) *apis.FieldError {
 	// Names cannot be duplicated
 	taskNames := sets.NewString()
@@ -247,13 +255,17 @@ func validatePipelineTaskName(ctx context.Context, prefix string, i int, t Pipel
 				"For more info refer to https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names",
 		}
 	}
+	if t.IsPipeline() {
+		return validatePipelineTaskPipeline(ctx, prefix, i, t, taskNames)
+	}
 	// can't have both taskRef and taskSpec at the same time
 	if (t.TaskRef != nil && t.TaskRef.Name != "") && t.TaskSpec != nil {
 		return apis.ErrMultipleOneOf(fmt.Sprintf(prefix+"[%d].taskRef", i), fmt.Sprintf(prefix+"[%d].taskSpec", i))
 	}
-	// Check that one of TaskRef and TaskSpec is present
+	// Check that one of TaskRef, TaskSpec, PipelineRef and PipelineSpec is present
 	if (t.TaskRef == nil || (t.TaskRef != nil && t.TaskRef.Name == "")) && t.TaskSpec == nil {
-		return apis.ErrMissingOneOf(fmt.Sprintf(prefix+"[%d].taskRef", i), fmt.Sprintf(prefix+"[%d].taskSpec", i))
+		return apis.ErrMissingOneOf(fmt.Sprintf(prefix+"[%d].taskRef", i), fmt.Sprintf(prefix+"[%d].taskSpec", i),
+			fmt.Sprintf(prefix+"[%d].pipelineRef", i), fmt.Sprintf(prefix+"[%d].pipelineSpec", i))
 	}
 	// Validate TaskSpec if it's present
 	if t.TaskSpec != nil {
@@ -261,6 +273,21 @@ func validatePipelineTaskName(ctx context.Context, prefix string, i int, t Pipel
 			return err
 		}
 	}
+	if t.RetryPolicy != nil {
+		if err := t.RetryPolicy.validate(t.Retries); err != nil {
+			return err.ViaField(fmt.Sprintf(prefix+"[%d].retryPolicy", i))
+		}
+	}
+	if t.Cache != nil {
+		if err := t.Cache.validate(); err != nil {
+			return err.ViaField(fmt.Sprintf(prefix+"[%d].cache", i))
+		}
+	}
+	if t.Concurrency != nil {
+		if err := t.Concurrency.validate(); err != nil {
+			return err.ViaField(fmt.Sprintf(prefix+"[%d].concurrency", i))
+		}
+	}
 	if t.TaskRef != nil && t.TaskRef.Name != "" {
 		// Task names are appended to the container name, which must exist and
 		// must be a valid k8s name
@@ -271,6 +298,12 @@ func validatePipelineTaskName(ctx context.Context, prefix string, i int, t Pipel
 		if errSlice := validation.IsQualifiedName(t.TaskRef.Name); len(errSlice) != 0 {
 			return apis.ErrInvalidValue(strings.Join(errSlice, ","), fmt.Sprintf(prefix+"[%d].taskRef.name", i))
 		}
+		if err := t.TaskRef.ResolverRef.validate(); err != nil {
+			return err.ViaField(fmt.Sprintf(prefix+"[%d].taskRef", i))
+		}
+		if err := validateBundle(t.TaskRef.Bundle, t.TaskRef.ResolverRef); err != nil {
+			return err.ViaField(fmt.Sprintf(prefix+"[%d].taskRef", i))
+		}
 		if _, ok := taskNames[t.Name]; ok {
 			return apis.ErrMultipleOneOf(fmt.Sprintf(prefix+"[%d].name", i))
 		}
@@ -279,6 +312,65 @@ func validatePipelineTaskName(ctx context.Context, prefix string, i int, t Pipel
 	return nil
 }
 
+// validatePipelineTaskPipeline validates a pipeline task which runs a Pipeline instead of a Task: it must
+// specify only one of pipelineRef or pipelineSpec, and none of the fields which only apply to a TaskRun
+func validatePipelineTaskPipeline(ctx context.Context, prefix string, i int, t PipelineTask, taskNames sets.String) *apis.FieldError {
+	if (t.TaskRef != nil && t.TaskRef.Name != "") || t.TaskSpec != nil {
+		return apis.ErrMultipleOneOf(fmt.Sprintf(prefix+"[%d].taskRef", i), fmt.Sprintf(prefix+"[%d].taskSpec", i),
+			fmt.Sprintf(prefix+"[%d].pipelineRef", i), fmt.Sprintf(prefix+"[%d].pipelineSpec", i))
+	}
+	if t.PipelineRef != nil && t.PipelineSpec != nil {
+		return apis.ErrMultipleOneOf(fmt.Sprintf(prefix+"[%d].pipelineRef", i), fmt.Sprintf(prefix+"[%d].pipelineSpec", i))
+	}
+	if t.PipelineRef != nil {
+		if t.PipelineRef.Name == "" {
+			return apis.ErrMissingField(fmt.Sprintf(prefix+"[%d].pipelineRef.name", i))
+		}
+		// PipelineRef name must be a valid k8s name
+		if errSlice := validation.IsQualifiedName(t.PipelineRef.Name); len(errSlice) != 0 {
+			return apis.ErrInvalidValue(strings.Join(errSlice, ","), fmt.Sprintf(prefix+"[%d].pipelineRef.name", i))
+		}
+		if err := t.PipelineRef.ResolverRef.validate(); err != nil {
+			return err.ViaField(fmt.Sprintf(prefix+"[%d].pipelineRef", i))
+		}
+		if err := validateBundle(t.PipelineRef.Bundle, t.PipelineRef.ResolverRef); err != nil {
+			return err.ViaField(fmt.Sprintf(prefix+"[%d].pipelineRef", i))
+		}
+	}
+	if t.PipelineSpec != nil {
+		if err := t.PipelineSpec.Validate(ctx); err != nil {
+			return err
+		}
+	}
+	// conditions, resources, matrix, retries, retry policies, caches and concurrency are only supported for pipeline tasks running a Task
+	if len(t.Conditions) > 0 {
+		return apis.ErrDisallowedFields(fmt.Sprintf(prefix+"[%d].conditions", i))
+	}
+	if t.Resources != nil {
+		return apis.ErrDisallowedFields(fmt.Sprintf(prefix+"[%d].resources", i))
+	}
+	if t.IsMatrixed() {
+		return apis.ErrDisallowedFields(fmt.Sprintf(prefix+"[%d].matrix", i))
+	}
+	if t.Retries != 0 {
+		return apis.ErrDisallowedFields(fmt.Sprintf(prefix+"[%d].retries", i))
+	}
+	if t.RetryPolicy != nil {
+		return apis.ErrDisallowedFields(fmt.Sprintf(prefix+"[%d].retryPolicy", i))
+	}
+	if t.Cache != nil {
+		return apis.ErrDisallowedFields(fmt.Sprintf(prefix+"[%d].cache", i))
+	}
+	if t.Concurrency != nil {
+		return apis.ErrDisallowedFields(fmt.Sprintf(prefix+"[%d].concurrency", i))
+	}
+	if taskNames.Has(t.Name) {
+		return apis.ErrMultipleOneOf(fmt.Sprintf(prefix+"[%d].name", i))
+	}
+	taskNames.Insert(t.Name)
+	return nil
+}
+
 // validatePipelineWorkspaces validates the specified workspaces, ensuring having unique name without any empty string,
 // and validates that all the referenced workspaces (by pipeline tasks) are specified in the pipeline
 func validatePipelineWorkspaces(wss []PipelineWorkspaceDeclaration, pts []PipelineTask, finalTasks []PipelineTask) *apis.FieldError {
@@ -320,11 +412,12 @@ func validatePipelineWorkspaces(wss []PipelineWorkspaceDeclaration, pts []Pipeli
 }
 
 // validatePipelineParameterVariables validates parameters with those specified by each pipeline task,
-// (1) it validates the type of parameter is either string or array (2) parameter default value matches
+// (1) it validates the type of parameter is either string, array or object (2) parameter default value matches
 // with the type of that param (3) ensures that the referenced param variable is defined is part of the param declarations
 func validatePipelineParameterVariables(tasks []PipelineTask, params []ParamSpec) *apis.FieldError {
 	parameterNames := sets.NewString()
 	arrayParameterNames := sets.NewString()
+	objectParameterKeys := map[string]sets.String{}
 
 	for _, p := range params {
 		// Verify that p is a valid type.
@@ -350,25 +443,36 @@ func validatePipelineParameterVariables(tasks []PipelineTask, params []ParamSpec
 			}
 		}
 
+		if err := p.validateObjectType(); err != nil {
+			return err.ViaField("spec.params")
+		}
+
 		if parameterNames.Has(p.Name) {
 			return apis.ErrGeneric("parameter appears more than once", fmt.Sprintf("spec.params.%s", p.Name))
 		}
-		// Add parameter name to parameterNames, and to arrayParameterNames if type is array.
+		// Add parameter name to parameterNames, and to arrayParameterNames if type is array
+		// or to objectParameterKeys if type is object.
 		parameterNames.Insert(p.Name)
-		if p.Type == ParamTypeArray {
+		switch p.Type {
+		case ParamTypeArray:
 			arrayParameterNames.Insert(p.Name)
+		case ParamTypeObject:
+			objectParameterKeys[p.Name] = sets.StringKeySet(p.Properties)
 		}
 	}
 
-	return validatePipelineParametersVariables(tasks, "params", parameterNames, arrayParameterNames)
+	return validatePipelineParametersVariables(tasks, "params", parameterNames, arrayParameterNames, objectParameterKeys)
 }
 
-func validatePipelineParametersVariables(tasks []PipelineTask, prefix string, paramNames sets.String, arrayParamNames sets.String) *apis.FieldError {
+func validatePipelineParametersVariables(tasks []PipelineTask, prefix string, paramNames sets.String, arrayParamNames sets.String, objectParamKeys map[string]sets.String) *apis.FieldError {
 	for _, task := range tasks {
-		if err := validatePipelineParametersVariablesInTaskParameters(task.Params, prefix, paramNames, arrayParamNames); err != nil {
+		if err := validatePipelineParametersVariablesInTaskParameters(task.Params, prefix, paramNames, arrayParamNames, objectParamKeys); err != nil {
 			return err
 		}
-		if err := task.WhenExpressions.validatePipelineParametersVariables(prefix, paramNames, arrayParamNames); err != nil {
+		if err := validatePipelineParametersVariablesInTaskParameters(task.Matrix, prefix, paramNames, arrayParamNames, objectParamKeys); err != nil {
+			return err
+		}
+		if err := task.WhenExpressions.validatePipelineParametersVariables(prefix, paramNames, arrayParamNames, objectParamKeys); err != nil {
 			return err
 		}
 	}
@@ -435,16 +539,27 @@ func filter(arr []string, cond func(string) bool) []string {
 	return result
 }
 
-// validatePipelineResults ensure that pipeline result variables are properly configured
-func validatePipelineResults(results []PipelineResult) error {
+// validatePipelineResults ensure that pipeline result variables are properly configured, and that
+// the finally tasks they reference exist
+func validatePipelineResults(results []PipelineResult, finalTasks []PipelineTask) error {
+	finalTaskNames := sets.NewString()
+	for _, f := range finalTasks {
+		finalTaskNames.Insert(f.Name)
+	}
 	for _, result := range results {
 		expressions, ok := GetVarSubstitutionExpressionsForPipelineResult(result)
 		if ok {
-			if LooksLikeContainsResultRefs(expressions) {
-				expressions = filter(expressions, looksLikeResultRef)
-				resultRefs := NewResultRefs(expressions)
-				if len(expressions) != len(resultRefs) {
-					return fmt.Errorf("expected all of the expressions %v to be result expressions but only %v were", expressions, resultRefs)
+			expressions = filter(expressions, looksLikePipelineResultRef)
+			if len(expressions) == 0 {
+				continue
+			}
+			resultRefs := NewPipelineResultRefs(expressions)
+			if len(expressions) != len(resultRefs) {
+				return fmt.Errorf("expected all of the expressions %v to be result expressions but only %v were", expressions, resultRefs)
+			}
+			for _, resultRef := range resultRefs {
+				if resultRef.Finally && !finalTaskNames.Has(resultRef.PipelineTask) {
+					return fmt.Errorf("pipeline result %q references result %q of %q which is not a finally task", result.Name, resultRef.Result, resultRef.PipelineTask)
 				}
 			}
 		}
@@ -485,7 +600,7 @@ func validateFinalTasks(finalTasks []PipelineTask) *apis.FieldError {
 
 func validateTaskResultReferenceNotUsed(tasks []PipelineTask) *apis.FieldError {
 	for _, t := range tasks {
-		for _, p := range t.Params {
+		for _, p := range append(append([]Param{}, t.Params...), t.Matrix...) {
 			expressions, ok := GetVarSubstitutionExpressionsForParam(p)
 			if ok {
 				if LooksLikeContainsResultRefs(expressions) {
@@ -522,6 +637,22 @@ func validateWhenExpressions(tasks []PipelineTask) *apis.FieldError {
 		if err := t.WhenExpressions.validate(); err != nil {
 			return err
 		}
+		if err := validateWhenExpressionsCEL(i, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateWhenExpressionsCEL type checks the CEL expressions of the when expressions of a pipeline task.
+func validateWhenExpressionsCEL(i int, t PipelineTask) *apis.FieldError {
+	for j, we := range t.WhenExpressions {
+		if we.CEL == "" {
+			continue
+		}
+		if err := we.checkCEL(); err != nil {
+			return apis.ErrInvalidValue(fmt.Sprintf("invalid cel expression %q: %v", we.CEL, err), fmt.Sprintf("spec.tasks[%d].when[%d].cel", i, j))
+		}
 	}
 	return nil
 }
@@ -533,3 +664,85 @@ func validateOneOfWhenExpressionsOrConditions(i int, t PipelineTask) *apis.Field
 	}
 	return nil
 }
+
+// validateMatrix ensures that the Matrix of each pipeline task only declares array parameters which are not
+// also passed in its Params, that it isn't combined with Conditions, and that no results of matrixed pipeline
+// tasks are referenced, since a matrixed pipeline task produces each of its results once per combination
+func validateMatrix(ps *PipelineSpec) *apis.FieldError {
+	matrixedTasks := sets.NewString()
+	for i, t := range ps.Tasks {
+		if err := validatePipelineTaskMatrix("spec.tasks", i, t); err != nil {
+			return err
+		}
+		if t.IsMatrixed() {
+			matrixedTasks.Insert(t.Name)
+		}
+	}
+	for i, t := range ps.Finally {
+		if err := validatePipelineTaskMatrix("spec.finally", i, t); err != nil {
+			return err
+		}
+	}
+	if matrixedTasks.Len() == 0 {
+		return nil
+	}
+
+	for i, t := range ps.Tasks {
+		var expressions []string
+		for _, param := range append(append([]Param{}, t.Params...), t.Matrix...) {
+			if e, ok := GetVarSubstitutionExpressionsForParam(param); ok {
+				expressions = append(expressions, e...)
+			}
+		}
+		for _, we := range t.WhenExpressions {
+			if e, ok := we.GetVarSubstitutionExpressions(); ok {
+				expressions = append(expressions, e...)
+			}
+		}
+		for _, resultRef := range NewResultRefs(expressions) {
+			if matrixedTasks.Has(resultRef.PipelineTask) {
+				return apis.ErrInvalidValue(fmt.Sprintf("pipeline task %q references result %q of matrixed pipeline task %q", t.Name, resultRef.Result, resultRef.PipelineTask),
+					fmt.Sprintf("spec.tasks[%d]", i))
+			}
+		}
+	}
+	for i, result := range ps.Results {
+		expressions, _ := GetVarSubstitutionExpressionsForPipelineResult(result)
+		for _, resultRef := range NewResultRefs(expressions) {
+			if matrixedTasks.Has(resultRef.PipelineTask) {
+				return apis.ErrInvalidValue(fmt.Sprintf("pipeline result %q references result %q of matrixed pipeline task %q", result.Name, resultRef.Result, resultRef.PipelineTask),
+					fmt.Sprintf("spec.results[%d].value", i))
+			}
+		}
+	}
+	return nil
+}
+
+func validatePipelineTaskMatrix(prefix string, i int, t PipelineTask) *apis.FieldError {
+	if !t.IsMatrixed() {
+		return nil
+	}
+	if len(t.Conditions) != 0 {
+		return apis.ErrMultipleOneOf(fmt.Sprintf(prefix+"[%d].matrix", i), fmt.Sprintf(prefix+"[%d].conditions", i))
+	}
+	if t.Concurrency != nil {
+		return apis.ErrMultipleOneOf(fmt.Sprintf(prefix+"[%d].matrix", i), fmt.Sprintf(prefix+"[%d].concurrency", i))
+	}
+	paramNames := sets.NewString()
+	for _, p := range t.Params {
+		paramNames.Insert(p.Name)
+	}
+	for j, mp := range t.Matrix {
+		if mp.Value.Type != ParamTypeArray {
+			return apis.ErrInvalidValue(fmt.Sprintf("matrix parameter %q must be an array", mp.Name), fmt.Sprintf(prefix+"[%d].matrix[%d]", i, j))
+		}
+		if len(mp.Value.ArrayVal) == 0 {
+			return apis.ErrInvalidValue(fmt.Sprintf("matrix parameter %q must not be empty", mp.Name), fmt.Sprintf(prefix+"[%d].matrix[%d]", i, j))
+		}
+		if paramNames.Has(mp.Name) {
+			return apis.ErrInvalidValue(fmt.Sprintf("parameter %q appears more than once in params and matrix", mp.Name), fmt.Sprintf(prefix+"[%d].matrix[%d]", i, j))
+		}
+		paramNames.Insert(mp.Name)
+	}
+	return nil
+}
@@ -38,6 +38,99 @@ func TestPipeline_Validate_Success(t *testing.T) {
 				Tasks: []PipelineTask{{Name: "foo", TaskRef: &TaskRef{Name: "foo-task"}}},
 			},
 		},
+	}, {
+		name: "valid pipeline with pipeline tasks running a pipeline",
+		p: &Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipeline"},
+			Spec: PipelineSpec{
+				Workspaces: []PipelineWorkspaceDeclaration{{Name: "source"}},
+				Tasks: []PipelineTask{{
+					Name:        "build-test-scan",
+					PipelineRef: &PipelineRef{Name: "build-test-scan"},
+					Params:      []Param{{Name: "revision", Value: *NewArrayOrString("main")}},
+					Workspaces:  []WorkspacePipelineTaskBinding{{Name: "src", Workspace: "source"}},
+				}, {
+					Name: "embedded",
+					PipelineSpec: &PipelineSpec{
+						Tasks: []PipelineTask{{Name: "foo", TaskRef: &TaskRef{Name: "foo-task"}}},
+					},
+				}, {
+					Name:    "publish",
+					TaskRef: &TaskRef{Name: "publish"},
+					Params:  []Param{{Name: "image", Value: *NewArrayOrString("$(tasks.build-test-scan.results.image)")}},
+				}},
+			},
+		},
+	}, {
+		name: "valid pipeline with matrix",
+		p: &Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipeline"},
+			Spec: PipelineSpec{
+				Params: []ParamSpec{{Name: "platforms", Type: ParamTypeArray}},
+				Tasks: []PipelineTask{{
+					Name:    "foo",
+					TaskRef: &TaskRef{Name: "foo-task"},
+					Params:  []Param{{Name: "version", Value: *NewArrayOrString("v1")}},
+					Matrix: []Param{{
+						Name: "platform", Value: ArrayOrString{Type: ParamTypeArray, ArrayVal: []string{"$(params.platforms)"}},
+					}, {
+						Name: "browser", Value: *NewArrayOrString("chrome", "firefox"),
+					}},
+				}},
+			},
+		},
+	}, {
+		name: "valid pipeline with max parallel",
+		p: &Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipeline"},
+			Spec: PipelineSpec{
+				Tasks:       []PipelineTask{{Name: "foo", TaskRef: &TaskRef{Name: "foo-task"}}},
+				MaxParallel: 2,
+			},
+		},
+	}, {
+		name: "valid pipeline with cel when expression",
+		p: &Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipeline"},
+			Spec: PipelineSpec{
+				Params: []ParamSpec{{Name: "env", Type: ParamTypeString}},
+				Tasks: []PipelineTask{{
+					Name:    "lint",
+					TaskRef: &TaskRef{Name: "lint-task"},
+				}, {
+					Name:    "deploy",
+					TaskRef: &TaskRef{Name: "deploy-task"},
+					WhenExpressions: []WhenExpression{{
+						CEL: "'$(tasks.lint.results.count)' == '0' && '$(params.env)' != 'prod'",
+					}},
+				}},
+			},
+		},
+	}, {
+		name: "valid pipeline with when expressions reading task statuses",
+		p: &Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "pipeline"},
+			Spec: PipelineSpec{
+				Tasks: []PipelineTask{{
+					Name:    "build",
+					TaskRef: &TaskRef{Name: "build-task"},
+				}, {
+					Name:    "notify",
+					TaskRef: &TaskRef{Name: "notify-task"},
+					WhenExpressions: []WhenExpression{{
+						Input:    "$(tasks.build.status)",
+						Operator: selection.In,
+						Values:   []string{PipelineTaskStatusFailed},
+					}},
+				}, {
+					Name:    "publish",
+					TaskRef: &TaskRef{Name: "publish-task"},
+					WhenExpressions: []WhenExpression{{
+						CEL: "tasks.build.status == 'Succeeded'",
+					}},
+				}},
+			},
+		},
 	}, {
 		name: "valid pipeline with params, resources, workspaces, task results, and pipeline results",
 		p: &Pipeline{
@@ -57,6 +150,15 @@ func TestPipeline_Validate_Success(t *testing.T) {
 					Name:    "my-task",
 					TaskRef: &TaskRef{Name: "foo-task"},
 					Retries: 5,
+					RetryPolicy: &RetryPolicy{
+						Delay:         &metav1.Duration{Duration: 10 * time.Second},
+						BackoffFactor: 2,
+						MaxDelay:      &metav1.Duration{Duration: 5 * time.Minute},
+						On:            []RetryReason{RetryReasonEvicted, RetryReasonOOMKilled},
+						ExitCodes:     []int32{137},
+					},
+					Cache:       &TaskCache{ConfigMap: "task-cache"},
+					Concurrency: &Concurrency{Key: "deploy-$(params.env)", Policy: ConcurrencyPolicyCancelPrevious},
 					Resources: &PipelineTaskResources{
 						Inputs: []PipelineTaskInputResource{{
 							Name:     "task-app-repo",
@@ -146,6 +248,12 @@ func TestPipelineSpec_Validate_Failure(t *testing.T) {
 		name string
 		ps   *PipelineSpec
 	}{{
+		name: "invalid pipeline with negative max parallel",
+		ps: &PipelineSpec{
+			Tasks:       []PipelineTask{{Name: "foo", TaskRef: &TaskRef{Name: "foo-task"}}},
+			MaxParallel: -1,
+		},
+	}, {
 		name: "invalid pipeline with one pipeline task having taskRef and taskSpec both",
 		ps: &PipelineSpec{
 			Description: "this is an invalid pipeline with invalid pipeline task",
@@ -176,7 +284,7 @@ func TestPipelineSpec_Validate_Failure(t *testing.T) {
 			}},
 		},
 	}, {
-		name: "invalid pipeline with one pipeline task having when expression with invalid operator (not In/NotIn)",
+		name: "invalid pipeline with one pipeline task having when expression with values for operator exists",
 		ps: &PipelineSpec{
 			Description: "this is an invalid pipeline with invalid pipeline task",
 			Tasks: []PipelineTask{{
@@ -246,6 +354,45 @@ func TestPipelineSpec_Validate_Failure(t *testing.T) {
 				}},
 			}},
 		},
+	}, {
+		name: "invalid pipeline with one pipeline task having cel when expression not evaluating to a boolean",
+		ps: &PipelineSpec{
+			Description: "this is an invalid pipeline with invalid pipeline task",
+			Tasks: []PipelineTask{{
+				Name:    "invalid-pipeline-task",
+				TaskRef: &TaskRef{Name: "bar-task"},
+				WhenExpressions: []WhenExpression{{
+					CEL: "'dev' + '-cluster'",
+				}},
+			}},
+		},
+	}, {
+		name: "invalid pipeline with one pipeline task having cel when expression using a variable outside string literals",
+		ps: &PipelineSpec{
+			Description: "this is an invalid pipeline with invalid pipeline task",
+			Tasks: []PipelineTask{{
+				Name:    "lint",
+				TaskRef: &TaskRef{Name: "lint-task"},
+			}, {
+				Name:    "invalid-pipeline-task",
+				TaskRef: &TaskRef{Name: "bar-task"},
+				WhenExpressions: []WhenExpression{{
+					CEL: "$(tasks.lint.results.count) == 0",
+				}},
+			}},
+		},
+	}, {
+		name: "invalid pipeline with one pipeline task having cel when expression with syntax error",
+		ps: &PipelineSpec{
+			Description: "this is an invalid pipeline with invalid pipeline task",
+			Tasks: []PipelineTask{{
+				Name:    "invalid-pipeline-task",
+				TaskRef: &TaskRef{Name: "bar-task"},
+				WhenExpressions: []WhenExpression{{
+					CEL: "'dev' != 'prod' &&",
+				}},
+			}},
+		},
 	}, {
 		name: "invalid pipeline with one pipeline task having blank when expression",
 		ps: &PipelineSpec{
@@ -324,6 +471,235 @@ func TestPipelineSpec_Validate_Failure(t *testing.T) {
 				Name: "bar", TaskRef: &TaskRef{Name: "bar-task"}, RunAfter: []string{"foo"},
 			}},
 		},
+	}, {
+		name: "invalid pipeline spec with a when expression reading the status of a pipeline task which does not exist",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name: "foo", TaskRef: &TaskRef{Name: "foo-task"},
+			}, {
+				Name:    "bar",
+				TaskRef: &TaskRef{Name: "bar-task"},
+				WhenExpressions: []WhenExpression{{
+					Input:    "$(tasks.baz.status)",
+					Operator: selection.In,
+					Values:   []string{PipelineTaskStatusFailed},
+				}},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with a cel when expression reading its own status",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:    "foo",
+				TaskRef: &TaskRef{Name: "foo-task"},
+				WhenExpressions: []WhenExpression{{
+					CEL: "tasks.foo.status == 'Failed'",
+				}},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with a cel when expression reading the tasks variable as a whole",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name: "foo", TaskRef: &TaskRef{Name: "foo-task"},
+			}, {
+				Name:    "bar",
+				TaskRef: &TaskRef{Name: "bar-task"},
+				WhenExpressions: []WhenExpression{{
+					CEL: "size(tasks) > 1",
+				}},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with both taskRef and pipelineRef",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:        "foo",
+				TaskRef:     &TaskRef{Name: "foo-task"},
+				PipelineRef: &PipelineRef{Name: "foo-pipeline"},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with both pipelineRef and pipelineSpec",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:        "foo",
+				PipelineRef: &PipelineRef{Name: "foo-pipeline"},
+				PipelineSpec: &PipelineSpec{
+					Tasks: []PipelineTask{{Name: "bar", TaskRef: &TaskRef{Name: "bar-task"}}},
+				},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with a taskRef using the git resolver without a url",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:    "foo",
+				TaskRef: &TaskRef{Name: "foo-task", ResolverRef: ResolverRef{Resolver: GitResolver}},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with a pipelineRef using an unknown resolver",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:        "foo",
+				PipelineRef: &PipelineRef{Name: "foo-pipeline", ResolverRef: ResolverRef{Resolver: "svn"}},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with a pipelineRef without a name",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:        "foo",
+				PipelineRef: &PipelineRef{},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with an invalid embedded pipelineSpec",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name: "foo",
+				PipelineSpec: &PipelineSpec{
+					Tasks: []PipelineTask{{Name: "bar"}},
+				},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with retries on a pipeline task running a pipeline",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:        "foo",
+				PipelineRef: &PipelineRef{Name: "foo-pipeline"},
+				Retries:     1,
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with a retry policy without retries",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:        "foo",
+				TaskRef:     &TaskRef{Name: "foo-task"},
+				RetryPolicy: &RetryPolicy{On: []RetryReason{RetryReasonEvicted}},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with a cache on a pipeline task running a pipeline",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:        "foo",
+				PipelineRef: &PipelineRef{Name: "foo-pipeline"},
+				Cache:       &TaskCache{},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with an invalid cache configmap",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:    "foo",
+				TaskRef: &TaskRef{Name: "foo-task"},
+				Cache:   &TaskCache{ConfigMap: "Task_Cache"},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with concurrency on a pipeline task running a pipeline",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:        "foo",
+				PipelineRef: &PipelineRef{Name: "foo-pipeline"},
+				Concurrency: &Concurrency{Key: "deploy"},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with a concurrency without key",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:        "foo",
+				TaskRef:     &TaskRef{Name: "foo-task"},
+				Concurrency: &Concurrency{Policy: ConcurrencyPolicyQueue},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with concurrency on a matrixed pipeline task",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:        "foo",
+				TaskRef:     &TaskRef{Name: "foo-task"},
+				Matrix:      []Param{{Name: "env", Value: *NewArrayOrString("dev", "prod")}},
+				Concurrency: &Concurrency{Key: "deploy"},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with duplicate names for pipeline tasks running a pipeline",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:        "foo",
+				PipelineRef: &PipelineRef{Name: "foo-pipeline"},
+			}, {
+				Name:    "foo",
+				TaskRef: &TaskRef{Name: "foo-task"},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with a string parameter in matrix",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:    "foo",
+				TaskRef: &TaskRef{Name: "foo-task"},
+				Matrix:  []Param{{Name: "platform", Value: *NewArrayOrString("linux")}},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with an empty array in matrix",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:    "foo",
+				TaskRef: &TaskRef{Name: "foo-task"},
+				Matrix:  []Param{{Name: "platform", Value: ArrayOrString{Type: ParamTypeArray, ArrayVal: []string{}}}},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with a parameter in both params and matrix",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:    "foo",
+				TaskRef: &TaskRef{Name: "foo-task"},
+				Params:  []Param{{Name: "platform", Value: *NewArrayOrString("linux")}},
+				Matrix:  []Param{{Name: "platform", Value: *NewArrayOrString("linux", "mac")}},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with both matrix and conditions",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:       "foo",
+				TaskRef:    &TaskRef{Name: "foo-task"},
+				Matrix:     []Param{{Name: "platform", Value: *NewArrayOrString("linux", "mac")}},
+				Conditions: []PipelineTaskCondition{{ConditionRef: "some-condition"}},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with a pipeline task consuming results of a matrixed pipeline task",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:    "foo",
+				TaskRef: &TaskRef{Name: "foo-task"},
+				Matrix:  []Param{{Name: "platform", Value: *NewArrayOrString("linux", "mac")}},
+			}, {
+				Name:    "bar",
+				TaskRef: &TaskRef{Name: "bar-task"},
+				Params:  []Param{{Name: "image", Value: *NewArrayOrString("$(tasks.foo.results.image)")}},
+			}},
+		},
+	}, {
+		name: "invalid pipeline spec with a pipeline result referencing results of a matrixed pipeline task",
+		ps: &PipelineSpec{
+			Tasks: []PipelineTask{{
+				Name:    "foo",
+				TaskRef: &TaskRef{Name: "foo-task"},
+				Matrix:  []Param{{Name: "platform", Value: *NewArrayOrString("linux", "mac")}},
+			}},
+			Results: []PipelineResult{{Name: "image", Value: "$(tasks.foo.results.image)"}},
+		},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -792,33 +1168,70 @@ func TestValidateParamResults_Failure(t *testing.T) {
 }
 
 func TestValidatePipelineResults_Success(t *testing.T) {
-	desc := "valid pipeline with valid pipeline results syntax"
-	results := []PipelineResult{{
-		Name:        "my-pipeline-result",
-		Description: "this is my pipeline result",
-		Value:       "$(tasks.a-task.results.output)",
+	finalTasks := []PipelineTask{{Name: "final-task", TaskRef: &TaskRef{Name: "foo-task"}}}
+	tests := []struct {
+		name    string
+		results []PipelineResult
+	}{{
+		name: "valid pipeline with valid pipeline results syntax",
+		results: []PipelineResult{{
+			Name:        "my-pipeline-result",
+			Description: "this is my pipeline result",
+			Value:       "$(tasks.a-task.results.output)",
+		}},
+	}, {
+		name: "valid pipeline result from a finally task",
+		results: []PipelineResult{{
+			Name:        "my-pipeline-result",
+			Description: "this is my pipeline result",
+			Value:       "report at $(finally.final-task.results.url)",
+		}},
 	}}
-	t.Run(desc, func(t *testing.T) {
-		err := validatePipelineResults(results)
-		if err != nil {
-			t.Errorf("Pipeline.validatePipelineResults() returned error for valid pipeline: %s: %v", desc, err)
-		}
-	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePipelineResults(tt.results, finalTasks)
+			if err != nil {
+				t.Errorf("Pipeline.validatePipelineResults() returned error for valid pipeline: %s: %v", tt.name, err)
+			}
+		})
+	}
 }
 
 func TestValidatePipelineResults_Failure(t *testing.T) {
-	desc := "invalid pipeline result reference"
-	results := []PipelineResult{{
-		Name:        "my-pipeline-result",
-		Description: "this is my pipeline result",
-		Value:       "$(tasks.a-task.results.output.output)",
+	finalTasks := []PipelineTask{{Name: "final-task", TaskRef: &TaskRef{Name: "foo-task"}}}
+	tests := []struct {
+		name    string
+		results []PipelineResult
+	}{{
+		name: "invalid pipeline result reference",
+		results: []PipelineResult{{
+			Name:        "my-pipeline-result",
+			Description: "this is my pipeline result",
+			Value:       "$(tasks.a-task.results.output.key.extra)",
+		}},
+	}, {
+		name: "invalid pipeline result reference to a finally task",
+		results: []PipelineResult{{
+			Name:        "my-pipeline-result",
+			Description: "this is my pipeline result",
+			Value:       "$(finally.final-task.result)",
+		}},
+	}, {
+		name: "pipeline result reference to a task which is not a finally task",
+		results: []PipelineResult{{
+			Name:        "my-pipeline-result",
+			Description: "this is my pipeline result",
+			Value:       "$(finally.a-task.results.output)",
+		}},
 	}}
-	t.Run(desc, func(t *testing.T) {
-		err := validatePipelineResults(results)
-		if err == nil {
-			t.Errorf("Pipeline.validatePipelineResults() did not return for invalid pipeline: %s", desc)
-		}
-	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePipelineResults(tt.results, finalTasks)
+			if err == nil {
+				t.Errorf("Pipeline.validatePipelineResults() did not return for invalid pipeline: %s", tt.name)
+			}
+		})
+	}
 }
 
 func TestValidatePipelineParameterVariables_Success(t *testing.T) {
@@ -900,6 +1313,30 @@ func TestValidatePipelineParameterVariables_Success(t *testing.T) {
 				Name: "a-param", Value: ArrayOrString{StringVal: "$(input.workspace.$(baz))"},
 			}},
 		}},
+	}, {
+		name: "valid object parameter variables",
+		params: []ParamSpec{{
+			Name: "image", Type: ParamTypeObject,
+			Properties: map[string]PropertySpec{"registry": {Type: ParamTypeString}, "digest": {Type: ParamTypeString}},
+		}},
+		tasks: []PipelineTask{{
+			Name:    "bar",
+			TaskRef: &TaskRef{Name: "bar-task"},
+			Params: []Param{{
+				Name: "a-param", Value: ArrayOrString{Type: ParamTypeString, StringVal: "$(params.image.registry)/app@$(params.image.digest)"},
+			}, {
+				Name: "an-array-param", Value: ArrayOrString{Type: ParamTypeArray, ArrayVal: []string{"$(params.image.registry)", "$(params.image.digest)"}},
+			}, {
+				Name: "an-object-param", Value: ArrayOrString{Type: ParamTypeObject, ObjectVal: map[string]string{"url": "$(params.image.registry)/app"}},
+			}, {
+				Name: "whole-object-param", Value: ArrayOrString{Type: ParamTypeString, StringVal: "$(params.image[*])"},
+			}},
+			WhenExpressions: []WhenExpression{{
+				Input:    "$(params.image.registry)",
+				Operator: selection.In,
+				Values:   []string{"gcr.io"},
+			}},
+		}},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -936,6 +1373,15 @@ func TestValidatePipelineParameterVariables_Failure(t *testing.T) {
 				Values:   []string{"foo"},
 			}},
 		}},
+	}, {
+		name: "invalid string parameter variables in cel when expression, missing param from the param declarations",
+		tasks: []PipelineTask{{
+			Name:    "bar",
+			TaskRef: &TaskRef{Name: "bar-task"},
+			WhenExpressions: []WhenExpression{{
+				CEL: "'$(params.baz)' != 'prod'",
+			}},
+		}},
 	}, {
 		name: "invalid string parameter variables in when expression, missing values param from the param declarations",
 		tasks: []PipelineTask{{
@@ -1109,6 +1555,56 @@ func TestValidatePipelineParameterVariables_Failure(t *testing.T) {
 			Name:    "foo",
 			TaskRef: &TaskRef{Name: "foo-task"},
 		}},
+	}, {
+		name: "object parameter without properties",
+		params: []ParamSpec{{
+			Name: "image", Type: ParamTypeObject,
+		}},
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{Name: "foo-task"},
+		}},
+	}, {
+		name: "object parameter referenced with undeclared key",
+		params: []ParamSpec{{
+			Name: "image", Type: ParamTypeObject,
+			Properties: map[string]PropertySpec{"registry": {Type: ParamTypeString}},
+		}},
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{Name: "foo-task"},
+			Params: []Param{{
+				Name: "a-param", Value: ArrayOrString{Type: ParamTypeString, StringVal: "$(params.image.digest)"},
+			}},
+		}},
+	}, {
+		name: "whole object parameter not isolated",
+		params: []ParamSpec{{
+			Name: "image", Type: ParamTypeObject,
+			Properties: map[string]PropertySpec{"registry": {Type: ParamTypeString}},
+		}},
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{Name: "foo-task"},
+			Params: []Param{{
+				Name: "a-param", Value: ArrayOrString{Type: ParamTypeString, StringVal: "image: $(params.image[*])"},
+			}},
+		}},
+	}, {
+		name: "object parameter referenced without key in when expression",
+		params: []ParamSpec{{
+			Name: "image", Type: ParamTypeObject,
+			Properties: map[string]PropertySpec{"registry": {Type: ParamTypeString}},
+		}},
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{Name: "foo-task"},
+			WhenExpressions: []WhenExpression{{
+				Input:    "$(params.image)",
+				Operator: selection.In,
+				Values:   []string{"gcr.io"},
+			}},
+		}},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
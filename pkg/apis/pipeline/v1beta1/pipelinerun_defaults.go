@@ -33,8 +33,13 @@ func (pr *PipelineRun) SetDefaults(ctx context.Context) {
 
 func (prs *PipelineRunSpec) SetDefaults(ctx context.Context) {
 	cfg := config.FromContextOrDefaults(ctx)
-	if prs.Timeout == nil {
-		prs.Timeout = &metav1.Duration{Duration: time.Duration(cfg.Defaults.DefaultTimeoutMinutes) * time.Minute}
+	defaultTimeout := &metav1.Duration{Duration: time.Duration(cfg.Defaults.DefaultTimeoutMinutes) * time.Minute}
+	if prs.Timeouts != nil {
+		if prs.Timeouts.Pipeline == nil {
+			prs.Timeouts.Pipeline = defaultTimeout
+		}
+	} else if prs.Timeout == nil {
+		prs.Timeout = defaultTimeout
 	}
 
 	defaultSA := cfg.Defaults.DefaultServiceAccount
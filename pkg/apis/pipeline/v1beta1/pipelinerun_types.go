@@ -98,6 +98,26 @@ func (pr *PipelineRun) IsCancelled() bool {
 	return pr.Spec.Status == PipelineRunSpecStatusCancelled
 }
 
+// IsGracefullyCancelled returns true if the PipelineRun's spec status is set to CancelledRunFinally state
+func (pr *PipelineRun) IsGracefullyCancelled() bool {
+	return pr.Spec.Status == PipelineRunSpecStatusCancelledRunFinally
+}
+
+// IsGracefullyStopped returns true if the PipelineRun's spec status is set to StoppedRunFinally state
+func (pr *PipelineRun) IsGracefullyStopped() bool {
+	return pr.Spec.Status == PipelineRunSpecStatusStoppedRunFinally
+}
+
+// IsPaused returns true if the PipelineRun's spec status is set to Paused state
+func (pr *PipelineRun) IsPaused() bool {
+	return pr.Spec.Status == PipelineRunSpecStatusPaused
+}
+
+// IsPending returns true if the PipelineRun's spec status is set to Pending state
+func (pr *PipelineRun) IsPending() bool {
+	return pr.Spec.Status == PipelineRunSpecStatusPending
+}
+
 // GetNamespacedName returns a k8s namespaced name that identifies this PipelineRun
 func (pr *PipelineRun) GetNamespacedName() types.NamespacedName {
 	return types.NamespacedName{Namespace: pr.Namespace, Name: pr.Name}
@@ -110,7 +130,7 @@ func (pr *PipelineRun) IsTimedOut() bool {
 
 // HasTimedOut returns true if a pipelinerun has exceeded its spec.Timeout based on its status.Timeout
 func (pr *PipelineRun) HasTimedOut() bool {
-	pipelineTimeout := pr.Spec.Timeout
+	pipelineTimeout := pr.PipelineTimeout()
 	startTime := pr.Status.StartTime
 
 	if !startTime.IsZero() && pipelineTimeout != nil {
@@ -126,6 +146,60 @@ func (pr *PipelineRun) HasTimedOut() bool {
 	return false
 }
 
+// PipelineTimeout returns the timeout of the whole pipelinerun, taken from spec.timeouts
+// if it is set and from spec.timeout otherwise
+func (pr *PipelineRun) PipelineTimeout() *metav1.Duration {
+	if pr.Spec.Timeouts != nil {
+		return pr.Spec.Timeouts.Pipeline
+	}
+	return pr.Spec.Timeout
+}
+
+// TasksTimeout returns the timeout of the tasks of the pipelinerun, or nil if they can
+// run as long as the pipelinerun itself. If only the pipeline and finally timeouts are
+// set, the tasks get what the finally tasks leave of the pipeline timeout.
+func (pr *PipelineRun) TasksTimeout() *metav1.Duration {
+	t := pr.Spec.Timeouts
+	if t == nil {
+		return nil
+	}
+	if t.Tasks != nil {
+		return t.Tasks
+	}
+	if t.Pipeline != nil && t.Pipeline.Duration != config.NoTimeoutDuration && t.Finally != nil {
+		return &metav1.Duration{Duration: t.Pipeline.Duration - t.Finally.Duration}
+	}
+	return nil
+}
+
+// FinallyTimeout returns the timeout of the finally tasks of the pipelinerun, or nil if
+// they can run as long as the pipelinerun itself
+func (pr *PipelineRun) FinallyTimeout() *metav1.Duration {
+	if pr.Spec.Timeouts == nil {
+		return nil
+	}
+	return pr.Spec.Timeouts.Finally
+}
+
+// HaveTasksTimedOut returns true if the tasks of a pipelinerun were still running when
+// their timeout elapsed, i.e. if they completed or the finally tasks could only start after it
+func (pr *PipelineRun) HaveTasksTimedOut() bool {
+	tasksTimeout := pr.TasksTimeout()
+	startTime := pr.Status.StartTime
+
+	if startTime.IsZero() || tasksTimeout == nil || tasksTimeout.Duration == config.NoTimeoutDuration {
+		return false
+	}
+	deadline := startTime.Add(tasksTimeout.Duration)
+	if pr.Status.TasksCompletionTime != nil {
+		return pr.Status.TasksCompletionTime.After(deadline)
+	}
+	if pr.Status.FinallyStartTime != nil {
+		return pr.Status.FinallyStartTime.After(deadline)
+	}
+	return time.Now().After(deadline)
+}
+
 // GetServiceAccountName returns the service account name for a given
 // PipelineTask if configured, otherwise it returns the PipelineRun's serviceAccountName.
 func (pr *PipelineRun) GetServiceAccountName(pipelineTaskName string) string {
@@ -167,13 +241,17 @@ type PipelineRunSpec struct {
 	// Deprecated: use taskRunSpecs.ServiceAccountName instead
 	// +optional
 	ServiceAccountNames []PipelineRunSpecServiceAccountName `json:"serviceAccountNames,omitempty"`
-	// Used for cancelling a pipelinerun (and maybe more later on)
+	// Used for cancelling, stopping or pausing a pipelinerun
 	// +optional
 	Status PipelineRunSpecStatus `json:"status,omitempty"`
 	// Time after which the Pipeline times out. Defaults to never.
 	// Refer to Go's ParseDuration documentation for expected format: https://golang.org/pkg/time/#ParseDuration
 	// +optional
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Timeouts allows to set the timeouts of the Pipeline, of its tasks and of its
+	// finally tasks separately. It cannot be used together with Timeout.
+	// +optional
+	Timeouts *TimeoutFields `json:"timeouts,omitempty"`
 	// PodTemplate holds pod specific configuration
 	PodTemplate *PodTemplate `json:"podTemplate,omitempty"`
 	// Workspaces holds a set of workspace bindings that must match names
@@ -183,6 +261,34 @@ type PipelineRunSpec struct {
 	// TaskRunSpecs holds a set of runtime specs
 	// +optional
 	TaskRunSpecs []PipelineTaskRunSpec `json:"taskRunSpecs,omitempty"`
+	// ResumeFrom is the name of a previous PipelineRun in the same namespace. The tasks
+	// which succeeded in it with the same resolved spec and params are not run again,
+	// their status and results are copied from it instead.
+	// +optional
+	ResumeFrom string `json:"resumeFrom,omitempty"`
+	// MaxParallel overrides the maximum number of TaskRuns and child PipelineRuns which run at once
+	// set by the Pipeline
+	// +optional
+	MaxParallel int `json:"maxParallel,omitempty"`
+	// Concurrency makes the PipelineRun wait for, or cancel, the other PipelineRuns in the
+	// namespace holding the same concurrency key, so that at most one of them runs at once
+	// +optional
+	Concurrency *Concurrency `json:"concurrency,omitempty"`
+}
+
+// TimeoutFields holds the timeouts of a PipelineRun
+type TimeoutFields struct {
+	// Pipeline sets the maximum allowed duration for the whole PipelineRun, including
+	// its finally tasks
+	// +optional
+	Pipeline *metav1.Duration `json:"pipeline,omitempty"`
+	// Tasks sets the maximum allowed duration for the tasks of the PipelineRun. Once it
+	// has elapsed, the tasks which did not start yet are skipped and the finally tasks run.
+	// +optional
+	Tasks *metav1.Duration `json:"tasks,omitempty"`
+	// Finally sets the maximum allowed duration for the finally tasks of the PipelineRun
+	// +optional
+	Finally *metav1.Duration `json:"finally,omitempty"`
 }
 
 // PipelineRunSpecStatus defines the pipelinerun spec status the user can provide
@@ -192,6 +298,22 @@ const (
 	// PipelineRunSpecStatusCancelled indicates that the user wants to cancel the task,
 	// if not already cancelled or terminated
 	PipelineRunSpecStatusCancelled = "PipelineRunCancelled"
+
+	// PipelineRunSpecStatusCancelledRunFinally indicates that the user wants to cancel the running
+	// tasks and skip the ones that did not start yet, but still run the final tasks
+	PipelineRunSpecStatusCancelledRunFinally = "CancelledRunFinally"
+
+	// PipelineRunSpecStatusStoppedRunFinally indicates that the user wants to let the running
+	// tasks finish and skip the ones that did not start yet, but still run the final tasks
+	PipelineRunSpecStatusStoppedRunFinally = "StoppedRunFinally"
+
+	// PipelineRunSpecStatusPaused indicates that the user wants to stop scheduling new tasks
+	// while the running ones finish, until the spec status is cleared
+	PipelineRunSpecStatusPaused = "PipelineRunPaused"
+
+	// PipelineRunSpecStatusPending indicates that the user wants to create the PipelineRun
+	// without starting it, until the spec status is cleared
+	PipelineRunSpecStatusPending = "PipelineRunPending"
 )
 
 // PipelineRef can be used to refer to a specific instance of a Pipeline.
@@ -202,6 +324,13 @@ type PipelineRef struct {
 	// API version of the referent
 	// +optional
 	APIVersion string `json:"apiVersion,omitempty"`
+	// Bundle is the reference of an OCI image, or Tekton bundle, from which the Pipeline named Name is
+	// retrieved instead of the cluster
+	// +optional
+	Bundle string `json:"bundle,omitempty"`
+	// ResolverRef fetches the Pipeline named Name from a remote location instead of the cluster
+	// +optional
+	ResolverRef `json:",inline"`
 }
 
 // PipelineRunStatus defines the observed state of PipelineRun
@@ -235,6 +364,23 @@ const (
 	// PipelineRunReasonStopping indicates that no new Tasks will be scheduled by the controller, and the
 	// pipeline will stop once all running tasks complete their work
 	PipelineRunReasonStopping PipelineRunReason = "PipelineRunStopping"
+	// PipelineRunReasonCancelledRunFinally is the reason set when the PipelineRun was cancelled by the user
+	// with the CancelledRunFinally spec status: corev1.ConditionUnknown while its final tasks are running,
+	// and corev1.ConditionFalse once they are done
+	PipelineRunReasonCancelledRunFinally PipelineRunReason = "CancelledRunFinally"
+	// PipelineRunReasonStoppedRunFinally is the reason set when the PipelineRun was stopped by the user
+	// with the StoppedRunFinally spec status: corev1.ConditionUnknown while its running and final tasks
+	// are running, and corev1.ConditionFalse once they are done
+	PipelineRunReasonStoppedRunFinally PipelineRunReason = "StoppedRunFinally"
+	// PipelineRunReasonPaused indicates that no new Tasks will be scheduled by the controller until
+	// the PipelineRun is resumed by clearing its spec status
+	PipelineRunReasonPaused PipelineRunReason = "Paused"
+	// PipelineRunReasonQueued indicates that the PipelineRun is waiting for another PipelineRun
+	// to release its concurrency key before scheduling any Task
+	PipelineRunReasonQueued PipelineRunReason = "Queued"
+	// PipelineRunReasonPending indicates that the PipelineRun was created with the PipelineRunPending
+	// spec status and does not start until it is cleared
+	PipelineRunReasonPending PipelineRunReason = "Pending"
 )
 
 func (t PipelineRunReason) String() string {
@@ -320,10 +466,24 @@ type PipelineRunStatusFields struct {
 	// +optional
 	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 
+	// FinallyStartTime is the time the finally tasks of the PipelineRun started.
+	// +optional
+	FinallyStartTime *metav1.Time `json:"finallyStartTime,omitempty"`
+
+	// TasksCompletionTime is the time the last of the tasks of the PipelineRun, excluding its
+	// finally tasks, completed.
+	// +optional
+	TasksCompletionTime *metav1.Time `json:"tasksCompletionTime,omitempty"`
+
 	// map of PipelineRunTaskRunStatus with the taskRun name as the key
 	// +optional
 	TaskRuns map[string]*PipelineRunTaskRunStatus `json:"taskRuns,omitempty"`
 
+	// map of PipelineRunPipelineRunStatus with the name of the child PipelineRun as the key,
+	// for the pipeline tasks which run a Pipeline instead of a Task
+	// +optional
+	PipelineRuns map[string]*PipelineRunPipelineRunStatus `json:"pipelineRuns,omitempty"`
+
 	// PipelineResults are the list of results written out by the pipeline task's containers
 	// +optional
 	PipelineResults []PipelineRunResult `json:"pipelineResults,omitempty"`
@@ -331,19 +491,43 @@ type PipelineRunStatusFields struct {
 	// PipelineRunSpec contains the exact spec used to instantiate the run
 	PipelineSpec *PipelineSpec `json:"pipelineSpec,omitempty"`
 
+	// Bundle is the reference, pinned by its digest, of the bundle the Pipeline was retrieved from
+	// +optional
+	Bundle string `json:"bundle,omitempty"`
+
 	// list of tasks that were skipped due to when expressions evaluating to false
 	// +optional
 	SkippedTasks []SkippedTask `json:"skippedTasks,omitempty"`
 }
 
-// SkippedTask is used to describe the Tasks that were skipped due to their When Expressions
-// evaluating to False. This is a struct because we are looking into including more details
-// about the When Expressions that caused this Task to be skipped.
+// SkippedTask is used to describe the Tasks that were skipped, for example due to their When Expressions
+// evaluating to False.
 type SkippedTask struct {
 	// Name is the Pipeline Task name
 	Name string `json:"name"`
+	// Reason is the cause of the Pipeline Task being skipped
+	// +optional
+	Reason SkippingReason `json:"reason,omitempty"`
+	// Message is a human readable description of why the Pipeline Task was skipped
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
+// SkippingReason explains why a Pipeline Task was skipped
+type SkippingReason string
+
+const (
+	// WhenExpressionsSkip means the Pipeline Task was skipped because one of its When Expressions,
+	// including CEL expressions, evaluated to False
+	WhenExpressionsSkip SkippingReason = "WhenExpressionsEvaluatedToFalse"
+	// ConditionChecksSkip means the Pipeline Task was skipped because one of its Condition Checks failed
+	ConditionChecksSkip SkippingReason = "ConditionChecksFailed"
+	// ParentTasksSkip means the Pipeline Task was skipped because one of its parent Tasks was skipped
+	ParentTasksSkip SkippingReason = "ParentTasksSkipped"
+	// StoppingSkip means the Pipeline Task was skipped because the PipelineRun is stopping
+	StoppingSkip SkippingReason = "PipelineRunStopping"
+)
+
 // PipelineRunResult used to describe the results of a pipeline
 type PipelineRunResult struct {
 	// Name is the result's name as declared by the Pipeline
@@ -363,6 +547,20 @@ type PipelineRunTaskRunStatus struct {
 	// ConditionChecks maps the name of a condition check to its Status
 	// +optional
 	ConditionChecks map[string]*PipelineRunConditionCheckStatus `json:"conditionChecks,omitempty"`
+	// ResumedFrom is the name of the PipelineRun the Status was copied from, when the
+	// PipelineTask was not run again because it succeeded in the resumed PipelineRun
+	// +optional
+	ResumedFrom string `json:"resumedFrom,omitempty"`
+}
+
+// PipelineRunPipelineRunStatus contains the name of the PipelineTask for this child PipelineRun and the
+// PipelineRun's Status
+type PipelineRunPipelineRunStatus struct {
+	// PipelineTaskName is the name of the PipelineTask.
+	PipelineTaskName string `json:"pipelineTaskName,omitempty"`
+	// Status is the PipelineRunStatus for the corresponding child PipelineRun
+	// +optional
+	Status *PipelineRunStatus `json:"status,omitempty"`
 }
 
 // PipelineRunConditionCheckStatus returns the condition check status
@@ -146,6 +146,53 @@ func TestPipelineRunIsCancelled(t *testing.T) {
 	}
 }
 
+func TestPipelineRunIsGracefullyCancelledOrStopped(t *testing.T) {
+	cancelled := &v1beta1.PipelineRun{
+		Spec: v1beta1.PipelineRunSpec{
+			Status: v1beta1.PipelineRunSpecStatusCancelledRunFinally,
+		},
+	}
+	if !cancelled.IsGracefullyCancelled() || cancelled.IsGracefullyStopped() || cancelled.IsCancelled() {
+		t.Fatal("Expected pipelinerun status to be gracefully cancelled only")
+	}
+	stopped := &v1beta1.PipelineRun{
+		Spec: v1beta1.PipelineRunSpec{
+			Status: v1beta1.PipelineRunSpecStatusStoppedRunFinally,
+		},
+	}
+	if !stopped.IsGracefullyStopped() || stopped.IsGracefullyCancelled() || stopped.IsCancelled() {
+		t.Fatal("Expected pipelinerun status to be gracefully stopped only")
+	}
+}
+
+func TestPipelineRunIsPaused(t *testing.T) {
+	pr := &v1beta1.PipelineRun{
+		Spec: v1beta1.PipelineRunSpec{
+			Status: v1beta1.PipelineRunSpecStatusPaused,
+		},
+	}
+	if !pr.IsPaused() {
+		t.Fatal("Expected pipelinerun status to be paused")
+	}
+	if pr.IsCancelled() {
+		t.Fatal("Expected paused pipelinerun not to be cancelled")
+	}
+}
+
+func TestPipelineRunIsPending(t *testing.T) {
+	pr := &v1beta1.PipelineRun{
+		Spec: v1beta1.PipelineRunSpec{
+			Status: v1beta1.PipelineRunSpecStatusPending,
+		},
+	}
+	if !pr.IsPending() {
+		t.Fatal("Expected pipelinerun status to be pending")
+	}
+	if pr.IsPaused() || pr.IsCancelled() {
+		t.Fatal("Expected pending pipelinerun not to be paused or cancelled")
+	}
+}
+
 func TestPipelineRunHasVolumeClaimTemplate(t *testing.T) {
 	pr := &v1beta1.PipelineRun{
 		Spec: v1beta1.PipelineRunSpec{
@@ -259,6 +306,93 @@ func TestPipelineRunHasTimedOut(t *testing.T) {
 	}
 }
 
+func TestPipelineRunHaveTasksTimedOut(t *testing.T) {
+	startTime := time.Now().Add(-time.Hour)
+	tcs := []struct {
+		name                string
+		timeouts            *v1beta1.TimeoutFields
+		finallyStartTime    *metav1.Time
+		tasksCompletionTime *metav1.Time
+		expected            bool
+	}{{
+		name:     "no timeouts",
+		expected: false,
+	}, {
+		name: "tasks timed out",
+		timeouts: &v1beta1.TimeoutFields{
+			Pipeline: &metav1.Duration{Duration: 2 * time.Hour},
+			Tasks:    &metav1.Duration{Duration: 30 * time.Minute},
+		},
+		expected: true,
+	}, {
+		name: "tasks not timed out",
+		timeouts: &v1beta1.TimeoutFields{
+			Pipeline: &metav1.Duration{Duration: 2 * time.Hour},
+			Tasks:    &metav1.Duration{Duration: 90 * time.Minute},
+		},
+		expected: false,
+	}, {
+		name: "tasks timeout from pipeline and finally timeouts",
+		timeouts: &v1beta1.TimeoutFields{
+			Pipeline: &metav1.Duration{Duration: 80 * time.Minute},
+			Finally:  &metav1.Duration{Duration: 30 * time.Minute},
+		},
+		expected: true,
+	}, {
+		name: "finally started before tasks timeout",
+		timeouts: &v1beta1.TimeoutFields{
+			Pipeline: &metav1.Duration{Duration: 2 * time.Hour},
+			Tasks:    &metav1.Duration{Duration: 30 * time.Minute},
+		},
+		finallyStartTime: &metav1.Time{Time: startTime.Add(10 * time.Minute)},
+		expected:         false,
+	}, {
+		name: "tasks completed before tasks timeout",
+		timeouts: &v1beta1.TimeoutFields{
+			Pipeline: &metav1.Duration{Duration: 2 * time.Hour},
+			Tasks:    &metav1.Duration{Duration: 30 * time.Minute},
+		},
+		tasksCompletionTime: &metav1.Time{Time: startTime.Add(10 * time.Minute)},
+		expected:            false,
+	}, {
+		name: "tasks completed after tasks timeout",
+		timeouts: &v1beta1.TimeoutFields{
+			Pipeline: &metav1.Duration{Duration: 2 * time.Hour},
+			Tasks:    &metav1.Duration{Duration: 30 * time.Minute},
+		},
+		finallyStartTime:    &metav1.Time{Time: startTime.Add(50 * time.Minute)},
+		tasksCompletionTime: &metav1.Time{Time: startTime.Add(40 * time.Minute)},
+		expected:            true,
+	}, {
+		name: "no tasks timeout",
+		timeouts: &v1beta1.TimeoutFields{
+			Pipeline: &metav1.Duration{Duration: 2 * time.Hour},
+			Tasks:    &metav1.Duration{Duration: 0},
+		},
+		expected: false,
+	}}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := &v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+				Spec: v1beta1.PipelineRunSpec{
+					Timeouts: tc.timeouts,
+				},
+				Status: v1beta1.PipelineRunStatus{PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+					StartTime:           &metav1.Time{Time: startTime},
+					FinallyStartTime:    tc.finallyStartTime,
+					TasksCompletionTime: tc.tasksCompletionTime,
+				}},
+			}
+
+			if pr.HaveTasksTimedOut() != tc.expected {
+				t.Fatalf("Expected HaveTasksTimedOut to be %t", tc.expected)
+			}
+		})
+	}
+}
+
 func TestPipelineRunGetServiceAccountName(t *testing.T) {
 	for _, tt := range []struct {
 		name    string
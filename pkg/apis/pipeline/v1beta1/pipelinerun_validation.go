@@ -19,19 +19,38 @@ package v1beta1
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/validate"
 	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 )
 
 var _ apis.Validatable = (*PipelineRun)(nil)
 
+var validPipelineRunSpecStatuses = []string{
+	PipelineRunSpecStatusCancelled,
+	PipelineRunSpecStatusCancelledRunFinally,
+	PipelineRunSpecStatusStoppedRunFinally,
+	PipelineRunSpecStatusPaused,
+	PipelineRunSpecStatusPending,
+}
+
 // Validate pipelinerun
 func (pr *PipelineRun) Validate(ctx context.Context) *apis.FieldError {
 	if err := validate.ObjectMetadata(pr.GetObjectMeta()).ViaField("metadata"); err != nil {
 		return err
 	}
+	if pr.Spec.ResumeFrom != "" && pr.Spec.ResumeFrom == pr.Name {
+		return apis.ErrInvalidValue(fmt.Sprintf("pipelinerun %s cannot resume from itself", pr.Name), "spec.resumeFrom")
+	}
+	if pr.IsPending() && pr.HasStarted() {
+		return apis.ErrInvalidValue(fmt.Sprintf("pipelinerun %s cannot be pending once it has started", pr.Name), "spec.status")
+	}
 	return pr.Spec.Validate(ctx)
 }
 
@@ -51,6 +70,15 @@ func (ps *PipelineRunSpec) Validate(ctx context.Context) *apis.FieldError {
 		return apis.ErrMissingField("spec.pipelineref.name", "spec.pipelinespec")
 	}
 
+	if ps.PipelineRef != nil {
+		if err := ps.PipelineRef.ResolverRef.validate(); err != nil {
+			return err.ViaField("spec.pipelineref")
+		}
+		if err := validateBundle(ps.PipelineRef.Bundle, ps.PipelineRef.ResolverRef); err != nil {
+			return err.ViaField("spec.pipelineref")
+		}
+	}
+
 	// Validate PipelineSpec if it's present
 	if ps.PipelineSpec != nil {
 		if err := ps.PipelineSpec.Validate(ctx); err != nil {
@@ -65,9 +93,34 @@ func (ps *PipelineRunSpec) Validate(ctx context.Context) *apis.FieldError {
 		}
 	}
 
+	if ps.Timeouts != nil {
+		if ps.Timeout != nil {
+			return apis.ErrMultipleOneOf("spec.timeout", "spec.timeouts")
+		}
+		if err := ps.Timeouts.validate(); err != nil {
+			return err.ViaField("spec.timeouts")
+		}
+	}
+
+	if ps.MaxParallel < 0 {
+		return apis.ErrInvalidValue(fmt.Sprintf("%d should be >= 0", ps.MaxParallel), "spec.maxParallel")
+	}
+
+	if ps.Concurrency != nil {
+		if err := ps.Concurrency.validate(); err != nil {
+			return err.ViaField("spec.concurrency")
+		}
+	}
+
+	if ps.ResumeFrom != "" {
+		if errs := validation.IsDNS1123Subdomain(ps.ResumeFrom); len(errs) > 0 {
+			return apis.ErrInvalidValue(fmt.Sprintf("%s is not a valid pipelinerun name: %s", ps.ResumeFrom, strings.Join(errs, ", ")), "spec.resumeFrom")
+		}
+	}
+
 	if ps.Status != "" {
-		if ps.Status != PipelineRunSpecStatusCancelled {
-			return apis.ErrInvalidValue(fmt.Sprintf("%s should be %s", ps.Status, PipelineRunSpecStatusCancelled), "spec.status")
+		if !sets.NewString(validPipelineRunSpecStatuses...).Has(string(ps.Status)) {
+			return apis.ErrInvalidValue(fmt.Sprintf("%s should be one of %s", ps.Status, strings.Join(validPipelineRunSpecStatuses, ", ")), "spec.status")
 		}
 	}
 
@@ -90,3 +143,30 @@ func (ps *PipelineRunSpec) Validate(ctx context.Context) *apis.FieldError {
 
 	return nil
 }
+
+// validate checks that the timeouts are valid durations of at least 0, and that the
+// tasks and the finally tasks fit in the pipeline timeout when it is set
+func (t *TimeoutFields) validate() *apis.FieldError {
+	for _, timeout := range []struct {
+		field    string
+		duration *metav1.Duration
+	}{{"pipeline", t.Pipeline}, {"tasks", t.Tasks}, {"finally", t.Finally}} {
+		if timeout.duration != nil && timeout.duration.Duration < 0 {
+			return apis.ErrInvalidValue(fmt.Sprintf("%s should be >= 0", timeout.duration.Duration.String()), timeout.field)
+		}
+	}
+
+	if t.Pipeline == nil || t.Pipeline.Duration == config.NoTimeoutDuration {
+		return nil
+	}
+	if t.Tasks != nil && (t.Tasks.Duration == config.NoTimeoutDuration || t.Tasks.Duration > t.Pipeline.Duration) {
+		return apis.ErrInvalidValue(fmt.Sprintf("%s should be <= pipeline timeout %s", t.Tasks.Duration.String(), t.Pipeline.Duration.String()), "tasks")
+	}
+	if t.Finally != nil && (t.Finally.Duration == config.NoTimeoutDuration || t.Finally.Duration > t.Pipeline.Duration) {
+		return apis.ErrInvalidValue(fmt.Sprintf("%s should be <= pipeline timeout %s", t.Finally.Duration.String(), t.Pipeline.Duration.String()), "finally")
+	}
+	if t.Tasks != nil && t.Finally != nil && t.Tasks.Duration+t.Finally.Duration > t.Pipeline.Duration {
+		return apis.ErrInvalidValue(fmt.Sprintf("%s + %s should be <= pipeline timeout %s", t.Tasks.Duration.String(), t.Finally.Duration.String(), t.Pipeline.Duration.String()), "tasks")
+	}
+	return nil
+}
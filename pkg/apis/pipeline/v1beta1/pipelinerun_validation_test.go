@@ -80,6 +80,91 @@ func TestPipelineRun_Invalidate(t *testing.T) {
 				},
 			},
 			want: apis.ErrInvalidValue("-48h0m0s should be >= 0", "spec.timeout"),
+		}, {
+			name: "timeout and timeouts",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					Timeout: &metav1.Duration{Duration: time.Hour},
+					Timeouts: &v1beta1.TimeoutFields{
+						Pipeline: &metav1.Duration{Duration: time.Hour},
+					},
+				},
+			},
+			want: apis.ErrMultipleOneOf("spec.timeout", "spec.timeouts"),
+		}, {
+			name: "negative finally timeout",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					Timeouts: &v1beta1.TimeoutFields{
+						Finally: &metav1.Duration{Duration: -time.Minute},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("-1m0s should be >= 0", "spec.timeouts.finally"),
+		}, {
+			name: "tasks timeout longer than pipeline timeout",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					Timeouts: &v1beta1.TimeoutFields{
+						Pipeline: &metav1.Duration{Duration: time.Hour},
+						Tasks:    &metav1.Duration{Duration: 2 * time.Hour},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("2h0m0s should be <= pipeline timeout 1h0m0s", "spec.timeouts.tasks"),
+		}, {
+			name: "no finally timeout with a pipeline timeout",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					Timeouts: &v1beta1.TimeoutFields{
+						Pipeline: &metav1.Duration{Duration: time.Hour},
+						Finally:  &metav1.Duration{Duration: 0},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("0s should be <= pipeline timeout 1h0m0s", "spec.timeouts.finally"),
+		}, {
+			name: "tasks and finally timeouts longer than pipeline timeout",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					Timeouts: &v1beta1.TimeoutFields{
+						Pipeline: &metav1.Duration{Duration: time.Hour},
+						Tasks:    &metav1.Duration{Duration: 40 * time.Minute},
+						Finally:  &metav1.Duration{Duration: 30 * time.Minute},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("40m0s + 30m0s should be <= pipeline timeout 1h0m0s", "spec.timeouts.tasks"),
 		}, {
 			name: "wrong pipelinerun cancel",
 			pr: v1beta1.PipelineRun{
@@ -93,7 +178,82 @@ func TestPipelineRun_Invalidate(t *testing.T) {
 					Status: "PipelineRunCancell",
 				},
 			},
-			want: apis.ErrInvalidValue("PipelineRunCancell should be PipelineRunCancelled", "spec.status"),
+			want: apis.ErrInvalidValue("PipelineRunCancell should be one of PipelineRunCancelled, CancelledRunFinally, StoppedRunFinally, PipelineRunPaused, PipelineRunPending", "spec.status"),
+		}, {
+			name: "pending once started",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelinename",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					Status: v1beta1.PipelineRunSpecStatusPending,
+				},
+				Status: v1beta1.PipelineRunStatus{
+					PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+						StartTime: &metav1.Time{Time: time.Now()},
+					},
+				},
+			},
+			want: apis.ErrInvalidValue("pipelinerun pipelinelinename cannot be pending once it has started", "spec.status"),
+		}, {
+			name: "resume from itself",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelinename",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					ResumeFrom: "pipelinelinename",
+				},
+			},
+			want: apis.ErrInvalidValue("pipelinerun pipelinelinename cannot resume from itself", "spec.resumeFrom"),
+		}, {
+			name: "resume from invalid name",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					ResumeFrom: "Failed_Run",
+				},
+			},
+			want: apis.ErrInvalidValue("Failed_Run is not a valid pipelinerun name: a DNS-1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')", "spec.resumeFrom"),
+		}, {
+			name: "negative max parallel",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelinename",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					MaxParallel: -1,
+				},
+			},
+			want: apis.ErrInvalidValue("-1 should be >= 0", "spec.maxParallel"),
+		}, {
+			name: "concurrency with an invalid policy",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelinename",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					Concurrency: &v1beta1.Concurrency{Key: "deploy", Policy: "wait"},
+				},
+			},
+			want: apis.ErrInvalidValue("wait should be one of queue, cancel-previous", "spec.concurrency.policy"),
 		},
 	}
 
@@ -137,6 +297,130 @@ func TestPipelineRun_Validate(t *testing.T) {
 					Timeout: &metav1.Duration{Duration: 0},
 				},
 			},
+		}, {
+			name: "timeouts",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					Timeouts: &v1beta1.TimeoutFields{
+						Pipeline: &metav1.Duration{Duration: time.Hour},
+						Tasks:    &metav1.Duration{Duration: 40 * time.Minute},
+						Finally:  &metav1.Duration{Duration: 20 * time.Minute},
+					},
+				},
+			},
+		}, {
+			name: "tasks timeout without pipeline timeout",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					Timeouts: &v1beta1.TimeoutFields{
+						Pipeline: &metav1.Duration{Duration: 0},
+						Tasks:    &metav1.Duration{Duration: 40 * time.Minute},
+					},
+				},
+			},
+		}, {
+			name: "paused",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					Status: v1beta1.PipelineRunSpecStatusPaused,
+				},
+			},
+		}, {
+			name: "pending",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					Status: v1beta1.PipelineRunSpecStatusPending,
+				},
+			},
+		}, {
+			name: "cancelled running finally",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					Status: v1beta1.PipelineRunSpecStatusCancelledRunFinally,
+				},
+			},
+		}, {
+			name: "stopped running finally",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					Status: v1beta1.PipelineRunSpecStatusStoppedRunFinally,
+				},
+			},
+		}, {
+			name: "resume from",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					ResumeFrom: "failed-run",
+				},
+			},
+		}, {
+			name: "max parallel",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					MaxParallel: 4,
+				},
+			},
+		}, {
+			name: "concurrency",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelineName",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					Concurrency: &v1beta1.Concurrency{Key: "deploy-$(params.env)", Policy: v1beta1.ConcurrencyPolicyQueue},
+				},
+			},
 		},
 	}
 
@@ -164,6 +448,28 @@ func TestPipelineRunSpec_Invalidate(t *testing.T) {
 			PipelineRef: &v1beta1.PipelineRef{},
 		},
 		wantErr: apis.ErrMissingField("spec.pipelineref.name", "spec.pipelinespec"),
+	}, {
+		name: "pipelineRef with git resolver without url",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{
+				Name:        "pipelinerefname",
+				ResolverRef: v1beta1.ResolverRef{Resolver: v1beta1.GitResolver},
+			},
+		},
+		wantErr: apis.ErrMissingField("spec.pipelineref.params.url"),
+	}, {
+		name: "pipelineRef with bundle and resolver",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{
+				Name:   "pipelinerefname",
+				Bundle: "gcr.io/tekton/catalog:v1",
+				ResolverRef: v1beta1.ResolverRef{
+					Resolver: v1beta1.GitResolver,
+					Params:   []v1beta1.Param{{Name: "url", Value: *v1beta1.NewArrayOrString("https://github.com/tektoncd/catalog")}},
+				},
+			},
+		},
+		wantErr: apis.ErrMultipleOneOf("spec.pipelineref.bundle", "spec.pipelineref.resolver"),
 	}, {
 		name: "pipelineRef and pipelineSpec together",
 		spec: v1beta1.PipelineRunSpec{
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+// ResolverName is the name of a resolver which fetches Tasks and Pipelines from a remote location
+type ResolverName string
+
+const (
+	// GitResolver fetches Tasks and Pipelines from the YAML files of a git repository
+	GitResolver ResolverName = "git"
+
+	// GitURLParam is the parameter of the git resolver holding the URL of the repository
+	GitURLParam = "url"
+	// GitRevisionParam is the parameter of the git resolver holding the revision to fetch,
+	// which defaults to the default branch of the repository
+	GitRevisionParam = "revision"
+	// GitPathParam is the parameter of the git resolver holding the path of the directory or
+	// file containing the YAML files in the repository, which defaults to the whole repository
+	GitPathParam = "path"
+
+	// HTTPResolver fetches a Task or Pipeline from the YAML file at an HTTP or HTTPS URL
+	HTTPResolver ResolverName = "http"
+
+	// HTTPURLParam is the parameter of the http resolver holding the URL of the YAML file
+	HTTPURLParam = "url"
+	// HTTPSHA256Param is the parameter of the http resolver holding the SHA-256 digest, hex encoded,
+	// which the contents of the YAML file must match
+	HTTPSHA256Param = "sha256"
+)
+
+// ResolverRef references a Task or Pipeline stored in a remote location, which is fetched by
+// a resolver instead of from the cluster
+type ResolverRef struct {
+	// Resolver is the name of the resolver fetching the Task or Pipeline: "git" or "http".
+	// +optional
+	Resolver ResolverName `json:"resolver,omitempty"`
+	// Params locate the Task or Pipeline for the resolver. The git resolver supports "url",
+	// "revision" and "path", the http resolver "url" and "sha256".
+	// +optional
+	Params []Param `json:"params,omitempty"`
+}
+
+// IsRemote returns true if the Task or Pipeline is fetched by a resolver
+func (r ResolverRef) IsRemote() bool {
+	return r.Resolver != ""
+}
+
+// ParamValue returns the value of the string parameter of the resolver with the given name,
+// or an empty string if there is none
+func (r ResolverRef) ParamValue(name string) string {
+	for _, p := range r.Params {
+		if p.Name == name {
+			return p.Value.StringVal
+		}
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/apis"
+)
+
+// validate checks that the resolver is supported and that it has the parameters it needs
+func (r ResolverRef) validate() *apis.FieldError {
+	if !r.IsRemote() {
+		if len(r.Params) != 0 {
+			return apis.ErrMissingField("resolver")
+		}
+		return nil
+	}
+	var supported sets.String
+	switch r.Resolver {
+	case GitResolver:
+		supported = sets.NewString(GitURLParam, GitRevisionParam, GitPathParam)
+	case HTTPResolver:
+		supported = sets.NewString(HTTPURLParam, HTTPSHA256Param)
+	default:
+		return apis.ErrInvalidValue(fmt.Sprintf("%s should be one of %s", r.Resolver, []ResolverName{GitResolver, HTTPResolver}), "resolver")
+	}
+	seen := sets.NewString()
+	for i, p := range r.Params {
+		if !supported.Has(p.Name) {
+			return apis.ErrInvalidValue(fmt.Sprintf("%s should be one of %s", p.Name, supported.List()), fmt.Sprintf("params[%d].name", i))
+		}
+		if seen.Has(p.Name) {
+			return apis.ErrGeneric(fmt.Sprintf("parameter %s of the %s resolver must be unique", p.Name, r.Resolver), fmt.Sprintf("params[%d].name", i))
+		}
+		seen.Insert(p.Name)
+		if p.Value.Type != "" && p.Value.Type != ParamTypeString {
+			return apis.ErrInvalidValue(fmt.Sprintf("parameter %s of the %s resolver should be a string", p.Name, r.Resolver), fmt.Sprintf("params[%d].value", i))
+		}
+	}
+	if r.ParamValue(GitURLParam) == "" {
+		return apis.ErrMissingField(fmt.Sprintf("params.%s", GitURLParam))
+	}
+	if r.Resolver == HTTPResolver {
+		return validateHTTPParams(r.ParamValue(HTTPURLParam), r.ParamValue(HTTPSHA256Param))
+	}
+	return nil
+}
+
+// validateHTTPParams checks that the http resolver fetches an HTTP or HTTPS URL, and that the
+// digest it checks, if any, is a hex encoded SHA-256 digest
+func validateHTTPParams(rawURL, digest string) *apis.FieldError {
+	if u, err := url.Parse(rawURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return apis.ErrInvalidValue(fmt.Sprintf("%s should be an http or https URL", rawURL), fmt.Sprintf("params.%s", HTTPURLParam))
+	}
+	if digest == "" {
+		return nil
+	}
+	if b, err := hex.DecodeString(digest); err != nil || len(b) != sha256.Size {
+		return apis.ErrInvalidValue(fmt.Sprintf("%s should be a hex encoded SHA-256 digest", digest), fmt.Sprintf("params.%s", HTTPSHA256Param))
+	}
+	return nil
+}
+
+// validateBundle checks that bundle is an image reference and that the reference does not
+// also use a resolver
+func validateBundle(bundle string, r ResolverRef) *apis.FieldError {
+	if bundle == "" {
+		return nil
+	}
+	if r.IsRemote() {
+		return apis.ErrMultipleOneOf("bundle", "resolver")
+	}
+	if _, err := name.ParseReference(bundle); err != nil {
+		return apis.ErrInvalidValue(fmt.Sprintf("%s should be an image reference", bundle), "bundle")
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/test/diff"
+	"knative.dev/pkg/apis"
+)
+
+func TestResolverRef_Valid(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  ResolverRef
+	}{{
+		name: "without resolver",
+		ref:  ResolverRef{},
+	}, {
+		name: "git resolver with url",
+		ref: ResolverRef{
+			Resolver: GitResolver,
+			Params:   []Param{{Name: "url", Value: *NewArrayOrString("https://github.com/tektoncd/catalog")}},
+		},
+	}, {
+		name: "git resolver with url, revision and path",
+		ref: ResolverRef{
+			Resolver: GitResolver,
+			Params: []Param{
+				{Name: "url", Value: *NewArrayOrString("https://github.com/tektoncd/catalog")},
+				{Name: "revision", Value: *NewArrayOrString("main")},
+				{Name: "path", Value: *NewArrayOrString("task/golang-build/0.1")},
+			},
+		},
+	}, {
+		name: "http resolver with url and sha256",
+		ref: ResolverRef{
+			Resolver: HTTPResolver,
+			Params: []Param{
+				{Name: "url", Value: *NewArrayOrString("https://artifacts.example.com/tasks/build.yaml")},
+				{Name: "sha256", Value: *NewArrayOrString("9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08")},
+			},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.ref.validate(); err != nil {
+				t.Errorf("ResolverRef.validate() returned an error for valid resolver: %v", err)
+			}
+		})
+	}
+}
+
+func TestResolverRef_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  ResolverRef
+		want *apis.FieldError
+	}{{
+		name: "params without resolver",
+		ref: ResolverRef{
+			Params: []Param{{Name: "url", Value: *NewArrayOrString("https://github.com/tektoncd/catalog")}},
+		},
+		want: apis.ErrMissingField("resolver"),
+	}, {
+		name: "unknown resolver",
+		ref:  ResolverRef{Resolver: "svn"},
+		want: apis.ErrInvalidValue("svn should be one of [git http]", "resolver"),
+	}, {
+		name: "git resolver without url",
+		ref: ResolverRef{
+			Resolver: GitResolver,
+			Params:   []Param{{Name: "revision", Value: *NewArrayOrString("main")}},
+		},
+		want: apis.ErrMissingField("params.url"),
+	}, {
+		name: "git resolver with unknown param",
+		ref: ResolverRef{
+			Resolver: GitResolver,
+			Params:   []Param{{Name: "branch", Value: *NewArrayOrString("main")}},
+		},
+		want: apis.ErrInvalidValue("branch should be one of [path revision url]", "params[0].name"),
+	}, {
+		name: "git resolver with duplicate param",
+		ref: ResolverRef{
+			Resolver: GitResolver,
+			Params: []Param{
+				{Name: "url", Value: *NewArrayOrString("https://github.com/tektoncd/catalog")},
+				{Name: "url", Value: *NewArrayOrString("https://github.com/tektoncd/pipeline")},
+			},
+		},
+		want: apis.ErrGeneric("parameter url of the git resolver must be unique", "params[1].name"),
+	}, {
+		name: "git resolver with array param",
+		ref: ResolverRef{
+			Resolver: GitResolver,
+			Params:   []Param{{Name: "url", Value: *NewArrayOrString("https://github.com/tektoncd/catalog", "https://github.com/tektoncd/pipeline")}},
+		},
+		want: apis.ErrInvalidValue("parameter url of the git resolver should be a string", "params[0].value"),
+	}, {
+		name: "http resolver with git param",
+		ref: ResolverRef{
+			Resolver: HTTPResolver,
+			Params: []Param{
+				{Name: "url", Value: *NewArrayOrString("https://artifacts.example.com/tasks/build.yaml")},
+				{Name: "revision", Value: *NewArrayOrString("main")},
+			},
+		},
+		want: apis.ErrInvalidValue("revision should be one of [sha256 url]", "params[1].name"),
+	}, {
+		name: "http resolver with another scheme",
+		ref: ResolverRef{
+			Resolver: HTTPResolver,
+			Params:   []Param{{Name: "url", Value: *NewArrayOrString("ftp://artifacts.example.com/tasks/build.yaml")}},
+		},
+		want: apis.ErrInvalidValue("ftp://artifacts.example.com/tasks/build.yaml should be an http or https URL", "params.url"),
+	}, {
+		name: "http resolver with invalid sha256",
+		ref: ResolverRef{
+			Resolver: HTTPResolver,
+			Params: []Param{
+				{Name: "url", Value: *NewArrayOrString("https://artifacts.example.com/tasks/build.yaml")},
+				{Name: "sha256", Value: *NewArrayOrString("abc123")},
+			},
+		},
+		want: apis.ErrInvalidValue("abc123 should be a hex encoded SHA-256 digest", "params.sha256"),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.ref.validate()
+			if err == nil {
+				t.Fatalf("Expected an error, got nothing for %v", tt.ref)
+			}
+			if d := cmp.Diff(tt.want.Error(), err.Error()); d != "" {
+				t.Errorf("ResolverRef.validate() errors diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestResolverRef_ParamValue(t *testing.T) {
+	ref := ResolverRef{
+		Resolver: GitResolver,
+		Params:   []Param{{Name: "url", Value: *NewArrayOrString("https://github.com/tektoncd/catalog")}},
+	}
+	if got := ref.ParamValue("url"); got != "https://github.com/tektoncd/catalog" {
+		t.Errorf("ParamValue(url) = %q, want %q", got, "https://github.com/tektoncd/catalog")
+	}
+	if got := ref.ParamValue("revision"); got != "" {
+		t.Errorf("ParamValue(revision) = %q, want an empty string", got)
+	}
+}
+
+func TestValidateBundle(t *testing.T) {
+	for _, bundle := range []string{"", "gcr.io/tekton/catalog:v1", "registry.example.com/catalog@sha256:" + strings.Repeat("a", 64)} {
+		if err := validateBundle(bundle, ResolverRef{}); err != nil {
+			t.Errorf("validateBundle(%q) returned an error for a valid bundle: %v", bundle, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		bundle string
+		ref    ResolverRef
+		want   *apis.FieldError
+	}{{
+		name:   "invalid image reference",
+		bundle: "gcr.io/tekton/Catalog:v1",
+		want:   apis.ErrInvalidValue("gcr.io/tekton/Catalog:v1 should be an image reference", "bundle"),
+	}, {
+		name:   "bundle with resolver",
+		bundle: "gcr.io/tekton/catalog:v1",
+		ref: ResolverRef{
+			Resolver: GitResolver,
+			Params:   []Param{{Name: "url", Value: *NewArrayOrString("https://github.com/tektoncd/catalog")}},
+		},
+		want: apis.ErrMultipleOneOf("bundle", "resolver"),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBundle(tt.bundle, tt.ref)
+			if err == nil {
+				t.Fatalf("Expected an error, got nothing for %s", tt.bundle)
+			}
+			if d := cmp.Diff(tt.want.Error(), err.Error()); d != "" {
+				t.Errorf("validateBundle() errors diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
@@ -26,16 +26,23 @@ import (
 type ResultRef struct {
 	PipelineTask string
 	Result       string
+	// Property is the key referenced in an object result, if any
+	Property string
+	// Finally is true if the reference is to a result of a finally task, which only the results of
+	// the pipeline can reference
+	Finally bool
 }
 
 const (
-	resultExpressionFormat = "tasks.<taskName>.results.<resultName>"
+	resultExpressionFormat = "tasks.<taskName>.results.<resultName>[.<objectKey>|[*]]"
 	// ResultTaskPart Constant used to define the "tasks" part of a pipeline result reference
 	ResultTaskPart = "tasks"
+	// ResultFinallyPart Constant used to define the "finally" part of a pipeline result reference to a finally task
+	ResultFinallyPart = "finally"
 	// ResultResultPart Constant used to define the "results" part of a pipeline result reference
 	ResultResultPart = "results"
 	// TODO(#2462) use one regex across all substitutions
-	variableSubstitutionFormat = `\$\([_a-zA-Z0-9.-]+(\.[_a-zA-Z0-9.-]+)*\)`
+	variableSubstitutionFormat = `\$\([_a-zA-Z0-9.-]+(\.[_a-zA-Z0-9.-]+)*(\[\*\])?\)`
 	// ResultNameFormat Constant used to define the the regex Result.Name should follow
 	ResultNameFormat = `^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`
 )
@@ -49,15 +56,24 @@ var resultNameFormatRegex = regexp.MustCompile(ResultNameFormat)
 func NewResultRefs(expressions []string) []*ResultRef {
 	var resultRefs []*ResultRef
 	for _, expression := range expressions {
-		pipelineTask, result, err := parseExpression(expression)
+		resultRef, err := parseExpression(expression)
 		// If the expression isn't a result but is some other expression,
 		// parseExpression will return an error, in which case we just skip that expression,
 		// since although it's not a result ref, it might be some other kind of reference
-		if err == nil {
-			resultRefs = append(resultRefs, &ResultRef{
-				PipelineTask: pipelineTask,
-				Result:       result,
-			})
+		if err == nil && !resultRef.Finally {
+			resultRefs = append(resultRefs, resultRef)
+		}
+	}
+	return resultRefs
+}
+
+// NewPipelineResultRefs extracts all ResultReferences from a pipeline result, which unlike a param
+// can also reference the results of finally tasks. Expressions which are not results are ignored.
+func NewPipelineResultRefs(expressions []string) []*ResultRef {
+	var resultRefs []*ResultRef
+	for _, expression := range expressions {
+		if resultRef, err := parseExpression(expression); err == nil {
+			resultRefs = append(resultRefs, resultRef)
 		}
 	}
 	return resultRefs
@@ -82,6 +98,12 @@ func looksLikeResultRef(expression string) bool {
 	return strings.HasPrefix(expression, "task") && strings.Contains(expression, ".result")
 }
 
+// looksLikePipelineResultRef attempts to check if the given string looks like it contains any
+// result references, including to the results of finally tasks. Returns true if it does, false otherwise
+func looksLikePipelineResultRef(expression string) bool {
+	return looksLikeResultRef(expression) || (strings.HasPrefix(expression, ResultFinallyPart+".") && strings.Contains(expression, ".result"))
+}
+
 // GetVarSubstitutionExpressionsForParam extracts all the value between "$(" and ")"" for a parameter
 func GetVarSubstitutionExpressionsForParam(param Param) ([]string, bool) {
 	var allExpressions []string
@@ -94,6 +116,11 @@ func GetVarSubstitutionExpressionsForParam(param Param) ([]string, bool) {
 	case ParamTypeString:
 		// string type
 		allExpressions = append(allExpressions, validateString(param.Value.StringVal)...)
+	case ParamTypeObject:
+		// object type
+		for _, value := range param.Value.ObjectVal {
+			allExpressions = append(allExpressions, validateString(value)...)
+		}
 	default:
 		return nil, false
 	}
@@ -122,10 +149,19 @@ func stripVarSubExpression(expression string) string {
 	return strings.TrimSuffix(strings.TrimPrefix(expression, "$("), ")")
 }
 
-func parseExpression(substitutionExpression string) (string, string, error) {
-	subExpressions := strings.Split(substitutionExpression, ".")
-	if len(subExpressions) != 4 || subExpressions[0] != ResultTaskPart || subExpressions[2] != ResultResultPart {
-		return "", "", fmt.Errorf("Must be of the form %q", resultExpressionFormat)
+func parseExpression(substitutionExpression string) (*ResultRef, error) {
+	// An array result can be referenced with the star operator, e.g. "tasks.foo.results.bar[*]"
+	subExpressions := strings.Split(strings.TrimSuffix(substitutionExpression, "[*]"), ".")
+	if len(subExpressions) < 4 || len(subExpressions) > 5 || (subExpressions[0] != ResultTaskPart && subExpressions[0] != ResultFinallyPart) || subExpressions[2] != ResultResultPart {
+		return nil, fmt.Errorf("Must be of the form %q", resultExpressionFormat)
+	}
+	resultRef := &ResultRef{
+		PipelineTask: subExpressions[1],
+		Result:       subExpressions[3],
+		Finally:      subExpressions[0] == ResultFinallyPart,
+	}
+	if len(subExpressions) == 5 {
+		resultRef.Property = subExpressions[4]
 	}
-	return subExpressions[1], subExpressions[3], nil
+	return resultRef, nil
 }
@@ -108,6 +108,49 @@ func TestNewResultReference(t *testing.T) {
 			PipelineTask: "sumTask1",
 			Result:       "sumResult",
 		}},
+	}, {
+		name: "object result key substitution",
+		param: v1beta1.Param{
+			Name:  "param",
+			Value: *v1beta1.NewArrayOrString("$(tasks.build.results.image.url)@$(tasks.build.results.image.digest)"),
+		},
+		want: []*v1beta1.ResultRef{{
+			PipelineTask: "build",
+			Result:       "image",
+			Property:     "url",
+		}, {
+			PipelineTask: "build",
+			Result:       "image",
+			Property:     "digest",
+		}},
+	}, {
+		name: "array result substitution with star operator",
+		param: v1beta1.Param{
+			Name:  "param",
+			Value: *v1beta1.NewArrayOrString("first", "$(tasks.changes.results.modules[*])"),
+		},
+		want: []*v1beta1.ResultRef{{
+			PipelineTask: "changes",
+			Result:       "modules",
+		}},
+	}, {
+		name: "result substitution in object param",
+		param: v1beta1.Param{
+			Name:  "param",
+			Value: *v1beta1.NewObject(map[string]string{"url": "$(tasks.build.results.image.url)"}),
+		},
+		want: []*v1beta1.ResultRef{{
+			PipelineTask: "build",
+			Result:       "image",
+			Property:     "url",
+		}},
+	}, {
+		name: "finally result substitution is not a param result reference",
+		param: v1beta1.Param{
+			Name:  "param",
+			Value: *v1beta1.NewArrayOrString("$(finally.report.results.url)"),
+		},
+		want: nil,
 	}} {
 		t.Run(tt.name, func(t *testing.T) {
 			expressions, ok := v1beta1.GetVarSubstitutionExpressionsForParam(tt.param)
@@ -123,6 +166,26 @@ func TestNewResultReference(t *testing.T) {
 	}
 }
 
+func TestNewPipelineResultReference(t *testing.T) {
+	result := v1beta1.PipelineResult{
+		Name:  "result",
+		Value: "$(tasks.build.results.image) reported at $(finally.report.results.url)",
+	}
+	want := []*v1beta1.ResultRef{{
+		PipelineTask: "build",
+		Result:       "image",
+	}, {
+		PipelineTask: "report",
+		Result:       "url",
+		Finally:      true,
+	}}
+	expressions, _ := v1beta1.GetVarSubstitutionExpressionsForPipelineResult(result)
+	got := v1beta1.NewPipelineResultRefs(expressions)
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("TestNewPipelineResultReference %s", diff.PrintWantGot(d))
+	}
+}
+
 func TestHasResultReference(t *testing.T) {
 	for _, tt := range []struct {
 		name    string
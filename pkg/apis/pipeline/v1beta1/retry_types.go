@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"math"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RetryReason is the kind of failure a PipelineTask is retried for
+type RetryReason string
+
+const (
+	// RetryReasonEvicted is the reason for the failure of a TaskRun whose pod was evicted
+	RetryReasonEvicted RetryReason = "Evicted"
+	// RetryReasonOOMKilled is the reason for the failure of a TaskRun with a step killed
+	// for running out of memory
+	RetryReasonOOMKilled RetryReason = "OOMKilled"
+	// RetryReasonExceededNodeResources is the reason for the failure of a TaskRun whose pod
+	// could not be scheduled because of resource constraints on the nodes
+	RetryReasonExceededNodeResources RetryReason = "ExceededNodeResources"
+	// RetryReasonExitCode is the reason for the failure of a TaskRun with a step which exited
+	// with one of the exit codes of the retry policy
+	RetryReasonExitCode RetryReason = "ExitCode"
+)
+
+// AllRetryReasons are the kinds of failures which can be listed in RetryPolicy.On
+var AllRetryReasons = []RetryReason{RetryReasonEvicted, RetryReasonOOMKilled, RetryReasonExceededNodeResources}
+
+// RetryPolicy configures when and how a PipelineTask is retried, within its Retries
+type RetryPolicy struct {
+	// Delay is the time to wait before the first retry. Defaults to retrying right away.
+	// +optional
+	Delay *metav1.Duration `json:"delay,omitempty"`
+	// BackoffFactor multiplies the delay after each retry. Defaults to 1, i.e. a constant delay.
+	// +optional
+	BackoffFactor int `json:"backoffFactor,omitempty"`
+	// MaxDelay caps the delay between two retries
+	// +optional
+	MaxDelay *metav1.Duration `json:"maxDelay,omitempty"`
+	// On restricts the retries to the given kinds of failures
+	// +optional
+	On []RetryReason `json:"on,omitempty"`
+	// ExitCodes restricts the retries to the failures of steps exiting with one of these codes
+	// +optional
+	ExitCodes []int32 `json:"exitCodes,omitempty"`
+}
+
+// HasFilters returns true if the retry policy only retries some failures
+func (rp *RetryPolicy) HasFilters() bool {
+	return rp != nil && (len(rp.On) > 0 || len(rp.ExitCodes) > 0)
+}
+
+// RetriesOn returns true if the retry policy retries the failures for the given reason
+func (rp *RetryPolicy) RetriesOn(reason RetryReason) bool {
+	if !rp.HasFilters() {
+		return true
+	}
+	for _, r := range rp.On {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// RetriesExitCode returns true if the retry policy retries the failures of steps exiting with
+// the given code
+func (rp *RetryPolicy) RetriesExitCode(exitCode int32) bool {
+	if !rp.HasFilters() {
+		return true
+	}
+	for _, c := range rp.ExitCodes {
+		if c == exitCode {
+			return true
+		}
+	}
+	return false
+}
+
+// DelayFor returns the time to wait before the given retry, counted from 0 for the first one
+func (rp *RetryPolicy) DelayFor(retry int) time.Duration {
+	if rp == nil || rp.Delay == nil {
+		return 0
+	}
+	delay := rp.Delay.Duration
+	for i := 0; i < retry && rp.BackoffFactor > 1; i++ {
+		if delay > math.MaxInt64/time.Duration(rp.BackoffFactor) {
+			delay = math.MaxInt64
+			break
+		}
+		delay *= time.Duration(rp.BackoffFactor)
+	}
+	if rp.MaxDelay != nil && delay > rp.MaxDelay.Duration {
+		return rp.MaxDelay.Duration
+	}
+	return delay
+}
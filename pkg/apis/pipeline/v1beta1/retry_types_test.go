@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRetryPolicy_DelayFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   *RetryPolicy
+		retry    int
+		expected time.Duration
+	}{{
+		name:     "no retry policy",
+		retry:    2,
+		expected: 0,
+	}, {
+		name:     "no delay",
+		policy:   &RetryPolicy{BackoffFactor: 2},
+		retry:    2,
+		expected: 0,
+	}, {
+		name:     "constant delay",
+		policy:   &RetryPolicy{Delay: &metav1.Duration{Duration: 10 * time.Second}},
+		retry:    2,
+		expected: 10 * time.Second,
+	}, {
+		name:     "first retry",
+		policy:   &RetryPolicy{Delay: &metav1.Duration{Duration: 10 * time.Second}, BackoffFactor: 3},
+		retry:    0,
+		expected: 10 * time.Second,
+	}, {
+		name:     "exponential backoff",
+		policy:   &RetryPolicy{Delay: &metav1.Duration{Duration: 10 * time.Second}, BackoffFactor: 3},
+		retry:    2,
+		expected: 90 * time.Second,
+	}, {
+		name: "capped exponential backoff",
+		policy: &RetryPolicy{
+			Delay:         &metav1.Duration{Duration: 10 * time.Second},
+			BackoffFactor: 3,
+			MaxDelay:      &metav1.Duration{Duration: time.Minute},
+		},
+		retry:    2,
+		expected: time.Minute,
+	}, {
+		name:     "overflowing exponential backoff",
+		policy:   &RetryPolicy{Delay: &metav1.Duration{Duration: time.Hour}, BackoffFactor: 10},
+		retry:    20,
+		expected: math.MaxInt64,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.DelayFor(tt.retry); got != tt.expected {
+				t.Errorf("RetryPolicy.DelayFor(%d) = %s, want %s", tt.retry, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Filters(t *testing.T) {
+	var noPolicy *RetryPolicy
+	if !noPolicy.RetriesOn(RetryReasonEvicted) || !noPolicy.RetriesExitCode(1) {
+		t.Errorf("Expected the failures to be retried without a retry policy")
+	}
+	policy := &RetryPolicy{On: []RetryReason{RetryReasonOOMKilled}, ExitCodes: []int32{137}}
+	if !policy.RetriesOn(RetryReasonOOMKilled) || !policy.RetriesExitCode(137) {
+		t.Errorf("Expected the failures of the retry policy to be retried")
+	}
+	if policy.RetriesOn(RetryReasonEvicted) || policy.RetriesExitCode(1) {
+		t.Errorf("Expected the other failures not to be retried")
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	"knative.dev/pkg/apis"
+)
+
+// validate checks that the retry policy applies to a task which is retried, that its delays and
+// backoff factor are valid, and that it only filters on known failures and non zero exit codes
+func (rp *RetryPolicy) validate(retries int) *apis.FieldError {
+	if retries == 0 {
+		return apis.ErrGeneric("retryPolicy can only be used together with retries", apis.CurrentField)
+	}
+	if rp.Delay != nil && rp.Delay.Duration < 0 {
+		return apis.ErrInvalidValue(fmt.Sprintf("%s should be >= 0", rp.Delay.Duration.String()), "delay")
+	}
+	if rp.BackoffFactor < 0 {
+		return apis.ErrInvalidValue(fmt.Sprintf("%d should be >= 0", rp.BackoffFactor), "backoffFactor")
+	}
+	if rp.MaxDelay != nil {
+		if rp.Delay == nil {
+			return apis.ErrMissingField("delay")
+		}
+		if rp.MaxDelay.Duration < rp.Delay.Duration {
+			return apis.ErrInvalidValue(fmt.Sprintf("%s should be >= delay %s", rp.MaxDelay.Duration.String(), rp.Delay.Duration.String()), "maxDelay")
+		}
+	}
+	for i, reason := range rp.On {
+		if !isValidRetryReason(reason) {
+			return apis.ErrInvalidValue(fmt.Sprintf("%s should be one of %v", reason, AllRetryReasons), apis.CurrentField).ViaFieldIndex("on", i)
+		}
+	}
+	for i, exitCode := range rp.ExitCodes {
+		if exitCode == 0 {
+			return apis.ErrInvalidValue("0 is the exit code of a successful step", apis.CurrentField).ViaFieldIndex("exitCodes", i)
+		}
+	}
+	return nil
+}
+
+func isValidRetryReason(reason RetryReason) bool {
+	for _, r := range AllRetryReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/test/diff"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+func TestRetryPolicy_Valid(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+	}{{
+		name:   "no filters",
+		policy: RetryPolicy{},
+	}, {
+		name: "exponential backoff",
+		policy: RetryPolicy{
+			Delay:         &metav1.Duration{Duration: 10 * time.Second},
+			BackoffFactor: 2,
+			MaxDelay:      &metav1.Duration{Duration: time.Minute},
+		},
+	}, {
+		name: "filters",
+		policy: RetryPolicy{
+			On:        []RetryReason{RetryReasonEvicted, RetryReasonOOMKilled, RetryReasonExceededNodeResources},
+			ExitCodes: []int32{1, 137},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.validate(1); err != nil {
+				t.Errorf("RetryPolicy.validate() returned an error for valid retry policy: %v", err)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   RetryPolicy
+		retries  int
+		expected *apis.FieldError
+	}{{
+		name:     "no retries",
+		policy:   RetryPolicy{},
+		expected: apis.ErrGeneric("retryPolicy can only be used together with retries", apis.CurrentField),
+	}, {
+		name:     "negative delay",
+		policy:   RetryPolicy{Delay: &metav1.Duration{Duration: -time.Second}},
+		retries:  1,
+		expected: apis.ErrInvalidValue("-1s should be >= 0", "delay"),
+	}, {
+		name:     "negative backoff factor",
+		policy:   RetryPolicy{BackoffFactor: -2},
+		retries:  1,
+		expected: apis.ErrInvalidValue("-2 should be >= 0", "backoffFactor"),
+	}, {
+		name:     "max delay without delay",
+		policy:   RetryPolicy{MaxDelay: &metav1.Duration{Duration: time.Minute}},
+		retries:  1,
+		expected: apis.ErrMissingField("delay"),
+	}, {
+		name: "max delay shorter than delay",
+		policy: RetryPolicy{
+			Delay:    &metav1.Duration{Duration: time.Minute},
+			MaxDelay: &metav1.Duration{Duration: time.Second},
+		},
+		retries:  1,
+		expected: apis.ErrInvalidValue("1s should be >= delay 1m0s", "maxDelay"),
+	}, {
+		name:     "unknown reason",
+		policy:   RetryPolicy{On: []RetryReason{RetryReasonEvicted, "Flaky"}},
+		retries:  1,
+		expected: apis.ErrInvalidValue("Flaky should be one of [Evicted OOMKilled ExceededNodeResources]", "on[1]"),
+	}, {
+		name:     "successful exit code",
+		policy:   RetryPolicy{ExitCodes: []int32{0}},
+		retries:  1,
+		expected: apis.ErrInvalidValue("0 is the exit code of a successful step", "exitCodes[0]"),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.validate(tt.retries)
+			if err == nil {
+				t.Fatalf("RetryPolicy.validate() did not return error for invalid retry policy")
+			}
+			if d := cmp.Diff(tt.expected.Error(), err.Error()); d != "" {
+				t.Errorf("RetryPolicy.validate() errors diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
@@ -22,5 +22,6 @@ import (
 
 func ApplyStepReplacements(step *Step, stringReplacements map[string]string, arrayReplacements map[string][]string) {
 	step.Script = substitution.ApplyReplacements(step.Script, stringReplacements)
+	step.WhenExpressions = step.WhenExpressions.ReplaceWhenExpressionsVariables(stringReplacements)
 	ApplyContainerReplacements(&step.Container, stringReplacements, arrayReplacements)
 }
@@ -132,6 +132,12 @@ func ApplyArrayReplacements(in string, stringReplacements map[string]string, arr
 		if (strings.Count(in, stringToReplace) == 1) && len(in) == len(stringToReplace) {
 			return v
 		}
+
+		// same replace logic for star array expressions
+		starStringToReplace := fmt.Sprintf("$(%s[*])", k)
+		if (strings.Count(in, starStringToReplace) == 1) && len(in) == len(starStringToReplace) {
+			return v
+		}
 	}
 
 	// Otherwise return a size-1 array containing the input string with standard stringReplacements applied.
@@ -112,8 +112,32 @@ type TaskResult struct {
 	// Description is a human-readable description of the result
 	// +optional
 	Description string `json:"description"`
+
+	// Type is the user-specified type of the result. The possible types
+	// are currently "string", "array" and "object", and "string" is the default.
+	// +optional
+	Type ResultsType `json:"type,omitempty"`
+
+	// Properties is the JSON Schema properties to support key-value pairs results.
+	// It is required for, and only allowed with, results of type "object".
+	// +optional
+	Properties map[string]PropertySpec `json:"properties,omitempty"`
 }
 
+// ResultsType indicates the type of a result;
+// Used to distinguish between a single string, an array of strings and an object of strings.
+type ResultsType string
+
+// Valid ResultsType:
+const (
+	ResultsTypeString ResultsType = "string"
+	ResultsTypeArray  ResultsType = "array"
+	ResultsTypeObject ResultsType = "object"
+)
+
+// AllResultsTypes can be used for ResultsTypes validation.
+var AllResultsTypes = []ResultsType{ResultsTypeString, ResultsTypeArray, ResultsTypeObject}
+
 // Step embeds the Container type, which allows it to include fields not
 // provided by Container.
 type Step struct {
@@ -123,8 +147,35 @@ type Step struct {
 	//
 	// If Script is not empty, the Step cannot have an Command or Args.
 	Script string `json:"script,omitempty"`
+
+	// WhenExpressions are evaluated right before the Step runs to determine whether it is
+	// executed or skipped. Parameters are substituted when the Pod is created, while Results
+	// written by earlier Steps are referenced as $(results.<name>) and substituted at runtime.
+	// +optional
+	WhenExpressions WhenExpressions `json:"when,omitempty"`
+
+	// OnError defines what happens when the Step exits with a non-zero exit code: "stopAndFail",
+	// the default, fails the TaskRun without running the following Steps, while "continue" records
+	// the exit code of the Step and runs the following Steps as usual.
+	// +optional
+	OnError OnErrorType `json:"onError,omitempty"`
+
+	// Timeout is the time after which the Step is terminated, in which case it fails with the
+	// StepTimedOut reason unless its OnError is "continue". Defaults to no timeout.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
+// OnErrorType defines what happens when a Step exits with a non-zero exit code
+type OnErrorType string
+
+const (
+	// StopAndFail fails the TaskRun when the Step exits with a non-zero exit code
+	StopAndFail OnErrorType = "stopAndFail"
+	// Continue runs the following Steps irrespective of the exit code of the Step
+	Continue OnErrorType = "continue"
+)
+
 // Sidecar embeds the Container type, which allows it to include fields not
 // provided by Container.
 type Sidecar struct {
@@ -155,6 +206,13 @@ type TaskRef struct {
 	// API version of the referent
 	// +optional
 	APIVersion string `json:"apiVersion,omitempty"`
+	// Bundle is the reference of an OCI image, or Tekton bundle, from which the Task named Name is
+	// retrieved instead of the cluster
+	// +optional
+	Bundle string `json:"bundle,omitempty"`
+	// ResolverRef fetches the Task named Name from a remote location instead of the cluster
+	// +optional
+	ResolverRef `json:",inline"`
 }
 
 // Check that Pipeline may be validated and defaulted.
@@ -59,6 +59,7 @@ func (ts *TaskSpec) Validate(ctx context.Context) (errs *apis.FieldError) {
 	errs = errs.Also(ValidateResourcesVariables(ts.Steps, ts.Resources))
 	errs = errs.Also(validateTaskContextVariables(ts.Steps))
 	errs = errs.Also(validateResults(ctx, ts.Results).ViaField("results"))
+	errs = errs.Also(validateStepWhenResultsVariables(ts.Steps, ts.Results))
 	return errs
 }
 
@@ -73,6 +74,23 @@ func (tr TaskResult) Validate(_ context.Context) *apis.FieldError {
 	if !resultNameFormatRegex.MatchString(tr.Name) {
 		return apis.ErrInvalidKeyName(tr.Name, "name", fmt.Sprintf("Name must consist of alphanumeric characters, '-', '_', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my-name',  or 'my_name', regex used for validation is '%s')", ResultNameFormat))
 	}
+	switch tr.Type {
+	case "", ResultsTypeString, ResultsTypeArray:
+		if tr.Properties != nil {
+			return apis.ErrDisallowedFields("properties")
+		}
+	case ResultsTypeObject:
+		if len(tr.Properties) == 0 {
+			return apis.ErrMissingField("properties")
+		}
+		for _, key := range sets.StringKeySet(tr.Properties).List() {
+			if t := tr.Properties[key].Type; t != "" && t != ParamTypeString {
+				return apis.ErrInvalidValue(t, fmt.Sprintf("properties.%s.type", key))
+			}
+		}
+	default:
+		return apis.ErrInvalidValue(tr.Type, "type")
+	}
 	return nil
 }
 
@@ -159,6 +177,16 @@ func validateStep(s Step, names sets.String) (errs *apis.FieldError) {
 		names.Insert(s.Name)
 	}
 
+	errs = errs.Also(s.WhenExpressions.validateStepWhenExpressions().ViaField("when"))
+
+	if s.OnError != "" && s.OnError != StopAndFail && s.OnError != Continue {
+		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s should be one of %s, %s", s.OnError, StopAndFail, Continue), "onError"))
+	}
+
+	if s.Timeout != nil && s.Timeout.Duration < 0 {
+		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s should be >= 0", s.Timeout.Duration.String()), "timeout"))
+	}
+
 	for j, vm := range s.VolumeMounts {
 		if strings.HasPrefix(vm.MountPath, "/tekton/") &&
 			!strings.HasPrefix(vm.MountPath, "/tekton/home") {
@@ -201,22 +229,57 @@ func (p ParamSpec) ValidateType() *apis.FieldError {
 			},
 		}
 	}
+	return p.validateObjectType()
+}
+
+// validateObjectType ensures that properties are declared for, and only for, object params
+// and that an object default value provides exactly the declared keys.
+func (p ParamSpec) validateObjectType() *apis.FieldError {
+	if p.Type != ParamTypeObject {
+		if p.Properties != nil {
+			return apis.ErrDisallowedFields(fmt.Sprintf("%s.properties", p.Name))
+		}
+		return nil
+	}
+	if len(p.Properties) == 0 {
+		return apis.ErrMissingField(fmt.Sprintf("%s.properties", p.Name))
+	}
+	for _, key := range sets.StringKeySet(p.Properties).List() {
+		if t := p.Properties[key].Type; t != "" && t != ParamTypeString {
+			return apis.ErrInvalidValue(t, fmt.Sprintf("%s.properties.%s.type", p.Name, key))
+		}
+	}
+	if p.Default != nil {
+		declaredKeys := sets.StringKeySet(p.Properties)
+		providedKeys := sets.StringKeySet(p.Default.ObjectVal)
+		if missing := declaredKeys.Difference(providedKeys); missing.Len() != 0 {
+			return apis.ErrGeneric(fmt.Sprintf("default value is missing keys %v", missing.List()), fmt.Sprintf("%s.default", p.Name))
+		}
+		if extra := providedKeys.Difference(declaredKeys); extra.Len() != 0 {
+			return apis.ErrGeneric(fmt.Sprintf("default value has undeclared keys %v", extra.List()), fmt.Sprintf("%s.default", p.Name))
+		}
+	}
 	return nil
 }
 
 func ValidateParameterVariables(steps []Step, params []ParamSpec) *apis.FieldError {
 	parameterNames := sets.NewString()
 	arrayParameterNames := sets.NewString()
+	objectParameterKeys := map[string]sets.String{}
 
 	for _, p := range params {
 		parameterNames.Insert(p.Name)
-		if p.Type == ParamTypeArray {
+		switch p.Type {
+		case ParamTypeArray:
 			arrayParameterNames.Insert(p.Name)
+		case ParamTypeObject:
+			objectParameterKeys[p.Name] = sets.StringKeySet(p.Properties)
 		}
 	}
 
 	errs := validateVariables(steps, "params", parameterNames)
-	return errs.Also(validateArrayUsage(steps, "params", arrayParameterNames))
+	errs = errs.Also(validateArrayUsage(steps, "params", arrayParameterNames))
+	return errs.Also(validateObjectUsage(steps, "params", objectParameterKeys))
 }
 
 func validateTaskContextVariables(steps []Step) *apis.FieldError {
@@ -277,6 +340,49 @@ func validateStepArrayUsage(step Step, prefix string, vars sets.String) *apis.Fi
 		errs = errs.Also(validateTaskNoArrayReferenced(v.MountPath, prefix, vars).ViaField("mountPath").ViaFieldIndex("volumeMount", i))
 		errs = errs.Also(validateTaskNoArrayReferenced(v.SubPath, prefix, vars).ViaField("subPath").ViaFieldIndex("volumeMount", i))
 	}
+	for i, we := range step.WhenExpressions {
+		errs = errs.Also(validateTaskNoArrayReferenced(we.Input, prefix, vars).ViaField("input").ViaFieldIndex("when", i))
+		for j, v := range we.Values {
+			errs = errs.Also(validateTaskNoArrayReferenced(v, prefix, vars).ViaFieldIndex("values", j).ViaFieldIndex("when", i))
+		}
+		errs = errs.Also(validateTaskNoArrayReferenced(we.CEL, prefix, vars).ViaField("cel").ViaFieldIndex("when", i))
+	}
+	return errs
+}
+
+func validateObjectUsage(steps []Step, prefix string, objectKeys map[string]sets.String) (errs *apis.FieldError) {
+	for idx, step := range steps {
+		errs = errs.Also(validateStepObjectUsage(step, prefix, objectKeys).ViaFieldIndex("steps", idx))
+	}
+	return errs
+}
+
+func validateStepObjectUsage(step Step, prefix string, objectKeys map[string]sets.String) *apis.FieldError {
+	errs := validateTaskObjectKeys(step.Name, prefix, objectKeys).ViaField("name")
+	errs = errs.Also(validateTaskObjectKeys(step.Image, prefix, objectKeys).ViaField("image"))
+	errs = errs.Also(validateTaskObjectKeys(step.WorkingDir, prefix, objectKeys).ViaField("workingDir"))
+	errs = errs.Also(validateTaskObjectKeys(step.Script, prefix, objectKeys).ViaField("script"))
+	for i, cmd := range step.Command {
+		errs = errs.Also(validateTaskObjectKeys(cmd, prefix, objectKeys).ViaFieldIndex("command", i))
+	}
+	for i, arg := range step.Args {
+		errs = errs.Also(validateTaskObjectKeys(arg, prefix, objectKeys).ViaFieldIndex("args", i))
+	}
+	for _, env := range step.Env {
+		errs = errs.Also(validateTaskObjectKeys(env.Value, prefix, objectKeys).ViaFieldKey("env", env.Name))
+	}
+	for i, v := range step.VolumeMounts {
+		errs = errs.Also(validateTaskObjectKeys(v.Name, prefix, objectKeys).ViaField("name").ViaFieldIndex("volumeMount", i))
+		errs = errs.Also(validateTaskObjectKeys(v.MountPath, prefix, objectKeys).ViaField("mountPath").ViaFieldIndex("volumeMount", i))
+		errs = errs.Also(validateTaskObjectKeys(v.SubPath, prefix, objectKeys).ViaField("subPath").ViaFieldIndex("volumeMount", i))
+	}
+	for i, we := range step.WhenExpressions {
+		errs = errs.Also(validateTaskObjectKeys(we.Input, prefix, objectKeys).ViaField("input").ViaFieldIndex("when", i))
+		for j, v := range we.Values {
+			errs = errs.Also(validateTaskObjectKeys(v, prefix, objectKeys).ViaFieldIndex("values", j).ViaFieldIndex("when", i))
+		}
+		errs = errs.Also(validateTaskObjectKeys(we.CEL, prefix, objectKeys).ViaField("cel").ViaFieldIndex("when", i))
+	}
 	return errs
 }
 
@@ -306,6 +412,29 @@ func validateStepVariables(step Step, prefix string, vars sets.String) *apis.Fie
 		errs = errs.Also(validateTaskVariable(v.MountPath, prefix, vars).ViaField("MountPath").ViaFieldIndex("volumeMount", i))
 		errs = errs.Also(validateTaskVariable(v.SubPath, prefix, vars).ViaField("SubPath").ViaFieldIndex("volumeMount", i))
 	}
+	return errs.Also(validateStepWhenVariables(step, prefix, vars))
+}
+
+func validateStepWhenVariables(step Step, prefix string, vars sets.String) (errs *apis.FieldError) {
+	for i, we := range step.WhenExpressions {
+		errs = errs.Also(validateTaskVariable(we.Input, prefix, vars).ViaField("input").ViaFieldIndex("when", i))
+		for j, v := range we.Values {
+			errs = errs.Also(validateTaskVariable(v, prefix, vars).ViaFieldIndex("values", j).ViaFieldIndex("when", i))
+		}
+		errs = errs.Also(validateTaskVariable(we.CEL, prefix, vars).ViaField("cel").ViaFieldIndex("when", i))
+	}
+	return errs
+}
+
+// validateStepWhenResultsVariables ensures the When Expressions of steps only reference declared results
+func validateStepWhenResultsVariables(steps []Step, results []TaskResult) (errs *apis.FieldError) {
+	resultNames := sets.NewString()
+	for _, r := range results {
+		resultNames.Insert(r.Name)
+	}
+	for idx, step := range steps {
+		errs = errs.Also(validateStepWhenVariables(step, "results", resultNames).ViaFieldIndex("steps", idx))
+	}
 	return errs
 }
 
@@ -320,3 +449,7 @@ func validateTaskNoArrayReferenced(value, prefix string, arrayNames sets.String)
 func validateTaskArraysIsolated(value, prefix string, arrayNames sets.String) *apis.FieldError {
 	return substitution.ValidateVariableIsolatedP(value, prefix, arrayNames)
 }
+
+func validateTaskObjectKeys(value, prefix string, objectKeys map[string]sets.String) *apis.FieldError {
+	return substitution.ValidateVariableObjectKeysP(value, prefix, objectKeys)
+}
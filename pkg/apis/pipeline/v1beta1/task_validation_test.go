@@ -19,12 +19,15 @@ package v1beta1_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/test/diff"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/selection"
 	"knative.dev/pkg/apis"
 )
 
@@ -161,6 +164,25 @@ func TestTaskSpecValidate(t *testing.T) {
 				WorkingDir: "/foo/bar/src/",
 			}}},
 		},
+	}, {
+		name: "valid object template variable",
+		fields: fields{
+			Params: []v1beta1.ParamSpec{{
+				Name: "image",
+				Type: v1beta1.ParamTypeObject,
+				Properties: map[string]v1beta1.PropertySpec{
+					"registry": {Type: v1beta1.ParamTypeString},
+					"digest":   {},
+				},
+				Default: v1beta1.NewObject(map[string]string{"registry": "gcr.io", "digest": "sha256:1234"}),
+			}},
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Name:       "mystep",
+				Image:      "$(params.image.registry)/myimage@$(params.image.digest)",
+				Args:       []string{"--digest=$(params.image.digest)"},
+				WorkingDir: "/foo/bar/src/",
+			}}},
+		},
 	}, {
 		name: "valid creds-init path variable",
 		fields: fields{
@@ -264,6 +286,40 @@ func TestTaskSpecValidate(t *testing.T) {
 				Description: "my great result",
 			}},
 		},
+	}, {
+		name: "valid object result",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+					Args:  []string{"arg"},
+				},
+			}},
+			Results: []v1beta1.TaskResult{{
+				Name:        "image",
+				Description: "the image that was built",
+				Type:        v1beta1.ResultsTypeObject,
+				Properties: map[string]v1beta1.PropertySpec{
+					"url":    {Type: v1beta1.ParamTypeString},
+					"digest": {},
+				},
+			}},
+		},
+	}, {
+		name: "valid array result",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+					Args:  []string{"arg"},
+				},
+			}},
+			Results: []v1beta1.TaskResult{{
+				Name:        "changed-modules",
+				Description: "the modules changed by the commit",
+				Type:        v1beta1.ResultsTypeArray,
+			}},
+		},
 	}, {
 		name: "valid task name context",
 		fields: fields{
@@ -316,6 +372,64 @@ func TestTaskSpecValidate(t *testing.T) {
 				hello "$(context.taskRun.namespace)"`,
 			}},
 		},
+	}, {
+		name: "valid step when expressions",
+		fields: fields{
+			Params: []v1beta1.ParamSpec{{
+				Name: "expected",
+				Type: v1beta1.ParamTypeString,
+			}},
+			Results: []v1beta1.TaskResult{{
+				Name: "sum",
+			}},
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+					Args:  []string{"arg"},
+				},
+			}, {
+				Container: corev1.Container{
+					Image: "my-image",
+					Args:  []string{"arg"},
+				},
+				WhenExpressions: v1beta1.WhenExpressions{{
+					Input:    "$(results.sum)",
+					Operator: selection.In,
+					Values:   []string{"$(params.expected)"},
+				}, {
+					CEL: "'$(results.sum)' != '0'",
+				}},
+			}},
+		},
+	}, {
+		name: "valid step onError",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+					Args:  []string{"lint"},
+				},
+				OnError: v1beta1.Continue,
+			}, {
+				Container: corev1.Container{
+					Image: "my-image",
+					Args:  []string{"publish"},
+				},
+				OnError: v1beta1.StopAndFail,
+			}},
+		},
+	}, {
+		name: "valid step timeout",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+					Args:  []string{"test"},
+				},
+				Timeout: &metav1.Duration{Duration: 5 * time.Minute},
+				OnError: v1beta1.Continue,
+			}},
+		},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -494,6 +608,97 @@ func TestTaskSpecValidateError(t *testing.T) {
 			Message: `"string" type does not match default value's type: "array"`,
 			Paths:   []string{"params.task.type", "params.task.default.type"},
 		},
+	}, {
+		name: "object param without properties",
+		fields: fields{
+			Params: []v1beta1.ParamSpec{{
+				Name: "image",
+				Type: v1beta1.ParamTypeObject,
+			}},
+			Steps: validSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"params.image.properties"},
+		},
+	}, {
+		name: "properties on string param",
+		fields: fields{
+			Params: []v1beta1.ParamSpec{{
+				Name:       "image",
+				Type:       v1beta1.ParamTypeString,
+				Properties: map[string]v1beta1.PropertySpec{"url": {}},
+			}},
+			Steps: validSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: "must not set the field(s)",
+			Paths:   []string{"params.image.properties"},
+		},
+	}, {
+		name: "object param with non string property",
+		fields: fields{
+			Params: []v1beta1.ParamSpec{{
+				Name:       "image",
+				Type:       v1beta1.ParamTypeObject,
+				Properties: map[string]v1beta1.PropertySpec{"url": {Type: v1beta1.ParamTypeArray}},
+			}},
+			Steps: validSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: array",
+			Paths:   []string{"params.image.properties.url.type"},
+		},
+	}, {
+		name: "object param default missing keys",
+		fields: fields{
+			Params: []v1beta1.ParamSpec{{
+				Name:       "image",
+				Type:       v1beta1.ParamTypeObject,
+				Properties: map[string]v1beta1.PropertySpec{"url": {}, "digest": {}},
+				Default:    v1beta1.NewObject(map[string]string{"url": "gcr.io/foo"}),
+			}},
+			Steps: validSteps,
+		},
+		expectedError: apis.FieldError{
+			Message: "default value is missing keys [digest]",
+			Paths:   []string{"params.image.default"},
+		},
+	}, {
+		name: "object param referenced without key",
+		fields: fields{
+			Params: []v1beta1.ParamSpec{{
+				Name:       "image",
+				Type:       v1beta1.ParamTypeObject,
+				Properties: map[string]v1beta1.PropertySpec{"url": {}},
+			}},
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Name:  "mystep",
+				Image: "myimage",
+				Args:  []string{"--image=$(params.image)"},
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `object variable must be referenced by key in "--image=$(params.image)"`,
+			Paths:   []string{"steps[0].args[0]"},
+		},
+	}, {
+		name: "object param referenced with undeclared key",
+		fields: fields{
+			Params: []v1beta1.ParamSpec{{
+				Name:       "image",
+				Type:       v1beta1.ParamTypeObject,
+				Properties: map[string]v1beta1.PropertySpec{"url": {}},
+			}},
+			Steps: []v1beta1.Step{{Container: corev1.Container{
+				Name:  "mystep",
+				Image: "$(params.image.registry)",
+			}}},
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent key "registry" of object variable in "$(params.image.registry)"`,
+			Paths:   []string{"steps[0].image"},
+		},
 	}, {
 		name: "invalid step",
 		fields: fields{
@@ -913,6 +1118,46 @@ func TestTaskSpecValidateError(t *testing.T) {
 			Paths:   []string{"results[0].name"},
 			Details: "Name must consist of alphanumeric characters, '-', '_', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my-name',  or 'my_name', regex used for validation is '^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$')",
 		},
+	}, {
+		name: "object result without properties",
+		fields: fields{
+			Steps: validSteps,
+			Results: []v1beta1.TaskResult{{
+				Name: "image",
+				Type: v1beta1.ResultsTypeObject,
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "missing field(s)",
+			Paths:   []string{"results[0].properties"},
+		},
+	}, {
+		name: "array result with properties",
+		fields: fields{
+			Steps: validSteps,
+			Results: []v1beta1.TaskResult{{
+				Name:       "changed-modules",
+				Type:       v1beta1.ResultsTypeArray,
+				Properties: map[string]v1beta1.PropertySpec{"url": {}},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "must not set the field(s)",
+			Paths:   []string{"results[0].properties"},
+		},
+	}, {
+		name: "result with invalid type",
+		fields: fields{
+			Steps: validSteps,
+			Results: []v1beta1.TaskResult{{
+				Name: "image",
+				Type: "map",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: "invalid value: map",
+			Paths:   []string{"results[0].type"},
+		},
 	}, {
 		name: "context not validate",
 		fields: fields{
@@ -930,6 +1175,127 @@ func TestTaskSpecValidateError(t *testing.T) {
 			Message: `non-existent variable in "\n\t\t\t\t#!/usr/bin/env  bash\n\t\t\t\thello \"$(context.task.missing)\""`,
 			Paths:   []string{"steps[0].script"},
 		},
+	}, {
+		name: "step when expression with undeclared result",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+					Args:  []string{"arg"},
+				},
+				WhenExpressions: v1beta1.WhenExpressions{{
+					Input:    "$(results.missing)",
+					Operator: selection.In,
+					Values:   []string{"foo"},
+				}},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent variable in "$(results.missing)"`,
+			Paths:   []string{"steps[0].when[0].input"},
+		},
+	}, {
+		name: "step when expression with undeclared param",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+					Args:  []string{"arg"},
+				},
+				WhenExpressions: v1beta1.WhenExpressions{{
+					Input:    "foo",
+					Operator: selection.In,
+					Values:   []string{"$(params.missing)"},
+				}},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent variable in "$(params.missing)"`,
+			Paths:   []string{"steps[0].when[0].values[0]"},
+		},
+	}, {
+		name: "step when expression referencing the results of a pipeline task",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+					Args:  []string{"arg"},
+				},
+				WhenExpressions: v1beta1.WhenExpressions{{
+					Input:    "$(tasks.build.results.sum)",
+					Operator: selection.In,
+					Values:   []string{"foo"},
+				}},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value: expressions [tasks.build.results.sum] cannot reference the results of pipeline tasks`,
+			Paths:   []string{"steps[0].when[0]"},
+		},
+	}, {
+		name: "step when expression reading the status of a pipeline task",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+					Args:  []string{"arg"},
+				},
+				WhenExpressions: v1beta1.WhenExpressions{{
+					CEL: "tasks.build.status == 'Failed'",
+				}},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value: expressions cannot read the status of pipeline tasks [build]`,
+			Paths:   []string{"steps[0].when[0]"},
+		},
+	}, {
+		name: "invalid step onError",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+					Args:  []string{"arg"},
+				},
+				OnError: "ignore",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value: ignore should be one of stopAndFail, continue`,
+			Paths:   []string{"steps[0].onError"},
+		},
+	}, {
+		name: "negative step timeout",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+					Args:  []string{"arg"},
+				},
+				Timeout: &metav1.Duration{Duration: -10 * time.Second},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value: -10s should be >= 0`,
+			Paths:   []string{"steps[0].timeout"},
+		},
+	}, {
+		name: "step when expression with invalid cel expression",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+					Args:  []string{"arg"},
+				},
+				WhenExpressions: v1beta1.WhenExpressions{{
+					CEL: "'foo'",
+				}},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value: invalid cel expression "'foo'": expression evaluates to primitive:STRING instead of a boolean`,
+			Paths:   []string{"steps[0].when[0].cel"},
+		},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
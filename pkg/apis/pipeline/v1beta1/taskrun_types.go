@@ -64,6 +64,13 @@ type TaskRunSpec struct {
 	// Workspaces is a list of WorkspaceBindings from volumes to workspaces.
 	// +optional
 	Workspaces []WorkspaceBinding `json:"workspaces,omitempty"`
+	// Cache reuses the results of a previous successful TaskRun with the same resolved
+	// spec, params, input resources and step images instead of creating a pod
+	// +optional
+	Cache *TaskCache `json:"cache,omitempty"`
+	// Debug holds the breakpoints at which the steps of the TaskRun are held for debugging
+	// +optional
+	Debug *TaskRunDebug `json:"debug,omitempty"`
 }
 
 // TaskRunSpecStatus defines the taskrun spec status the user can provide
@@ -117,6 +124,9 @@ const (
 	TaskRunReasonCancelled TaskRunReason = "TaskRunCancelled"
 	// TaskRunReasonTimedOut is the reason set when the Taskrun has timed out
 	TaskRunReasonTimedOut TaskRunReason = "TaskRunTimeout"
+	// TaskRunReasonCacheHit is the reason set when the TaskRun reused the results of a
+	// previous TaskRun instead of running
+	TaskRunReasonCacheHit TaskRunReason = "CacheHit"
 )
 
 func (t TaskRunReason) String() string {
@@ -188,6 +198,12 @@ type TaskRunStatusFields struct {
 	// +optional
 	RetriesStatus []TaskRunStatus `json:"retriesStatus,omitempty"`
 
+	// RetryReason is the kind of failure the TaskRun was retried for, or the reason of its
+	// condition if the failure is of no particular kind. It is only set on the TaskRunStatus
+	// stored in RetriesStatus.
+	// +optional
+	RetryReason RetryReason `json:"retryReason,omitempty"`
+
 	// Results from Resources built during the taskRun. currently includes
 	// the digest of build container images
 	// +optional
@@ -203,6 +219,14 @@ type TaskRunStatusFields struct {
 
 	// TaskSpec contains the Spec from the dereferenced Task definition used to instantiate this TaskRun.
 	TaskSpec *TaskSpec `json:"taskSpec,omitempty"`
+
+	// Bundle is the reference, pinned by its digest, of the bundle the Task was retrieved from
+	// +optional
+	Bundle string `json:"bundle,omitempty"`
+
+	// Debug reports the step container held at a breakpoint, if any
+	// +optional
+	Debug *TaskRunDebugStatus `json:"debug,omitempty"`
 }
 
 // TaskRunResult used to describe the results of a task
@@ -210,8 +234,13 @@ type TaskRunResult struct {
 	// Name the given name
 	Name string `json:"name"`
 
-	// Value the given value of the result
+	// Value the given value of the result. The value of an array or object
+	// result is its JSON encoding.
 	Value string `json:"value"`
+
+	// Type is the type declared for the result by the Task.
+	// +optional
+	Type ResultsType `json:"type,omitempty"`
 }
 
 // GetOwnerReference gets the task run as owner reference for any related objects
@@ -261,8 +290,21 @@ type StepState struct {
 	Name                  string `json:"name,omitempty"`
 	ContainerName         string `json:"container,omitempty"`
 	ImageID               string `json:"imageID,omitempty"`
+	// Skipped is true if the step did not run because its when expressions evaluated to false
+	// +optional
+	Skipped bool `json:"skipped,omitempty"`
+	// SkipMessage describes why the step was skipped
+	// +optional
+	SkipMessage string `json:"skipMessage,omitempty"`
+	// Continued is true if the step failed but its onError is continue, so its exit code did not
+	// fail the TaskRun
+	// +optional
+	Continued bool `json:"continued,omitempty"`
 }
 
+// StepTimedOutReason is the reason of the terminated state of a step which ran longer than its timeout
+const StepTimedOutReason = "StepTimedOut"
+
 // SidecarState reports the results of running a sidecar in a Task.
 type SidecarState struct {
 	corev1.ContainerState `json:",inline"`
@@ -53,6 +53,15 @@ func (ts *TaskRunSpec) Validate(ctx context.Context) *apis.FieldError {
 		return apis.ErrMissingField("spec.taskref.name", "spec.taskspec")
 	}
 
+	if ts.TaskRef != nil {
+		if err := ts.TaskRef.ResolverRef.validate(); err != nil {
+			return err.ViaField("spec.taskref")
+		}
+		if err := validateBundle(ts.TaskRef.Bundle, ts.TaskRef.ResolverRef); err != nil {
+			return err.ViaField("spec.taskref")
+		}
+	}
+
 	// Validate TaskSpec if it's present
 	if ts.TaskSpec != nil {
 		if err := ts.TaskSpec.Validate(ctx).ViaField("taskspec"); err != nil {
@@ -86,6 +95,18 @@ func (ts *TaskRunSpec) Validate(ctx context.Context) *apis.FieldError {
 		}
 	}
 
+	if ts.Cache != nil {
+		if err := ts.Cache.validate(); err != nil {
+			return err.ViaField("spec.cache")
+		}
+	}
+
+	if ts.Debug != nil {
+		if err := ts.Debug.validate(); err != nil {
+			return err.ViaField("spec.debug")
+		}
+	}
+
 	return nil
 }
 
@@ -161,6 +161,42 @@ func TestTaskRunSpec_Invalidate(t *testing.T) {
 			Timeout: &metav1.Duration{Duration: -48 * time.Hour},
 		},
 		wantErr: apis.ErrInvalidValue("-48h0m0s should be >= 0", "spec.timeout"),
+	}, {
+		name: "invalid cache configmap",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{
+				Name: "taskrefname",
+			},
+			Cache: &v1beta1.TaskCache{ConfigMap: "Task_Cache"},
+		},
+		wantErr: apis.ErrInvalidValue("a DNS-1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')", "spec.cache.configMap"),
+	}, {
+		name: "taskref with unknown resolver",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{
+				Name:        "taskrefname",
+				ResolverRef: v1beta1.ResolverRef{Resolver: "svn"},
+			},
+		},
+		wantErr: apis.ErrInvalidValue("svn should be one of [git http]", "spec.taskref.resolver"),
+	}, {
+		name: "taskref with invalid bundle",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{
+				Name:   "taskrefname",
+				Bundle: "not a reference",
+			},
+		},
+		wantErr: apis.ErrInvalidValue("not a reference should be an image reference", "spec.taskref.bundle"),
+	}, {
+		name: "invalid debug breakpoint",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{
+				Name: "taskrefname",
+			},
+			Debug: &v1beta1.TaskRunDebug{Breakpoint: []string{"always"}},
+		},
+		wantErr: apis.ErrInvalidValue("always should be onFailure", "spec.debug.breakpoint[0]"),
 	}, {
 		name: "wrong taskrun cancel",
 		spec: v1beta1.TaskRunSpec{
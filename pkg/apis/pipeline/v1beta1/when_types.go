@@ -17,9 +17,41 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/operators"
+	lru "github.com/hashicorp/golang-lru"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
 	"k8s.io/apimachinery/pkg/selection"
 )
 
+const (
+	// WhenOperatorDoesNotExist is the operator for a When Expression which is true when its Input is empty
+	WhenOperatorDoesNotExist selection.Operator = "doesnotexist"
+	// WhenOperatorMatches is the operator for a When Expression which is true when its Input matches
+	// any of the regular expressions in its Values
+	WhenOperatorMatches selection.Operator = "matches"
+)
+
+const (
+	// PipelineTaskStatusSucceeded is the status of a PipelineTask which succeeded, as read by When Expressions
+	// from $(tasks.<name>.status) or tasks.<name>.status
+	PipelineTaskStatusSucceeded = "Succeeded"
+	// PipelineTaskStatusFailed is the status of a PipelineTask which failed or was cancelled
+	PipelineTaskStatusFailed = "Failed"
+	// PipelineTaskStatusNone is the status of a PipelineTask which didn't run
+	PipelineTaskStatusNone = "None"
+
+	// taskStatusPart is the last part of a reference to the status of a PipelineTask, tasks.<name>.status
+	taskStatusPart = "status"
+)
+
 // WhenExpression allows a PipelineTask to declare expressions to be evaluated before the Task is run
 // to determine whether the Task should be executed or skipped
 type WhenExpression struct {
@@ -28,8 +60,155 @@ type WhenExpression struct {
 	// Operator that represents an Input's relationship to the values
 	Operator selection.Operator
 	// Values is an array of strings, which is compared against the input, for guard checking
-	// It must be non-empty
+	// It must be empty for the exists and doesnotexist operators, hold exactly one number for the gt and lt
+	// operators, and be non-empty otherwise
 	Values []string
+	// CEL is a Common Expression Language expression which must evaluate to a boolean, for guard checking
+	// Variables, such as Parameters and Results, can be used inside its string literals
+	// It cannot be combined with Input, Operator and Values
+	// +optional
+	CEL string `json:"cel,omitempty"`
+}
+
+// celEscaper escapes the values substituted into the string literals of a CEL expression
+var celEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, `"`, `\"`)
+
+// celProgramCacheSize is the number of compiled CEL expressions which are kept in memory
+const celProgramCacheSize = 1024
+
+var (
+	// celEnv is the environment in which every CEL expression is compiled; it declares the variable
+	// tasks, which holds the status of each PipelineTask, as in tasks.<name>.status
+	celEnv *cel.Env
+	// celPrograms holds the CEL expressions compiled successfully, keyed by expression, so that the
+	// guards of a PipelineTask are not compiled again each time they are validated or evaluated
+	celPrograms *lru.Cache
+)
+
+func init() {
+	var err error
+	if celEnv, err = cel.NewEnv(cel.Declarations(
+		decls.NewVar(ResultTaskPart, decls.NewMapType(decls.String, decls.NewMapType(decls.String, decls.String))),
+	)); err != nil {
+		panic(err)
+	}
+	if celPrograms, err = lru.New(celProgramCacheSize); err != nil {
+		panic(err)
+	}
+}
+
+// celExpression is a compiled CEL expression
+type celExpression struct {
+	program cel.Program
+	// taskStatusRefs are the names of the PipelineTasks whose status the expression reads
+	taskStatusRefs []string
+}
+
+// compileCEL parses and type checks a CEL expression, making sure that it evaluates to a boolean and that
+// it only reads the tasks variable as tasks.<name>.status
+func compileCEL(expression string) (*celExpression, error) {
+	if compiled, ok := celPrograms.Get(expression); ok {
+		return compiled.(*celExpression), nil
+	}
+	ast, issues := celEnv.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if !proto.Equal(ast.ResultType(), decls.Bool) {
+		return nil, fmt.Errorf("expression evaluates to %v instead of a boolean", ast.ResultType())
+	}
+	taskStatusRefs, err := celTaskStatusRefs(ast.Expr())
+	if err != nil {
+		return nil, err
+	}
+	program, err := celEnv.Program(ast)
+	if err != nil {
+		return nil, err
+	}
+	compiled := &celExpression{program: program, taskStatusRefs: taskStatusRefs}
+	celPrograms.Add(expression, compiled)
+	return compiled, nil
+}
+
+// celTaskStatusRefs returns the names of the PipelineTasks whose status the CEL expression e reads, as
+// tasks.<name>.status or tasks['<name>'].status, which is the only way it may read the tasks variable
+func celTaskStatusRefs(e *exprpb.Expr) ([]string, error) {
+	if e == nil {
+		return nil, nil
+	}
+	if operand, field, ok := celAccess(e); ok && field == taskStatusPart {
+		if tasks, name, ok := celAccess(operand); ok && tasks.GetIdentExpr().GetName() == ResultTaskPart {
+			return []string{name}, nil
+		}
+	}
+	var children []*exprpb.Expr
+	switch {
+	case e.GetIdentExpr() != nil:
+		if e.GetIdentExpr().GetName() == ResultTaskPart {
+			return nil, fmt.Errorf("the %s variable can only be read as %s.<name>.%s", ResultTaskPart, ResultTaskPart, taskStatusPart)
+		}
+	case e.GetSelectExpr() != nil:
+		children = append(children, e.GetSelectExpr().GetOperand())
+	case e.GetCallExpr() != nil:
+		children = append(children, e.GetCallExpr().GetTarget())
+		children = append(children, e.GetCallExpr().GetArgs()...)
+	case e.GetListExpr() != nil:
+		children = append(children, e.GetListExpr().GetElements()...)
+	case e.GetStructExpr() != nil:
+		for _, entry := range e.GetStructExpr().GetEntries() {
+			children = append(children, entry.GetMapKey(), entry.GetValue())
+		}
+	case e.GetComprehensionExpr() != nil:
+		c := e.GetComprehensionExpr()
+		children = append(children, c.GetIterRange(), c.GetAccuInit(), c.GetLoopCondition(), c.GetLoopStep(), c.GetResult())
+	}
+	var refs []string
+	for _, child := range children {
+		childRefs, err := celTaskStatusRefs(child)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, childRefs...)
+	}
+	return refs, nil
+}
+
+// celAccess returns the operand and the key of e if it reads a constant key of a map, as operand.key or
+// operand['key']
+func celAccess(e *exprpb.Expr) (*exprpb.Expr, string, bool) {
+	if sel := e.GetSelectExpr(); sel != nil && !sel.GetTestOnly() {
+		return sel.GetOperand(), sel.GetField(), true
+	}
+	if call := e.GetCallExpr(); call != nil && call.GetFunction() == operators.Index && len(call.GetArgs()) == 2 {
+		if key := call.GetArgs()[1].GetConstExpr(); key != nil {
+			if _, ok := key.GetConstantKind().(*exprpb.Constant_StringValue); ok {
+				return call.GetArgs()[0], key.GetStringValue(), true
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// evaluateCEL evaluates the CEL expression, which must not contain any variables to substitute anymore,
+// with the status of each PipelineTask in taskStatuses, keyed by PipelineTask name
+func (we *WhenExpression) evaluateCEL(taskStatuses map[string]string) (bool, error) {
+	compiled, err := compileCEL(we.CEL)
+	if err != nil {
+		return false, err
+	}
+	tasks := make(map[string]map[string]string, len(taskStatuses))
+	for name, status := range taskStatuses {
+		tasks[name] = map[string]string{taskStatusPart: status}
+	}
+	out, _, err := compiled.program.Eval(map[string]interface{}{ResultTaskPart: tasks})
+	if err != nil {
+		return false, err
+	}
+	allowed, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression evaluated to %v instead of a boolean", out.Value())
+	}
+	return allowed, nil
 }
 
 func (we *WhenExpression) isInputInValues() bool {
@@ -41,9 +220,64 @@ func (we *WhenExpression) isInputInValues() bool {
 	return false
 }
 
-func (we *WhenExpression) isTrue() bool {
-	if we.Operator == selection.In {
+func (we *WhenExpression) isInputMatchingValues() bool {
+	for i := range we.Values {
+		re, err := regexp.Compile(we.Values[i])
+		if err != nil {
+			continue
+		}
+		if re.MatchString(we.Input) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareInputToValue parses the Input and the only Value as numbers and returns -1, 0 or 1
+// depending on whether the Input is less than, equal to or greater than the Value.
+// It returns false if either of them is not a number.
+func (we *WhenExpression) compareInputToValue() (int, bool) {
+	if len(we.Values) != 1 {
+		return 0, false
+	}
+	input, err := strconv.ParseFloat(we.Input, 64)
+	if err != nil {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(we.Values[0], 64)
+	if err != nil {
+		return 0, false
+	}
+	switch {
+	case input < value:
+		return -1, true
+	case input > value:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+func (we *WhenExpression) isTrue(taskStatuses map[string]string) bool {
+	if we.CEL != "" {
+		allowed, err := we.evaluateCEL(taskStatuses)
+		return err == nil && allowed
+	}
+	switch we.Operator {
+	case selection.In:
 		return we.isInputInValues()
+	case selection.Exists:
+		return we.Input != ""
+	case WhenOperatorDoesNotExist:
+		return we.Input == ""
+	case selection.GreaterThan:
+		cmp, ok := we.compareInputToValue()
+		return ok && cmp > 0
+	case selection.LessThan:
+		cmp, ok := we.compareInputToValue()
+		return ok && cmp < 0
+	case WhenOperatorMatches:
+		return we.isInputMatchingValues()
 	}
 	// selection.NotIn
 	return !we.isInputInValues()
@@ -64,7 +298,13 @@ func (we *WhenExpression) applyReplacements(replacements map[string]string) When
 		replacedValues = append(replacedValues, ApplyReplacements(val, replacements))
 	}
 
-	return WhenExpression{Input: replacedInput, Operator: we.Operator, Values: replacedValues}
+	celReplacements := make(map[string]string, len(replacements))
+	for k, v := range replacements {
+		celReplacements[k] = celEscaper.Replace(v)
+	}
+	replacedCEL := ApplyReplacements(we.CEL, celReplacements)
+
+	return WhenExpression{Input: replacedInput, Operator: we.Operator, Values: replacedValues, CEL: replacedCEL}
 }
 
 // GetVarSubstitutionExpressions extracts all the values between "$(" and ")" in a When Expression
@@ -74,25 +314,78 @@ func (we *WhenExpression) GetVarSubstitutionExpressions() ([]string, bool) {
 	for _, value := range we.Values {
 		allExpressions = append(allExpressions, validateString(value)...)
 	}
+	allExpressions = append(allExpressions, validateString(we.CEL)...)
 	return allExpressions, len(allExpressions) != 0
 }
 
+// taskStatusRefs returns the names of the PipelineTasks whose status the When Expression reads, as
+// $(tasks.<name>.status) or, in its CEL expression, as tasks.<name>.status
+func (we *WhenExpression) taskStatusRefs() []string {
+	var refs []string
+	expressions, _ := we.GetVarSubstitutionExpressions()
+	for _, expression := range expressions {
+		if parts := strings.Split(expression, "."); len(parts) == 3 && parts[0] == ResultTaskPart && parts[2] == taskStatusPart {
+			refs = append(refs, parts[1])
+		}
+	}
+	if we.CEL != "" {
+		// The variables are substituted into string literals, so they are removed to type check the expression
+		if compiled, err := compileCEL(variableSubstitutionRegex.ReplaceAllString(we.CEL, "")); err == nil {
+			refs = append(refs, compiled.taskStatusRefs...)
+		}
+	}
+	return refs
+}
+
+// skipMessage describes why the When Expression does not allow execution
+func (we *WhenExpression) skipMessage(taskStatuses map[string]string) string {
+	if we.CEL != "" {
+		if _, err := we.evaluateCEL(taskStatuses); err != nil {
+			return fmt.Sprintf("cel expression %q failed to evaluate: %v", we.CEL, err)
+		}
+		return fmt.Sprintf("cel expression %q evaluated to false", we.CEL)
+	}
+	return fmt.Sprintf("input %q with operator %q and values %q evaluated to false", we.Input, we.Operator, we.Values)
+}
+
 // WhenExpressions are used to specify whether a Task should be executed or skipped
 // All of them need to evaluate to True for a guarded Task to be executed.
 type WhenExpressions []WhenExpression
 
 // AllowsExecution evaluates an Input's relationship to an array of Values, based on the Operator,
 // to determine whether all the When Expressions are True. If they are all True, the guarded Task is
-// executed, otherwise it is skipped.
-func (wes WhenExpressions) AllowsExecution() bool {
+// executed, otherwise it is skipped. The CEL expressions read the status of each PipelineTask from
+// taskStatuses, keyed by PipelineTask name.
+func (wes WhenExpressions) AllowsExecution(taskStatuses map[string]string) bool {
 	for _, we := range wes {
-		if !we.isTrue() {
+		if !we.isTrue(taskStatuses) {
 			return false
 		}
 	}
 	return true
 }
 
+// SkipMessage describes the first When Expression which does not allow execution, or is
+// empty if all of them evaluate to True.
+func (wes WhenExpressions) SkipMessage(taskStatuses map[string]string) string {
+	for _, we := range wes {
+		if !we.isTrue(taskStatuses) {
+			return we.skipMessage(taskStatuses)
+		}
+	}
+	return ""
+}
+
+// TaskStatusRefs returns the names of the PipelineTasks whose status the When Expressions read,
+// as $(tasks.<name>.status) or, in their CEL expressions, as tasks.<name>.status
+func (wes WhenExpressions) TaskStatusRefs() []string {
+	var refs []string
+	for _, we := range wes {
+		refs = append(refs, we.taskStatusRefs()...)
+	}
+	return refs
+}
+
 // HaveVariables indicates whether When Expressions contains variables, such as Parameters
 // or Results in the Inputs or Values.
 func (wes WhenExpressions) HaveVariables() bool {
@@ -105,7 +398,7 @@ func (wes WhenExpressions) HaveVariables() bool {
 }
 
 // ReplaceWhenExpressionsVariables interpolates variables, such as Parameters and Results, in
-// the Input and Values.
+// the Input and Values. Once all of its variables are interpolated, a CEL expression can be evaluated.
 func (wes WhenExpressions) ReplaceWhenExpressionsVariables(replacements map[string]string) WhenExpressions {
 	replaced := wes
 	for i := range wes {
@@ -28,6 +28,7 @@ func TestAllowsExecution(t *testing.T) {
 	tests := []struct {
 		name            string
 		whenExpressions WhenExpressions
+		taskStatuses    map[string]string
 		expected        bool
 	}{{
 		name: "in expression",
@@ -49,6 +50,93 @@ func TestAllowsExecution(t *testing.T) {
 			},
 		},
 		expected: false,
+	}, {
+		name: "exists expression",
+		whenExpressions: WhenExpressions{
+			{
+				Input:    "main",
+				Operator: selection.Exists,
+			},
+		},
+		expected: true,
+	}, {
+		name: "exists expression - empty input",
+		whenExpressions: WhenExpressions{
+			{
+				Input:    "",
+				Operator: selection.Exists,
+			},
+		},
+		expected: false,
+	}, {
+		name: "doesnotexist expression",
+		whenExpressions: WhenExpressions{
+			{
+				Input:    "",
+				Operator: WhenOperatorDoesNotExist,
+			},
+		},
+		expected: true,
+	}, {
+		name: "gt expression",
+		whenExpressions: WhenExpressions{
+			{
+				Input:    "85.2",
+				Operator: selection.GreaterThan,
+				Values:   []string{"80"},
+			},
+		},
+		expected: true,
+	}, {
+		name: "gt expression - equal",
+		whenExpressions: WhenExpressions{
+			{
+				Input:    "80",
+				Operator: selection.GreaterThan,
+				Values:   []string{"80"},
+			},
+		},
+		expected: false,
+	}, {
+		name: "lt expression",
+		whenExpressions: WhenExpressions{
+			{
+				Input:    "72",
+				Operator: selection.LessThan,
+				Values:   []string{"80"},
+			},
+		},
+		expected: true,
+	}, {
+		name: "lt expression - non-numeric input",
+		whenExpressions: WhenExpressions{
+			{
+				Input:    "unknown",
+				Operator: selection.LessThan,
+				Values:   []string{"80"},
+			},
+		},
+		expected: false,
+	}, {
+		name: "matches expression",
+		whenExpressions: WhenExpressions{
+			{
+				Input:    "release-v0.21",
+				Operator: WhenOperatorMatches,
+				Values:   []string{"^main$", "^release-.*"},
+			},
+		},
+		expected: true,
+	}, {
+		name: "matches expression - no match",
+		whenExpressions: WhenExpressions{
+			{
+				Input:    "feature-foo",
+				Operator: WhenOperatorMatches,
+				Values:   []string{"^main$", "^release-.*"},
+			},
+		},
+		expected: false,
 	}, {
 		name: "multiple expressions - false",
 		whenExpressions: WhenExpressions{
@@ -77,10 +165,51 @@ func TestAllowsExecution(t *testing.T) {
 			},
 		},
 		expected: true,
+	}, {
+		name: "cel expression - true",
+		whenExpressions: WhenExpressions{
+			{
+				CEL: "'0' == '0' && 'dev' != 'prod'",
+			},
+		},
+		expected: true,
+	}, {
+		name: "cel expression - false",
+		whenExpressions: WhenExpressions{
+			{
+				CEL: "int('72') >= 80",
+			},
+		},
+		expected: false,
+	}, {
+		name: "cel expression - evaluation error",
+		whenExpressions: WhenExpressions{
+			{
+				CEL: "int('unknown') >= 80",
+			},
+		},
+		expected: false,
+	}, {
+		name: "cel expression - task status",
+		whenExpressions: WhenExpressions{
+			{
+				CEL: "tasks.build.status == 'Failed' && tasks['unit-tests'].status == 'Succeeded'",
+			},
+		},
+		taskStatuses: map[string]string{"build": PipelineTaskStatusFailed, "unit-tests": PipelineTaskStatusSucceeded},
+		expected:     true,
+	}, {
+		name: "cel expression - unknown task status",
+		whenExpressions: WhenExpressions{
+			{
+				CEL: "tasks.build.status == 'Failed'",
+			},
+		},
+		expected: false,
 	}}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := tc.whenExpressions.AllowsExecution()
+			got := tc.whenExpressions.AllowsExecution(tc.taskStatuses)
 			if d := cmp.Diff(tc.expected, got); d != "" {
 				t.Errorf("Error evaluating AllowsExecution() for When Expressions in test case %s: %s", tc.name, diff.PrintWantGot(d))
 			}
@@ -137,6 +266,14 @@ func TestHaveVariables(t *testing.T) {
 			},
 		},
 		expected: true,
+	}, {
+		name: "have variable - cel",
+		whenExpressions: WhenExpressions{
+			{
+				CEL: "'$(tasks.lint.results.count)' == '0'",
+			},
+		},
+		expected: true,
 	}}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -155,6 +292,22 @@ func TestReplaceWhenExpressionsVariables(t *testing.T) {
 		replacements    map[string]string
 		expected        WhenExpressions
 	}{{
+		name: "params and results replacement in cel expression",
+		whenExpressions: WhenExpressions{
+			{
+				CEL: "'$(tasks.lint.results.count)' == '0' && '$(params.env)' != 'prod'",
+			},
+		},
+		replacements: map[string]string{
+			"tasks.lint.results.count": "0",
+			"params.env":               `it's a "test"`,
+		},
+		expected: WhenExpressions{
+			{
+				CEL: `'0' == '0' && 'it\'s a \"test\"' != 'prod'`,
+			},
+		},
+	}, {
 		name: "params replacement in input",
 		whenExpressions: WhenExpressions{
 			{
@@ -317,3 +470,126 @@ func TestApplyReplacements(t *testing.T) {
 		})
 	}
 }
+
+func TestSkipMessage(t *testing.T) {
+	tests := []struct {
+		name            string
+		whenExpressions WhenExpressions
+		expected        string
+	}{{
+		name: "all when expressions are true",
+		whenExpressions: WhenExpressions{
+			{
+				Input:    "foo",
+				Operator: selection.In,
+				Values:   []string{"foo"},
+			},
+		},
+		expected: "",
+	}, {
+		name: "when expression is false",
+		whenExpressions: WhenExpressions{
+			{
+				Input:    "foo",
+				Operator: selection.In,
+				Values:   []string{"foo"},
+			}, {
+				Input:    "foo",
+				Operator: selection.NotIn,
+				Values:   []string{"foo"},
+			},
+		},
+		expected: `input "foo" with operator "notin" and values ["foo"] evaluated to false`,
+	}, {
+		name: "cel expression is false",
+		whenExpressions: WhenExpressions{
+			{
+				CEL: "'prod' != 'prod'",
+			},
+		},
+		expected: `cel expression "'prod' != 'prod'" evaluated to false`,
+	}, {
+		name: "cel expression fails to evaluate",
+		whenExpressions: WhenExpressions{
+			{
+				CEL: "int('unknown') >= 80",
+			},
+		},
+		expected: `cel expression "int('unknown') >= 80" failed to evaluate: type conversion error from 'string' to 'int'`,
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.whenExpressions.SkipMessage(nil)
+			if d := cmp.Diff(tc.expected, got); d != "" {
+				t.Errorf("Error evaluating SkipMessage() for When Expressions in test case %s: %s", tc.name, diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestTaskStatusRefs(t *testing.T) {
+	tests := []struct {
+		name            string
+		whenExpressions WhenExpressions
+		expected        []string
+	}{{
+		name: "no task status",
+		whenExpressions: WhenExpressions{
+			{
+				Input:    "$(tasks.lint.results.count)",
+				Operator: selection.In,
+				Values:   []string{"0"},
+			}, {
+				CEL: "'$(params.env)' != 'prod'",
+			},
+		},
+	}, {
+		name: "variables",
+		whenExpressions: WhenExpressions{
+			{
+				Input:    "$(tasks.build.status)",
+				Operator: selection.In,
+				Values:   []string{"Failed"},
+			}, {
+				CEL: "'$(tasks.unit-tests.status)' == 'Succeeded'",
+			},
+		},
+		expected: []string{"build", "unit-tests"},
+	}, {
+		name: "cel variable",
+		whenExpressions: WhenExpressions{
+			{
+				CEL: "tasks.build.status == 'Failed' || tasks['unit-tests'].status == 'Failed'",
+			},
+		},
+		expected: []string{"build", "unit-tests"},
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if d := cmp.Diff(tc.expected, tc.whenExpressions.TaskStatusRefs()); d != "" {
+				t.Errorf("Error getting TaskStatusRefs() for When Expressions in test case %s: %s", tc.name, diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestCompileCELReusesPrograms(t *testing.T) {
+	expression := "'foo' in ['foo', 'bar']"
+	first, err := compileCEL(expression)
+	if err != nil {
+		t.Fatalf("Unexpected error compiling %q: %v", expression, err)
+	}
+	second, err := compileCEL(expression)
+	if err != nil {
+		t.Fatalf("Unexpected error compiling %q: %v", expression, err)
+	}
+	if first != second {
+		t.Errorf("Expected the program of %q to be compiled once and reused", expression)
+	}
+	if _, err := compileCEL("'foo'"); err == nil {
+		t.Errorf("Expected an error compiling an expression which does not evaluate to a boolean")
+	}
+	if _, err := compileCEL("size(tasks) > 0"); err == nil {
+		t.Errorf("Expected an error compiling an expression which reads the tasks variable as a whole")
+	}
+}
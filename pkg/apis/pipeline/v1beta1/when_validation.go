@@ -18,6 +18,8 @@ package v1beta1
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/tektoncd/pipeline/pkg/substitution"
@@ -30,6 +32,11 @@ import (
 var validWhenOperators = []string{
 	string(selection.In),
 	string(selection.NotIn),
+	string(selection.Exists),
+	string(WhenOperatorDoesNotExist),
+	string(selection.GreaterThan),
+	string(selection.LessThan),
+	string(WhenOperatorMatches),
 }
 
 func (wes WhenExpressions) validate() *apis.FieldError {
@@ -45,26 +52,100 @@ func (wes WhenExpressions) validate() *apis.FieldError {
 func (wes WhenExpressions) validateWhenExpressionsFields() *apis.FieldError {
 	for _, we := range wes {
 		if err := we.validateWhenExpressionFields(); err != nil {
-			return err
+			return err.ViaField("spec.task.when")
 		}
 	}
 	return nil
 }
 
+// validateStepWhenExpressions validates the When Expressions of a Step, which can only reference
+// Parameters, the context and the Results written by earlier Steps
+func (wes WhenExpressions) validateStepWhenExpressions() (errs *apis.FieldError) {
+	for i, we := range wes {
+		if err := we.validateWhenExpressionFields(); err != nil {
+			errs = errs.Also(err.ViaIndex(i))
+			continue
+		}
+		if expressions, ok := we.GetVarSubstitutionExpressions(); ok && LooksLikeContainsResultRefs(expressions) {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("expressions %v cannot reference the results of pipeline tasks", expressions), apis.CurrentField).ViaIndex(i))
+		}
+		if refs := we.taskStatusRefs(); len(refs) != 0 {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("expressions cannot read the status of pipeline tasks %v", refs), apis.CurrentField).ViaIndex(i))
+		}
+		if we.CEL != "" {
+			if err := we.checkCEL(); err != nil {
+				errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("invalid cel expression %q: %v", we.CEL, err), "cel").ViaIndex(i))
+			}
+		}
+	}
+	return errs
+}
+
+// checkCEL type checks the CEL expression. Variables can only be used inside its string literals, so
+// they are checked as empty strings until they are substituted.
+func (we *WhenExpression) checkCEL() error {
+	_, err := compileCEL(variableSubstitutionRegex.ReplaceAllString(we.CEL, ""))
+	return err
+}
+
 func (we *WhenExpression) validateWhenExpressionFields() *apis.FieldError {
 	if equality.Semantic.DeepEqual(we, &WhenExpression{}) || we == nil {
 		return apis.ErrMissingField(apis.CurrentField)
 	}
+	if we.CEL != "" {
+		if we.Input != "" || we.Operator != "" || len(we.Values) != 0 {
+			return apis.ErrInvalidValue("expecting either a cel expression or input, operator and values", apis.CurrentField)
+		}
+		return nil
+	}
 	if !sets.NewString(validWhenOperators...).Has(string(we.Operator)) {
 		message := fmt.Sprintf("operator %q is not recognized. valid operators: %s", we.Operator, strings.Join(validWhenOperators, ","))
-		return apis.ErrInvalidValue(message, "spec.task.when")
+		return apis.ErrInvalidValue(message, apis.CurrentField)
+	}
+	switch we.Operator {
+	case selection.Exists, WhenOperatorDoesNotExist:
+		if len(we.Values) != 0 {
+			return apis.ErrInvalidValue(fmt.Sprintf("expecting empty values field for operator %q", we.Operator), apis.CurrentField)
+		}
+		return nil
+	case selection.GreaterThan, selection.LessThan:
+		if len(we.Values) != 1 {
+			return apis.ErrInvalidValue(fmt.Sprintf("expecting exactly one value for operator %q", we.Operator), apis.CurrentField)
+		}
+		for _, v := range []string{we.Input, we.Values[0]} {
+			if isNumericLiteral(v) {
+				continue
+			}
+			return apis.ErrInvalidValue(fmt.Sprintf("expecting a number for operator %q but got %q", we.Operator, v), apis.CurrentField)
+		}
+		return nil
 	}
 	if len(we.Values) == 0 {
-		return apis.ErrInvalidValue("expecting non-empty values field", "spec.task.when")
+		return apis.ErrInvalidValue("expecting non-empty values field", apis.CurrentField)
+	}
+	if we.Operator == WhenOperatorMatches {
+		for _, v := range we.Values {
+			if len(validateString(v)) != 0 {
+				continue
+			}
+			if _, err := regexp.Compile(v); err != nil {
+				return apis.ErrInvalidValue(fmt.Sprintf("invalid regular expression %q: %v", v, err), apis.CurrentField)
+			}
+		}
 	}
 	return nil
 }
 
+// isNumericLiteral returns true if the value is a number or contains variables, whose
+// values can only be checked once they are substituted.
+func isNumericLiteral(value string) bool {
+	if len(validateString(value)) != 0 {
+		return true
+	}
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
 func (wes WhenExpressions) validateTaskResultsVariables() *apis.FieldError {
 	for _, we := range wes {
 		expressions, ok := we.GetVarSubstitutionExpressions()
@@ -82,25 +163,31 @@ func (wes WhenExpressions) validateTaskResultsVariables() *apis.FieldError {
 	return nil
 }
 
-func (wes WhenExpressions) validatePipelineParametersVariables(prefix string, paramNames sets.String, arrayParamNames sets.String) *apis.FieldError {
+func (wes WhenExpressions) validatePipelineParametersVariables(prefix string, paramNames sets.String, arrayParamNames sets.String, objectParamKeys map[string]sets.String) *apis.FieldError {
 	for _, we := range wes {
-		if err := validateStringVariable(fmt.Sprintf("input[%s]", we.Input), we.Input, prefix, paramNames, arrayParamNames); err != nil {
+		if err := validateStringVariable(fmt.Sprintf("input[%s]", we.Input), we.Input, prefix, paramNames, arrayParamNames, objectParamKeys); err != nil {
 			return err
 		}
 		for _, val := range we.Values {
-			if err := validateStringVariable(fmt.Sprintf("values[%s]", val), val, prefix, paramNames, arrayParamNames); err != nil {
+			if err := validateStringVariable(fmt.Sprintf("values[%s]", val), val, prefix, paramNames, arrayParamNames, objectParamKeys); err != nil {
 				return err
 			}
 		}
+		if err := validateStringVariable(fmt.Sprintf("cel[%s]", we.CEL), we.CEL, prefix, paramNames, arrayParamNames, objectParamKeys); err != nil {
+			return err
+		}
 	}
 	return nil
 }
-func validateStringVariable(name, value, prefix string, stringVars sets.String, arrayVars sets.String) *apis.FieldError {
+func validateStringVariable(name, value, prefix string, stringVars sets.String, arrayVars sets.String, objectKeys map[string]sets.String) *apis.FieldError {
 	if err := substitution.ValidateVariable(name, value, prefix, "task when expression", "pipelinespec.when", stringVars); err != nil {
 		return err
 	}
 	if err := substitution.ValidateVariableProhibited(name, value, prefix, "task when expression", "pipelinespec.when", arrayVars); err != nil {
 		return err
 	}
+	if err := substitution.ValidateVariableObjectKeys(name, value, prefix, "task when expression", "pipelinespec.when", objectKeys); err != nil {
+		return err
+	}
 	return nil
 }
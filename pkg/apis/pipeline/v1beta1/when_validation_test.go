@@ -40,6 +40,44 @@ func TestWhenExpressions_Valid(t *testing.T) {
 			Operator: selection.NotIn,
 			Values:   []string{"bar"},
 		}},
+	}, {
+		name: "valid operator - Exists - without values",
+		wes: []WhenExpression{{
+			Input:    "$(params.branch)",
+			Operator: selection.Exists,
+		}},
+	}, {
+		name: "valid operator - DoesNotExist - without values",
+		wes: []WhenExpression{{
+			Input:    "$(params.branch)",
+			Operator: WhenOperatorDoesNotExist,
+		}},
+	}, {
+		name: "valid operator - Gt - and numeric value",
+		wes: []WhenExpression{{
+			Input:    "$(tasks.test.results.coverage)",
+			Operator: selection.GreaterThan,
+			Values:   []string{"80.5"},
+		}},
+	}, {
+		name: "valid operator - Lt - and variable value",
+		wes: []WhenExpression{{
+			Input:    "3",
+			Operator: selection.LessThan,
+			Values:   []string{"$(params.max-retries)"},
+		}},
+	}, {
+		name: "valid operator - Matches - and regular expressions",
+		wes: []WhenExpression{{
+			Input:    "$(params.branch)",
+			Operator: WhenOperatorMatches,
+			Values:   []string{"^release-.*$", "^main$", "$(params.pattern)"},
+		}},
+	}, {
+		name: "valid cel expression",
+		wes: []WhenExpression{{
+			CEL: "'$(tasks.lint.results.count)' == '0' && '$(params.env)' != 'prod'",
+		}},
 	}, {
 		wes: []WhenExpression{{
 			Input:    "$(tasks.a-task.results.output)",
@@ -66,12 +104,60 @@ func TestWhenExpressions_Invalid(t *testing.T) {
 		name string
 		wes  WhenExpressions
 	}{{
-		name: "invalid operator - exists",
+		name: "invalid operator - equals",
+		wes: []WhenExpression{{
+			Input:    "foo",
+			Operator: selection.Equals,
+			Values:   []string{"foo"},
+		}},
+	}, {
+		name: "invalid values - exists with values",
 		wes: []WhenExpression{{
 			Input:    "foo",
 			Operator: selection.Exists,
 			Values:   []string{"foo"},
 		}},
+	}, {
+		name: "invalid values - doesnotexist with values",
+		wes: []WhenExpression{{
+			Input:    "foo",
+			Operator: WhenOperatorDoesNotExist,
+			Values:   []string{"foo"},
+		}},
+	}, {
+		name: "invalid values - gt with more than one value",
+		wes: []WhenExpression{{
+			Input:    "5",
+			Operator: selection.GreaterThan,
+			Values:   []string{"1", "2"},
+		}},
+	}, {
+		name: "invalid values - gt with non-numeric value",
+		wes: []WhenExpression{{
+			Input:    "5",
+			Operator: selection.GreaterThan,
+			Values:   []string{"eighty"},
+		}},
+	}, {
+		name: "invalid input - lt with non-numeric input",
+		wes: []WhenExpression{{
+			Input:    "five",
+			Operator: selection.LessThan,
+			Values:   []string{"80"},
+		}},
+	}, {
+		name: "invalid values - matches with invalid regular expression",
+		wes: []WhenExpression{{
+			Input:    "foo",
+			Operator: WhenOperatorMatches,
+			Values:   []string{"release-(.*"},
+		}},
+	}, {
+		name: "invalid values - matches without values",
+		wes: []WhenExpression{{
+			Input:    "foo",
+			Operator: WhenOperatorMatches,
+		}},
 	}, {
 		name: "invalid values - empty",
 		wes: []WhenExpression{{
@@ -98,6 +184,17 @@ func TestWhenExpressions_Invalid(t *testing.T) {
 			Operator: selection.In,
 			Values:   []string{"bar"},
 		}},
+	}, {
+		name: "cel expression with input",
+		wes: []WhenExpression{{
+			Input: "foo",
+			CEL:   "'foo' == 'bar'",
+		}},
+	}, {
+		name: "cel expression with invalid variable",
+		wes: []WhenExpression{{
+			CEL: "'$(tasks.a-task.resultsTypo.output)' == 'bar'",
+		}},
 	}, {
 		name: "missing when expression",
 		wes:  []WhenExpression{{}},
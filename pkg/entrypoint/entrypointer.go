@@ -17,10 +17,15 @@ limitations under the License.
 package entrypoint
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
@@ -34,6 +39,23 @@ const (
 	timeFormat = "2006-01-02T15:04:05.000Z07:00"
 )
 
+// ContinueOnError is the value of OnError which makes the next steps run when the command
+// exits with a non-zero exit code
+const ContinueOnError = "continue"
+
+const (
+	// BreakpointSuffix is appended to the post file to name the file written while the step is
+	// held at a breakpoint after failing
+	BreakpointSuffix = ".breakpoint"
+	// BreakpointExitSuffix is appended to the post file to name the file which resumes a step held
+	// at a breakpoint as if it succeeded, or as failed if it is suffixed with ".err"
+	BreakpointExitSuffix = ".breakpointexit"
+)
+
+// TimedOutExitCode is the exit code reported for a command terminated because it ran longer
+// than its timeout
+const TimedOutExitCode = 1
+
 // Entrypointer holds fields for running commands with redirected
 // entrypoints.
 type Entrypointer struct {
@@ -63,6 +85,17 @@ type Entrypointer struct {
 
 	// Results is the set of files that might contain task results
 	Results []string
+	// WhenExpressions are evaluated before running the command, which is skipped
+	// if any of them evaluates to false
+	WhenExpressions v1beta1.WhenExpressions
+	// OnError is ContinueOnError if the next steps should run even if the command exits with
+	// a non-zero exit code, which is then recorded in the termination message
+	OnError string
+	// Timeout is the time after which the command is terminated, if it is positive
+	Timeout time.Duration
+	// BreakpointOnFailure holds the step after the command fails until a file suffixed with
+	// BreakpointExitSuffix is written, so that its container can be debugged
+	BreakpointOnFailure bool
 }
 
 // Waiter encapsulates waiting for files to exist.
@@ -73,7 +106,9 @@ type Waiter interface {
 
 // Runner encapsulates running commands.
 type Runner interface {
-	Run(args ...string) error
+	// Run runs the command until it exits, or terminates it once ctx is done, in which case
+	// it returns the error of ctx.
+	Run(ctx context.Context, args ...string) error
 }
 
 // PostWriter encapsulates writing a file when complete.
@@ -118,7 +153,44 @@ func (e Entrypointer) Go() error {
 		Value: time.Now().Format(timeFormat),
 	})
 
-	err := e.Runner.Run(e.Args...)
+	if skipMessage := e.evaluateWhenExpressions(pipeline.DefaultResultPath); skipMessage != "" {
+		// The step is skipped without failing, so the next steps run as usual.
+		logger.Infof("Skipping step because %s", skipMessage)
+		output = append(output, v1beta1.PipelineResourceResult{
+			Key:   "Skipped",
+			Value: skipMessage,
+		})
+		e.WritePostFile(e.PostFile, nil)
+		return nil
+	}
+
+	ctx := context.Background()
+	if e.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.Timeout)
+		defer cancel()
+	}
+	err := e.Runner.Run(ctx, e.Args...)
+	if errors.Is(err, context.DeadlineExceeded) {
+		logger.Infof("Step timed out after %s", e.Timeout)
+		output = append(output, v1beta1.PipelineResourceResult{
+			Key:   "TimedOut",
+			Value: e.Timeout.String(),
+		})
+	}
+	if exitCode, ok := e.continuesOn(err); ok {
+		// The step failed without failing the TaskRun, so its exit code is reported
+		// and the next steps run as usual.
+		logger.Infof("Continuing after the step exited with code %d because onError is %s", exitCode, e.OnError)
+		output = append(output, v1beta1.PipelineResourceResult{
+			Key:   "ExitCode",
+			Value: strconv.Itoa(exitCode),
+		})
+	}
+
+	if _, ok := e.continuesOn(err); err != nil && !ok && e.BreakpointOnFailure {
+		err = e.holdOnFailure(err, logger)
+	}
 
 	// Write the post file *no matter what*
 	e.WritePostFile(e.PostFile, err)
@@ -131,9 +203,44 @@ func (e Entrypointer) Go() error {
 		}
 	}
 
+	if _, ok := e.continuesOn(err); ok {
+		return nil
+	}
 	return err
 }
 
+// continuesOn returns the exit code of the command if it failed or timed out with err but the
+// next steps should run anyway
+func (e Entrypointer) continuesOn(err error) (int, bool) {
+	if e.OnError != ContinueOnError {
+		return 0, false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return TimedOutExitCode, true
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return 0, false
+	}
+	return exitErr.ExitCode(), true
+}
+
+// holdOnFailure keeps the step running after the command failed with err until the breakpoint
+// exit file is written, and returns the error the step should fail with, if any
+func (e Entrypointer) holdOnFailure(err error, logger *zap.SugaredLogger) error {
+	if e.PostFile == "" {
+		return err
+	}
+	exitFile := e.PostFile + BreakpointExitSuffix
+	e.PostWriter.Write(e.PostFile + BreakpointSuffix)
+	logger.Infof("Holding the step after it failed with %v: write %s to continue, or %s.err to fail", err, exitFile, exitFile)
+	if wErr := e.Waiter.Wait(exitFile, false); wErr != nil {
+		return err
+	}
+	logger.Info("Continuing as if the step succeeded")
+	return nil
+}
+
 func (e Entrypointer) readResultsFromDisk() error {
 	output := []v1beta1.PipelineResourceResult{}
 	for _, resultFile := range e.Results {
@@ -162,9 +269,37 @@ func (e Entrypointer) readResultsFromDisk() error {
 	return nil
 }
 
-// WritePostFile write the postfile
+// evaluateWhenExpressions substitutes the results written by earlier steps, read from resultsDir,
+// into the When Expressions and evaluates them. It returns a message describing why the step
+// should be skipped, or an empty message if it should run.
+func (e Entrypointer) evaluateWhenExpressions(resultsDir string) string {
+	if len(e.WhenExpressions) == 0 {
+		return ""
+	}
+	replacements := map[string]string{}
+	for _, we := range e.WhenExpressions {
+		expressions, _ := we.GetVarSubstitutionExpressions()
+		for _, expression := range expressions {
+			parts := strings.Split(expression, ".")
+			if len(parts) != 2 || parts[0] != "results" {
+				continue
+			}
+			// a result which has not been written yet is substituted as an empty string
+			fileContents, err := ioutil.ReadFile(filepath.Join(resultsDir, parts[1]))
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Sprintf("result %q could not be read: %v", parts[1], err)
+			}
+			replacements[expression] = string(fileContents)
+		}
+	}
+	whenExpressions := e.WhenExpressions.DeepCopy().ReplaceWhenExpressionsVariables(replacements)
+	return whenExpressions.SkipMessage(nil)
+}
+
+// WritePostFile write the postfile, or the postfile suffixed with ".err" to make the next steps
+// bail if err failed the step
 func (e Entrypointer) WritePostFile(postFile string, err error) {
-	if err != nil && postFile != "" {
+	if _, ok := e.continuesOn(err); err != nil && !ok && postFile != "" {
 		postFile = fmt.Sprintf("%s.err", postFile)
 	}
 	if postFile != "" {
@@ -17,16 +17,23 @@ limitations under the License.
 package entrypoint
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/test/diff"
+	"k8s.io/apimachinery/pkg/selection"
 )
 
 func TestEntrypointerFailures(t *testing.T) {
@@ -205,6 +212,293 @@ func TestEntrypointer(t *testing.T) {
 	}
 }
 
+func TestEntrypointerSkipsStep(t *testing.T) {
+	fw, fr, fpw := &fakeWaiter{}, &fakeRunner{}, &fakePostWriter{}
+	err := Entrypointer{
+		Entrypoint:      "echo",
+		Args:            []string{"some", "args"},
+		PostFile:        "writeme",
+		Waiter:          fw,
+		Runner:          fr,
+		PostWriter:      fpw,
+		TerminationPath: "termination",
+		WhenExpressions: v1beta1.WhenExpressions{{
+			Input:    "prod",
+			Operator: selection.NotIn,
+			Values:   []string{"prod"},
+		}},
+	}.Go()
+	if err != nil {
+		t.Fatalf("Entrypointer failed: %v", err)
+	}
+	if fr.args != nil {
+		t.Errorf("Ran %s for a skipped step", *fr.args)
+	}
+	if fpw.wrote == nil || *fpw.wrote != "writeme" {
+		t.Errorf("Wanted post file %q written, got %v", "writeme", fpw.wrote)
+	}
+	fileContents, err := ioutil.ReadFile("termination")
+	if err != nil {
+		t.Fatalf("Wanted termination file written: %v", err)
+	}
+	defer os.Remove("termination")
+	var entries []v1beta1.PipelineResourceResult
+	if err := json.Unmarshal(fileContents, &entries); err != nil {
+		t.Fatalf("Couldn't parse termination message %q: %v", fileContents, err)
+	}
+	var skipMessage string
+	for _, result := range entries {
+		if result.Key == "Skipped" {
+			skipMessage = result.Value
+		}
+	}
+	want := `input "prod" with operator "notin" and values ["prod"] evaluated to false`
+	if d := cmp.Diff(want, skipMessage); d != "" {
+		t.Errorf("Skipped entry diff %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestEntrypointerOnError(t *testing.T) {
+	for _, c := range []struct {
+		desc         string
+		onError      string
+		runner       Runner
+		wantErr      bool
+		wantPostFile string
+		wantExitCode string
+	}{{
+		desc:         "continue on a non-zero exit code",
+		onError:      ContinueOnError,
+		runner:       &fakeExitErrorRunner{exitCode: 3},
+		wantPostFile: "writeme",
+		wantExitCode: "3",
+	}, {
+		desc:         "stop on a non-zero exit code",
+		runner:       &fakeExitErrorRunner{exitCode: 3},
+		wantErr:      true,
+		wantPostFile: "writeme.err",
+	}, {
+		desc:         "stop on an error which is not an exit code despite continue",
+		onError:      ContinueOnError,
+		runner:       &fakeErrorRunner{},
+		wantErr:      true,
+		wantPostFile: "writeme.err",
+	}, {
+		desc:         "continue on success",
+		onError:      ContinueOnError,
+		runner:       &fakeRunner{},
+		wantPostFile: "writeme",
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			fpw := &fakePostWriter{}
+			err := Entrypointer{
+				Entrypoint:      "lint",
+				PostFile:        "writeme",
+				Waiter:          &fakeWaiter{},
+				Runner:          c.runner,
+				PostWriter:      fpw,
+				TerminationPath: "termination",
+				OnError:         c.onError,
+			}.Go()
+			if (err != nil) != c.wantErr {
+				t.Errorf("Expected an error: %t, got %v", c.wantErr, err)
+			}
+			if fpw.wrote == nil || *fpw.wrote != c.wantPostFile {
+				t.Errorf("Wanted post file %q written, got %v", c.wantPostFile, fpw.wrote)
+			}
+			fileContents, err := ioutil.ReadFile("termination")
+			if err != nil {
+				t.Fatalf("Wanted termination file written: %v", err)
+			}
+			defer os.Remove("termination")
+			var entries []v1beta1.PipelineResourceResult
+			if err := json.Unmarshal(fileContents, &entries); err != nil {
+				t.Fatalf("Couldn't parse termination message %q: %v", fileContents, err)
+			}
+			var exitCode string
+			for _, result := range entries {
+				if result.Key == "ExitCode" {
+					exitCode = result.Value
+				}
+			}
+			if d := cmp.Diff(c.wantExitCode, exitCode); d != "" {
+				t.Errorf("ExitCode entry diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestEntrypointerTimeout(t *testing.T) {
+	for _, c := range []struct {
+		desc         string
+		onError      string
+		wantErr      error
+		wantPostFile string
+		wantExitCode string
+	}{{
+		desc:         "stop when the step times out",
+		wantErr:      context.DeadlineExceeded,
+		wantPostFile: "writeme.err",
+	}, {
+		desc:         "continue when the step times out",
+		onError:      ContinueOnError,
+		wantPostFile: "writeme",
+		wantExitCode: "1",
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			fpw := &fakePostWriter{}
+			err := Entrypointer{
+				Entrypoint:      "test",
+				PostFile:        "writeme",
+				Waiter:          &fakeWaiter{},
+				Runner:          &fakeTimeoutRunner{},
+				PostWriter:      fpw,
+				TerminationPath: "termination",
+				OnError:         c.onError,
+				Timeout:         10 * time.Millisecond,
+			}.Go()
+			if err != c.wantErr {
+				t.Errorf("Expected error %v, got %v", c.wantErr, err)
+			}
+			if fpw.wrote == nil || *fpw.wrote != c.wantPostFile {
+				t.Errorf("Wanted post file %q written, got %v", c.wantPostFile, fpw.wrote)
+			}
+			fileContents, err := ioutil.ReadFile("termination")
+			if err != nil {
+				t.Fatalf("Wanted termination file written: %v", err)
+			}
+			defer os.Remove("termination")
+			var entries []v1beta1.PipelineResourceResult
+			if err := json.Unmarshal(fileContents, &entries); err != nil {
+				t.Fatalf("Couldn't parse termination message %q: %v", fileContents, err)
+			}
+			var exitCode, timedOut string
+			for _, result := range entries {
+				switch result.Key {
+				case "ExitCode":
+					exitCode = result.Value
+				case "TimedOut":
+					timedOut = result.Value
+				}
+			}
+			if d := cmp.Diff(c.wantExitCode, exitCode); d != "" {
+				t.Errorf("ExitCode entry diff %s", diff.PrintWantGot(d))
+			}
+			if d := cmp.Diff("10ms", timedOut); d != "" {
+				t.Errorf("TimedOut entry diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestEntrypointerBreakpointOnFailure(t *testing.T) {
+	for _, c := range []struct {
+		desc         string
+		onError      string
+		runner       Runner
+		exitWaiter   Waiter
+		wantErr      bool
+		wantPostFile string
+		wantWaited   []string
+	}{{
+		desc:         "continue after a failed step held at the breakpoint",
+		runner:       &fakeExitErrorRunner{exitCode: 2},
+		exitWaiter:   &fakeWaiter{},
+		wantPostFile: "writeme",
+		wantWaited:   []string{"writeme.breakpointexit"},
+	}, {
+		desc:         "fail after a failed step held at the breakpoint",
+		runner:       &fakeExitErrorRunner{exitCode: 2},
+		exitWaiter:   &fakeErrorWaiter{},
+		wantErr:      true,
+		wantPostFile: "writeme.err",
+	}, {
+		desc:         "successful step is not held",
+		runner:       &fakeRunner{},
+		exitWaiter:   &fakeWaiter{},
+		wantPostFile: "writeme",
+	}, {
+		desc:         "failed step which continues on error is not held",
+		onError:      ContinueOnError,
+		runner:       &fakeExitErrorRunner{exitCode: 2},
+		exitWaiter:   &fakeWaiter{},
+		wantPostFile: "writeme",
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			fpw := &fakePostWriter{}
+			err := Entrypointer{
+				Entrypoint:          "test",
+				PostFile:            "writeme",
+				Waiter:              c.exitWaiter,
+				Runner:              c.runner,
+				PostWriter:          fpw,
+				TerminationPath:     "termination",
+				OnError:             c.onError,
+				BreakpointOnFailure: true,
+			}.Go()
+			defer os.Remove("termination")
+			if (err != nil) != c.wantErr {
+				t.Errorf("Expected an error: %t, got %v", c.wantErr, err)
+			}
+			if fpw.wrote == nil || *fpw.wrote != c.wantPostFile {
+				t.Errorf("Wanted post file %q written, got %v", c.wantPostFile, fpw.wrote)
+			}
+			if fw, ok := c.exitWaiter.(*fakeWaiter); ok {
+				if d := cmp.Diff(c.wantWaited, fw.waited); d != "" {
+					t.Errorf("Waited files diff %s", diff.PrintWantGot(d))
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateWhenExpressions(t *testing.T) {
+	resultsDir, err := ioutil.TempDir("", "results")
+	if err != nil {
+		t.Fatalf("Couldn't create results directory: %v", err)
+	}
+	defer os.RemoveAll(resultsDir)
+	if err := ioutil.WriteFile(filepath.Join(resultsDir, "image-changed"), []byte("true"), 0644); err != nil {
+		t.Fatalf("Couldn't write result: %v", err)
+	}
+
+	for _, c := range []struct {
+		desc            string
+		whenExpressions v1beta1.WhenExpressions
+		want            string
+	}{{
+		desc: "no when expressions",
+	}, {
+		desc: "result written by an earlier step",
+		whenExpressions: v1beta1.WhenExpressions{{
+			Input:    "$(results.image-changed)",
+			Operator: selection.In,
+			Values:   []string{"true"},
+		}},
+	}, {
+		desc: "cel expression with result written by an earlier step",
+		whenExpressions: v1beta1.WhenExpressions{{
+			CEL: "'$(results.image-changed)' == 'false'",
+		}},
+		want: `cel expression "'true' == 'false'" evaluated to false`,
+	}, {
+		desc: "result not written by an earlier step",
+		whenExpressions: v1beta1.WhenExpressions{{
+			Input:    "$(results.signature)",
+			Operator: selection.Exists,
+		}},
+		want: `input "" with operator "exists" and values [] evaluated to false`,
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			e := Entrypointer{WhenExpressions: c.whenExpressions}
+			got := e.evaluateWhenExpressions(resultsDir)
+			if d := cmp.Diff(c.want, got); d != "" {
+				t.Errorf("evaluateWhenExpressions diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
 type fakeWaiter struct{ waited []string }
 
 func (f *fakeWaiter) Wait(file string, _ bool) error {
@@ -214,7 +508,7 @@ func (f *fakeWaiter) Wait(file string, _ bool) error {
 
 type fakeRunner struct{ args *[]string }
 
-func (f *fakeRunner) Run(args ...string) error {
+func (f *fakeRunner) Run(_ context.Context, args ...string) error {
 	f.args = &args
 	return nil
 }
@@ -232,7 +526,20 @@ func (f *fakeErrorWaiter) Wait(file string, expectContent bool) error {
 
 type fakeErrorRunner struct{ args *[]string }
 
-func (f *fakeErrorRunner) Run(args ...string) error {
+func (f *fakeErrorRunner) Run(_ context.Context, args ...string) error {
 	f.args = &args
 	return errors.New("runner failed")
 }
+
+type fakeExitErrorRunner struct{ exitCode int }
+
+func (f *fakeExitErrorRunner) Run(_ context.Context, args ...string) error {
+	return exec.Command("sh", "-c", fmt.Sprintf("exit %d", f.exitCode)).Run()
+}
+
+type fakeTimeoutRunner struct{}
+
+func (f *fakeTimeoutRunner) Run(ctx context.Context, args ...string) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
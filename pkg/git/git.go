@@ -17,12 +17,15 @@ package git
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"go.uber.org/zap"
@@ -34,6 +37,11 @@ const (
 )
 
 func run(logger *zap.SugaredLogger, dir string, args ...string) (string, error) {
+	return runContext(context.Background(), logger, dir, args...)
+}
+
+// runContext runs git like run, but kills it once ctx is done.
+func runContext(ctx context.Context, logger *zap.SugaredLogger, dir string, args ...string) (string, error) {
 	c := exec.Command("git", args...)
 	var output bytes.Buffer
 	c.Stderr = &output
@@ -43,7 +51,26 @@ func run(logger *zap.SugaredLogger, dir string, args ...string) (string, error)
 	if dir != "" {
 		c.Dir = dir
 	}
-	if err := c.Run(); err != nil {
+	// dedicated PID group, so that the remote helpers started by git, which hold its output
+	// open, are killed with it
+	if ctx.Done() != nil {
+		c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	}
+	err := c.Start()
+	if err == nil {
+		done := make(chan error, 1)
+		go func() {
+			done <- c.Wait()
+		}()
+		select {
+		case err = <-done:
+		case <-ctx.Done():
+			_ = syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+			<-done
+			err = ctx.Err()
+		}
+	}
+	if err != nil {
 		logger.Errorf("Error running git %v: %v\n%v", args, err, output.String())
 		return "", err
 	}
@@ -62,6 +89,11 @@ type FetchSpec struct {
 	HTTPProxy  string
 	HTTPSProxy string
 	NOProxy    string
+	// Timeout bounds the time taken by the fetch, if not zero.
+	Timeout time.Duration
+	// DisableRedirects stops git from following the HTTP redirects of the remote, so that it
+	// only fetches from the host of URL.
+	DisableRedirects bool
 }
 
 // Fetch fetches the specified git repository at the revision into path, using the refspec to fetch if provided.
@@ -69,19 +101,24 @@ func Fetch(logger *zap.SugaredLogger, spec FetchSpec) error {
 	if err := ensureHomeEnv(logger); err != nil {
 		return err
 	}
+	ctx := context.Background()
+	if spec.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, spec.Timeout)
+		defer cancel()
+	}
 
+	// The git commands run in spec.Path rather than in the working directory of the process, which is left
+	// unchanged so that several repositories may be fetched concurrently.
 	if spec.Path != "" {
 		if _, err := run(logger, "", "init", spec.Path); err != nil {
 			return err
 		}
-		if err := os.Chdir(spec.Path); err != nil {
-			return fmt.Errorf("failed to change directory with path %s; err: %w", spec.Path, err)
-		}
 	} else if _, err := run(logger, "", "init"); err != nil {
 		return err
 	}
 	trimmedURL := strings.TrimSpace(spec.URL)
-	if _, err := run(logger, "", "remote", "add", "origin", trimmedURL); err != nil {
+	if _, err := run(logger, spec.Path, "remote", "add", "origin", trimmedURL); err != nil {
 		return err
 	}
 
@@ -102,12 +139,12 @@ func Fetch(logger *zap.SugaredLogger, spec FetchSpec) error {
 	}
 	if spec.Revision == "" {
 		spec.Revision = "HEAD"
-		if _, err := run(logger, "", "symbolic-ref", spec.Revision, "refs/remotes/origin/HEAD"); err != nil {
+		if _, err := run(logger, spec.Path, "symbolic-ref", spec.Revision, "refs/remotes/origin/HEAD"); err != nil {
 			return err
 		}
 	}
 
-	fetchArgs := []string{"fetch"}
+	fetchArgs := append(spec.configArgs(), "fetch")
 	if spec.Submodules {
 		fetchArgs = append(fetchArgs, "--recurse-submodules=yes")
 	}
@@ -134,7 +171,7 @@ func Fetch(logger *zap.SugaredLogger, spec FetchSpec) error {
 	// when the refspec specifies the same destination twice)
 	fetchArgs = append(fetchArgs, "origin", "--update-head-ok", "--force")
 	fetchArgs = append(fetchArgs, fetchParam...)
-	if _, err := run(logger, spec.Path, fetchArgs...); err != nil {
+	if _, err := runContext(ctx, logger, spec.Path, fetchArgs...); err != nil {
 		return fmt.Errorf("failed to fetch %v: %v", fetchParam, err)
 	}
 	// After performing a fetch, verify that the item to checkout is actually valid
@@ -142,7 +179,7 @@ func Fetch(logger *zap.SugaredLogger, spec FetchSpec) error {
 		return fmt.Errorf("error parsing %s after fetching refspec %s", checkoutParam, spec.Refspec)
 	}
 
-	if _, err := run(logger, "", "checkout", "-f", checkoutParam); err != nil {
+	if _, err := run(logger, spec.Path, "checkout", "-f", checkoutParam); err != nil {
 		return err
 	}
 
@@ -156,13 +193,21 @@ func Fetch(logger *zap.SugaredLogger, spec FetchSpec) error {
 	}
 	logger.Infof("Successfully cloned %s @ %s (%s) in path %s", trimmedURL, commit, ref, spec.Path)
 	if spec.Submodules {
-		if err := SubmoduleFetch(logger, spec); err != nil {
+		if err := submoduleFetch(ctx, logger, spec); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// configArgs returns the options of the git commands reaching the remote.
+func (spec FetchSpec) configArgs() []string {
+	if spec.DisableRedirects {
+		return []string{"-c", "http.followRedirects=false"}
+	}
+	return nil
+}
+
 func ShowCommit(logger *zap.SugaredLogger, revision, path string) (string, error) {
 	output, err := run(logger, path, "show", "-q", "--pretty=format:%H", revision)
 	if err != nil {
@@ -180,19 +225,18 @@ func ShowRef(logger *zap.SugaredLogger, revision, path string) (string, error) {
 }
 
 func SubmoduleFetch(logger *zap.SugaredLogger, spec FetchSpec) error {
-	if spec.Path != "" {
-		if err := os.Chdir(spec.Path); err != nil {
-			return fmt.Errorf("failed to change directory with path %s; err: %w", spec.Path, err)
-		}
-	}
-	if _, err := run(logger, "", "submodule", "init"); err != nil {
+	return submoduleFetch(context.Background(), logger, spec)
+}
+
+func submoduleFetch(ctx context.Context, logger *zap.SugaredLogger, spec FetchSpec) error {
+	if _, err := runContext(ctx, logger, spec.Path, "submodule", "init"); err != nil {
 		return err
 	}
-	updateArgs := []string{"submodule", "update", "--recursive"}
+	updateArgs := append(spec.configArgs(), "submodule", "update", "--recursive")
 	if spec.Depth > 0 {
 		updateArgs = append(updateArgs, fmt.Sprintf("--depth=%d", spec.Depth))
 	}
-	if _, err := run(logger, "", updateArgs...); err != nil {
+	if _, err := runContext(ctx, logger, spec.Path, updateArgs...); err != nil {
 		return err
 	}
 	logger.Infof("Successfully initialized and updated submodules in path %s", spec.Path)
@@ -0,0 +1,394 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	resourcev1alpha1 "github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun/resources"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun"
+	taskrunresources "github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
+	"github.com/tektoncd/pipeline/pkg/workspace"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// placeholder is the value given to the params of a Pipeline which have no default, when checking the Pipeline
+// without a PipelineRun.
+const placeholder = "placeholder"
+
+// checker cross-checks resources against the Tasks, ClusterTasks, Pipelines and PipelineResources they reference.
+type checker struct {
+	ctx       context.Context
+	tasks     map[string]*v1beta1.Task
+	clusters  map[string]*v1beta1.ClusterTask
+	pipelines map[string]*v1beta1.Pipeline
+	resources map[string]*resourcev1alpha1.PipelineResource
+	// seen maps the key of each resource added to the file it was read from
+	seen map[string]string
+	// converted maps the resources added to their v1beta1 version
+	converted map[runtime.Object]runtime.Object
+}
+
+// add indexes r so that the other resources can reference it, reporting resources defined more than once.
+func (c *checker) add(r resource, report func(string)) {
+	if o, ok := r.obj.(metav1.Object); ok && o.GetName() != "" {
+		k := key(r.kind(), o.GetName())
+		if file, ok := c.seen[k]; ok {
+			report(fmt.Sprintf("already defined in %s", file))
+			return
+		}
+		c.seen[k] = r.file
+	}
+
+	obj, err := toV1Beta1(c.ctx, r.obj)
+	if err != nil {
+		report(fmt.Sprintf("couldn't convert to v1beta1: %v", err))
+		return
+	}
+	if c.converted == nil {
+		c.converted = map[runtime.Object]runtime.Object{}
+	}
+	c.converted[r.obj] = obj
+	switch o := obj.(type) {
+	case *v1beta1.Task:
+		c.tasks[o.Name] = o
+	case *v1beta1.ClusterTask:
+		c.clusters[o.Name] = o
+	case *v1beta1.Pipeline:
+		c.pipelines[o.Name] = o
+	case *resourcev1alpha1.PipelineResource:
+		c.resources[o.Name] = o
+	}
+}
+
+// check returns the mistakes found by cross-checking r.
+func (c *checker) check(r resource) []string {
+	switch o := c.converted[r.obj].(type) {
+	case *v1beta1.Pipeline:
+		return c.checkPipeline(&o.Spec, placeholderRun(&o.Spec))
+	case *v1beta1.PipelineRun:
+		return c.checkPipelineRun(o)
+	case *v1beta1.TaskRun:
+		return c.checkTaskRun(o)
+	}
+	return nil
+}
+
+// placeholderRun returns a PipelineRun of spec which provides a value of the right type for each param without a
+// default.
+func placeholderRun(spec *v1beta1.PipelineSpec) *v1beta1.PipelineRun {
+	pr := &v1beta1.PipelineRun{}
+	for _, p := range spec.Params {
+		if p.Default != nil {
+			continue
+		}
+		value := v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: placeholder}
+		switch p.Type {
+		case v1beta1.ParamTypeArray:
+			value = v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{placeholder}}
+		case v1beta1.ParamTypeObject:
+			pairs := map[string]string{}
+			for k := range p.Properties {
+				pairs[k] = placeholder
+			}
+			value = *v1beta1.NewObject(pairs)
+		}
+		pr.Spec.Params = append(pr.Spec.Params, v1beta1.Param{Name: p.Name, Value: value})
+	}
+	return pr
+}
+
+// checkPipelineRun checks that pr provides what its Pipeline needs, as the PipelineRun reconciler does before
+// starting to run it. The tasks of a Pipeline read from the files are checked with the Pipeline itself.
+func (c *checker) checkPipelineRun(pr *v1beta1.PipelineRun) []string {
+	var spec *v1beta1.PipelineSpec
+	switch {
+	case pr.Spec.PipelineSpec != nil:
+		spec = pr.Spec.PipelineSpec
+	case pr.Spec.PipelineRef != nil && pr.Spec.PipelineRef.Bundle == "" && pr.Spec.PipelineRef.Resolver == "":
+		p, ok := c.pipelines[pr.Spec.PipelineRef.Name]
+		if !ok {
+			return nil
+		}
+		spec = &p.Spec
+	default:
+		return nil
+	}
+
+	var problems []string
+	for _, validate := range []func() error{
+		func() error { return resources.ValidateRequiredParametersProvided(&spec.Params, &pr.Spec.Params) },
+		func() error { return resources.ValidateParamTypesMatching(spec, pr) },
+		func() error { return resources.ValidateResourceBindings(spec, pr) },
+		func() error { return resources.ValidateWorkspaceBindings(spec, pr) },
+		func() error { return resources.ValidateServiceaccountMapping(spec, pr) },
+		func() error { return resources.ValidateTaskRunSpecs(spec, pr) },
+	} {
+		if err := validate(); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 || pr.Spec.PipelineSpec == nil {
+		return problems
+	}
+	return c.checkPipeline(spec, pr)
+}
+
+// checkPipeline checks the tasks of spec against the Tasks they reference, with the params of pr applied.
+func (c *checker) checkPipeline(spec *v1beta1.PipelineSpec, pr *v1beta1.PipelineRun) []string {
+	declared := map[string]v1beta1.PipelineResourceType{}
+	for _, r := range spec.Resources {
+		declared[r.Name] = r.Type
+	}
+	applied := resources.ApplyParameters(spec, pr)
+
+	var problems []string
+	taskSpecs := map[string]*v1beta1.TaskSpec{}
+	finallySpecs := map[string]*v1beta1.TaskSpec{}
+	for _, tasks := range []struct {
+		pipelineTasks []v1beta1.PipelineTask
+		specs         map[string]*v1beta1.TaskSpec
+	}{{applied.Tasks, taskSpecs}, {applied.Finally, finallySpecs}} {
+		for _, pt := range tasks.pipelineTasks {
+			ts, name := c.pipelineTaskSpec(pt)
+			if ts == nil {
+				continue
+			}
+			tasks.specs[pt.Name] = ts
+			for _, err := range checkPipelineTask(pt, ts, name, declared) {
+				problems = append(problems, fmt.Sprintf("pipeline task %q: %v", pt.Name, err))
+			}
+		}
+	}
+
+	// Check that the results referenced are declared by the Tasks of the pipeline tasks
+	for _, pt := range append(append([]v1beta1.PipelineTask{}, applied.Tasks...), applied.Finally...) {
+		var expressions []string
+		for _, p := range append(append([]v1beta1.Param{}, pt.Params...), pt.Matrix...) {
+			e, _ := v1beta1.GetVarSubstitutionExpressionsForParam(p)
+			expressions = append(expressions, e...)
+		}
+		for i := range pt.WhenExpressions {
+			e, _ := pt.WhenExpressions[i].GetVarSubstitutionExpressions()
+			expressions = append(expressions, e...)
+		}
+		for _, ref := range v1beta1.NewResultRefs(expressions) {
+			if err := checkResultRef(ref, taskSpecs); err != nil {
+				problems = append(problems, fmt.Sprintf("pipeline task %q: %v", pt.Name, err))
+			}
+		}
+	}
+	for _, result := range applied.Results {
+		expressions, _ := v1beta1.GetVarSubstitutionExpressionsForPipelineResult(result)
+		for _, ref := range v1beta1.NewPipelineResultRefs(expressions) {
+			specs := taskSpecs
+			if ref.Finally {
+				specs = finallySpecs
+			}
+			if err := checkResultRef(ref, specs); err != nil {
+				problems = append(problems, fmt.Sprintf("pipeline result %q: %v", result.Name, err))
+			}
+		}
+	}
+	return problems
+}
+
+// pipelineTaskSpec returns the spec and the name of the Task run by pt, or nil if it isn't embedded in pt nor read
+// from the files.
+func (c *checker) pipelineTaskSpec(pt v1beta1.PipelineTask) (*v1beta1.TaskSpec, string) {
+	switch {
+	case pt.IsPipeline():
+		return nil, ""
+	case pt.TaskSpec != nil && pt.TaskSpec.TaskSpec != nil:
+		return pt.TaskSpec.TaskSpec, pt.Name
+	case pt.TaskRef != nil:
+		return c.taskRefSpec(pt.TaskRef)
+	}
+	return nil, ""
+}
+
+// taskRefSpec returns the spec of the Task or ClusterTask read from the files which ref references, or nil if
+// there is none.
+func (c *checker) taskRefSpec(ref *v1beta1.TaskRef) (*v1beta1.TaskSpec, string) {
+	if ref.Bundle != "" || ref.Resolver != "" {
+		return nil, ""
+	}
+	switch ref.Kind {
+	case v1beta1.ClusterTaskKind:
+		if t, ok := c.clusters[ref.Name]; ok {
+			return &t.Spec, t.Name
+		}
+	case "", v1beta1.NamespacedTaskKind:
+		if t, ok := c.tasks[ref.Name]; ok {
+			return &t.Spec, t.Name
+		}
+	}
+	return nil, ""
+}
+
+// checkPipelineTask checks that pt provides the params, resources and workspaces ts needs.
+func checkPipelineTask(pt v1beta1.PipelineTask, ts *v1beta1.TaskSpec, name string, declared map[string]v1beta1.PipelineResourceType) []error {
+	params := pt.Params
+	if combinations := pt.MatrixCombinations(); len(combinations) > 0 {
+		params = append(append([]v1beta1.Param{}, params...), combinations[0]...)
+	}
+	rtr := &taskrunresources.ResolvedTaskResources{
+		TaskName: name,
+		TaskSpec: ts,
+		Inputs:   map[string]*resourcev1alpha1.PipelineResource{},
+		Outputs:  map[string]*resourcev1alpha1.PipelineResource{},
+	}
+	if pt.Resources != nil {
+		for _, r := range pt.Resources.Inputs {
+			rtr.Inputs[r.Name] = placeholderResource(r.Resource, declared[r.Resource])
+		}
+		for _, r := range pt.Resources.Outputs {
+			rtr.Outputs[r.Name] = placeholderResource(r.Resource, declared[r.Resource])
+		}
+	}
+
+	var errs []error
+	if err := taskrun.ValidateResolvedTaskResources(params, rtr); err != nil {
+		errs = append(errs, err)
+	}
+	bindings := make([]v1beta1.WorkspaceBinding, 0, len(pt.Workspaces))
+	for _, ws := range pt.Workspaces {
+		bindings = append(bindings, v1beta1.WorkspaceBinding{Name: ws.Name, EmptyDir: &corev1.EmptyDirVolumeSource{}})
+	}
+	if err := workspace.ValidateBindings(ts.Workspaces, bindings); err != nil {
+		errs = append(errs, fmt.Errorf("invalid workspaces for task %s: %w", name, err))
+	}
+	return errs
+}
+
+// checkResultRef checks that the Task of the pipeline task ref references declares the result, and the property
+// of an object result, that ref references. References to pipeline tasks whose Task isn't known are ignored.
+func checkResultRef(ref *v1beta1.ResultRef, specs map[string]*v1beta1.TaskSpec) error {
+	ts, ok := specs[ref.PipelineTask]
+	if !ok {
+		return nil
+	}
+	for _, result := range ts.Results {
+		if result.Name != ref.Result {
+			continue
+		}
+		if ref.Property == "" || result.Type != v1beta1.ResultsTypeObject {
+			return nil
+		}
+		if _, ok := result.Properties[ref.Property]; !ok {
+			return fmt.Errorf("result %q of pipeline task %q has no property %q", ref.Result, ref.PipelineTask, ref.Property)
+		}
+		return nil
+	}
+	return fmt.Errorf("pipeline task %q has no result %q", ref.PipelineTask, ref.Result)
+}
+
+// checkTaskRun checks that tr provides what its Task needs, as the TaskRun reconciler does before running it.
+func (c *checker) checkTaskRun(tr *v1beta1.TaskRun) []string {
+	var ts *v1beta1.TaskSpec
+	name := tr.Name
+	switch {
+	case tr.Spec.TaskSpec != nil:
+		ts = tr.Spec.TaskSpec
+	case tr.Spec.TaskRef != nil:
+		ts, name = c.taskRefSpec(tr.Spec.TaskRef)
+	}
+	if ts == nil {
+		return nil
+	}
+
+	rtr := &taskrunresources.ResolvedTaskResources{
+		TaskName: name,
+		TaskSpec: ts,
+		Inputs:   map[string]*resourcev1alpha1.PipelineResource{},
+		Outputs:  map[string]*resourcev1alpha1.PipelineResource{},
+	}
+	if tr.Spec.Resources != nil {
+		var inputs, outputs []v1beta1.TaskResource
+		if ts.Resources != nil {
+			inputs, outputs = ts.Resources.Inputs, ts.Resources.Outputs
+		}
+		for _, b := range tr.Spec.Resources.Inputs {
+			rtr.Inputs[b.Name] = c.boundResource(b, inputs)
+		}
+		for _, b := range tr.Spec.Resources.Outputs {
+			rtr.Outputs[b.Name] = c.boundResource(b, outputs)
+		}
+	}
+
+	var problems []string
+	if err := taskrun.ValidateResolvedTaskResources(tr.Spec.Params, rtr); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if err := workspace.ValidateBindings(ts.Workspaces, c.workspaceBindings(tr, ts)); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid workspaces for task %s: %v", name, err))
+	}
+	return problems
+}
+
+// workspaceBindings returns the workspaces bound by tr, and the default binding of the workspaces of ts it doesn't
+// bind when config-defaults has one, as the TaskRun reconciler does.
+func (c *checker) workspaceBindings(tr *v1beta1.TaskRun, ts *v1beta1.TaskSpec) []v1beta1.WorkspaceBinding {
+	bindings := tr.Spec.Workspaces
+	if config.FromContextOrDefaults(c.ctx).Defaults.DefaultTaskRunWorkspaceBinding == "" {
+		return bindings
+	}
+	bound := map[string]bool{}
+	for _, b := range bindings {
+		bound[b.Name] = true
+	}
+	for _, ws := range ts.Workspaces {
+		if !bound[ws.Name] {
+			bindings = append(bindings, v1beta1.WorkspaceBinding{Name: ws.Name, EmptyDir: &corev1.EmptyDirVolumeSource{}})
+		}
+	}
+	return bindings
+}
+
+// boundResource returns a PipelineResource standing for the resource bound by b. Its type is the one of the
+// embedded spec or of the PipelineResource read from the files if any, or else the type declared by the Task, which
+// can't be checked.
+func (c *checker) boundResource(b v1beta1.TaskResourceBinding, declared []v1beta1.TaskResource) *resourcev1alpha1.PipelineResource {
+	if b.ResourceSpec != nil {
+		return &resourcev1alpha1.PipelineResource{Spec: *b.ResourceSpec}
+	}
+	if b.ResourceRef != nil {
+		if r, ok := c.resources[b.ResourceRef.Name]; ok {
+			return r
+		}
+	}
+	for _, d := range declared {
+		if d.Name == b.Name {
+			return placeholderResource(b.Name, d.Type)
+		}
+	}
+	return placeholderResource(b.Name, "")
+}
+
+// placeholderResource returns a PipelineResource of the given type.
+func placeholderResource(name string, t v1beta1.PipelineResourceType) *resourcev1alpha1.PipelineResource {
+	r := &resourcev1alpha1.PipelineResource{}
+	r.Name = name
+	r.Spec.Type = t
+	return r
+}
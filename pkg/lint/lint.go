@@ -0,0 +1,272 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	resourcev1alpha1 "github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned/scheme"
+	"github.com/tektoncd/pipeline/pkg/contexts"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"knative.dev/pkg/apis"
+)
+
+// Problem is a mistake found in a resource, or in a file which couldn't be read.
+type Problem struct {
+	File    string
+	Kind    string
+	Name    string
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Kind == "" {
+		return fmt.Sprintf("%s: %s", p.File, p.Message)
+	}
+	return fmt.Sprintf("%s: %s %s: %s", p.File, p.Kind, p.Name, p.Message)
+}
+
+// resource is a Tekton resource read from a file
+type resource struct {
+	file string
+	obj  runtime.Object
+}
+
+func (r resource) kind() string {
+	return r.obj.GetObjectKind().GroupVersionKind().Kind
+}
+
+// name returns the name of the resource, or its generateName if it has none.
+func (r resource) name() string {
+	o, ok := r.obj.(metav1.Object)
+	if !ok {
+		return ""
+	}
+	if o.GetName() == "" {
+		return o.GetGenerateName()
+	}
+	return o.GetName()
+}
+
+// Linter checks Tekton resources read from local files, without a cluster: each resource is defaulted and
+// validated as the webhook would, and the Pipelines, PipelineRuns and TaskRuns are checked against the Tasks and
+// Pipelines they reference which are found in the same files.
+type Linter struct {
+	resources []resource
+	// configMaps maps the ConfigMaps holding the configuration to the files they were read from
+	configMaps map[*corev1.ConfigMap]string
+	// problems are the problems found while reading the files
+	problems []Problem
+}
+
+// NewLinter returns a Linter without any resource.
+func NewLinter() *Linter {
+	return &Linter{configMaps: map[*corev1.ConfigMap]string{}}
+}
+
+// AddPath reads the resources of the YAML file at path, or of the .yaml and .yml files under path if it is a
+// directory. "-" reads the standard input.
+func (l *Linter) AddPath(path string) error {
+	if path == "-" {
+		return l.AddReader("<stdin>", os.Stdin)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return l.addFile(path)
+	}
+	return filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || (filepath.Ext(file) != ".yaml" && filepath.Ext(file) != ".yml") {
+			return nil
+		}
+		return l.addFile(file)
+	})
+}
+
+func (l *Linter) addFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return l.AddReader(file, f)
+}
+
+// AddReader reads the resources of the YAML documents read from r, reported as coming from file. Documents which
+// aren't Tekton resources are ignored, except the ConfigMaps holding the defaults and the feature flags, which
+// configure the validation as they configure the webhook.
+func (l *Linter) AddReader(file string, r io.Reader) error {
+	reader := yaml.NewYAMLReader(bufio.NewReader(r))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		if err := l.decode(file, doc); err != nil {
+			l.problems = append(l.problems, Problem{File: file, Message: err.Error()})
+		}
+	}
+}
+
+// decode adds the resource of the YAML document doc. Tekton resources are decoded as the webhook decodes them,
+// rejecting unknown fields.
+func (l *Linter) decode(file string, doc []byte) error {
+	data, err := yaml.ToJSON(doc)
+	if err != nil {
+		return err
+	}
+	var tm metav1.TypeMeta
+	if err := json.Unmarshal(data, &tm); err != nil {
+		return err
+	}
+	gvk := tm.GroupVersionKind()
+	switch {
+	case gvk.Group == pipeline.GroupName:
+		obj, err := scheme.Scheme.New(gvk)
+		if err != nil {
+			return fmt.Errorf("unknown kind %s in %s", tm.Kind, tm.APIVersion)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(obj); err != nil {
+			return fmt.Errorf("couldn't decode %s: %w", tm.Kind, err)
+		}
+		l.resources = append(l.resources, resource{file: file, obj: obj})
+	case gvk == corev1.SchemeGroupVersion.WithKind("ConfigMap"):
+		cm := &corev1.ConfigMap{}
+		if err := json.Unmarshal(data, cm); err != nil {
+			return err
+		}
+		l.configMaps[cm] = file
+	}
+	return nil
+}
+
+// Lint returns the problems found in the resources read.
+func (l *Linter) Lint() []Problem {
+	ctx, err := l.context()
+	problems := append([]Problem{}, l.problems...)
+	if err != nil {
+		return append(problems, *err)
+	}
+
+	c := &checker{
+		ctx:       ctx,
+		tasks:     map[string]*v1beta1.Task{},
+		clusters:  map[string]*v1beta1.ClusterTask{},
+		pipelines: map[string]*v1beta1.Pipeline{},
+		resources: map[string]*resourcev1alpha1.PipelineResource{},
+		seen:      map[string]string{},
+	}
+	var valid []resource
+	for _, r := range l.resources {
+		report := func(message string) {
+			problems = append(problems, Problem{File: r.file, Kind: r.kind(), Name: r.name(), Message: message})
+		}
+		if d, ok := r.obj.(apis.Defaultable); ok {
+			d.SetDefaults(ctx)
+		}
+		c.add(r, report)
+		if v, ok := r.obj.(apis.Validatable); ok {
+			if err := v.Validate(ctx); err != nil {
+				report(err.Error())
+				continue
+			}
+		}
+		valid = append(valid, r)
+	}
+	// Cross-check the valid resources once all of them have been indexed
+	for _, r := range valid {
+		for _, message := range c.check(r) {
+			problems = append(problems, Problem{File: r.file, Kind: r.kind(), Name: r.name(), Message: message})
+		}
+	}
+	return problems
+}
+
+// context returns the context of the validation, holding the configuration read from the ConfigMaps.
+func (l *Linter) context() (context.Context, *Problem) {
+	ctx := context.Background()
+	cfg := config.FromContextOrDefaults(ctx)
+	for cm, file := range l.configMaps {
+		var err error
+		switch cm.Name {
+		case config.GetDefaultsConfigName():
+			cfg.Defaults, err = config.NewDefaultsFromConfigMap(cm)
+		case config.GetFeatureFlagsConfigName():
+			cfg.FeatureFlags, err = config.NewFeatureFlagsFromConfigMap(cm)
+		}
+		if err != nil {
+			return nil, &Problem{File: file, Kind: "ConfigMap", Name: cm.Name, Message: err.Error()}
+		}
+	}
+	return apis.WithinCreate(contexts.WithUpgradeViaDefaulting(config.ToContext(ctx, cfg))), nil
+}
+
+// toV1Beta1 converts a v1alpha1 resource to its v1beta1 version.
+func toV1Beta1(ctx context.Context, obj runtime.Object) (runtime.Object, error) {
+	var sink apis.Convertible
+	switch obj.(type) {
+	case *v1alpha1.Task:
+		sink = &v1beta1.Task{}
+	case *v1alpha1.ClusterTask:
+		sink = &v1beta1.ClusterTask{}
+	case *v1alpha1.Pipeline:
+		sink = &v1beta1.Pipeline{}
+	case *v1alpha1.TaskRun:
+		sink = &v1beta1.TaskRun{}
+	case *v1alpha1.PipelineRun:
+		sink = &v1beta1.PipelineRun{}
+	default:
+		return obj, nil
+	}
+	if err := obj.(apis.Convertible).ConvertTo(ctx, sink); err != nil {
+		return nil, err
+	}
+	return sink.(runtime.Object), nil
+}
+
+// key identifies a resource by its kind and name, ignoring its version.
+func key(kind, name string) string {
+	return strings.ToLower(kind) + "/" + name
+}
@@ -0,0 +1,473 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/test/diff"
+)
+
+const task = `
+apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: build
+spec:
+  params:
+  - name: url
+  - name: flags
+    type: array
+    default: []
+  workspaces:
+  - name: source
+  results:
+  - name: digest
+  steps:
+  - image: busybox
+    script: echo $(params.url)
+`
+
+func TestLint(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		yaml string
+		want []string
+	}{{
+		name: "valid pipeline",
+		yaml: task + `
+---
+apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: release
+spec:
+  params:
+  - name: url
+  workspaces:
+  - name: ws
+  tasks:
+  - name: build
+    taskRef:
+      name: build
+    params:
+    - name: url
+      value: $(params.url)
+    workspaces:
+    - name: source
+      workspace: ws
+  - name: publish
+    taskRef:
+      name: build
+    params:
+    - name: url
+      value: $(tasks.build.results.digest)
+    workspaces:
+    - name: source
+      workspace: ws
+  results:
+  - name: digest
+    value: $(tasks.publish.results.digest)
+---
+apiVersion: tekton.dev/v1beta1
+kind: PipelineRun
+metadata:
+  name: release-run
+spec:
+  pipelineRef:
+    name: release
+  params:
+  - name: url
+    value: https://example.com
+  workspaces:
+  - name: ws
+    emptyDir: {}
+`,
+	}, {
+		name: "invalid task",
+		yaml: `
+apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: build
+spec:
+  steps:
+  - name: build
+`,
+		want: []string{"test.yaml: Task build: missing field(s): spec.steps[0].Image"},
+	}, {
+		name: "pipeline task params don't match the task",
+		yaml: task + `
+---
+apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: release
+spec:
+  params:
+  - name: url
+  workspaces:
+  - name: ws
+  tasks:
+  - name: build
+    taskRef:
+      name: build
+    params:
+    - name: flags
+      value: [--verbose]
+    workspaces:
+    - name: source
+      workspace: ws
+  - name: publish
+    taskRef:
+      name: build
+    params:
+    - name: url
+      value: $(params.url)
+    - name: flags
+      value: $(params.url)
+    workspaces:
+    - name: source
+      workspace: ws
+`,
+		want: []string{
+			`test.yaml: Pipeline release: pipeline task "build": invalid input params for task build: missing values for these params which have no default values: [url]`,
+			`test.yaml: Pipeline release: pipeline task "publish": invalid input params for task build: param types don't match the user-specified type: [flags]`,
+		},
+	}, {
+		name: "undeclared results",
+		yaml: task + `
+---
+apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: release
+spec:
+  workspaces:
+  - name: ws
+  tasks:
+  - name: build
+    taskRef:
+      name: build
+    params:
+    - name: url
+      value: https://example.com
+    workspaces:
+    - name: source
+      workspace: ws
+  - name: publish
+    taskRef:
+      name: build
+    params:
+    - name: url
+      value: $(tasks.build.results.image)
+    workspaces:
+    - name: source
+      workspace: ws
+  results:
+  - name: sha
+    value: $(tasks.publish.results.sha)
+`,
+		want: []string{
+			`test.yaml: Pipeline release: pipeline task "publish": pipeline task "build" has no result "image"`,
+			`test.yaml: Pipeline release: pipeline result "sha": pipeline task "publish" has no result "sha"`,
+		},
+	}, {
+		name: "unbound workspace",
+		yaml: task + `
+---
+apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: release
+spec:
+  tasks:
+  - name: build
+    taskRef:
+      name: build
+    params:
+    - name: url
+      value: https://example.com
+`,
+		want: []string{`test.yaml: Pipeline release: pipeline task "build": invalid workspaces for task build: bound workspaces did not match declared workspaces: didn't provide required values: [source]`},
+	}, {
+		name: "pipelinerun missing params and workspaces",
+		yaml: `
+apiVersion: tekton.dev/v1beta1
+kind: PipelineRun
+metadata:
+  name: release-run
+spec:
+  pipelineSpec:
+    params:
+    - name: url
+    workspaces:
+    - name: ws
+    tasks:
+    - name: echo
+      params:
+      - name: url
+        value: $(params.url)
+      taskSpec:
+        params:
+        - name: url
+        steps:
+        - image: busybox
+          script: echo $(params.url)
+`,
+		want: []string{
+			"test.yaml: PipelineRun release-run: PipelineRun missing parameters: [url]",
+			`test.yaml: PipelineRun release-run: pipeline expects workspace with name "ws" be provided by pipelinerun`,
+		},
+	}, {
+		name: "taskrun missing workspace",
+		yaml: task + `
+---
+apiVersion: tekton.dev/v1beta1
+kind: TaskRun
+metadata:
+  name: build-run
+spec:
+  taskRef:
+    name: build
+  params:
+  - name: url
+    value: https://example.com
+`,
+		want: []string{`test.yaml: TaskRun build-run: invalid workspaces for task build: bound workspaces did not match declared workspaces: didn't provide required values: [source]`},
+	}, {
+		name: "taskrun checked against the pipelineresources",
+		yaml: `
+apiVersion: tekton.dev/v1alpha1
+kind: PipelineResource
+metadata:
+  name: image
+spec:
+  type: image
+  params:
+  - name: url
+    value: example.com/image
+---
+apiVersion: tekton.dev/v1beta1
+kind: TaskRun
+metadata:
+  name: build-run
+spec:
+  resources:
+    inputs:
+    - name: source
+      resourceRef:
+        name: image
+  taskSpec:
+    resources:
+      inputs:
+      - name: source
+        type: git
+    steps:
+    - image: busybox
+      script: ls
+`,
+		want: []string{`test.yaml: TaskRun build-run: invalid input resources for task build-run: resource "source" should be type "image" but was "git"`},
+	}, {
+		name: "v1alpha1 resources",
+		yaml: `
+apiVersion: tekton.dev/v1alpha1
+kind: Task
+metadata:
+  name: build
+spec:
+  params:
+  - name: url
+  steps:
+  - image: busybox
+    script: echo $(params.url)
+---
+apiVersion: tekton.dev/v1alpha1
+kind: TaskRun
+metadata:
+  name: build-run
+spec:
+  taskRef:
+    name: build
+`,
+		want: []string{"test.yaml: TaskRun build-run: invalid input params for task build: missing values for these params which have no default values: [url]"},
+	}, {
+		name: "default workspace binding",
+		yaml: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config-defaults
+data:
+  default-task-run-workspace-binding: |
+    emptyDir: {}
+---` + task + `
+---
+apiVersion: tekton.dev/v1beta1
+kind: TaskRun
+metadata:
+  name: build-run
+spec:
+  taskRef:
+    name: build
+  params:
+  - name: url
+    value: https://example.com
+`,
+	}, {
+		name: "unknown fields",
+		yaml: `
+apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: build
+spec:
+  step:
+  - image: busybox
+`,
+		want: []string{`test.yaml: couldn't decode Task: json: unknown field "step"`},
+	}, {
+		name: "invalid configuration",
+		yaml: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config-defaults
+data:
+  default-timeout-minutes: forever
+---` + task,
+		want: []string{`test.yaml: ConfigMap config-defaults: failed parsing tracing config "default-timeout-minutes"`},
+	}, {
+		name: "duplicates and unknown kinds",
+		yaml: task + `
+---
+` + task + `
+---
+apiVersion: tekton.dev/v1beta1
+kind: Build
+metadata:
+  name: build
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: token
+`,
+		want: []string{
+			"test.yaml: unknown kind Build in tekton.dev/v1beta1",
+			"test.yaml: Task build: already defined in test.yaml",
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			l := NewLinter()
+			if err := l.AddReader("test.yaml", strings.NewReader(tc.yaml)); err != nil {
+				t.Fatalf("AddReader() = %v", err)
+			}
+			var got []string
+			for _, p := range l.Lint() {
+				got = append(got, p.String())
+			}
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("Lint() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestLint_AddPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tekton-lint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.MkdirAll(filepath.Join(dir, "tasks"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for file, contents := range map[string]string{
+		"tasks/build.yaml": task,
+		"run.yml": `
+apiVersion: tekton.dev/v1beta1
+kind: TaskRun
+metadata:
+  name: build-run
+spec:
+  taskRef:
+    name: build
+  workspaces:
+  - name: source
+    emptyDir: {}
+`,
+		"README.md": "not a resource",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l := NewLinter()
+	if err := l.AddPath(dir); err != nil {
+		t.Fatalf("AddPath() = %v", err)
+	}
+	want := []string{filepath.Join(dir, "run.yml") + ": TaskRun build-run: invalid input params for task build: missing values for these params which have no default values: [url]"}
+	var got []string
+	for _, p := range l.Lint() {
+		got = append(got, p.String())
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Lint() %s", diff.PrintWantGot(d))
+	}
+
+	if err := l.AddPath(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("AddPath() of a missing file should have failed")
+	}
+}
+
+// TestLint_Examples checks that the examples run by the end to end tests, each of which is applied on its own,
+// are valid.
+func TestLint_Examples(t *testing.T) {
+	root := filepath.Join("..", "..", "examples", "v1beta1")
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == "no-ci" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".yaml" {
+			return nil
+		}
+		l := NewLinter()
+		if err := l.AddPath(path); err != nil {
+			return err
+		}
+		for _, p := range l.Lint() {
+			t.Errorf("Lint() = %s", p)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
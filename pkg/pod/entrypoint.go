@@ -17,12 +17,14 @@ limitations under the License.
 package pod
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/entrypoint"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -42,6 +44,10 @@ const (
 
 	stepPrefix    = "step-"
 	sidecarPrefix = "sidecar-"
+
+	// breakpointProbePeriodSeconds is how often the readiness probe checks whether a step is held
+	// at a breakpoint
+	breakpointProbePeriodSeconds = 5
 )
 
 var (
@@ -86,7 +92,7 @@ var (
 // method, using entrypoint_lookup.go.
 //
 // TODO(#1605): Also use entrypoint injection to order sidecar start/stop.
-func orderContainers(entrypointImage string, extraEntrypointArgs []string, steps []corev1.Container, results []v1beta1.TaskResult) (corev1.Container, []corev1.Container, error) {
+func orderContainers(entrypointImage string, extraEntrypointArgs []string, steps []corev1.Container, taskSpec *v1beta1.TaskSpec) (corev1.Container, []corev1.Container, error) {
 	initContainer := corev1.Container{
 		Name:         "place-tools",
 		Image:        entrypointImage,
@@ -119,7 +125,22 @@ func orderContainers(entrypointImage string, extraEntrypointArgs []string, steps
 			}
 		}
 		argsForEntrypoint = append(argsForEntrypoint, extraEntrypointArgs...)
-		argsForEntrypoint = append(argsForEntrypoint, resultArgument(steps, results)...)
+		if taskSpec != nil {
+			argsForEntrypoint = append(argsForEntrypoint, resultArgument(steps, taskSpec.Results)...)
+			if i < len(taskSpec.Steps) {
+				whenArgs, err := whenArgument(taskSpec.Steps[i].WhenExpressions)
+				if err != nil {
+					return corev1.Container{}, nil, fmt.Errorf("Step %d has invalid when expressions: %w", i, err)
+				}
+				argsForEntrypoint = append(argsForEntrypoint, whenArgs...)
+				if taskSpec.Steps[i].OnError == v1beta1.Continue {
+					argsForEntrypoint = append(argsForEntrypoint, "-on_error", string(v1beta1.Continue))
+				}
+				if timeout := taskSpec.Steps[i].Timeout; timeout != nil && timeout.Duration > 0 {
+					argsForEntrypoint = append(argsForEntrypoint, "-timeout", timeout.Duration.String())
+				}
+			}
+		}
 
 		cmd, args := s.Command, s.Args
 		if len(cmd) == 0 {
@@ -143,6 +164,21 @@ func orderContainers(entrypointImage string, extraEntrypointArgs []string, steps
 	return initContainer, steps, nil
 }
 
+// addBreakpointProbes adds a readiness probe to each step, which succeeds once the entrypoint
+// holds the step at a breakpoint after it failed, so that a held step is reported as ready.
+func addBreakpointProbes(steps []corev1.Container) {
+	for i := range steps {
+		steps[i].ReadinessProbe = &corev1.Probe{
+			Handler: corev1.Handler{
+				Exec: &corev1.ExecAction{
+					Command: []string{entrypointBinary, "-breakpoint_probe", filepath.Join(mountPoint, fmt.Sprintf("%d%s", i, entrypoint.BreakpointSuffix))},
+				},
+			},
+			PeriodSeconds: breakpointProbePeriodSeconds,
+		}
+	}
+}
+
 func resultArgument(steps []corev1.Container, results []v1beta1.TaskResult) []string {
 	if len(results) == 0 {
 		return nil
@@ -150,6 +186,19 @@ func resultArgument(steps []corev1.Container, results []v1beta1.TaskResult) []st
 	return []string{"-results", collectResultsName(results)}
 }
 
+// whenArgument passes the When Expressions of a step to the entrypoint, which evaluates them
+// once the results written by earlier steps are available.
+func whenArgument(whenExpressions v1beta1.WhenExpressions) ([]string, error) {
+	if len(whenExpressions) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(whenExpressions)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"-when", string(b)}, nil
+}
+
 func collectResultsName(results []v1beta1.TaskResult) string {
 	var resultNames []string
 	for _, r := range results {
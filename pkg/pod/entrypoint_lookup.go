@@ -84,6 +84,39 @@ func resolveEntrypoints(cache EntrypointCache, namespace, serviceAccountName str
 	return steps, nil
 }
 
+// ResolveImageDigests returns the images of the steps specified by digest. Images that
+// are not specified by digest are looked up like in resolveEntrypoints, whether or not the
+// steps specify a Command.
+func ResolveImageDigests(cache EntrypointCache, namespace, serviceAccountName string, steps []corev1.Container) ([]string, error) {
+	localCache := map[name.Reference]name.Digest{}
+	digests := make([]string, len(steps))
+	for i, s := range steps {
+		origRef, err := name.ParseReference(s.Image, name.WeakValidation)
+		if err != nil {
+			return nil, err
+		}
+		if d, ok := origRef.(name.Digest); ok {
+			// Nothing to resolve.
+			digests[i] = d.String()
+			continue
+		}
+		digest, found := localCache[origRef]
+		if !found {
+			img, err := cache.Get(origRef, namespace, serviceAccountName)
+			if err != nil {
+				return nil, err
+			}
+			if _, digest, err = imageData(origRef, img); err != nil {
+				return nil, err
+			}
+			cache.Set(digest, img)
+			localCache[origRef] = digest
+		}
+		digests[i] = digest.String()
+	}
+	return digests, nil
+}
+
 // imageData pulls the entrypoint from the image, and returns the given
 // original reference, with image digest resolved.
 func imageData(ref name.Reference, img v1.Image) ([]string, name.Digest, error) {
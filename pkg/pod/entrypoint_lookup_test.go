@@ -104,6 +104,45 @@ func TestResolveEntrypoints(t *testing.T) {
 	}
 }
 
+func TestResolveImageDigests(t *testing.T) {
+	img, err := random.Image(1, 1)
+	if err != nil {
+		t.Fatalf("random.Image: %v", err)
+	}
+	dig, err := img.Digest()
+	if err != nil {
+		t.Fatalf("image.Digest: %v", err)
+	}
+
+	cache := fakeCache{
+		"gcr.io/my/image:latest": &data{img: img},
+	}
+
+	got, err := ResolveImageDigests(cache, "namespace", "serviceAccountName", []corev1.Container{{
+		// This step specifies its command, but its digest is still looked up.
+		Image:   "gcr.io/my/image",
+		Command: []string{"specified", "command"},
+	}, {
+		// This step is specified by digest, so there's nothing to resolve.
+		Image: "gcr.io/other/image@" + dig.String(),
+	}, {
+		// This step was already looked up, so it's resolved from the local cache.
+		Image: "gcr.io/my/image",
+	}})
+	if err != nil {
+		t.Fatalf("ResolveImageDigests: %v", err)
+	}
+
+	want := []string{
+		"gcr.io/my/image@" + dig.String(),
+		"gcr.io/other/image@" + dig.String(),
+		"gcr.io/my/image@" + dig.String(),
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Fatalf("Diff %s", diff.PrintWantGot(d))
+	}
+}
+
 type fakeCache map[string]*data
 type data struct {
 	img  v1.Image
@@ -141,10 +141,17 @@ func (b *Builder) Build(ctx context.Context, taskRun *v1beta1.TaskRun, taskSpec
 
 	// Rewrite steps with entrypoint binary. Append the entrypoint init
 	// container to place the entrypoint binary.
-	entrypointInit, stepContainers, err := orderContainers(b.Images.EntrypointImage, credEntrypointArgs, stepContainers, taskSpec.Results)
+	entrypointArgs := credEntrypointArgs
+	if taskRun.Spec.Debug.NeedsBreakpointOnFailure() {
+		entrypointArgs = append(entrypointArgs, "-breakpoint_on_failure")
+	}
+	entrypointInit, stepContainers, err := orderContainers(b.Images.EntrypointImage, entrypointArgs, stepContainers, &taskSpec)
 	if err != nil {
 		return nil, err
 	}
+	if taskRun.Spec.Debug.NeedsBreakpointOnFailure() {
+		addBreakpointProbes(stepContainers)
+	}
 	initContainers = append(initContainers, entrypointInit)
 	volumes = append(volumes, toolsVolume, downwardVolume)
 
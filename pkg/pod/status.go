@@ -19,11 +19,15 @@ package pod
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/entrypoint"
 	"github.com/tektoncd/pipeline/pkg/names"
 	"github.com/tektoncd/pipeline/pkg/termination"
 	"go.uber.org/zap"
@@ -41,6 +45,10 @@ const (
 	// that references within the TaskRun could not be resolved
 	ReasonFailedResolution = "TaskRunResolutionFailed"
 
+	// ReasonResourceVerificationFailed indicates that the reason for failure status is
+	// that the Task retrieved from a remote location is not signed by a trusted key
+	ReasonResourceVerificationFailed = "ResourceVerificationFailed"
+
 	// ReasonFailedValidation indicated that the reason for failure status is
 	// that taskrun failed runtime validation
 	ReasonFailedValidation = "TaskRunValidationFailed"
@@ -53,6 +61,9 @@ const (
 	// to resource constraints on the node
 	ReasonExceededNodeResources = "ExceededNodeResources"
 
+	// ReasonEvicted indicates that the TaskRun failed because its pod was evicted from its node
+	ReasonEvicted = "Evicted"
+
 	// ReasonCreateContainerConfigError indicates that the TaskRun failed to create a pod due to
 	// config error of container
 	ReasonCreateContainerConfigError = "CreateContainerConfigError"
@@ -69,6 +80,19 @@ const (
 	timeFormat = "2006-01-02T15:04:05.000Z07:00"
 )
 
+// The keys of the results the entrypoint writes in the termination message of a step to report the
+// state of the step.
+const (
+	// startedAtKey is the time the step started running at
+	startedAtKey = "StartedAt"
+	// skippedKey is the reason why the step was skipped, when its when expressions evaluate to false
+	skippedKey = "Skipped"
+	// exitCodeKey is the exit code of a step which failed but whose onError is continue
+	exitCodeKey = "ExitCode"
+	// timedOutKey is the timeout of a step which ran longer than its timeout
+	timedOutKey = "TimedOut"
+)
+
 const oomKilled = "OOMKilled"
 
 // SidecarsReady returns true if all of the Pod's sidecars are Ready or
@@ -107,17 +131,52 @@ func MakeTaskRunStatus(logger *zap.SugaredLogger, tr v1beta1.TaskRun, pod *corev
 	trs.PodName = pod.Name
 	trs.Steps = []v1beta1.StepState{}
 	trs.Sidecars = []v1beta1.SidecarState{}
+	trs.Debug = nil
 
 	for _, s := range pod.Status.ContainerStatuses {
 		if IsContainerStep(s.Name) {
+			if tr.Spec.Debug.NeedsBreakpointOnFailure() && isHeldAtBreakpoint(s) {
+				trs.Debug = &v1beta1.TaskRunDebugStatus{
+					PodName:       pod.Name,
+					ContainerName: s.Name,
+				}
+			}
+			var skipMessage string
+			var skipped, continued bool
 			if s.State.Terminated != nil && len(s.State.Terminated.Message) != 0 {
-				message, time, err := removeStartInfoFromTerminationMessage(s)
+				// The state is copied so that the pod keeps the state reported by the kubelet
+				terminated := s.State.Terminated.DeepCopy()
+				s.State.Terminated = terminated
+				message, internal, err := removeInternalResults(terminated.Message)
 				if err != nil {
-					logger.Errorf("error setting the start time of step %q in taskrun %q: %w", s.Name, tr.Name, err)
+					logger.Errorf("error reading the termination message of step %q in taskrun %q: %w", s.Name, tr.Name, err)
+				} else {
+					terminated.Message = message
+				}
+				if value, ok := internal[startedAtKey]; ok {
+					startedAt, err := time.Parse(timeFormat, value)
+					if err != nil {
+						logger.Errorf("error setting the start time of step %q in taskrun %q: %w", s.Name, tr.Name, err)
+					} else {
+						terminated.StartedAt = metav1.NewTime(startedAt)
+					}
 				}
-				if time != nil {
-					s.State.Terminated.StartedAt = *time
-					s.State.Terminated.Message = message
+				if value, ok := internal[skippedKey]; ok {
+					skipped, skipMessage = true, value
+				}
+				if value, ok := internal[exitCodeKey]; ok {
+					// the step exited with a non-zero exit code, which the entrypoint replaced
+					// with 0 so that the next steps run since its onError is continue
+					exitCode, err := strconv.ParseInt(value, 10, 32)
+					if err != nil {
+						logger.Errorf("error reading the exit code of step %q in taskrun %q: %w", s.Name, tr.Name, err)
+					} else {
+						terminated.ExitCode = int32(exitCode)
+					}
+					continued = true
+				}
+				if _, ok := internal[timedOutKey]; ok {
+					terminated.Reason = v1beta1.StepTimedOutReason
 				}
 			}
 			trs.Steps = append(trs.Steps, v1beta1.StepState{
@@ -125,6 +184,9 @@ func MakeTaskRunStatus(logger *zap.SugaredLogger, tr v1beta1.TaskRun, pod *corev
 				Name:           trimStepPrefix(s.Name),
 				ContainerName:  s.Name,
 				ImageID:        s.ImageID,
+				Skipped:        skipped,
+				SkipMessage:    skipMessage,
+				Continued:      continued,
 			})
 		} else if isContainerSidecar(s.Name) {
 			trs.Sidecars = append(trs.Sidecars, v1beta1.SidecarState{
@@ -140,9 +202,14 @@ func MakeTaskRunStatus(logger *zap.SugaredLogger, tr v1beta1.TaskRun, pod *corev
 	complete := areStepsComplete(pod) || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
 
 	if complete {
-		updateCompletedTaskRun(trs, pod)
+		updateCompletedTaskRun(trs, pod, tr.Annotations[pipeline.GroupName+pipeline.RetryPolicyAnnotationKey] == "true")
 	} else {
 		updateIncompleteTaskRun(trs, pod)
+		if trs.Debug != nil {
+			exitFile := breakpointExitFile(pod, trs.Debug.ContainerName)
+			MarkStatusRunning(trs, v1beta1.TaskRunReasonRunning.String(), fmt.Sprintf("Step container %q of pod %q failed and is held for debugging; write %s to continue or %s.err to fail",
+				trs.Debug.ContainerName, pod.Name, exitFile, exitFile))
+		}
 	}
 
 	// Sort step states according to the order specified in the TaskRun spec's steps.
@@ -151,41 +218,61 @@ func MakeTaskRunStatus(logger *zap.SugaredLogger, tr v1beta1.TaskRun, pod *corev
 	return *trs
 }
 
-// removeStartInfoFromTerminationMessage searches for a result called "StartedAt" in the JSON-formatted
-// termination message of a step and returns the values to use for sets State.Terminated if it's
-// found. The "StartedAt" result is also removed from the list of results in the container status.
-func removeStartInfoFromTerminationMessage(s corev1.ContainerStatus) (string, *metav1.Time, error) {
-	r, err := termination.ParseMessage(s.State.Terminated.Message)
+// IsInternalResult returns true if r is one of the results the entrypoint writes in the termination
+// message of a step to report the state of the step, which aren't results of the step.
+func IsInternalResult(r v1beta1.PipelineResourceResult) bool {
+	if r.ResultType != v1beta1.UnknownResultType {
+		return false
+	}
+	switch r.Key {
+	case startedAtKey, skippedKey, exitCodeKey, timedOutKey:
+		return true
+	}
+	return false
+}
+
+// removeInternalResults parses the JSON-formatted termination message of a step and removes the
+// results the entrypoint writes to report the state of the step. It returns the remaining
+// termination message and the values of the internal results found, by key.
+func removeInternalResults(message string) (string, map[string]string, error) {
+	r, err := termination.ParseMessage(message)
 	if err != nil {
 		return "", nil, fmt.Errorf("termination message could not be parsed as JSON: %w", err)
 	}
-	for index, result := range r {
-		if result.Key == "StartedAt" {
-			t, err := time.Parse(timeFormat, result.Value)
-			if err != nil {
-				return "", nil, fmt.Errorf("could not parse time value %q in StartedAt field: %w", result.Value, err)
-			}
-			message := ""
-			startedAt := metav1.NewTime(t)
-			// remove the entry for the starting time
-			r = append(r[:index], r[index+1:]...)
-			if len(r) == 0 {
-				message = ""
-			} else if bytes, err := json.Marshal(r); err != nil {
-				return "", nil, fmt.Errorf("error marshalling remaining results back into termination message: %w", err)
-			} else {
-				message = string(bytes)
-			}
-			return message, &startedAt, nil
+	internal := map[string]string{}
+	var remaining []v1beta1.PipelineResourceResult
+	for _, result := range r {
+		if IsInternalResult(result) {
+			internal[result.Key] = result.Value
+			continue
 		}
+		remaining = append(remaining, result)
 	}
-	return "", nil, nil
+	if len(remaining) == 0 {
+		return "", internal, nil
+	}
+	bytes, err := json.Marshal(remaining)
+	if err != nil {
+		return "", nil, fmt.Errorf("error marshalling remaining results back into termination message: %w", err)
+	}
+	return string(bytes), internal, nil
 }
 
-func updateCompletedTaskRun(trs *v1beta1.TaskRunStatus, pod *corev1.Pod) {
+func updateCompletedTaskRun(trs *v1beta1.TaskRunStatus, pod *corev1.Pod, retryable bool) {
 	if DidTaskRunFail(pod) {
 		msg := getFailureMessage(pod)
-		MarkStatusFailure(trs, msg)
+		if pod.Status.Reason == ReasonEvicted && retryable {
+			// the eviction of the pod is reported if the retry policy of the PipelineTask of the
+			// TaskRun can retry it for it
+			trs.SetCondition(&apis.Condition{
+				Type:    apis.ConditionSucceeded,
+				Status:  corev1.ConditionFalse,
+				Reason:  ReasonEvicted,
+				Message: msg,
+			})
+		} else {
+			MarkStatusFailure(trs, msg)
+		}
 	} else {
 		MarkStatusSuccess(trs)
 	}
@@ -432,6 +519,23 @@ func (trt *stepStateSorter) Less(i, j int) bool {
 	return true
 }
 
+// isHeldAtBreakpoint returns true if the step is running but ready, which its readiness probe
+// reports once the entrypoint holds it at a breakpoint after it failed
+func isHeldAtBreakpoint(s corev1.ContainerStatus) bool {
+	return s.State.Running != nil && s.Ready
+}
+
+// breakpointExitFile returns the file to write to resume the step held at a breakpoint in the
+// container named containerName
+func breakpointExitFile(pod *corev1.Pod, containerName string) string {
+	for i, c := range pod.Spec.Containers {
+		if c.Name == containerName {
+			return filepath.Join(mountPoint, fmt.Sprintf("%d%s", i, entrypoint.BreakpointExitSuffix))
+		}
+	}
+	return ""
+}
+
 func isOOMKilled(s corev1.ContainerStatus) bool {
 	return s.State.Terminated.Reason == oomKilled
 }
@@ -41,10 +41,11 @@ func TestMakeTaskRunStatus(t *testing.T) {
 		Message: "Not all Steps in the Task have finished executing",
 	}
 	for _, c := range []struct {
-		desc      string
-		podStatus corev1.PodStatus
-		taskSpec  v1beta1.TaskSpec
-		want      v1beta1.TaskRunStatus
+		desc        string
+		podStatus   corev1.PodStatus
+		annotations map[string]string
+		taskSpec    v1beta1.TaskSpec
+		want        v1beta1.TaskRunStatus
 	}{{
 		desc:      "empty",
 		podStatus: corev1.PodStatus{},
@@ -166,6 +167,170 @@ func TestMakeTaskRunStatus(t *testing.T) {
 				CompletionTime: &metav1.Time{Time: time.Now()},
 			},
 		},
+	}, {
+		desc: "skipped step",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "step-step-push",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 0,
+						Message:  `[{"key":"Skipped","value":"input \"foo\" with operator \"in\" and values [\"bar\"] evaluated to false"}]`,
+					},
+				},
+				ImageID: "image-id",
+			}},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionTrue,
+					Reason:  v1beta1.TaskRunReasonSuccessful.String(),
+					Message: "All Steps have completed executing",
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 0,
+						}},
+					Name:          "step-push",
+					ContainerName: "step-step-push",
+					ImageID:       "image-id",
+					Skipped:       true,
+					SkipMessage:   `input "foo" with operator "in" and values ["bar"] evaluated to false`,
+				}},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+			},
+		},
+	}, {
+		desc: "failed step with onError continue",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "step-lint",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 0,
+						Message:  `[{"key":"ExitCode","value":"3"}]`,
+					},
+				},
+				ImageID: "image-id",
+			}},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionTrue,
+					Reason:  v1beta1.TaskRunReasonSuccessful.String(),
+					Message: "All Steps have completed executing",
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 3,
+						}},
+					Name:          "lint",
+					ContainerName: "step-lint",
+					ImageID:       "image-id",
+					Continued:     true,
+				}},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+			},
+		},
+	}, {
+		desc: "timed out step with onError continue",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "step-test",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 0,
+						Reason:   "Completed",
+						Message:  `[{"key":"TimedOut","value":"1m0s"},{"key":"ExitCode","value":"1"}]`,
+					},
+				},
+				ImageID: "image-id",
+			}},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionTrue,
+					Reason:  v1beta1.TaskRunReasonSuccessful.String(),
+					Message: "All Steps have completed executing",
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 1,
+							Reason:   v1beta1.StepTimedOutReason,
+						}},
+					Name:          "test",
+					ContainerName: "step-test",
+					ImageID:       "image-id",
+					Continued:     true,
+				}},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+			},
+		},
+	}, {
+		desc: "timed out step",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "step-test",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+						Reason:   "Error",
+						Message:  `[{"key":"TimedOut","value":"1m0s"}]`,
+					},
+				},
+				ImageID: "image-id",
+			}},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  v1beta1.TaskRunReasonFailed.String(),
+					Message: "\"step-test\" exited with code 1 (image: \"image-id\"); for logs run: kubectl -n foo logs pod -c step-test\n",
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 1,
+							Reason:   v1beta1.StepTimedOutReason,
+						}},
+					Name:          "test",
+					ContainerName: "step-test",
+					ImageID:       "image-id",
+				}},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+			},
+		},
 	}, {
 		desc: "running",
 		podStatus: corev1.PodStatus{
@@ -257,6 +422,53 @@ func TestMakeTaskRunStatus(t *testing.T) {
 				CompletionTime: &metav1.Time{Time: time.Now()},
 			},
 		},
+	}, {
+		desc: "evicted",
+		podStatus: corev1.PodStatus{
+			Phase:   corev1.PodFailed,
+			Reason:  "Evicted",
+			Message: "The node was low on resource: memory.",
+		},
+		annotations: map[string]string{"tekton.dev/retryPolicy": "true"},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  ReasonEvicted,
+					Message: "The node was low on resource: memory.",
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps:    []v1beta1.StepState{},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+			},
+		},
+	}, {
+		desc: "evicted without retry policy",
+		podStatus: corev1.PodStatus{
+			Phase:   corev1.PodFailed,
+			Reason:  "Evicted",
+			Message: "The node was low on resource: memory.",
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  v1beta1.TaskRunReasonFailed.String(),
+					Message: "The node was low on resource: memory.",
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps:    []v1beta1.StepState{},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+			},
+		},
 	}, {
 		desc: "failed with OOM",
 		podStatus: corev1.PodStatus{
@@ -706,8 +918,9 @@ func TestMakeTaskRunStatus(t *testing.T) {
 			startTime := time.Date(2010, 1, 1, 1, 1, 1, 1, time.UTC)
 			tr := v1beta1.TaskRun{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "task-run",
-					Namespace: "foo",
+					Name:        "task-run",
+					Namespace:   "foo",
+					Annotations: c.annotations,
 				},
 				Status: v1beta1.TaskRunStatus{
 					TaskRunStatusFields: v1beta1.TaskRunStatusFields{
@@ -739,6 +952,80 @@ func TestMakeTaskRunStatus(t *testing.T) {
 	}
 }
 
+func TestMakeTaskRunStatusBreakpoint(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod",
+			Namespace: "foo",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "step-build"}, {Name: "step-test"}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "step-build",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{},
+				},
+			}, {
+				Name:  "step-test",
+				Ready: true,
+				State: corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{},
+				},
+			}},
+		},
+	}
+	for _, c := range []struct {
+		desc        string
+		debug       *v1beta1.TaskRunDebug
+		ready       bool
+		wantDebug   *v1beta1.TaskRunDebugStatus
+		wantMessage string
+	}{{
+		desc:  "step held at the breakpoint",
+		debug: &v1beta1.TaskRunDebug{Breakpoint: []string{v1beta1.BreakpointOnFailure}},
+		ready: true,
+		wantDebug: &v1beta1.TaskRunDebugStatus{
+			PodName:       "pod",
+			ContainerName: "step-test",
+		},
+		wantMessage: `Step container "step-test" of pod "pod" failed and is held for debugging; write /tekton/tools/1.breakpointexit to continue or /tekton/tools/1.breakpointexit.err to fail`,
+	}, {
+		desc:        "step running with the breakpoint",
+		debug:       &v1beta1.TaskRunDebug{Breakpoint: []string{v1beta1.BreakpointOnFailure}},
+		wantMessage: "Not all Steps in the Task have finished executing",
+	}, {
+		desc:        "ready step without the breakpoint",
+		ready:       true,
+		wantMessage: "Not all Steps in the Task have finished executing",
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			pod := pod.DeepCopy()
+			pod.Status.ContainerStatuses[1].Ready = c.ready
+			tr := v1beta1.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "task-run",
+					Namespace: "foo",
+				},
+				Spec: v1beta1.TaskRunSpec{
+					Debug: c.debug,
+				},
+			}
+
+			logger, _ := logging.NewLogger("", "status")
+			got := MakeTaskRunStatus(logger, tr, pod, v1beta1.TaskSpec{})
+			if d := cmp.Diff(c.wantDebug, got.Debug); d != "" {
+				t.Errorf("Debug status diff %s", diff.PrintWantGot(d))
+			}
+			if d := cmp.Diff(c.wantMessage, got.GetCondition(apis.ConditionSucceeded).Message); d != "" {
+				t.Errorf("Condition message diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
 func TestSidecarsReady(t *testing.T) {
 	for _, c := range []struct {
 		desc     string
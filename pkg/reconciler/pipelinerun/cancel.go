@@ -27,26 +27,36 @@ import (
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	clientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
+	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun/resources"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"knative.dev/pkg/apis"
 )
 
-// cancelPipelineRun marks the PipelineRun as cancelled and any resolved TaskRun(s) too.
+// cancelPipelineRun marks the PipelineRun as cancelled and any resolved TaskRun(s) and child PipelineRun(s) too.
 func cancelPipelineRun(logger *zap.SugaredLogger, pr *v1beta1.PipelineRun, clientSet clientset.Interface) error {
 	errs := []string{}
 
 	// Use Patch to update the TaskRuns since the TaskRun controller may be operating on the
 	// TaskRuns at the same time and trying to update the entire object may cause a race
-	b, err := getCancelPatch()
+	b, err := getCancelPatch(v1beta1.TaskRunSpecStatusCancelled)
 	if err != nil {
 		return fmt.Errorf("couldn't make patch to update TaskRun cancellation: %v", err)
 	}
+	childB, err := getCancelPatch(v1beta1.PipelineRunSpecStatusCancelled)
+	if err != nil {
+		return fmt.Errorf("couldn't make patch to update PipelineRun cancellation: %v", err)
+	}
 
 	// Loop over the TaskRuns in the PipelineRun status.
 	// If a TaskRun is not in the status yet we should not cancel it anyways.
-	for taskRunName := range pr.Status.TaskRuns {
+	for taskRunName, prtrs := range pr.Status.TaskRuns {
+		if prtrs != nil && prtrs.ResumedFrom != "" {
+			// there is no TaskRun for a PipelineTask resumed from another PipelineRun
+			continue
+		}
 		logger.Infof("cancelling TaskRun %s", taskRunName)
 
 		if _, err := clientSet.TektonV1beta1().TaskRuns(pr.Namespace).Patch(taskRunName, types.JSONPatchType, b, ""); err != nil {
@@ -54,6 +64,15 @@ func cancelPipelineRun(logger *zap.SugaredLogger, pr *v1beta1.PipelineRun, clien
 			continue
 		}
 	}
+	// Loop over the child PipelineRuns in the PipelineRun status, which cancel their own TaskRuns
+	for pipelineRunName := range pr.Status.PipelineRuns {
+		logger.Infof("cancelling PipelineRun %s", pipelineRunName)
+
+		if _, err := clientSet.TektonV1beta1().PipelineRuns(pr.Namespace).Patch(pipelineRunName, types.JSONPatchType, childB, ""); err != nil {
+			errs = append(errs, fmt.Errorf("Failed to patch PipelineRun `%s` with cancellation: %s", pipelineRunName, err).Error())
+			continue
+		}
+	}
 	// If we successfully cancelled all the TaskRuns, we can consider the PipelineRun cancelled.
 	if len(errs) == 0 {
 		pr.Status.SetCondition(&apis.Condition{
@@ -78,11 +97,122 @@ func cancelPipelineRun(logger *zap.SugaredLogger, pr *v1beta1.PipelineRun, clien
 	return nil
 }
 
-func getCancelPatch() ([]byte, error) {
+// gracefullyCancelPipelineRun cancels the running TaskRun(s) and child PipelineRun(s) of the PipelineTasks in the
+// specified dag, which lets the final tasks of the PipelineRun run once they are done. The child PipelineRun(s) are
+// gracefully cancelled too, so that their own final tasks run as well.
+func gracefullyCancelPipelineRun(logger *zap.SugaredLogger, pr *v1beta1.PipelineRun, clientSet clientset.Interface, state resources.PipelineRunState, d *dag.Graph) error {
+	errs := []string{}
+
+	b, err := getCancelPatch(v1beta1.TaskRunSpecStatusCancelled)
+	if err != nil {
+		return fmt.Errorf("couldn't make patch to update TaskRun cancellation: %v", err)
+	}
+	childB, err := getCancelPatch(v1beta1.PipelineRunSpecStatusCancelledRunFinally)
+	if err != nil {
+		return fmt.Errorf("couldn't make patch to update PipelineRun cancellation: %v", err)
+	}
+
+	cancelTaskRun := func(tr *v1beta1.TaskRun) *v1beta1.TaskRun {
+		if tr == nil || tr.IsDone() || tr.IsCancelled() {
+			return tr
+		}
+		logger.Infof("cancelling TaskRun %s", tr.Name)
+		patched, err := clientSet.TektonV1beta1().TaskRuns(pr.Namespace).Patch(tr.Name, types.JSONPatchType, b, "")
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Failed to patch TaskRun `%s` with cancellation: %s", tr.Name, err).Error())
+			return tr
+		}
+		return patched
+	}
+
+	for _, rprt := range state {
+		if _, ok := d.Nodes[rprt.PipelineTask.Name]; !ok {
+			// final tasks are not cancelled
+			continue
+		}
+		switch {
+		case rprt.IsPipeline():
+			if err := patchChildPipelineRun(logger, pr, clientSet, rprt, v1beta1.PipelineRunSpecStatusCancelledRunFinally, childB); err != nil {
+				errs = append(errs, err.Error())
+			}
+		case rprt.IsMatrixed():
+			for i, tr := range rprt.TaskRuns {
+				rprt.TaskRuns[i] = cancelTaskRun(tr)
+			}
+		default:
+			rprt.TaskRun = cancelTaskRun(rprt.TaskRun)
+		}
+	}
+
+	if len(errs) > 0 {
+		e := strings.Join(errs, "\n")
+		// Indicate that we failed to cancel the PipelineRun
+		pr.Status.SetCondition(&apis.Condition{
+			Type:    apis.ConditionSucceeded,
+			Status:  corev1.ConditionUnknown,
+			Reason:  ReasonCouldntCancel,
+			Message: fmt.Sprintf("PipelineRun %q was cancelled but had errors trying to cancel TaskRuns: %s", pr.Name, e),
+		})
+		return fmt.Errorf("error(s) from cancelling TaskRun(s) from PipelineRun %s: %s", pr.Name, e)
+	}
+	return nil
+}
+
+// gracefullyStopPipelineRun gracefully stops the running child PipelineRun(s) of the PipelineTasks in the specified
+// dag, so that they run their own final tasks once their running tasks are done, like the PipelineRun itself.
+// The running TaskRun(s) are left to finish.
+func gracefullyStopPipelineRun(logger *zap.SugaredLogger, pr *v1beta1.PipelineRun, clientSet clientset.Interface, state resources.PipelineRunState, d *dag.Graph) error {
+	errs := []string{}
+
+	childB, err := getCancelPatch(v1beta1.PipelineRunSpecStatusStoppedRunFinally)
+	if err != nil {
+		return fmt.Errorf("couldn't make patch to update PipelineRun stop: %v", err)
+	}
+
+	for _, rprt := range state {
+		if _, ok := d.Nodes[rprt.PipelineTask.Name]; !ok || !rprt.IsPipeline() {
+			continue
+		}
+		if err := patchChildPipelineRun(logger, pr, clientSet, rprt, v1beta1.PipelineRunSpecStatusStoppedRunFinally, childB); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		e := strings.Join(errs, "\n")
+		// Indicate that we failed to stop the PipelineRun
+		pr.Status.SetCondition(&apis.Condition{
+			Type:    apis.ConditionSucceeded,
+			Status:  corev1.ConditionUnknown,
+			Reason:  ReasonCouldntCancel,
+			Message: fmt.Sprintf("PipelineRun %q was stopped but had errors trying to stop child PipelineRuns: %s", pr.Name, e),
+		})
+		return fmt.Errorf("error(s) from stopping child PipelineRun(s) from PipelineRun %s: %s", pr.Name, e)
+	}
+	return nil
+}
+
+// patchChildPipelineRun patches the spec status of the running child PipelineRun of rprt to specStatus, unless it is
+// already set to it or the child PipelineRun is already cancelled, which is the strongest way to stop it.
+func patchChildPipelineRun(logger *zap.SugaredLogger, pr *v1beta1.PipelineRun, clientSet clientset.Interface, rprt *resources.ResolvedPipelineRunTask, specStatus v1beta1.PipelineRunSpecStatus, patch []byte) error {
+	child := rprt.PipelineRun
+	if child == nil || child.IsDone() || child.Spec.Status == specStatus || child.IsGracefullyCancelled() {
+		return nil
+	}
+	logger.Infof("setting the spec status of PipelineRun %s to %s", child.Name, specStatus)
+	patched, err := clientSet.TektonV1beta1().PipelineRuns(pr.Namespace).Patch(child.Name, types.JSONPatchType, patch, "")
+	if err != nil {
+		return fmt.Errorf("Failed to patch PipelineRun `%s` with %s: %s", child.Name, specStatus, err)
+	}
+	rprt.PipelineRun = patched
+	return nil
+}
+
+func getCancelPatch(specStatus string) ([]byte, error) {
 	patches := []jsonpatch.JsonPatchOperation{{
 		Operation: "add",
 		Path:      "/spec/status",
-		Value:     v1beta1.TaskRunSpecStatusCancelled,
+		Value:     specStatus,
 	}}
 	patchBytes, err := json.Marshal(patches)
 	if err != nil {
@@ -0,0 +1,332 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun/resources"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// concurrencyLeasePrefix prefixes the names of the leases held for concurrency keys
+	concurrencyLeasePrefix = "tekton-concurrency-"
+	// concurrencyKeyAnnotationKey annotates the lease of a concurrency key with the key itself,
+	// since its name only holds a hash of it
+	concurrencyKeyAnnotationKey = pipeline.GroupName + "/concurrencyKey"
+	// taskConcurrencyQueuedLabelPrefix prefixes the hash of the concurrency key of a PipelineTask in
+	// the label of a PipelineRun waiting for it, which fits in the 63 characters of the name of a label
+	taskConcurrencyQueuedLabelPrefix = pipeline.GroupName + "/queued-"
+	// concurrencyPollInterval is how often a PipelineRun waiting for a concurrency key checks
+	// whether it was released
+	concurrencyPollInterval = 10 * time.Second
+	// concurrencyHolderGracePeriod is how long the holder of a concurrency key is considered
+	// active when it can't be found, to give the informers the time to see a new TaskRun
+	concurrencyHolderGracePeriod = time.Minute
+)
+
+// concurrencyKeyHash returns the hash a concurrency key is referred to by in the names of leases
+// and in labels, whose values are limited to 63 characters, which fits the 56 of a SHA-224 hash
+func concurrencyKeyHash(key string) string {
+	return fmt.Sprintf("%x", sha256.Sum224([]byte(key)))
+}
+
+// acquireConcurrencyKey makes holder, a "PipelineRun/<name>" or "TaskRun/<name>", the holder of
+// the lease of the concurrency key in the namespace, unless it is held by another active holder.
+// If the lease isn't held by an active holder and next is not nil, next returns the holder which
+// should acquire it first, if any. The holder waited for is returned, or "" if the key was acquired.
+func (c *Reconciler) acquireConcurrencyKey(namespace, key, holder string, owner metav1.OwnerReference, next func() string) (string, error) {
+	leases := c.KubeClientSet.CoordinationV1().Leases(namespace)
+	name := concurrencyLeasePrefix + concurrencyKeyHash(key)
+	now := metav1.NewMicroTime(time.Now())
+
+	lease, err := leases.Get(name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		if next != nil {
+			if first := next(); first != "" {
+				return first, nil
+			}
+		}
+		_, err = leases.Create(&coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       namespace,
+				Annotations:     map[string]string{concurrencyKeyAnnotationKey: key},
+				OwnerReferences: []metav1.OwnerReference{owner},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity: &holder,
+				AcquireTime:    &now,
+			},
+		})
+		// another holder may have created the lease in the meantime, in which case the
+		// error requeues the reconcile to try again
+		return "", err
+	} else if err != nil {
+		return "", err
+	}
+
+	current := ""
+	if lease.Spec.HolderIdentity != nil {
+		current = *lease.Spec.HolderIdentity
+	}
+	if current == holder {
+		return "", nil
+	}
+	if current != "" && c.isConcurrencyHolderActive(namespace, current, lease.Spec.AcquireTime) {
+		return current, nil
+	}
+	if next != nil {
+		if first := next(); first != "" {
+			return first, nil
+		}
+	}
+	lease = lease.DeepCopy()
+	lease.OwnerReferences = []metav1.OwnerReference{owner}
+	lease.Spec.HolderIdentity = &holder
+	lease.Spec.AcquireTime = &now
+	// the update fails with a conflict if another holder took over the lease in the meantime
+	_, err = leases.Update(lease)
+	return "", err
+}
+
+// isConcurrencyHolderActive returns true if the PipelineRun or TaskRun holding a concurrency key
+// since acquired is not done yet
+func (c *Reconciler) isConcurrencyHolderActive(namespace, holder string, acquired *metav1.MicroTime) bool {
+	parts := strings.SplitN(holder, "/", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	var done bool
+	var err error
+	switch parts[0] {
+	case pipeline.PipelineRunControllerName:
+		var pr *v1beta1.PipelineRun
+		if pr, err = c.pipelineRunLister.PipelineRuns(namespace).Get(parts[1]); err == nil {
+			done = pr.IsDone()
+		}
+	case pipeline.TaskRunControllerName:
+		var tr *v1beta1.TaskRun
+		if tr, err = c.taskRunLister.TaskRuns(namespace).Get(parts[1]); err == nil {
+			done = tr.IsDone()
+		}
+	default:
+		return false
+	}
+	if err != nil {
+		return acquired != nil && time.Since(acquired.Time) < concurrencyHolderGracePeriod
+	}
+	return !done
+}
+
+// queueForConcurrencyKey acquires the concurrency key of the PipelineRun before it runs its first
+// Task. With the queue policy, the PipelineRuns waiting for the key acquire it in the order they
+// were created. With the cancel-previous policy, the older PipelineRuns holding or waiting for the
+// key are cancelled. True is returned if the PipelineRun has to wait for the key.
+func (c *Reconciler) queueForConcurrencyKey(ctx context.Context, pr *v1beta1.PipelineRun, pipelineSpec *v1beta1.PipelineSpec, pipelineName string) (bool, error) {
+	logger := logging.FromContext(ctx)
+	key := resources.ApplyConcurrencyKey(pr.Spec.Concurrency.Key, pipelineSpec, pipelineName, pr)
+	hash := concurrencyKeyHash(key)
+	// the label lets the PipelineRuns waiting for the same key be found
+	pr.ObjectMeta.Labels[pipeline.GroupName+pipeline.ConcurrencyKeyLabelKey] = hash
+
+	waiting, err := c.pipelineRunsWithConcurrencyKey(pr, hash)
+	if err != nil {
+		return false, err
+	}
+	var next func() string
+	if pr.Spec.Concurrency.CancelsPrevious() {
+		if err := c.cancelPipelineRuns(ctx, pr.Namespace, waiting); err != nil {
+			return false, err
+		}
+	} else {
+		next = func() string {
+			for _, w := range waiting {
+				if !w.IsCancelled() {
+					return fmt.Sprintf("%s/%s", pipeline.PipelineRunControllerName, w.Name)
+				}
+			}
+			return ""
+		}
+	}
+
+	holder, err := c.acquireConcurrencyKey(pr.Namespace, key, fmt.Sprintf("%s/%s", pipeline.PipelineRunControllerName, pr.Name), pr.GetOwnerReference(), next)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire the concurrency key %q of PipelineRun %s: %w", key, pr.Name, err)
+	}
+	if holder == "" {
+		return false, nil
+	}
+	logger.Infof("PipelineRun %s is waiting for %s to release the concurrency key %q", pr.Name, holder, key)
+	pr.Status.MarkRunning(v1beta1.PipelineRunReasonQueued.String(), "Waiting for %s to release the concurrency key %q", holder, key)
+	c.enqueueAfter(pr.GetNamespacedName(), concurrencyPollInterval)
+	return true, nil
+}
+
+// pipelineRunsWithConcurrencyKey returns the PipelineRuns with the hash of the concurrency key which
+// are not done and were created before the PipelineRun, oldest first
+func (c *Reconciler) pipelineRunsWithConcurrencyKey(pr *v1beta1.PipelineRun, hash string) ([]*v1beta1.PipelineRun, error) {
+	prs, err := c.pipelineRunLister.PipelineRuns(pr.Namespace).List(labels.SelectorFromSet(labels.Set{
+		pipeline.GroupName + pipeline.ConcurrencyKeyLabelKey: hash,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	var older []*v1beta1.PipelineRun
+	for _, other := range prs {
+		if other.Name != pr.Name && !other.IsDone() && createdBefore(&other.ObjectMeta, &pr.ObjectMeta) {
+			older = append(older, other)
+		}
+	}
+	sort.Slice(older, func(i, j int) bool { return createdBefore(&older[i].ObjectMeta, &older[j].ObjectMeta) })
+	return older, nil
+}
+
+// createdBefore returns true if a was created before b, using their names to break ties
+func createdBefore(a, b *metav1.ObjectMeta) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
+// cancelPipelineRuns cancels the PipelineRuns which aren't cancelled yet
+func (c *Reconciler) cancelPipelineRuns(ctx context.Context, namespace string, prs []*v1beta1.PipelineRun) error {
+	logger := logging.FromContext(ctx)
+	b, err := getCancelPatch(v1beta1.PipelineRunSpecStatusCancelled)
+	if err != nil {
+		return err
+	}
+	for _, pr := range prs {
+		if pr.IsCancelled() {
+			continue
+		}
+		logger.Infof("Cancelling PipelineRun %s holding or waiting for the same concurrency key", pr.Name)
+		if _, err := c.PipelineClientSet.TektonV1beta1().PipelineRuns(namespace).Patch(pr.Name, types.JSONPatchType, b, ""); err != nil {
+			return fmt.Errorf("failed to cancel PipelineRun %s: %w", pr.Name, err)
+		}
+	}
+	return nil
+}
+
+// releaseConcurrencyKey requeues the PipelineRuns waiting for the concurrency key of a done
+// PipelineRun, so that the next one acquires it without waiting for its next poll
+func (c *Reconciler) releaseConcurrencyKey(ctx context.Context, pr *v1beta1.PipelineRun) {
+	hash := pr.ObjectMeta.Labels[pipeline.GroupName+pipeline.ConcurrencyKeyLabelKey]
+	if pr.Spec.Concurrency == nil || hash == "" {
+		return
+	}
+	prs, err := c.pipelineRunLister.PipelineRuns(pr.Namespace).List(labels.SelectorFromSet(labels.Set{
+		pipeline.GroupName + pipeline.ConcurrencyKeyLabelKey: hash,
+	}))
+	if err != nil {
+		logging.FromContext(ctx).Warnf("Failed to list the PipelineRuns waiting for the concurrency key of PipelineRun %s: %v", pr.Name, err)
+		return
+	}
+	for _, waiting := range prs {
+		if waiting.Name != pr.Name && !waiting.IsDone() {
+			c.enqueueAfter(waiting.GetNamespacedName(), 0)
+		}
+	}
+}
+
+// acquireTaskConcurrencyKey acquires the concurrency key of a PipelineTask for its TaskRun. While it
+// waits for the key, the PipelineRun is labeled with the hash of the key, so that with the queue
+// policy the PipelineRuns waiting for the key acquire it in the order they were created. With the
+// cancel-previous policy, a TaskRun holding the key which was created before the PipelineRun is
+// cancelled. True is returned if the TaskRun has to wait for the key.
+func (c *Reconciler) acquireTaskConcurrencyKey(ctx context.Context, pr *v1beta1.PipelineRun, rprt *resources.ResolvedPipelineRunTask) (bool, error) {
+	logger := logging.FromContext(ctx)
+	recorder := controller.GetEventRecorder(ctx)
+	concurrency := rprt.PipelineTask.Concurrency
+	label := taskConcurrencyQueuedLabelPrefix + concurrencyKeyHash(concurrency.Key)
+	var next func() string
+	if !concurrency.CancelsPrevious() {
+		next = func() string {
+			return c.firstQueuedPipelineRun(pr, label)
+		}
+	}
+	holder, err := c.acquireConcurrencyKey(pr.Namespace, concurrency.Key, fmt.Sprintf("%s/%s", pipeline.TaskRunControllerName, rprt.TaskRunName), pr.GetOwnerReference(), next)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire the concurrency key %q of PipelineTask %s: %w", concurrency.Key, rprt.PipelineTask.Name, err)
+	}
+	if holder == "" {
+		delete(pr.ObjectMeta.Labels, label)
+		return false, nil
+	}
+	if pr.ObjectMeta.Labels == nil {
+		pr.ObjectMeta.Labels = make(map[string]string)
+	}
+	pr.ObjectMeta.Labels[label] = "true"
+
+	if name := strings.TrimPrefix(holder, pipeline.TaskRunControllerName+"/"); concurrency.CancelsPrevious() && name != holder {
+		tr, err := c.taskRunLister.TaskRuns(pr.Namespace).Get(name)
+		if err == nil && !tr.IsCancelled() && tr.CreationTimestamp.Before(&pr.CreationTimestamp) {
+			logger.Infof("Cancelling TaskRun %s holding the concurrency key %q of PipelineTask %s", tr.Name, concurrency.Key, rprt.PipelineTask.Name)
+			b, err := getCancelPatch(v1beta1.TaskRunSpecStatusCancelled)
+			if err != nil {
+				return false, err
+			}
+			if _, err := c.PipelineClientSet.TektonV1beta1().TaskRuns(pr.Namespace).Patch(tr.Name, types.JSONPatchType, b, ""); err != nil {
+				return false, fmt.Errorf("failed to cancel TaskRun %s: %w", tr.Name, err)
+			}
+		}
+	}
+	logger.Infof("PipelineTask %s of PipelineRun %s is waiting for %s to release the concurrency key %q", rprt.PipelineTask.Name, pr.Name, holder, concurrency.Key)
+	recorder.Eventf(pr, corev1.EventTypeNormal, "ConcurrencyQueued", "PipelineTask %s is waiting for %s to release the concurrency key %q", rprt.PipelineTask.Name, holder, concurrency.Key)
+	return true, nil
+}
+
+// firstQueuedPipelineRun returns the "PipelineRun/<name>" of the oldest PipelineRun created before pr
+// which is labeled as waiting for the concurrency key of a PipelineTask and is not done, if any
+func (c *Reconciler) firstQueuedPipelineRun(pr *v1beta1.PipelineRun, label string) string {
+	exists, err := labels.NewRequirement(label, selection.Exists, nil)
+	if err != nil {
+		return ""
+	}
+	prs, err := c.pipelineRunLister.PipelineRuns(pr.Namespace).List(labels.NewSelector().Add(*exists))
+	if err != nil {
+		return ""
+	}
+	var first *v1beta1.PipelineRun
+	for _, other := range prs {
+		if other.Name != pr.Name && !other.IsDone() && !other.IsCancelled() && createdBefore(&other.ObjectMeta, &pr.ObjectMeta) &&
+			(first == nil || createdBefore(&other.ObjectMeta, &first.ObjectMeta)) {
+			first = other
+		}
+	}
+	if first == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s", pipeline.PipelineRunControllerName, first.Name)
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"testing"
+	"time"
+
+	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/apis"
+)
+
+func concurrencyPipelineRun(name string, created time.Time, ops ...tb.PipelineRunSpecOp) *v1beta1.PipelineRun {
+	pr := tb.PipelineRun(name, tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", append(ops, tb.PipelineRunServiceAccountName("test-sa"))...),
+	)
+	pr.CreationTimestamp = metav1.Time{Time: created}
+	return pr
+}
+
+func concurrencyLease(key, holder string) *coordinationv1.Lease {
+	acquired := metav1.NewMicroTime(time.Now())
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      concurrencyLeasePrefix + concurrencyKeyHash(key),
+			Namespace: "foo",
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity: &holder,
+			AcquireTime:    &acquired,
+		},
+	}
+}
+
+func createdTaskRuns(clients test.Clients) int {
+	taskRuns := 0
+	for _, a := range clients.Pipeline.Actions() {
+		if action, ok := a.(ktesting.CreateAction); ok {
+			if _, ok := action.GetObject().(*v1beta1.TaskRun); ok {
+				taskRuns++
+			}
+		}
+	}
+	return taskRuns
+}
+
+// TestReconcileWithConcurrency runs "Reconcile" on a PipelineRun with a concurrency key held by
+// another PipelineRun, and verifies that it only runs its tasks once the key is released
+func TestReconcileWithConcurrency(t *testing.T) {
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineParamSpec("env", v1beta1.ParamTypeString),
+		tb.PipelineTask("deploy", "hello-world"),
+	))}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	now := time.Now()
+	succeeded := tb.PipelineRunStatus(tb.PipelineRunStatusCondition(apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionTrue,
+	}))
+
+	for _, tc := range []struct {
+		name         string
+		policy       v1beta1.ConcurrencyPolicy
+		holderOps    []tb.PipelineRunOp
+		wantQueued   bool
+		wantCanceled bool
+	}{{
+		name:       "key held by a running pipelinerun",
+		policy:     v1beta1.ConcurrencyPolicyQueue,
+		wantQueued: true,
+	}, {
+		name:      "key held by a done pipelinerun",
+		policy:    v1beta1.ConcurrencyPolicyQueue,
+		holderOps: []tb.PipelineRunOp{succeeded},
+	}, {
+		name:         "key held by a running pipelinerun cancelled by the new one",
+		policy:       v1beta1.ConcurrencyPolicyCancelPrevious,
+		wantQueued:   true,
+		wantCanceled: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			holder := concurrencyPipelineRun("test-pipeline-run-holder", now.Add(-time.Minute),
+				tb.PipelineRunParam("env", "prod"),
+				tb.PipelineRunConcurrency("deploy-$(params.env)", tc.policy),
+			)
+			for _, op := range tc.holderOps {
+				op(holder)
+			}
+			holder.Labels = map[string]string{pipeline.GroupName + pipeline.ConcurrencyKeyLabelKey: concurrencyKeyHash("deploy-prod")}
+			pr := concurrencyPipelineRun("test-pipeline-run-waiting", now,
+				tb.PipelineRunParam("env", "prod"),
+				tb.PipelineRunConcurrency("deploy-$(params.env)", tc.policy),
+			)
+			d := test.Data{
+				PipelineRuns: []*v1beta1.PipelineRun{holder, pr},
+				Pipelines:    ps,
+				Tasks:        ts,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+			if _, err := prt.TestAssets.Clients.Kube.CoordinationV1().Leases("foo").Create(concurrencyLease("deploy-prod", "PipelineRun/test-pipeline-run-holder")); err != nil {
+				t.Fatal(err)
+			}
+
+			reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-waiting", nil, false)
+
+			condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+			if queued := condition.Reason == v1beta1.PipelineRunReasonQueued.String(); queued != tc.wantQueued {
+				t.Errorf("Expected the PipelineRun to be queued: %t, got condition %v", tc.wantQueued, condition)
+			}
+			wantTaskRuns := 1
+			if tc.wantQueued {
+				wantTaskRuns = 0
+			}
+			if taskRuns := createdTaskRuns(clients); taskRuns != wantTaskRuns {
+				t.Errorf("Expected %d TaskRuns to be created but got %d", wantTaskRuns, taskRuns)
+			}
+			if got := reconciledRun.Labels[pipeline.GroupName+pipeline.ConcurrencyKeyLabelKey]; got != concurrencyKeyHash("deploy-prod") {
+				t.Errorf("Expected the PipelineRun to be labeled with the hash of its concurrency key, got %q", got)
+			}
+
+			canceled := false
+			for _, a := range clients.Pipeline.Actions() {
+				if action, ok := a.(ktesting.PatchAction); ok && action.GetName() == holder.Name {
+					canceled = true
+				}
+			}
+			if canceled != tc.wantCanceled {
+				t.Errorf("Expected the holder PipelineRun to be cancelled: %t, got %t", tc.wantCanceled, canceled)
+			}
+
+			lease, err := clients.Kube.CoordinationV1().Leases("foo").Get(concurrencyLeasePrefix+concurrencyKeyHash("deploy-prod"), metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantHolder := "PipelineRun/test-pipeline-run-holder"
+			if !tc.wantQueued {
+				wantHolder = "PipelineRun/test-pipeline-run-waiting"
+			}
+			if *lease.Spec.HolderIdentity != wantHolder {
+				t.Errorf("Expected the lease to be held by %s, got %s", wantHolder, *lease.Spec.HolderIdentity)
+			}
+		})
+	}
+}
+
+// TestReconcileWithConcurrencyQueueOrder verifies that the PipelineRuns waiting for a released
+// concurrency key acquire it in the order they were created
+func TestReconcileWithConcurrencyQueueOrder(t *testing.T) {
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("deploy", "hello-world"),
+	))}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	now := time.Now()
+	older := concurrencyPipelineRun("test-pipeline-run-older", now.Add(-time.Minute), tb.PipelineRunConcurrency("deploy", ""))
+	older.Labels = map[string]string{pipeline.GroupName + pipeline.ConcurrencyKeyLabelKey: concurrencyKeyHash("deploy")}
+	newer := concurrencyPipelineRun("test-pipeline-run-newer", now, tb.PipelineRunConcurrency("deploy", ""))
+	d := test.Data{
+		PipelineRuns: []*v1beta1.PipelineRun{older, newer},
+		Pipelines:    ps,
+		Tasks:        ts,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-newer", nil, false)
+	condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+	if condition.Reason != v1beta1.PipelineRunReasonQueued.String() || createdTaskRuns(clients) != 0 {
+		t.Fatalf("Expected the newer PipelineRun to wait for the older one, got condition %v", condition)
+	}
+
+	reconciledRun, clients = prt.reconcileRun("foo", "test-pipeline-run-older", nil, false)
+	condition = reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+	if condition.Reason == v1beta1.PipelineRunReasonQueued.String() || createdTaskRuns(clients) != 1 {
+		t.Errorf("Expected the older PipelineRun to acquire the key and run, got condition %v", condition)
+	}
+}
+
+// TestReconcileWithTaskConcurrency runs "Reconcile" on a PipelineRun whose PipelineTask has a
+// concurrency key held by the TaskRun of another PipelineRun, and verifies that its TaskRun waits
+func TestReconcileWithTaskConcurrency(t *testing.T) {
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("deploy", "hello-world", tb.PipelineTaskConcurrency("deploy-$(context.pipeline.name)", v1beta1.ConcurrencyPolicyQueue)),
+		tb.PipelineTask("build", "hello-world"),
+	))}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	for _, tc := range []struct {
+		name       string
+		holderDone bool
+		wantQueued bool
+	}{{
+		name:       "key held by a running taskrun",
+		wantQueued: true,
+	}, {
+		name:       "key held by a done taskrun",
+		holderDone: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			holder := tb.TaskRun("other-run-deploy", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(tb.TaskRunTaskRef("hello-world")))
+			if tc.holderDone {
+				holder.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue})
+			}
+			d := test.Data{
+				PipelineRuns: []*v1beta1.PipelineRun{concurrencyPipelineRun("test-pipeline-run-task-concurrency", time.Now())},
+				Pipelines:    ps,
+				Tasks:        ts,
+				TaskRuns:     []*v1beta1.TaskRun{holder},
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+			if _, err := prt.TestAssets.Clients.Kube.CoordinationV1().Leases("foo").Create(concurrencyLease("deploy-test-pipeline", "TaskRun/other-run-deploy")); err != nil {
+				t.Fatal(err)
+			}
+
+			var wantEvents []string
+			wantTaskRuns := 2
+			if tc.wantQueued {
+				wantEvents = []string{
+					"Normal Started",
+					`Normal ConcurrencyQueued PipelineTask deploy is waiting for TaskRun/other-run-deploy to release the concurrency key "deploy-test-pipeline"`,
+					"Normal Running Tasks Completed: 0",
+				}
+				wantTaskRuns = 1
+			}
+			_, clients := prt.reconcileRun("foo", "test-pipeline-run-task-concurrency", wantEvents, false)
+			if taskRuns := createdTaskRuns(clients); taskRuns != wantTaskRuns {
+				t.Errorf("Expected %d TaskRuns to be created but got %d", wantTaskRuns, taskRuns)
+			}
+		})
+	}
+}
+
+// TestReconcileWithTaskConcurrencyQueueOrder verifies that the PipelineRuns whose PipelineTasks wait
+// for a released concurrency key acquire it in the order they were created
+func TestReconcileWithTaskConcurrencyQueueOrder(t *testing.T) {
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("deploy", "hello-world", tb.PipelineTaskConcurrency("deploy", v1beta1.ConcurrencyPolicyQueue)),
+	))}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	label := taskConcurrencyQueuedLabelPrefix + concurrencyKeyHash("deploy")
+	now := time.Now()
+	older := concurrencyPipelineRun("test-pipeline-run-older", now.Add(-time.Minute))
+	older.Labels = map[string]string{label: "true"}
+	newer := concurrencyPipelineRun("test-pipeline-run-newer", now)
+	d := test.Data{
+		PipelineRuns: []*v1beta1.PipelineRun{older, newer},
+		Pipelines:    ps,
+		Tasks:        ts,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-newer", nil, false)
+	if createdTaskRuns(clients) != 0 || reconciledRun.Labels[label] != "true" {
+		t.Fatalf("Expected the newer PipelineRun to wait for the older one, got labels %v", reconciledRun.Labels)
+	}
+
+	reconciledRun, clients = prt.reconcileRun("foo", "test-pipeline-run-older", nil, false)
+	if createdTaskRuns(clients) != 1 {
+		t.Errorf("Expected the older PipelineRun to acquire the key and run its TaskRun")
+	}
+	if _, ok := reconciledRun.Labels[label]; ok {
+		t.Errorf("Expected the older PipelineRun to no longer be labeled as waiting, got labels %v", reconciledRun.Labels)
+	}
+}
@@ -32,6 +32,7 @@ import (
 	pipelinerunreconciler "github.com/tektoncd/pipeline/pkg/client/injection/reconciler/pipeline/v1beta1/pipelinerun"
 	resourceinformer "github.com/tektoncd/pipeline/pkg/client/resource/injection/informers/resource/v1alpha1/pipelineresource"
 	cloudeventclient "github.com/tektoncd/pipeline/pkg/reconciler/events/cloudevent"
+	taskrunresources "github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
 	"github.com/tektoncd/pipeline/pkg/reconciler/volumeclaim"
 	"github.com/tektoncd/pipeline/pkg/timeout"
 	"k8s.io/client-go/tools/cache"
@@ -76,16 +77,19 @@ func NewController(namespace string, images pipeline.Images) func(context.Contex
 			cloudEventClient:  cloudeventclient.Get(ctx),
 			metrics:           metrics,
 			pvcHandler:        volumeclaim.NewPVCHandler(kubeclientset, logger),
+			remoteConfigStore: taskrunresources.NewRemoteConfigStore(kubeclientset),
 		}
 		impl := pipelinerunreconciler.NewImpl(ctx, c, func(impl *controller.Impl) controller.Options {
 			configStore := config.NewStore(logger.Named("config-store"))
 			configStore.WatchConfigs(cmw)
+			c.remoteConfigStore.WatchConfigs(cmw)
 			return controller.Options{
 				AgentName:   pipeline.PipelineRunControllerName,
 				ConfigStore: configStore,
 			}
 		})
 
+		c.enqueueAfter = impl.EnqueueKeyAfter
 		timeoutHandler.SetCallbackFunc(impl.EnqueueKey)
 		timeoutHandler.CheckTimeouts(ctx, namespace, kubeclientset, pipelineclientset)
 
@@ -100,6 +104,10 @@ func NewController(namespace string, images pipeline.Images) func(context.Contex
 		taskRunInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 			UpdateFunc: controller.PassNew(impl.EnqueueControllerOf),
 		})
+		// Child PipelineRuns of PipelineTasks running a Pipeline are owned by their parent PipelineRun
+		pipelineRunInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			UpdateFunc: controller.PassNew(impl.EnqueueControllerOf),
+		})
 
 		go metrics.ReportRunningPipelineRuns(ctx, pipelineRunInformer.Lister())
 
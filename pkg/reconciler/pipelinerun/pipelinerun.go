@@ -18,9 +18,12 @@ package pipelinerun
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -43,14 +46,17 @@ import (
 	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
 	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun/resources"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun"
+	taskrunresources "github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
 	"github.com/tektoncd/pipeline/pkg/reconciler/volumeclaim"
+	"github.com/tektoncd/pipeline/pkg/remote"
 	"github.com/tektoncd/pipeline/pkg/timeout"
 	"github.com/tektoncd/pipeline/pkg/workspace"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/controller"
@@ -63,6 +69,12 @@ const (
 	// ReasonCouldntGetPipeline indicates that the reason for the failure status is that the
 	// associated Pipeline couldn't be retrieved
 	ReasonCouldntGetPipeline = "CouldntGetPipeline"
+	// ReasonResourceVerificationFailed indicates that the reason for the failure status is that the
+	// signatures of the Pipeline or of one of its Tasks couldn't be verified
+	ReasonResourceVerificationFailed = "ResourceVerificationFailed"
+	// ReasonCouldntGetResumedPipelineRun indicates that the reason for the failure status is that the
+	// PipelineRun it resumes from couldn't be retrieved
+	ReasonCouldntGetResumedPipelineRun = "CouldntGetResumedPipelineRun"
 	// ReasonInvalidBindings indicates that the reason for the failure status is that the
 	// PipelineResources bound in the PipelineRun didn't match those declared in the Pipeline
 	ReasonInvalidBindings = "InvalidPipelineResourceBindings"
@@ -87,12 +99,21 @@ const (
 	// ReasonParameterMissing indicates that the reason for the failure status is that the
 	// associated PipelineRun didn't provide all the required parameters
 	ReasonParameterMissing = "ParameterMissing"
+	// ReasonInvalidTaskResultReference indicates that the reason for the failure status is that a
+	// PipelineTask references an array result where it can't be expanded into its values
+	ReasonInvalidTaskResultReference = "InvalidTaskResultReference"
+	// ReasonEmptyMatrixParameter indicates that the reason for the failure status is that a
+	// Matrix parameter of a PipelineTask has no values once its references are substituted
+	ReasonEmptyMatrixParameter = "EmptyMatrixParameter"
 	// ReasonFailedValidation indicates that the reason for failure status is
 	// that pipelinerun failed runtime validation
 	ReasonFailedValidation = "PipelineValidationFailed"
 	// ReasonInvalidGraph indicates that the reason for the failure status is that the
 	// associated Pipeline is an invalid graph (a.k.a wrong order, cycle, …)
 	ReasonInvalidGraph = "PipelineInvalidGraph"
+	// ReasonPipelineRefCycle indicates that the reason for the failure status is that a
+	// PipelineTask references a Pipeline which the PipelineRun is already nested in
+	ReasonPipelineRefCycle = "PipelineRefCycle"
 	// ReasonCancelled indicates that a PipelineRun was cancelled.
 	ReasonCancelled = "PipelineRunCancelled"
 	// ReasonCouldntCancel indicates that a PipelineRun was cancelled but attempting to update
@@ -119,6 +140,10 @@ type Reconciler struct {
 	timeoutHandler    *timeout.Handler
 	metrics           *Recorder
 	pvcHandler        volumeclaim.PvcHandler
+	remoteConfigStore *taskrunresources.RemoteConfigStore
+	// enqueueAfter reconciles a PipelineRun again after a delay. A PipelineRun already waiting in the
+	// work queue is only reconciled once, at the earliest of the delays.
+	enqueueAfter func(types.NamespacedName, time.Duration)
 }
 
 var (
@@ -136,7 +161,9 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, pr *v1beta1.PipelineRun)
 	// Read the initial condition
 	before := pr.Status.GetCondition(apis.ConditionSucceeded)
 
-	if !pr.HasStarted() {
+	// a pending PipelineRun is only started once its spec status is cleared, so that the time it
+	// spends pending doesn't count toward its timeout
+	if !pr.HasStarted() && !pr.IsPending() {
 		pr.Status.InitializeConditions()
 		// In case node time was not synchronized, when controller has been scheduled to other nodes.
 		if pr.Status.StartTime.Sub(pr.CreationTimestamp.Time) < 0 {
@@ -146,6 +173,10 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, pr *v1beta1.PipelineRun)
 
 		// start goroutine to track pipelinerun timeout only startTime is not set
 		go c.timeoutHandler.Wait(pr.GetNamespacedName(), *pr.Status.StartTime, getPipelineRunTimeout(ctx, pr))
+		// the pipelinerun also needs to be reconciled when its tasks time out, to start the finally tasks
+		if tasksTimeout := pr.TasksTimeout(); tasksTimeout != nil && tasksTimeout.Duration != apisconfig.NoTimeoutDuration {
+			go c.timeoutHandler.SetTimer(pr.GetNamespacedName(), time.Until(pr.Status.StartTime.Add(tasksTimeout.Duration)))
+		}
 		// Emit events. During the first reconcile the status of the PipelineRun may change twice
 		// from not Started to Started and then to Running, so we need to sent the event here
 		// and at the end of 'Reconcile' again.
@@ -173,10 +204,15 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, pr *v1beta1.PipelineRun)
 			return c.finishReconcileUpdateEmitEvents(ctx, pr, before, err)
 		}
 		c.timeoutHandler.Release(pr.GetNamespacedName())
+		c.releaseConcurrencyKey(ctx, pr)
 		if err := c.updateTaskRunsStatusDirectly(pr); err != nil {
 			logger.Errorf("Failed to update TaskRun status for PipelineRun %s: %v", pr.Name, err)
 			return c.finishReconcileUpdateEmitEvents(ctx, pr, before, err)
 		}
+		if err := c.updateChildPipelineRunsStatusDirectly(pr); err != nil {
+			logger.Errorf("Failed to update child PipelineRun status for PipelineRun %s: %v", pr.Name, err)
+			return c.finishReconcileUpdateEmitEvents(ctx, pr, before, err)
+		}
 		go func(metrics *Recorder) {
 			err := metrics.DurationAndCount(pr)
 			if err != nil {
@@ -192,6 +228,17 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, pr *v1beta1.PipelineRun)
 		return c.finishReconcileUpdateEmitEvents(ctx, pr, before, err)
 	}
 
+	if pr.IsPending() {
+		// the Pipeline is not resolved and no TaskRun is created until the PipelineRun is started
+		pr.Status.MarkRunning(v1beta1.PipelineRunReasonPending.String(), "PipelineRun %q is pending", pr.Name)
+		if before == nil {
+			// the Started event is only emitted once the PipelineRun starts, so the Pending
+			// condition is emitted as a change from an empty Unknown condition instead
+			before = &apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionUnknown}
+		}
+		return c.finishReconcileUpdateEmitEvents(ctx, pr, before, nil)
+	}
+
 	if err := c.tracker.Track(pr.GetTaskRunRef(), pr); err != nil {
 		logger.Errorf("Failed to create tracker for TaskRuns for PipelineRun %s: %v", pr.Name, err)
 		return c.finishReconcileUpdateEmitEvents(ctx, pr, before, err)
@@ -232,18 +279,71 @@ func (c *Reconciler) finishReconcileUpdateEmitEvents(ctx context.Context, pr *v1
 	return merr
 }
 
-func (c *Reconciler) updatePipelineResults(ctx context.Context, pr *v1beta1.PipelineRun) {
-	logger := logging.FromContext(ctx)
-
-	// TODO: Use factory func instead of hard-coding this once OCI images are supported.
+// getPipelineFunc returns the function which retrieves the Pipeline referenced by pr, either from the local cluster,
+// from its bundle or from the remote location named by its resolver. For a Pipeline in a bundle, it records the
+// bundle pinned by its digest in the status of pr, and keeps reading the same image in the following reconciles.
+func (c *Reconciler) getPipelineFunc(ctx context.Context, pr *v1beta1.PipelineRun, getOptions taskrunresources.GetRemoteOptions, getBundle taskrunresources.GetBundle) (resources.GetPipeline, error) {
+	if pr.Spec.PipelineRef != nil && pr.Spec.PipelineRef.Bundle != "" {
+		bundle := pr.Spec.PipelineRef.Bundle
+		if pr.Status.Bundle != "" {
+			bundle = pr.Status.Bundle
+		}
+		digest, err := resources.BundleDigest(bundle, pr.Spec.ServiceAccountName, getBundle)
+		if err != nil {
+			return nil, err
+		}
+		resolver, err := getBundle(bundle, pr.Spec.ServiceAccountName)
+		if err != nil {
+			return nil, err
+		}
+		pr.Status.Bundle = digest
+		return (&resources.RemotePipelineRefResolver{Resolver: resolver}).GetPipeline, nil
+	}
 	resolver := &resources.LocalPipelineRefResolver{
 		Namespace:    pr.Namespace,
 		Tektonclient: c.PipelineClientSet,
 	}
-	_, pipelineSpec, err := resources.GetPipelineData(ctx, pr, resolver.GetPipeline)
+	return resources.GetPipelineFunc(ctx, pr.Spec.PipelineRef, resolver.GetPipeline, getOptions)
+}
+
+// getBundleFunc returns the function which creates the resolvers of the bundles of the Pipeline and the Tasks of
+// pr. Each resolver is created once for each bundle and service account, so that a reconcile asks the registry for
+// the digest of a bundle once, however many of the Pipeline and its Tasks it holds.
+func (c *Reconciler) getBundleFunc(pr *v1beta1.PipelineRun, getOptions taskrunresources.GetRemoteOptions) taskrunresources.GetBundle {
+	return resources.OnceEachBundle(func(bundle, serviceAccountName string) (remote.Resolver, error) {
+		opts, err := getOptions()
+		if err != nil {
+			return nil, err
+		}
+		resolver, err := taskrunresources.NewBundleResolver(c.KubeClientSet, pr.Namespace, serviceAccountName, bundle, opts.Verifier)
+		if err != nil {
+			return nil, err
+		}
+		return resolver, nil
+	})
+}
+
+// couldntGetPipelineReason returns the reason of the failure of a PipelineRun whose Pipeline couldn't be retrieved
+// because of err.
+func couldntGetPipelineReason(err error) string {
+	if errors.Is(err, remote.ErrResourceVerificationFailed) {
+		return ReasonResourceVerificationFailed
+	}
+	return ReasonCouldntGetPipeline
+}
+
+func (c *Reconciler) updatePipelineResults(ctx context.Context, pr *v1beta1.PipelineRun) {
+	logger := logging.FromContext(ctx)
+
+	var pipelineSpec *v1beta1.PipelineSpec
+	getRemoteOptions := c.remoteConfigStore.GetRemoteOptionsFunc(pr.Namespace)
+	getPipeline, err := c.getPipelineFunc(ctx, pr, getRemoteOptions, c.getBundleFunc(pr, getRemoteOptions))
+	if err == nil {
+		_, pipelineSpec, err = resources.GetPipelineData(ctx, pr, getPipeline)
+	}
 	if err != nil {
 		logger.Errorf("Failed to determine Pipeline spec to use for pipelinerun %s: %v", pr.Name, err)
-		pr.Status.MarkFailed(ReasonCouldntGetPipeline,
+		pr.Status.MarkFailed(couldntGetPipelineReason(err),
 			"Error retrieving pipeline for pipelinerun %s/%s: %s",
 			pr.Namespace, pr.Name, err)
 		return
@@ -258,15 +358,19 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 	// and may not have had all of the assumed default specified.
 	pr.SetDefaults(contexts.WithUpgradeViaDefaulting(ctx))
 
-	// TODO: Use factory func instead of hard-coding this once OCI images are supported.
-	resolver := &resources.LocalPipelineRefResolver{
-		Namespace:    pr.Namespace,
-		Tektonclient: c.PipelineClientSet,
+	var pipelineMeta *metav1.ObjectMeta
+	var pipelineSpec *v1beta1.PipelineSpec
+	// The RemoteOptions are only loaded if the Pipeline or one of its Tasks is in a bundle or a remote location.
+	getRemoteOptions := c.remoteConfigStore.GetRemoteOptionsFunc(pr.Namespace)
+	// the Pipeline and its Tasks share the resolvers of their bundles during the reconcile
+	getBundle := c.getBundleFunc(pr, getRemoteOptions)
+	getPipeline, err := c.getPipelineFunc(ctx, pr, getRemoteOptions, getBundle)
+	if err == nil {
+		pipelineMeta, pipelineSpec, err = resources.GetPipelineData(ctx, pr, getPipeline)
 	}
-	pipelineMeta, pipelineSpec, err := resources.GetPipelineData(ctx, pr, resolver.GetPipeline)
 	if err != nil {
 		logger.Errorf("Failed to determine Pipeline spec to use for pipelinerun %s: %v", pr.Name, err)
-		pr.Status.MarkFailed(ReasonCouldntGetPipeline,
+		pr.Status.MarkFailed(couldntGetPipelineReason(err),
 			"Error retrieving pipeline for pipelinerun %s/%s: %s",
 			pr.Namespace, pr.Name, err)
 		return controller.NewPermanentError(err)
@@ -324,6 +428,14 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 		return controller.NewPermanentError(err)
 	}
 
+	if err := checkPipelineRefCycle(pr, pipelineSpec); err != nil {
+		// This Run has failed, so we need to mark it as failed and stop reconciling it
+		pr.Status.MarkFailed(ReasonPipelineRefCycle,
+			"PipelineRun %s/%s can't be Run: %s",
+			pr.Namespace, pr.Name, err)
+		return controller.NewPermanentError(err)
+	}
+
 	if err := resources.ValidateResourceBindings(pipelineSpec, pr); err != nil {
 		// This Run has failed, so we need to mark it as failed and stop reconciling it
 		pr.Status.MarkFailed(ReasonInvalidBindings,
@@ -388,6 +500,14 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 	pipelineSpec = resources.ApplyParameters(pipelineSpec, pr)
 	pipelineSpec = resources.ApplyContexts(pipelineSpec, pipelineMeta.Name, pr)
 
+	// Ensure that the Matrix parameters still have values once the parameters they reference are substituted
+	if err := resources.ValidateMatrixNotEmpty(append(append([]v1beta1.PipelineTask{}, pipelineSpec.Tasks...), pipelineSpec.Finally...)); err != nil {
+		pr.Status.MarkFailed(ReasonEmptyMatrixParameter,
+			"PipelineRun %s/%s can't be Run; %s",
+			pr.Namespace, pr.Name, err)
+		return controller.NewPermanentError(err)
+	}
+
 	// pipelineRunState holds a list of pipeline tasks after resolving conditions and pipeline resources
 	// pipelineRunState also holds a taskRun for each pipeline task after the taskRun is created
 	// pipelineRunState is instantiated and updated on every reconcile cycle
@@ -402,9 +522,14 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 		func(name string) (v1beta1.TaskInterface, error) {
 			return c.clusterTaskLister.Get(name)
 		},
+		getBundle,
+		getRemoteOptions,
 		func(name string) (*v1alpha1.Condition, error) {
 			return c.conditionLister.Conditions(pr.Namespace).Get(name)
 		},
+		func(name string) (*v1beta1.PipelineRun, error) {
+			return c.pipelineRunLister.PipelineRuns(pr.Namespace).Get(name)
+		},
 		append(pipelineSpec.Tasks, pipelineSpec.Finally...), providedResources,
 	)
 
@@ -412,7 +537,11 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 		// This Run has failed, so we need to mark it as failed and stop reconciling it
 		switch err := err.(type) {
 		case *resources.TaskNotFoundError:
-			pr.Status.MarkFailed(ReasonCouldntGetTask,
+			reason := ReasonCouldntGetTask
+			if errors.Is(err, remote.ErrResourceVerificationFailed) {
+				reason = ReasonResourceVerificationFailed
+			}
+			pr.Status.MarkFailed(reason,
 				"Pipeline %s/%s can't be Run; it contains Tasks that don't exist: %s",
 				pipelineMeta.Namespace, pipelineMeta.Name, err)
 		case *resources.ConditionNotFoundError:
@@ -426,9 +555,30 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 		}
 		return controller.NewPermanentError(err)
 	}
+	// whether the tasks timed out depends on when they completed rather than on when they are reconciled
+	if pr.Status.TasksCompletionTime == nil {
+		pr.Status.TasksCompletionTime = pipelineRunState.TasksCompletionTime(pr, d)
+		if pr.Status.TasksCompletionTime != nil && !pr.HaveTasksTimedOut() && !pr.IsGracefullyCancelled() && !pr.IsGracefullyStopped() {
+			// the tasks completed in time, they were only resolved as timed out because they are reconciled late
+			for _, rprt := range pipelineRunState {
+				rprt.GracefullyStopped = false
+			}
+		}
+	}
+	// the failed tasks of the dag are not retried once the PipelineRun is gracefully stopped
+	pipelineRunState.StopRetries(d)
 
 	for _, rprt := range pipelineRunState {
-		err := taskrun.ValidateResolvedTaskResources(rprt.PipelineTask.Params, rprt.ResolvedTaskResources)
+		if rprt.IsPipeline() {
+			// the parameters of a child PipelineRun are validated when it is reconciled
+			continue
+		}
+		params := rprt.PipelineTask.Params
+		if combinations := rprt.PipelineTask.MatrixCombinations(); len(combinations) > 0 {
+			// every combination passes the same parameters, so validating one of them is enough
+			params = append(append([]v1beta1.Param{}, params...), combinations[0]...)
+		}
+		err := taskrun.ValidateResolvedTaskResources(params, rprt.ResolvedTaskResources)
 		if err != nil {
 			logger.Errorf("Failed to validate pipelinerun %q with error %v", pr.Name, err)
 			pr.Status.MarkFailed(ReasonFailedValidation, err.Error())
@@ -436,7 +586,15 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 		}
 	}
 
-	if pipelineRunState.IsBeforeFirstTaskRun() {
+	beforeFirstTaskRun := pipelineRunState.IsBeforeFirstTaskRun()
+	if beforeFirstTaskRun && pr.Spec.Concurrency != nil {
+		// no Task is scheduled until the PipelineRun holds its concurrency key
+		queued, err := c.queueForConcurrencyKey(ctx, pr, pipelineSpec, pipelineMeta.Name)
+		if err != nil || queued {
+			return err
+		}
+	}
+	if beforeFirstTaskRun {
 		if pr.HasVolumeClaimTemplate() {
 			// create workspace PVC from template
 			if err = c.pvcHandler.CreatePersistentVolumeClaimsForWorkspaces(pr.Spec.Workspaces, pr.GetOwnerReference(), pr.Namespace); err != nil {
@@ -460,13 +618,38 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 		}
 	}
 
+	if beforeFirstTaskRun && pr.Spec.ResumeFrom != "" {
+		resumed, err := c.pipelineRunLister.PipelineRuns(pr.Namespace).Get(pr.Spec.ResumeFrom)
+		if err != nil {
+			logger.Errorf("Failed to get PipelineRun %s resumed by pipelinerun %s: %v", pr.Spec.ResumeFrom, pr.Name, err)
+			pr.Status.MarkFailed(ReasonCouldntGetResumedPipelineRun,
+				"PipelineRun %s/%s can't resume from PipelineRun %s: %s",
+				pr.Namespace, pr.Name, pr.Spec.ResumeFrom, err)
+			return controller.NewPermanentError(err)
+		}
+		tasks := pipelineRunState.ResumeFrom(pr, resumed, d)
+		logger.Infof("PipelineRun %s resumed tasks %v from PipelineRun %s", pr.Name, tasks, resumed.Name)
+	}
+
 	as, err := artifacts.InitializeArtifactStorage(ctx, c.Images, pr, pipelineSpec, c.KubeClientSet)
 	if err != nil {
 		logger.Infof("PipelineRun failed to initialize artifact storage %s", pr.Name)
 		return controller.NewPermanentError(err)
 	}
 
-	if err := c.runNextSchedulableTask(ctx, pr, d, dfinally, pipelineRunState, as); err != nil {
+	if pr.IsGracefullyCancelled() {
+		// cancel the running tasks of the dag, the final tasks are scheduled once they are done
+		if err := gracefullyCancelPipelineRun(logger, pr, c.PipelineClientSet, pipelineRunState, d); err != nil {
+			return err
+		}
+	} else if pr.IsGracefullyStopped() {
+		// stop the child PipelineRuns of the dag the same way, the running TaskRuns are left to finish
+		if err := gracefullyStopPipelineRun(logger, pr, c.PipelineClientSet, pipelineRunState, d); err != nil {
+			return err
+		}
+	}
+
+	if err := c.runNextSchedulableTask(ctx, pr, d, dfinally, pipelineRunState, as, getMaxParallel(pr, pipelineSpec)); err != nil {
 		return err
 	}
 
@@ -482,7 +665,14 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 	// Read the condition the way it was set by the Mark* helpers
 	after = pr.Status.GetCondition(apis.ConditionSucceeded)
 	pr.Status.TaskRuns = pipelineRunState.GetTaskRunsStatus(pr)
+	pr.Status.PipelineRuns = pipelineRunState.GetPipelineRunsStatus()
 	pr.Status.SkippedTasks = pipelineRunState.GetSkippedTasks(pr, d)
+	if pr.IsDone() {
+		// Resolve the results as soon as the PipelineRun is done, so that they are available to a
+		// parent PipelineRun as soon as it sees this PipelineRun as done
+		resolvedResultRefs := resources.ResolvePipelineResultRefs(pr.Status, pipelineSpec.Results)
+		pr.Status.PipelineResults = getPipelineRunResults(pipelineSpec, resolvedResultRefs)
+	}
 	logger.Infof("PipelineRun %s status is being set to %s", pr.Name, after)
 	return nil
 }
@@ -490,15 +680,24 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 // runNextSchedulableTask gets the next schedulable Tasks from the dag based on the current
 // pipeline run state, and starts them
 // after all DAG tasks are done, it's responsible for scheduling final tasks and start executing them
-func (c *Reconciler) runNextSchedulableTask(ctx context.Context, pr *v1beta1.PipelineRun, d *dag.Graph, dfinally *dag.Graph, pipelineRunState resources.PipelineRunState, as artifacts.ArtifactStorageInterface) error {
+// no more than maxParallel TaskRuns and child PipelineRuns run at once, unless it is 0
+func (c *Reconciler) runNextSchedulableTask(ctx context.Context, pr *v1beta1.PipelineRun, d *dag.Graph, dfinally *dag.Graph, pipelineRunState resources.PipelineRunState, as artifacts.ArtifactStorageInterface, maxParallel int) error {
 
 	logger := logging.FromContext(ctx)
 	recorder := controller.GetEventRecorder(ctx)
 
+	// when pipeline run is paused, do not schedule any new task, including final tasks,
+	// until the pipeline run is resumed; the running tasks are left to complete
+	if pr.IsPaused() {
+		logger.Infof("PipelineRun %s is paused, not scheduling any new tasks", pr.Name)
+		return nil
+	}
+
 	var nextRprts []*resources.ResolvedPipelineRunTask
 
-	// when pipeline run is stopping, do not schedule any new task and only
-	// wait for all running tasks to complete and report their status
+	// when pipeline run is stopping, do not schedule any new task except the ones whose when
+	// expressions read the status of the failed tasks, and only wait for all running tasks to
+	// complete and report their status
 	if !pipelineRunState.IsStopping(d) {
 		// candidateTasks is initialized to DAG root nodes to start pipeline execution
 		// candidateTasks is derived based on successfully finished tasks and/or skipped tasks
@@ -509,26 +708,75 @@ func (c *Reconciler) runNextSchedulableTask(ctx context.Context, pr *v1beta1.Pip
 		}
 		// nextRprts holds a list of pipeline tasks which should be executed next
 		nextRprts = pipelineRunState.GetNextTasks(candidateTasks)
+	} else {
+		nextRprts = pipelineRunState.GetStatusGuardedTasks(d)
 	}
 
 	resolvedResultRefs, err := resources.ResolveResultRefs(pipelineRunState, nextRprts)
 	if err != nil {
 		logger.Infof("Failed to resolve all task params for %q with error %v", pr.Name, err)
-		pr.Status.MarkFailed(ReasonFailedValidation, err.Error())
+		reason := ReasonFailedValidation
+		if errors.Is(err, resources.ErrInvalidArrayResultReference) {
+			reason = ReasonInvalidTaskResultReference
+		}
+		pr.Status.MarkFailed(reason, err.Error())
 		return controller.NewPermanentError(err)
 	}
 
 	resources.ApplyTaskResults(nextRprts, resolvedResultRefs)
 
+	for _, rprt := range nextRprts {
+		if err := resources.ValidateMatrixNotEmpty([]v1beta1.PipelineTask{*rprt.PipelineTask}); err != nil {
+			logger.Infof("Failed to fan out a pipeline task of %q with error %v", pr.Name, err)
+			pr.Status.MarkFailed(ReasonEmptyMatrixParameter, err.Error())
+			return controller.NewPermanentError(err)
+		}
+	}
+
 	// GetFinalTasks only returns tasks when a DAG is complete
-	nextRprts = append(nextRprts, pipelineRunState.GetFinalTasks(d, dfinally)...)
+	finalRprts := pipelineRunState.GetFinalTasks(d, dfinally)
+	if len(finalRprts) > 0 && pr.Status.FinallyStartTime == nil {
+		pr.Status.FinallyStartTime = &metav1.Time{Time: time.Now()}
+	}
+	nextRprts = append(nextRprts, finalRprts...)
 
+	var startRprts []*resources.ResolvedPipelineRunTask
 	for _, rprt := range nextRprts {
-		if rprt == nil || rprt.Skip(pipelineRunState, d) {
-			continue
+		if rprt != nil && !rprt.Skip(pipelineRunState, d) {
+			startRprts = append(startRprts, rprt)
 		}
-		if rprt.ResolvedConditionChecks == nil || rprt.ResolvedConditionChecks.IsSuccess() {
-			rprt.TaskRun, err = c.createTaskRun(ctx, rprt, pr, as.StorageBasePath(pr))
+	}
+	// the tasks which don't fit in the maxParallel limit stay queued until some running ones are done
+	concurrencyQueued := false
+	for _, rprt := range pipelineRunState.LimitParallel(startRprts, maxParallel) {
+		if rprt.IsPipeline() {
+			rprt.PipelineRun, err = c.createPipelineRun(ctx, rprt, pr)
+			if err != nil {
+				recorder.Eventf(pr, corev1.EventTypeWarning, "PipelineRunCreationFailed", "Failed to create PipelineRun %q: %v", rprt.PipelineRunName, err)
+				return fmt.Errorf("error creating PipelineRun called %s for PipelineTask %s from PipelineRun %s: %w", rprt.PipelineRunName, rprt.PipelineTask.Name, pr.Name, err)
+			}
+		} else if rprt.IsMatrixed() {
+			combinations := rprt.PipelineTask.MatrixCombinations()
+			for _, i := range rprt.CombinationsToRun() {
+				params := append(append([]v1beta1.Param{}, rprt.PipelineTask.Params...), combinations[i]...)
+				rprt.TaskRuns[i], err = c.createTaskRun(ctx, rprt.TaskRunNames[i], params, rprt, pr, as.StorageBasePath(pr))
+				if err != nil {
+					recorder.Eventf(pr, corev1.EventTypeWarning, "TaskRunCreationFailed", "Failed to create TaskRun %q: %v", rprt.TaskRunNames[i], err)
+					return fmt.Errorf("error creating TaskRun called %s for PipelineTask %s from PipelineRun %s: %w", rprt.TaskRunNames[i], rprt.PipelineTask.Name, pr.Name, err)
+				}
+			}
+		} else if rprt.ResolvedConditionChecks == nil || rprt.ResolvedConditionChecks.IsSuccess() {
+			if rprt.PipelineTask.Concurrency != nil {
+				waiting, err := c.acquireTaskConcurrencyKey(ctx, pr, rprt)
+				if err != nil {
+					return err
+				}
+				if waiting {
+					concurrencyQueued = true
+					continue
+				}
+			}
+			rprt.TaskRun, err = c.createTaskRun(ctx, rprt.TaskRunName, rprt.PipelineTask.Params, rprt, pr, as.StorageBasePath(pr))
 			if err != nil {
 				recorder.Eventf(pr, corev1.EventTypeWarning, "TaskRunCreationFailed", "Failed to create TaskRun %q: %v", rprt.TaskRunName, err)
 				return fmt.Errorf("error creating TaskRun called %s for PipelineTask %s from PipelineRun %s: %w", rprt.TaskRunName, rprt.PipelineTask.Name, pr.Name, err)
@@ -543,15 +791,39 @@ func (c *Reconciler) runNextSchedulableTask(ctx context.Context, pr *v1beta1.Pip
 			}
 		}
 	}
+
+	// the failed TaskRuns whose retry is delayed by a retry policy are retried on a later reconcile
+	if delay := pipelineRunState.RetryDelay(); delay > 0 {
+		c.enqueueAfter(pr.GetNamespacedName(), delay)
+	}
+	// the tasks waiting for a concurrency key held by another PipelineRun check it again later
+	if concurrencyQueued {
+		c.enqueueAfter(pr.GetNamespacedName(), concurrencyPollInterval)
+	}
 	return nil
 }
 
+// getMaxParallel returns the maximum number of TaskRuns and child PipelineRuns the PipelineRun runs
+// at once, set by the PipelineRun or else by its Pipeline, or 0 if there is no limit
+func getMaxParallel(pr *v1beta1.PipelineRun, pipelineSpec *v1beta1.PipelineSpec) int {
+	if pr.Spec.MaxParallel > 0 {
+		return pr.Spec.MaxParallel
+	}
+	return pipelineSpec.MaxParallel
+}
+
 func getPipelineRunResults(pipelineSpec *v1beta1.PipelineSpec, resolvedResultRefs resources.ResolvedResultRefs) []v1beta1.PipelineRunResult {
 	var results []v1beta1.PipelineRunResult
 	stringReplacements := map[string]string{}
 
 	for _, resolvedResultRef := range resolvedResultRefs {
-		replaceTarget := fmt.Sprintf("%s.%s.%s.%s", v1beta1.ResultTaskPart, resolvedResultRef.ResultReference.PipelineTask, v1beta1.ResultResultPart, resolvedResultRef.ResultReference.Result)
+		replaceTarget := resources.GetReplaceTarget(resolvedResultRef.ResultReference)
+		if resolvedResultRef.Value.Type == v1beta1.ParamTypeArray {
+			// Pipeline results are strings, so array results are emitted in their JSON encoding
+			value, _ := json.Marshal(resolvedResultRef.Value.ArrayVal)
+			stringReplacements[replaceTarget] = string(value)
+			continue
+		}
 		stringReplacements[replaceTarget] = resolvedResultRef.Value.StringVal
 	}
 	for _, result := range pipelineSpec.Results {
@@ -574,7 +846,7 @@ func (c *Reconciler) updateTaskRunsStatusDirectly(pr *v1beta1.PipelineRun) error
 		tr, err := c.taskRunLister.TaskRuns(pr.Namespace).Get(taskRunName)
 		if err != nil {
 			// If the TaskRun isn't found, it just means it won't be run
-			if !errors.IsNotFound(err) {
+			if !k8serrors.IsNotFound(err) {
 				return fmt.Errorf("error retrieving TaskRun %s: %w", taskRunName, err)
 			}
 		} else {
@@ -584,13 +856,30 @@ func (c *Reconciler) updateTaskRunsStatusDirectly(pr *v1beta1.PipelineRun) error
 	return nil
 }
 
-func (c *Reconciler) createTaskRun(ctx context.Context, rprt *resources.ResolvedPipelineRunTask, pr *v1beta1.PipelineRun, storageBasePath string) (*v1beta1.TaskRun, error) {
+func (c *Reconciler) updateChildPipelineRunsStatusDirectly(pr *v1beta1.PipelineRun) error {
+	for pipelineRunName := range pr.Status.PipelineRuns {
+		prprs := pr.Status.PipelineRuns[pipelineRunName]
+		childPr, err := c.pipelineRunLister.PipelineRuns(pr.Namespace).Get(pipelineRunName)
+		if err != nil {
+			// If the PipelineRun isn't found, it just means it won't be run
+			if !k8serrors.IsNotFound(err) {
+				return fmt.Errorf("error retrieving PipelineRun %s: %w", pipelineRunName, err)
+			}
+		} else {
+			prprs.Status = &childPr.Status
+		}
+	}
+	return nil
+}
+
+func (c *Reconciler) createTaskRun(ctx context.Context, taskRunName string, params []v1beta1.Param, rprt *resources.ResolvedPipelineRunTask, pr *v1beta1.PipelineRun, storageBasePath string) (*v1beta1.TaskRun, error) {
 	logger := logging.FromContext(ctx)
 
-	tr, _ := c.taskRunLister.TaskRuns(pr.Namespace).Get(rprt.TaskRunName)
+	tr, _ := c.taskRunLister.TaskRuns(pr.Namespace).Get(taskRunName)
 	if tr != nil {
 		//is a retry
-		addRetryHistory(tr)
+		reason, _ := resources.RetryReason(tr, rprt.PipelineTask.RetryPolicy)
+		addRetryHistory(tr, reason)
 		clearStatus(tr)
 		tr.Status.SetCondition(&apis.Condition{
 			Type:   apis.ConditionSucceeded,
@@ -602,17 +891,18 @@ func (c *Reconciler) createTaskRun(ctx context.Context, rprt *resources.Resolved
 	serviceAccountName, podTemplate := pr.GetTaskRunSpecs(rprt.PipelineTask.Name)
 	tr = &v1beta1.TaskRun{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            rprt.TaskRunName,
+			Name:            taskRunName,
 			Namespace:       pr.Namespace,
 			OwnerReferences: []metav1.OwnerReference{pr.GetOwnerReference()},
 			Labels:          combineTaskRunAndTaskSpecLabels(pr, rprt.PipelineTask),
 			Annotations:     combineTaskRunAndTaskSpecAnnotations(pr, rprt.PipelineTask),
 		},
 		Spec: v1beta1.TaskRunSpec{
-			Params:             rprt.PipelineTask.Params,
+			Params:             params,
 			ServiceAccountName: serviceAccountName,
 			Timeout:            getTaskRunTimeout(ctx, pr, rprt),
 			PodTemplate:        podTemplate,
+			Cache:              rprt.PipelineTask.Cache,
 		}}
 
 	if rprt.ResolvedTaskResources.TaskName != "" {
@@ -620,34 +910,151 @@ func (c *Reconciler) createTaskRun(ctx context.Context, rprt *resources.Resolved
 			Name: rprt.ResolvedTaskResources.TaskName,
 			Kind: rprt.ResolvedTaskResources.Kind,
 		}
+		if rprt.PipelineTask.TaskRef != nil {
+			tr.Spec.TaskRef.Bundle = rprt.Bundle
+			tr.Spec.TaskRef.ResolverRef = rprt.PipelineTask.TaskRef.ResolverRef
+		}
 	} else if rprt.ResolvedTaskResources.TaskSpec != nil {
 		tr.Spec.TaskSpec = rprt.ResolvedTaskResources.TaskSpec
 	}
 
+	workspaces, pipelinePVCWorkspaceName, err := getPipelineTaskWorkspaces(pr, rprt.PipelineTask)
+	if err != nil {
+		return nil, err
+	}
+	tr.Spec.Workspaces = workspaces
+
+	if !c.isAffinityAssistantDisabled(ctx) && pipelinePVCWorkspaceName != "" {
+		tr.Annotations[workspace.AnnotationAffinityAssistantName] = getAffinityAssistantName(pipelinePVCWorkspaceName, pr.Name)
+	}
+	// a TaskRun which may be retried keeps reporting that its pod could not be scheduled when it times out
+	if rprt.PipelineTask.RetryPolicy != nil {
+		tr.Annotations[pipeline.GroupName+pipeline.RetryPolicyAnnotationKey] = "true"
+	}
+
+	resources.WrapSteps(&tr.Spec, rprt.PipelineTask, rprt.ResolvedTaskResources.Inputs, rprt.ResolvedTaskResources.Outputs, storageBasePath)
+	logger.Infof("Creating a new TaskRun object %s", taskRunName)
+	return c.PipelineClientSet.TektonV1beta1().TaskRuns(pr.Namespace).Create(tr)
+}
+
+// createPipelineRun creates the child PipelineRun of a PipelineTask running a Pipeline. The child
+// PipelineRun is bound to the same workspaces as a TaskRun would be, so that what it writes to them
+// is available to the PipelineTasks running after it.
+func (c *Reconciler) createPipelineRun(ctx context.Context, rprt *resources.ResolvedPipelineRunTask, pr *v1beta1.PipelineRun) (*v1beta1.PipelineRun, error) {
+	logger := logging.FromContext(ctx)
+
+	serviceAccountName, podTemplate := pr.GetTaskRunSpecs(rprt.PipelineTask.Name)
+	childPr := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            rprt.PipelineRunName,
+			Namespace:       pr.Namespace,
+			OwnerReferences: []metav1.OwnerReference{pr.GetOwnerReference()},
+			Labels:          getTaskrunLabels(pr, rprt.PipelineTask.Name),
+			Annotations:     getTaskrunAnnotations(pr),
+		},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef:        rprt.PipelineTask.PipelineRef,
+			PipelineSpec:       rprt.PipelineTask.PipelineSpec,
+			Params:             rprt.PipelineTask.Params,
+			ServiceAccountName: serviceAccountName,
+			Timeout:            getTaskRunTimeout(ctx, pr, rprt),
+			PodTemplate:        podTemplate,
+		}}
+
+	workspaces, _, err := getPipelineTaskWorkspaces(pr, rprt.PipelineTask)
+	if err != nil {
+		return nil, err
+	}
+	childPr.Spec.Workspaces = workspaces
+
+	// the child PipelineRun records the Pipelines it is nested in, so that a cycle is detected
+	refs, err := pipelineRefsOf(pr)
+	if err != nil {
+		return nil, err
+	}
+	if len(refs) > 0 {
+		b, err := json.Marshal(refs)
+		if err != nil {
+			return nil, err
+		}
+		childPr.Annotations[pipeline.GroupName+pipeline.ParentPipelineRefsAnnotationKey] = string(b)
+	}
+
+	logger.Infof("Creating a new PipelineRun object %s", rprt.PipelineRunName)
+	return c.PipelineClientSet.TektonV1beta1().PipelineRuns(pr.Namespace).Create(childPr)
+}
+
+// pipelineRefsOf returns the references to the Pipelines run by pr and by the PipelineRuns it is nested in
+func pipelineRefsOf(pr *v1beta1.PipelineRun) ([]v1beta1.PipelineRef, error) {
+	var refs []v1beta1.PipelineRef
+	if value, ok := pr.Annotations[pipeline.GroupName+pipeline.ParentPipelineRefsAnnotationKey]; ok {
+		if err := json.Unmarshal([]byte(value), &refs); err != nil {
+			return nil, fmt.Errorf("invalid annotation %s%s: %w", pipeline.GroupName, pipeline.ParentPipelineRefsAnnotationKey, err)
+		}
+	}
+	if pr.Spec.PipelineRef != nil {
+		refs = append(refs, *pr.Spec.PipelineRef)
+	}
+	return refs, nil
+}
+
+// checkPipelineRefCycle returns an error if a PipelineTask of pipelineSpec references a Pipeline which is
+// run by pr or by one of the PipelineRuns it is nested in, which would nest PipelineRuns without end
+func checkPipelineRefCycle(pr *v1beta1.PipelineRun, pipelineSpec *v1beta1.PipelineSpec) error {
+	refs, err := pipelineRefsOf(pr)
+	if err != nil {
+		return err
+	}
+	visited := make(map[string]struct{}, len(refs))
+	for _, ref := range refs {
+		visited[pipelineRefKey(ref)] = struct{}{}
+	}
+	for _, tasks := range [][]v1beta1.PipelineTask{pipelineSpec.Tasks, pipelineSpec.Finally} {
+		for _, pt := range tasks {
+			if pt.PipelineRef == nil {
+				continue
+			}
+			if _, ok := visited[pipelineRefKey(*pt.PipelineRef)]; ok {
+				return fmt.Errorf("PipelineTask %s references Pipeline %s, which it is already nested in", pt.Name, pt.PipelineRef.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// pipelineRefKey returns the key identifying the Pipeline referenced by ref: its name, along with its bundle
+// or the remote location it is fetched from
+func pipelineRefKey(ref v1beta1.PipelineRef) string {
+	params := make([]string, 0, len(ref.Params))
+	for _, p := range ref.Params {
+		params = append(params, p.Name+"="+p.Value.StringVal)
+	}
+	sort.Strings(params)
+	return strings.Join(append([]string{ref.Name, ref.Bundle, string(ref.Resolver)}, params...), "\n")
+}
+
+// getPipelineTaskWorkspaces returns the bindings of the workspaces of the PipelineTask to the workspaces
+// provided by the PipelineRun, along with the name of the last PipelineRun workspace which is backed by
+// a PersistentVolumeClaim, if any.
+func getPipelineTaskWorkspaces(pr *v1beta1.PipelineRun, pt *v1beta1.PipelineTask) ([]v1beta1.WorkspaceBinding, string, error) {
+	var workspaces []v1beta1.WorkspaceBinding
 	var pipelinePVCWorkspaceName string
 	pipelineRunWorkspaces := make(map[string]v1beta1.WorkspaceBinding)
 	for _, binding := range pr.Spec.Workspaces {
 		pipelineRunWorkspaces[binding.Name] = binding
 	}
-	for _, ws := range rprt.PipelineTask.Workspaces {
+	for _, ws := range pt.Workspaces {
 		taskWorkspaceName, pipelineTaskSubPath, pipelineWorkspaceName := ws.Name, ws.SubPath, ws.Workspace
 		if b, hasBinding := pipelineRunWorkspaces[pipelineWorkspaceName]; hasBinding {
 			if b.PersistentVolumeClaim != nil || b.VolumeClaimTemplate != nil {
 				pipelinePVCWorkspaceName = pipelineWorkspaceName
 			}
-			tr.Spec.Workspaces = append(tr.Spec.Workspaces, taskWorkspaceByWorkspaceVolumeSource(b, taskWorkspaceName, pipelineTaskSubPath, pr.GetOwnerReference()))
+			workspaces = append(workspaces, taskWorkspaceByWorkspaceVolumeSource(b, taskWorkspaceName, pipelineTaskSubPath, pr.GetOwnerReference()))
 		} else {
-			return nil, fmt.Errorf("expected workspace %q to be provided by pipelinerun for pipeline task %q", pipelineWorkspaceName, rprt.PipelineTask.Name)
+			return nil, "", fmt.Errorf("expected workspace %q to be provided by pipelinerun for pipeline task %q", pipelineWorkspaceName, pt.Name)
 		}
 	}
-
-	if !c.isAffinityAssistantDisabled(ctx) && pipelinePVCWorkspaceName != "" {
-		tr.Annotations[workspace.AnnotationAffinityAssistantName] = getAffinityAssistantName(pipelinePVCWorkspaceName, pr.Name)
-	}
-
-	resources.WrapSteps(&tr.Spec, rprt.PipelineTask, rprt.ResolvedTaskResources.Inputs, rprt.ResolvedTaskResources.Outputs, storageBasePath)
-	logger.Infof("Creating a new TaskRun object %s", rprt.TaskRunName)
-	return c.PipelineClientSet.TektonV1beta1().TaskRuns(pr.Namespace).Create(tr)
+	return workspaces, pipelinePVCWorkspaceName, nil
 }
 
 // taskWorkspaceByWorkspaceVolumeSource is returning the WorkspaceBinding with the TaskRun specified name.
@@ -682,9 +1089,10 @@ func combinedSubPath(workspaceSubPath string, pipelineTaskSubPath string) string
 	return filepath.Join(workspaceSubPath, pipelineTaskSubPath)
 }
 
-func addRetryHistory(tr *v1beta1.TaskRun) {
+func addRetryHistory(tr *v1beta1.TaskRun, reason v1beta1.RetryReason) {
 	newStatus := *tr.Status.DeepCopy()
 	newStatus.RetriesStatus = nil
+	newStatus.RetryReason = reason
 	tr.Status.RetriesStatus = append(tr.Status.RetriesStatus, newStatus)
 }
 
@@ -761,23 +1169,17 @@ func combineTaskRunAndTaskSpecAnnotations(pr *v1beta1.PipelineRun, pipelineTask
 }
 
 func getPipelineRunTimeout(ctx context.Context, pr *v1beta1.PipelineRun) metav1.Duration {
-	if pr.Spec.Timeout == nil {
+	if pr.PipelineTimeout() == nil {
 		defaultTimeout := time.Duration(config.FromContextOrDefaults(ctx).Defaults.DefaultTimeoutMinutes)
 		return metav1.Duration{Duration: defaultTimeout * time.Minute}
 	}
-	return *pr.Spec.Timeout
+	return *pr.PipelineTimeout()
 }
 
 func getTaskRunTimeout(ctx context.Context, pr *v1beta1.PipelineRun, rprt *resources.ResolvedPipelineRunTask) *metav1.Duration {
 	var taskRunTimeout = &metav1.Duration{Duration: apisconfig.NoTimeoutDuration}
 
-	var timeout time.Duration
-	if pr.Spec.Timeout == nil {
-		defaultTimeout := time.Duration(config.FromContextOrDefaults(ctx).Defaults.DefaultTimeoutMinutes)
-		timeout = defaultTimeout * time.Minute
-	} else {
-		timeout = pr.Spec.Timeout.Duration
-	}
+	timeout := getPipelineRunTimeout(ctx, pr).Duration
 
 	// If the value of the timeout is 0 for any resource, there is no timeout.
 	// It is impossible for pr.Spec.Timeout to be nil, since SetDefault always assigns it with a value.
@@ -805,6 +1207,23 @@ func getTaskRunTimeout(ctx context.Context, pr *v1beta1.PipelineRun, rprt *resou
 		taskRunTimeout = &metav1.Duration{Duration: rprt.PipelineTask.Timeout.Duration}
 	}
 
+	// the TaskRun must not outlive the tasks or the finally tasks timeout it falls under.
+	// The finally tasks only start once all the tasks are done, so the TaskRuns created
+	// after that are the ones of the finally tasks.
+	sectionTimeout, sectionStartTime := pr.TasksTimeout(), pr.Status.StartTime
+	if pr.Status.FinallyStartTime != nil {
+		sectionTimeout, sectionStartTime = pr.FinallyTimeout(), pr.Status.FinallyStartTime
+	}
+	if sectionTimeout != nil && sectionTimeout.Duration != apisconfig.NoTimeoutDuration {
+		remaining := time.Until(sectionStartTime.Add(sectionTimeout.Duration))
+		if remaining <= 0 {
+			remaining = 1 * time.Second
+		}
+		if taskRunTimeout.Duration == apisconfig.NoTimeoutDuration || remaining < taskRunTimeout.Duration {
+			taskRunTimeout = &metav1.Duration{Duration: remaining}
+		}
+	}
+
 	return taskRunTimeout
 }
 
@@ -888,9 +1307,37 @@ func (c *Reconciler) updatePipelineRunStatusFromInformer(ctx context.Context, pr
 		return err
 	}
 	pr.Status = updatePipelineRunStatusFromTaskRuns(logger, pr.Name, pr.Status, taskRuns)
+
+	childPipelineRuns, err := c.pipelineRunLister.PipelineRuns(pr.Namespace).List(labels.SelectorFromSet(pipelineRunLabels))
+	if err != nil {
+		logger.Errorf("could not list PipelineRuns %#v", err)
+		return err
+	}
+	pr.Status = updatePipelineRunStatusFromChildPipelineRuns(pr.Status, childPipelineRuns)
 	return nil
 }
 
+// updatePipelineRunStatusFromChildPipelineRuns adds the child PipelineRuns missing from the status,
+// so that they are not created again
+func updatePipelineRunStatusFromChildPipelineRuns(prStatus v1beta1.PipelineRunStatus, childPrs []*v1beta1.PipelineRun) v1beta1.PipelineRunStatus {
+	// If no child PipelineRun was found, nothing to be done. We never remove child pipelineruns from the status
+	if len(childPrs) == 0 {
+		return prStatus
+	}
+	if prStatus.PipelineRuns == nil {
+		prStatus.PipelineRuns = make(map[string]*v1beta1.PipelineRunPipelineRunStatus)
+	}
+	for _, childPr := range childPrs {
+		if _, ok := prStatus.PipelineRuns[childPr.Name]; !ok {
+			prStatus.PipelineRuns[childPr.Name] = &v1beta1.PipelineRunPipelineRunStatus{
+				PipelineTaskName: childPr.GetLabels()[pipeline.GroupName+pipeline.PipelineTaskLabelKey],
+				Status:           &childPr.Status,
+			}
+		}
+	}
+	return prStatus
+}
+
 func updatePipelineRunStatusFromTaskRuns(logger *zap.SugaredLogger, prName string, prStatus v1beta1.PipelineRunStatus, trs []*v1beta1.TaskRun) v1beta1.PipelineRunStatus {
 	// If no TaskRun was found, nothing to be done. We never remove taskruns from the status
 	if trs == nil || len(trs) == 0 {
@@ -19,13 +19,18 @@ package pipelinerun
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"regexp"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-containerregistry/pkg/registry"
 	tbv1alpha1 "github.com/tektoncd/pipeline/internal/builder/v1alpha1"
 	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/apis/config"
@@ -482,10 +487,147 @@ func TestReconcile_PipelineSpecTaskSpec(t *testing.T) {
 	if _, exists := reconciledRun.Status.TaskRuns["test-pipeline-run-success-unit-test-task-spec-9l9zj"]; !exists {
 		t.Errorf("Expected PipelineRun status to include TaskRun status but was %v", reconciledRun.Status.TaskRuns)
 	}
+
+	// Nothing is remote, so the configuration of the resolvers isn't read
+	for _, a := range clients.Kube.Actions() {
+		if a.GetVerb() == "get" && (a.GetResource().Resource == "configmaps" || a.GetResource().Resource == "secrets") {
+			t.Errorf("Expected no %s to be read, got %v", a.GetResource().Resource, a)
+		}
+	}
 }
 
 // TestReconcile_InvalidPipelineRuns runs "Reconcile" on several PipelineRuns that are invalid in different ways.
 // It verifies that reconcile fails, how it fails and which events are triggered.
+func TestReconcile_PipelineAndTasksInBundle(t *testing.T) {
+	// TestReconcile_PipelineAndTasksInBundle runs "Reconcile" on a PipelineRun whose Pipeline and Tasks are in a bundle.
+	// It verifies that the bundle is recorded in the status and that TaskRuns are created for the Tasks of the bundle,
+	// pinned by its digest.
+	names.TestingSeed()
+
+	// Set up a fake registry to push the bundle to.
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := fmt.Sprintf("%s/test-bundle/pipeline:latest", u.Host)
+	task := tb.Task("unit-test-task", tb.TaskType(), tb.TaskSpec(tb.Step("myimage", tb.StepName("mystep"))))
+	pipeline := &v1beta1.Pipeline{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "tekton.dev/v1beta1", Kind: "Pipeline"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pipeline"},
+		Spec: v1beta1.PipelineSpec{
+			Tasks: []v1beta1.PipelineTask{{
+				Name:    "unit-test-1",
+				TaskRef: &v1beta1.TaskRef{Name: "unit-test-task", Bundle: bundle},
+			}, {
+				Name:    "unit-test-2",
+				TaskRef: &v1beta1.TaskRef{Name: "unit-test-task", Bundle: bundle},
+			}},
+		},
+	}
+	digest, err := test.CreateImage(bundle, pipeline, task)
+	if err != nil {
+		t.Fatalf("could not push bundle: %v", err)
+	}
+
+	pr := tb.PipelineRun("test-pipeline-run-bundle", tb.PipelineRunNamespace("foo"), tb.PipelineRunSpec("test-pipeline"))
+	pr.Spec.PipelineRef.Bundle = bundle
+	d := test.Data{
+		PipelineRuns: []*v1beta1.PipelineRun{pr},
+		ServiceAccounts: []*corev1.ServiceAccount{{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+		}},
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	wantEvents := []string{
+		"Normal Started",
+		"Normal Running Tasks Completed: 0",
+	}
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-bundle", wantEvents, false)
+
+	if reconciledRun.Status.Bundle != digest {
+		t.Errorf("Expected the bundle %s to be recorded in the status, got %q", digest, reconciledRun.Status.Bundle)
+	}
+	if len(reconciledRun.Status.TaskRuns) != 2 {
+		t.Fatalf("Expected a TaskRun for each Task of the bundle, got %v", reconciledRun.Status.TaskRuns)
+	}
+	taskRuns, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tr := range taskRuns.Items {
+		if tr.Spec.TaskRef == nil || tr.Spec.TaskRef.Bundle != digest {
+			t.Errorf("Expected TaskRun %s to reference the Task in bundle %s, got %v", tr.Name, digest, tr.Spec.TaskRef)
+		}
+	}
+}
+
+func TestReconcile_PipelineAndTasksInBundlePulledOnce(t *testing.T) {
+	// TestReconcile_PipelineAndTasksInBundlePulledOnce runs "Reconcile" on a PipelineRun whose Pipeline and 30 Tasks
+	// are in the same bundle. It verifies that the registry is asked for the digest of the bundle and for its manifest
+	// once for the whole reconcile.
+	names.TestingSeed()
+
+	// Set up a fake registry counting the requests for the manifests of the bundle.
+	var heads, gets int32
+	reg := registry.New()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/manifests/") {
+			switch r.Method {
+			case http.MethodHead:
+				atomic.AddInt32(&heads, 1)
+			case http.MethodGet:
+				atomic.AddInt32(&gets, 1)
+			}
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := fmt.Sprintf("%s/test-bundle/pulled-once:latest", u.Host)
+	task := tb.Task("unit-test-task", tb.TaskType(), tb.TaskSpec(tb.Step("myimage", tb.StepName("mystep"))))
+	pipeline := &v1beta1.Pipeline{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "tekton.dev/v1beta1", Kind: "Pipeline"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pipeline"},
+	}
+	for i := 0; i < 30; i++ {
+		pipeline.Spec.Tasks = append(pipeline.Spec.Tasks, v1beta1.PipelineTask{
+			Name:    fmt.Sprintf("unit-test-%d", i),
+			TaskRef: &v1beta1.TaskRef{Name: "unit-test-task", Bundle: bundle},
+		})
+	}
+	if _, err := test.CreateImage(bundle, pipeline, task); err != nil {
+		t.Fatalf("could not push bundle: %v", err)
+	}
+	atomic.StoreInt32(&heads, 0)
+	atomic.StoreInt32(&gets, 0)
+
+	pr := tb.PipelineRun("test-pipeline-run-bundle", tb.PipelineRunNamespace("foo"), tb.PipelineRunSpec("test-pipeline"))
+	pr.Spec.PipelineRef.Bundle = bundle
+	d := test.Data{
+		PipelineRuns: []*v1beta1.PipelineRun{pr},
+		ServiceAccounts: []*corev1.ServiceAccount{{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+		}},
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	reconciledRun, _ := prt.reconcileRun("foo", "test-pipeline-run-bundle", nil, false)
+	if len(reconciledRun.Status.TaskRuns) != 30 {
+		t.Fatalf("Expected a TaskRun for each Task of the bundle, got %d", len(reconciledRun.Status.TaskRuns))
+	}
+	if h, g := atomic.LoadInt32(&heads), atomic.LoadInt32(&gets); h != 1 || g != 1 {
+		t.Errorf("Expected the digest and the manifest of the bundle to be requested once, got %d HEAD and %d GET requests", h, g)
+	}
+}
+
 func TestReconcile_InvalidPipelineRuns(t *testing.T) {
 	ts := []*v1beta1.Task{
 		tb.Task("a-task-that-exists", tb.TaskNamespace("foo")),
@@ -1106,6 +1248,83 @@ func TestReconcileOnCancelledPipelineRun(t *testing.T) {
 	}
 }
 
+func TestReconcileOnPausedPipelineRun(t *testing.T) {
+	// TestReconcileOnPausedPipelineRun runs "Reconcile" on a PipelineRun that has been paused.
+	// It verifies that reconcile is successful, no new TaskRuns are created and the status is updated.
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-paused",
+		tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa"),
+			tb.PipelineRunPaused,
+		),
+		tb.PipelineRunStatus(tb.PipelineRunStartTime(time.Now())),
+	)}
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+		tb.PipelineTask("hello-world-2", "hello-world", tb.RunAfter("hello-world-1")),
+	))}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-paused", nil, false)
+
+	for _, a := range clients.Pipeline.Actions() {
+		if a.GetVerb() == "create" {
+			t.Errorf("Expected no resources to be created for a paused PipelineRun, but got %v", a)
+		}
+	}
+
+	condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+	if !condition.IsUnknown() || condition.Reason != v1beta1.PipelineRunReasonPaused.String() {
+		t.Errorf("Expected PipelineRun to be paused, but condition is %v", condition)
+	}
+	if reconciledRun.Status.CompletionTime != nil {
+		t.Errorf("Expected no CompletionTime on a paused PipelineRun but was %v", reconciledRun.Status.CompletionTime)
+	}
+}
+
+func TestReconcileOnPendingPipelineRun(t *testing.T) {
+	// TestReconcileOnPendingPipelineRun runs "Reconcile" on a PipelineRun that is pending.
+	// It verifies that reconcile is successful, the Pipeline is not resolved, no TaskRuns are
+	// created and the PipelineRun is not started, so that its timeout doesn't run.
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-pending",
+		tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa"),
+			tb.PipelineRunPending,
+		),
+	)}
+	d := test.Data{
+		PipelineRuns: prs,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	wantEvents := []string{
+		"Normal Pending PipelineRun \"test-pipeline-run-pending\" is pending",
+	}
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-pending", wantEvents, false)
+
+	for _, a := range clients.Pipeline.Actions() {
+		if a.GetVerb() == "create" || a.GetResource().Resource == "pipelines" {
+			t.Errorf("Expected the Pipeline not to be resolved and no resources to be created for a pending PipelineRun, but got %v", a)
+		}
+	}
+
+	condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+	if !condition.IsUnknown() || condition.Reason != v1beta1.PipelineRunReasonPending.String() {
+		t.Errorf("Expected PipelineRun to be pending, but condition is %v", condition)
+	}
+	if reconciledRun.Status.StartTime != nil {
+		t.Errorf("Expected no StartTime on a pending PipelineRun but was %v", reconciledRun.Status.StartTime)
+	}
+}
+
 func TestReconcileWithTimeout(t *testing.T) {
 	// TestReconcileWithTimeout runs "Reconcile" on a PipelineRun that has timed out.
 	// It verifies that reconcile is successful, the pipeline status updated and events generated.
@@ -1318,6 +1537,415 @@ func TestReconcileCancelledPipelineRun(t *testing.T) {
 	}
 }
 
+func TestReconcileGracefullyCancelledPipelineRun(t *testing.T) {
+	// TestReconcileGracefullyCancelledPipelineRun runs "Reconcile" on a PipelineRun that has been gracefully
+	// cancelled while one of its tasks is running. It verifies that the running TaskRun is cancelled, that no
+	// new TaskRun is created for the tasks of the dag, and that the final tasks only run once it is done.
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+		tb.PipelineTask("hello-world-2", "hello-world", tb.RunAfter("hello-world-1")),
+		tb.FinalPipelineTask("final-task-1", "hello-world"),
+	))}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+
+	for _, tc := range []struct {
+		name           string
+		taskRunStatus  apis.Condition
+		wantCancelled  bool
+		wantFinal      bool
+		wantStatus     corev1.ConditionStatus
+		wantReason     string
+		wantSkippedDAG int
+	}{{
+		name: "running task is cancelled",
+		taskRunStatus: apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: corev1.ConditionUnknown,
+			Reason: v1beta1.TaskRunReasonRunning.String(),
+		},
+		wantCancelled:  true,
+		wantStatus:     corev1.ConditionUnknown,
+		wantReason:     v1beta1.PipelineRunReasonCancelledRunFinally.String(),
+		wantSkippedDAG: 1,
+	}, {
+		name: "final task runs once the cancelled task is done",
+		taskRunStatus: apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: corev1.ConditionFalse,
+			Reason: v1beta1.TaskRunReasonCancelled.String(),
+		},
+		wantFinal:      true,
+		wantStatus:     corev1.ConditionUnknown,
+		wantReason:     v1beta1.PipelineRunReasonCancelledRunFinally.String(),
+		wantSkippedDAG: 1,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			names.TestingSeed()
+			prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-cancelled-run-finally",
+				tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa"),
+					func(spec *v1beta1.PipelineRunSpec) {
+						spec.Status = v1beta1.PipelineRunSpecStatusCancelledRunFinally
+					},
+				),
+				tb.PipelineRunStatus(
+					tb.PipelineRunTaskRunsStatus("test-pipeline-run-cancelled-run-finally-hello-world-1", &v1beta1.PipelineRunTaskRunStatus{
+						PipelineTaskName: "hello-world-1",
+						Status:           &v1beta1.TaskRunStatus{},
+					}),
+					tb.PipelineRunStartTime(time.Now()),
+				),
+			)}
+			trs := []*v1beta1.TaskRun{
+				tb.TaskRun("test-pipeline-run-cancelled-run-finally-hello-world-1",
+					tb.TaskRunNamespace("foo"),
+					tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineLabelKey, "test-pipeline"),
+					tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineRunLabelKey, "test-pipeline-run-cancelled-run-finally"),
+					tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, "hello-world-1"),
+					tb.TaskRunSpec(tb.TaskRunTaskRef("hello-world")),
+					tb.TaskRunStatus(tb.StatusCondition(tc.taskRunStatus)),
+				),
+			}
+
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    ps,
+				Tasks:        ts,
+				TaskRuns:     trs,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-cancelled-run-finally", nil, false)
+
+			cancelled := false
+			var created []string
+			for _, a := range clients.Pipeline.Actions() {
+				switch action := a.(type) {
+				case ktesting.PatchAction:
+					if action.GetResource().Resource == "taskruns" && action.GetName() == "test-pipeline-run-cancelled-run-finally-hello-world-1" {
+						cancelled = true
+					}
+				case ktesting.CreateAction:
+					if tr, ok := action.GetObject().(*v1beta1.TaskRun); ok {
+						created = append(created, tr.Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey])
+					}
+				}
+			}
+			if cancelled != tc.wantCancelled {
+				t.Errorf("Expected the running TaskRun to be cancelled: %t, but was %t", tc.wantCancelled, cancelled)
+			}
+			wantCreated := []string(nil)
+			if tc.wantFinal {
+				wantCreated = []string{"final-task-1"}
+			}
+			if d := cmp.Diff(wantCreated, created); d != "" {
+				t.Errorf("Unexpected TaskRuns created for PipelineTasks %s", diff.PrintWantGot(d))
+			}
+
+			condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+			if condition.Status != tc.wantStatus || condition.Reason != tc.wantReason {
+				t.Errorf("Expected PipelineRun condition to be %s with reason %s, but was %v", tc.wantStatus, tc.wantReason, condition)
+			}
+			if len(reconciledRun.Status.SkippedTasks) != tc.wantSkippedDAG {
+				t.Errorf("Expected %d skipped tasks, but got %v", tc.wantSkippedDAG, reconciledRun.Status.SkippedTasks)
+			}
+		})
+	}
+}
+
+func TestReconcileGracefullyStoppedPipelineRun(t *testing.T) {
+	// TestReconcileGracefullyStoppedPipelineRun runs "Reconcile" on a PipelineRun that has been gracefully
+	// stopped. It verifies that the running TaskRun is not cancelled, that no new TaskRun is created for
+	// the tasks of the dag, not even to retry a failed one, that the final tasks run once it is done, and
+	// that the PipelineRun reports that it was stopped once the final tasks are done.
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world", tb.Retries(1)),
+		tb.PipelineTask("hello-world-2", "hello-world", tb.RunAfter("hello-world-1")),
+		tb.FinalPipelineTask("final-task-1", "hello-world"),
+	))}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	succeeded := apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionTrue,
+		Reason: v1beta1.TaskRunReasonSuccessful.String(),
+	}
+	makeTaskRun := func(pipelineTask string, condition apis.Condition) *v1beta1.TaskRun {
+		return tb.TaskRun("test-pipeline-run-stopped-run-finally-"+pipelineTask,
+			tb.TaskRunNamespace("foo"),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineLabelKey, "test-pipeline"),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineRunLabelKey, "test-pipeline-run-stopped-run-finally"),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, pipelineTask),
+			tb.TaskRunSpec(tb.TaskRunTaskRef("hello-world")),
+			tb.TaskRunStatus(tb.StatusCondition(condition)),
+		)
+	}
+
+	for _, tc := range []struct {
+		name        string
+		trs         []*v1beta1.TaskRun
+		wantCreated []string
+		wantStatus  corev1.ConditionStatus
+	}{{
+		name: "running task is left to finish",
+		trs: []*v1beta1.TaskRun{makeTaskRun("hello-world-1", apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: corev1.ConditionUnknown,
+			Reason: v1beta1.TaskRunReasonRunning.String(),
+		})},
+		wantStatus: corev1.ConditionUnknown,
+	}, {
+		name:        "final task runs once the running task is done",
+		trs:         []*v1beta1.TaskRun{makeTaskRun("hello-world-1", succeeded)},
+		wantCreated: []string{"final-task-1"},
+		wantStatus:  corev1.ConditionUnknown,
+	}, {
+		name: "failed task is not retried and final task runs",
+		trs: []*v1beta1.TaskRun{makeTaskRun("hello-world-1", apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: corev1.ConditionFalse,
+			Reason: v1beta1.TaskRunReasonFailed.String(),
+		})},
+		wantCreated: []string{"final-task-1"},
+		wantStatus:  corev1.ConditionUnknown,
+	}, {
+		name:       "pipelinerun is stopped once the final task is done",
+		trs:        []*v1beta1.TaskRun{makeTaskRun("hello-world-1", succeeded), makeTaskRun("final-task-1", succeeded)},
+		wantStatus: corev1.ConditionFalse,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			names.TestingSeed()
+			statusOps := []tb.PipelineRunStatusOp{tb.PipelineRunStartTime(time.Now())}
+			for _, tr := range tc.trs {
+				statusOps = append(statusOps, tb.PipelineRunTaskRunsStatus(tr.Name, &v1beta1.PipelineRunTaskRunStatus{
+					PipelineTaskName: tr.Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey],
+					Status:           &v1beta1.TaskRunStatus{},
+				}))
+			}
+			prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-stopped-run-finally",
+				tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa"),
+					func(spec *v1beta1.PipelineRunSpec) {
+						spec.Status = v1beta1.PipelineRunSpecStatusStoppedRunFinally
+					},
+				),
+				tb.PipelineRunStatus(statusOps...),
+			)}
+
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    ps,
+				Tasks:        ts,
+				TaskRuns:     tc.trs,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-stopped-run-finally", nil, false)
+
+			var created []string
+			for _, a := range clients.Pipeline.Actions() {
+				switch action := a.(type) {
+				case ktesting.PatchAction:
+					if action.GetResource().Resource == "taskruns" {
+						t.Errorf("Expected no TaskRun to be cancelled, but %s was patched", action.GetName())
+					}
+				case ktesting.CreateAction:
+					if tr, ok := action.GetObject().(*v1beta1.TaskRun); ok {
+						created = append(created, tr.Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey])
+					}
+				}
+			}
+			if d := cmp.Diff(tc.wantCreated, created); d != "" {
+				t.Errorf("Unexpected TaskRuns created for PipelineTasks %s", diff.PrintWantGot(d))
+			}
+
+			condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+			if condition.Status != tc.wantStatus || condition.Reason != v1beta1.PipelineRunReasonStoppedRunFinally.String() {
+				t.Errorf("Expected PipelineRun condition to be %s with reason %s, but was %v", tc.wantStatus, v1beta1.PipelineRunReasonStoppedRunFinally, condition)
+			}
+			wantSkipped := []v1beta1.SkippedTask{{Name: "hello-world-2", Reason: v1beta1.StoppingSkip}}
+			if d := cmp.Diff(wantSkipped, reconciledRun.Status.SkippedTasks); d != "" {
+				t.Errorf("Unexpected skipped tasks %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestReconcileWithTasksTimeout(t *testing.T) {
+	// TestReconcileWithTasksTimeout runs "Reconcile" on a PipelineRun whose tasks timed out. It verifies
+	// that the tasks which did not start are skipped, that the final tasks run within their own timeout,
+	// and that the PipelineRun reports that it timed out once the final tasks are done.
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+		tb.PipelineTask("hello-world-2", "hello-world", tb.RunAfter("hello-world-1")),
+		tb.FinalPipelineTask("final-task-1", "hello-world"),
+	))}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	succeeded := apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionTrue,
+		Reason: v1beta1.TaskRunReasonSuccessful.String(),
+	}
+	makeTaskRun := func(pipelineTask string) *v1beta1.TaskRun {
+		return tb.TaskRun("test-pipeline-run-tasks-timeout-"+pipelineTask,
+			tb.TaskRunNamespace("foo"),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineLabelKey, "test-pipeline"),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineRunLabelKey, "test-pipeline-run-tasks-timeout"),
+			tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, pipelineTask),
+			tb.TaskRunSpec(tb.TaskRunTaskRef("hello-world")),
+			tb.TaskRunStatus(tb.StatusCondition(succeeded)),
+		)
+	}
+
+	for _, tc := range []struct {
+		name           string
+		trs            []*v1beta1.TaskRun
+		finallyStarted bool
+		wantCreated    []string
+		wantStatus     corev1.ConditionStatus
+		wantReason     string
+	}{{
+		name:        "final task runs once the tasks timed out",
+		trs:         []*v1beta1.TaskRun{makeTaskRun("hello-world-1")},
+		wantCreated: []string{"final-task-1"},
+		wantStatus:  corev1.ConditionUnknown,
+		wantReason:  v1beta1.PipelineRunReasonRunning.String(),
+	}, {
+		name:           "pipelinerun times out once the final task is done",
+		trs:            []*v1beta1.TaskRun{makeTaskRun("hello-world-1"), makeTaskRun("final-task-1")},
+		finallyStarted: true,
+		wantStatus:     corev1.ConditionFalse,
+		wantReason:     v1beta1.PipelineRunReasonTimedOut.String(),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			names.TestingSeed()
+			statusOps := []tb.PipelineRunStatusOp{tb.PipelineRunStartTime(time.Now().Add(-2 * time.Hour))}
+			if tc.finallyStarted {
+				statusOps = append(statusOps, tb.PipelineRunFinallyStartTime(time.Now().Add(-5*time.Minute)))
+			}
+			for _, tr := range tc.trs {
+				statusOps = append(statusOps, tb.PipelineRunTaskRunsStatus(tr.Name, &v1beta1.PipelineRunTaskRunStatus{
+					PipelineTaskName: tr.Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey],
+					Status:           &v1beta1.TaskRunStatus{},
+				}))
+			}
+			prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-tasks-timeout",
+				tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa"),
+					tb.PipelineRunTimeouts(v1beta1.TimeoutFields{
+						Pipeline: &metav1.Duration{Duration: 3 * time.Hour},
+						Tasks:    &metav1.Duration{Duration: 1 * time.Hour},
+						Finally:  &metav1.Duration{Duration: 30 * time.Minute},
+					}),
+				),
+				tb.PipelineRunStatus(statusOps...),
+			)}
+
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    ps,
+				Tasks:        ts,
+				TaskRuns:     tc.trs,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-tasks-timeout", nil, false)
+
+			var created []string
+			for _, a := range clients.Pipeline.Actions() {
+				if action, ok := a.(ktesting.CreateAction); ok {
+					if tr, ok := action.GetObject().(*v1beta1.TaskRun); ok {
+						created = append(created, tr.Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey])
+						if tr.Spec.Timeout.Duration > 30*time.Minute || tr.Spec.Timeout.Duration < 29*time.Minute {
+							t.Errorf("Expected TaskRun %s to time out with the finally tasks, but its timeout was %s", tr.Name, tr.Spec.Timeout.Duration)
+						}
+					}
+				}
+			}
+			if d := cmp.Diff(tc.wantCreated, created); d != "" {
+				t.Errorf("Unexpected TaskRuns created for PipelineTasks %s", diff.PrintWantGot(d))
+			}
+			if reconciledRun.Status.FinallyStartTime == nil {
+				t.Errorf("Expected the start time of the finally tasks to be set")
+			}
+
+			condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+			if condition.Status != tc.wantStatus || condition.Reason != tc.wantReason {
+				t.Errorf("Expected PipelineRun condition to be %s with reason %s, but was %v", tc.wantStatus, tc.wantReason, condition)
+			}
+			wantSkipped := []v1beta1.SkippedTask{{Name: "hello-world-2", Reason: v1beta1.StoppingSkip}}
+			if d := cmp.Diff(wantSkipped, reconciledRun.Status.SkippedTasks); d != "" {
+				t.Errorf("Unexpected skipped tasks %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestReconcileWithTasksCompletedBeforeTasksTimeout(t *testing.T) {
+	// TestReconcileWithTasksCompletedBeforeTasksTimeout runs "Reconcile" on a PipelineRun whose tasks
+	// completed before their timeout, but which is only reconciled after it. It verifies that the
+	// completion time of the tasks is recorded and that the PipelineRun succeeds instead of timing out.
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+	))}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	startTime := time.Now().Add(-2 * time.Hour)
+	completionTime := startTime.Add(30 * time.Minute)
+	trs := []*v1beta1.TaskRun{tb.TaskRun("test-pipeline-run-tasks-completed-hello-world-1",
+		tb.TaskRunNamespace("foo"),
+		tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineLabelKey, "test-pipeline"),
+		tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineRunLabelKey, "test-pipeline-run-tasks-completed"),
+		tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, "hello-world-1"),
+		tb.TaskRunSpec(tb.TaskRunTaskRef("hello-world")),
+		tb.TaskRunStatus(
+			tb.StatusCondition(apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionTrue,
+				Reason: v1beta1.TaskRunReasonSuccessful.String(),
+			}),
+			tb.TaskRunStartTime(startTime),
+			tb.TaskRunCompletionTime(completionTime),
+		),
+	)}
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-tasks-completed",
+		tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa"),
+			tb.PipelineRunTimeouts(v1beta1.TimeoutFields{
+				Pipeline: &metav1.Duration{Duration: 3 * time.Hour},
+				Tasks:    &metav1.Duration{Duration: 1 * time.Hour},
+			}),
+		),
+		tb.PipelineRunStatus(
+			tb.PipelineRunStartTime(startTime),
+			tb.PipelineRunTaskRunsStatus(trs[0].Name, &v1beta1.PipelineRunTaskRunStatus{
+				PipelineTaskName: "hello-world-1",
+				Status:           &v1beta1.TaskRunStatus{},
+			}),
+		),
+	)}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+		TaskRuns:     trs,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	reconciledRun, _ := prt.reconcileRun("foo", "test-pipeline-run-tasks-completed", nil, false)
+
+	if tasksCompletionTime := reconciledRun.Status.TasksCompletionTime; tasksCompletionTime == nil || !tasksCompletionTime.Time.Equal(completionTime) {
+		t.Errorf("Expected the completion time of the tasks to be %s, but was %v", completionTime, tasksCompletionTime)
+	}
+	condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+	if !condition.IsTrue() || condition.Reason != v1beta1.PipelineRunReasonSuccessful.String() {
+		t.Errorf("Expected PipelineRun to succeed, but its condition was %v", condition)
+	}
+}
+
 func TestReconcilePropagateLabels(t *testing.T) {
 	names.TestingSeed()
 	taskName := "hello-world-1"
@@ -1325,31 +1953,388 @@ func TestReconcilePropagateLabels(t *testing.T) {
 	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
 		tb.PipelineTask(taskName, "hello-world"),
 	))}
-	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-with-labels", tb.PipelineRunNamespace("foo"),
-		tb.PipelineRunLabel("PipelineRunLabel", "PipelineRunValue"),
-		tb.PipelineRunLabel("tekton.dev/pipeline", "WillNotBeUsed"),
-		tb.PipelineRunSpec("test-pipeline",
-			tb.PipelineRunServiceAccountName("test-sa"),
-		),
-	)}
-	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-with-labels", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunLabel("PipelineRunLabel", "PipelineRunValue"),
+		tb.PipelineRunLabel("tekton.dev/pipeline", "WillNotBeUsed"),
+		tb.PipelineRunSpec("test-pipeline",
+			tb.PipelineRunServiceAccountName("test-sa"),
+		),
+	)}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+
+	expected := tb.TaskRun("test-pipeline-run-with-labels-hello-world-1-9l9zj",
+		tb.TaskRunNamespace("foo"),
+		tb.TaskRunOwnerReference("PipelineRun", "test-pipeline-run-with-labels",
+			tb.OwnerReferenceAPIVersion("tekton.dev/v1beta1"),
+			tb.Controller, tb.BlockOwnerDeletion,
+		),
+		tb.TaskRunLabel("tekton.dev/pipeline", "test-pipeline"),
+		tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, "hello-world-1"),
+		tb.TaskRunLabel("tekton.dev/pipelineRun", "test-pipeline-run-with-labels"),
+		tb.TaskRunLabel("PipelineRunLabel", "PipelineRunValue"),
+		tb.TaskRunSpec(
+			tb.TaskRunTaskRef("hello-world"),
+			tb.TaskRunServiceAccountName("test-sa"),
+		),
+	)
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	_, clients := prt.reconcileRun("foo", "test-pipeline-run-with-labels", []string{}, false)
+	actions := clients.Pipeline.Actions()
+	if len(actions) < 2 {
+		t.Fatalf("Expected client to have at least two action implementation but it has %d", len(actions))
+	}
+
+	// Check that the expected TaskRun was created
+	actual := actions[1].(ktesting.CreateAction).GetObject().(*v1beta1.TaskRun)
+	if actual == nil {
+		t.Errorf("Expected a TaskRun to be created, but it wasn't.")
+	}
+
+	if d := cmp.Diff(actual, expected); d != "" {
+		t.Errorf("expected to see TaskRun %v created. Diff %s", expected, diff.PrintWantGot(d))
+	}
+}
+
+func TestReconcileWithDifferentServiceAccounts(t *testing.T) {
+	names.TestingSeed()
+
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-0", "hello-world-task"),
+		tb.PipelineTask("hello-world-1", "hello-world-task"),
+	))}
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-different-service-accs", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline",
+			tb.PipelineRunServiceAccountName("test-sa-0"),
+			tb.PipelineRunServiceAccountNameTask("hello-world-1", "test-sa-1"),
+		),
+	)}
+	ts := []*v1beta1.Task{
+		tb.Task("hello-world-task", tb.TaskNamespace("foo")),
+	}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	_, clients := prt.reconcileRun("foo", "test-pipeline-run-different-service-accs", []string{}, false)
+
+	taskRunNames := []string{"test-pipeline-run-different-service-accs-hello-world-0-9l9zj", "test-pipeline-run-different-service-accs-hello-world-1-mz4c7"}
+
+	expectedTaskRuns := []*v1beta1.TaskRun{
+		tb.TaskRun(taskRunNames[0],
+			tb.TaskRunNamespace("foo"),
+			tb.TaskRunOwnerReference("PipelineRun", "test-pipeline-run-different-service-accs",
+				tb.OwnerReferenceAPIVersion("tekton.dev/v1beta1"),
+				tb.Controller, tb.BlockOwnerDeletion,
+			),
+			tb.TaskRunSpec(
+				tb.TaskRunTaskRef("hello-world-task"),
+				tb.TaskRunServiceAccountName("test-sa-0"),
+			),
+			tb.TaskRunLabel("tekton.dev/pipeline", "test-pipeline"),
+			tb.TaskRunLabel("tekton.dev/pipelineRun", "test-pipeline-run-different-service-accs"),
+			tb.TaskRunLabel("tekton.dev/pipelineTask", "hello-world-0"),
+		),
+		tb.TaskRun(taskRunNames[1],
+			tb.TaskRunNamespace("foo"),
+			tb.TaskRunOwnerReference("PipelineRun", "test-pipeline-run-different-service-accs",
+				tb.OwnerReferenceAPIVersion("tekton.dev/v1beta1"),
+				tb.Controller, tb.BlockOwnerDeletion,
+			),
+			tb.TaskRunSpec(
+				tb.TaskRunTaskRef("hello-world-task"),
+				tb.TaskRunServiceAccountName("test-sa-1"),
+			),
+			tb.TaskRunLabel("tekton.dev/pipeline", "test-pipeline"),
+			tb.TaskRunLabel("tekton.dev/pipelineRun", "test-pipeline-run-different-service-accs"),
+			tb.TaskRunLabel("tekton.dev/pipelineTask", "hello-world-1"),
+		),
+	}
+	for i := range ps[0].Spec.Tasks {
+		// Check that the expected TaskRun was created
+		actual, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").Get(taskRunNames[i], metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected a TaskRun to be created, but it wasn't: %s", err)
+		}
+		if d := cmp.Diff(actual, expectedTaskRuns[i], ignoreResourceVersion); d != "" {
+			t.Errorf("expected to see TaskRun %v created. Diff %s", expectedTaskRuns[i], diff.PrintWantGot(d))
+		}
+
+	}
+}
+
+// TestReconcileWithRetryPolicy runs "Reconcile" against a pipeline whose task failed and has a retry
+// policy. It verifies that the task is only retried for the failures the policy filters on and once its
+// delay has elapsed, and that the reason for the retry is recorded.
+func TestReconcileWithRetryPolicy(t *testing.T) {
+	oomKilled := tb.StepState(tb.SetStepStateTerminated(corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}))
+	exitCode := tb.StepState(tb.SetStepStateTerminated(corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"}))
+	for _, tc := range []struct {
+		name            string
+		policy          v1beta1.RetryPolicy
+		stepState       tb.TaskRunStatusOp
+		wantRetryReason v1beta1.RetryReason
+		wantStatus      corev1.ConditionStatus
+	}{{
+		name:            "retried for the failure of the policy",
+		policy:          v1beta1.RetryPolicy{On: []v1beta1.RetryReason{v1beta1.RetryReasonOOMKilled}},
+		stepState:       oomKilled,
+		wantRetryReason: v1beta1.RetryReasonOOMKilled,
+		wantStatus:      corev1.ConditionUnknown,
+	}, {
+		name:            "retried for an exit code of the policy",
+		policy:          v1beta1.RetryPolicy{ExitCodes: []int32{1}},
+		stepState:       exitCode,
+		wantRetryReason: v1beta1.RetryReasonExitCode,
+		wantStatus:      corev1.ConditionUnknown,
+	}, {
+		name:       "not retried for another failure",
+		policy:     v1beta1.RetryPolicy{On: []v1beta1.RetryReason{v1beta1.RetryReasonOOMKilled}, ExitCodes: []int32{42}},
+		stepState:  exitCode,
+		wantStatus: corev1.ConditionFalse,
+	}, {
+		name:       "not retried before the delay",
+		policy:     v1beta1.RetryPolicy{Delay: &metav1.Duration{Duration: time.Hour}},
+		stepState:  exitCode,
+		wantStatus: corev1.ConditionUnknown,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline-retry", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+				tb.PipelineTask("hello-world-1", "hello-world", tb.Retries(1), tb.PipelineTaskRetryPolicy(tc.policy)),
+			))}
+			trs := []*v1beta1.TaskRun{
+				tb.TaskRun("test-pipeline-run-retry-policy-hello-world-1",
+					tb.TaskRunNamespace("foo"),
+					tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, "hello-world-1"),
+					tb.TaskRunSpec(tb.TaskRunTaskRef("hello-world")),
+					tb.TaskRunStatus(
+						tb.PodName("my-pod-name"),
+						tb.StatusCondition(apis.Condition{
+							Type:   apis.ConditionSucceeded,
+							Status: corev1.ConditionFalse,
+							Reason: v1beta1.TaskRunReasonFailed.String(),
+						}),
+						tb.TaskRunCompletionTime(time.Now()),
+						tc.stepState,
+					)),
+			}
+			prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-retry-policy", tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline-retry", tb.PipelineRunServiceAccountName("test-sa")),
+				tb.PipelineRunStatus(
+					tb.PipelineRunStartTime(time.Now()),
+					tb.PipelineRunTaskRunsStatus(trs[0].Name, &v1beta1.PipelineRunTaskRunStatus{
+						PipelineTaskName: "hello-world-1",
+						Status:           &trs[0].Status,
+					}),
+				),
+			)}
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    ps,
+				Tasks:        []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))},
+				TaskRuns:     trs,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, _ := prt.reconcileRun("foo", "test-pipeline-run-retry-policy", nil, false)
+
+			retriesStatus := reconciledRun.Status.TaskRuns[trs[0].Name].Status.RetriesStatus
+			if tc.wantRetryReason == "" {
+				if len(retriesStatus) != 0 {
+					t.Errorf("Expected no retry, but got %v", retriesStatus)
+				}
+			} else if len(retriesStatus) != 1 || retriesStatus[0].RetryReason != tc.wantRetryReason {
+				t.Errorf("Expected a retry for %s, but got %v", tc.wantRetryReason, retriesStatus)
+			}
+			if status := reconciledRun.Status.GetCondition(apis.ConditionSucceeded).Status; status != tc.wantStatus {
+				t.Errorf("Expected PipelineRun condition to be %s but was %s", tc.wantStatus, status)
+			}
+		})
+	}
+}
+
+// TestReconcileWithRetryPolicyAnnotation runs "Reconcile" on a PipelineRun with PipelineTasks with and
+// without a retry policy, and verifies that only the TaskRuns of the former are annotated with it
+func TestReconcileWithRetryPolicyAnnotation(t *testing.T) {
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world", tb.Retries(1),
+			tb.PipelineTaskRetryPolicy(v1beta1.RetryPolicy{On: []v1beta1.RetryReason{v1beta1.RetryReasonExceededNodeResources}})),
+		tb.PipelineTask("hello-world-2", "hello-world", tb.Retries(1)),
+	))}
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-retry-annotation", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa")),
+	)}
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))},
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	_, clients := prt.reconcileRun("foo", "test-pipeline-run-retry-annotation", nil, false)
+
+	annotated := map[string]bool{}
+	for _, a := range clients.Pipeline.Actions() {
+		if action, ok := a.(ktesting.CreateAction); ok {
+			if tr, ok := action.GetObject().(*v1beta1.TaskRun); ok {
+				annotated[tr.Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey]] = tr.Annotations[pipeline.GroupName+pipeline.RetryPolicyAnnotationKey] == "true"
+			}
+		}
+	}
+	if d := cmp.Diff(map[string]bool{"hello-world-1": true, "hello-world-2": false}, annotated); d != "" {
+		t.Errorf("Unexpected TaskRuns annotated with the retry policy %s", diff.PrintWantGot(d))
+	}
+}
+
+// TestReconcileWithCache runs "Reconcile" on a PipelineRun with a cached PipelineTask, and verifies
+// that its TaskRun is created with the cache
+func TestReconcileWithCache(t *testing.T) {
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world", tb.PipelineTaskCache(v1beta1.TaskCache{ConfigMap: "task-cache"})),
+	))}
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-cache", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa")),
+	)}
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))},
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	_, clients := prt.reconcileRun("foo", "test-pipeline-run-cache", nil, false)
+
+	var cache *v1beta1.TaskCache
+	for _, a := range clients.Pipeline.Actions() {
+		if action, ok := a.(ktesting.CreateAction); ok {
+			if tr, ok := action.GetObject().(*v1beta1.TaskRun); ok {
+				cache = tr.Spec.Cache
+			}
+		}
+	}
+	if d := cmp.Diff(&v1beta1.TaskCache{ConfigMap: "task-cache"}, cache); d != "" {
+		t.Errorf("Expected the TaskRun to be created with the cache of the PipelineTask %s", diff.PrintWantGot(d))
+	}
+}
+
+// TestReconcileWithMaxParallel runs "Reconcile" on PipelineRuns of a Pipeline with more independent
+// tasks than its maxParallel limit, and verifies that the tasks beyond the limit are queued
+func TestReconcileWithMaxParallel(t *testing.T) {
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("shard-1", "hello-world"),
+		tb.PipelineTask("shard-2", "hello-world"),
+		tb.PipelineTask("shard-3", "hello-world"),
+		tb.PipelineMaxParallel(2),
+	))}
+	for _, tc := range []struct {
+		name         string
+		prOps        []tb.PipelineRunSpecOp
+		wantTaskRuns int
+		wantMessage  string
+	}{{
+		name:         "limit of the pipeline",
+		wantTaskRuns: 2,
+		wantMessage:  "Tasks Completed: 0 (Failed: 0, Cancelled 0), Incomplete: 3, Skipped: 0, 1 tasks queued by maxParallel",
+	}, {
+		name:         "limit overridden by the pipelinerun",
+		prOps:        []tb.PipelineRunSpecOp{tb.PipelineRunMaxParallel(1)},
+		wantTaskRuns: 1,
+		wantMessage:  "Tasks Completed: 0 (Failed: 0, Cancelled 0), Incomplete: 3, Skipped: 0, 2 tasks queued by maxParallel",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-max-parallel", tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline", append(tc.prOps, tb.PipelineRunServiceAccountName("test-sa"))...),
+			)}
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    ps,
+				Tasks:        []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))},
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-max-parallel", nil, false)
+
+			taskRuns := 0
+			for _, a := range clients.Pipeline.Actions() {
+				if action, ok := a.(ktesting.CreateAction); ok {
+					if _, ok := action.GetObject().(*v1beta1.TaskRun); ok {
+						taskRuns++
+					}
+				}
+			}
+			if taskRuns != tc.wantTaskRuns {
+				t.Errorf("Expected %d TaskRuns to be created but got %d", tc.wantTaskRuns, taskRuns)
+			}
+			if d := cmp.Diff(tc.wantMessage, reconciledRun.Status.GetCondition(apis.ConditionSucceeded).Message); d != "" {
+				t.Errorf("Unexpected condition message %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+// TestReconcileWithResumeFrom runs "Reconcile" on a PipelineRun resuming a failed PipelineRun, and
+// verifies that only the PipelineTask which failed in it is run again
+func TestReconcileWithResumeFrom(t *testing.T) {
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+		tb.PipelineTask("hello-world-2", "hello-world", tb.RunAfter("hello-world-1")),
+	))}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"), tb.TaskSpec(tb.Step("busybox")))}
+	taskSpec := ts[0].Spec.DeepCopy()
+	taskSpec.SetDefaults(context.Background())
 
-	expected := tb.TaskRun("test-pipeline-run-with-labels-hello-world-1-9l9zj",
-		tb.TaskRunNamespace("foo"),
-		tb.TaskRunOwnerReference("PipelineRun", "test-pipeline-run-with-labels",
-			tb.OwnerReferenceAPIVersion("tekton.dev/v1beta1"),
-			tb.Controller, tb.BlockOwnerDeletion,
-		),
-		tb.TaskRunLabel("tekton.dev/pipeline", "test-pipeline"),
-		tb.TaskRunLabel(pipeline.GroupName+pipeline.PipelineTaskLabelKey, "hello-world-1"),
-		tb.TaskRunLabel("tekton.dev/pipelineRun", "test-pipeline-run-with-labels"),
-		tb.TaskRunLabel("PipelineRunLabel", "PipelineRunValue"),
-		tb.TaskRunSpec(
-			tb.TaskRunTaskRef("hello-world"),
-			tb.TaskRunServiceAccountName("test-sa"),
+	failedRun := tb.PipelineRun("test-pipeline-run-failed", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa")),
+		tb.PipelineRunStatus(
+			tb.PipelineRunStatusCondition(apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionFalse,
+				Reason: v1beta1.PipelineRunReasonFailed.String(),
+			}),
+			tb.PipelineRunTaskRunsStatus("test-pipeline-run-failed-hello-world-1", &v1beta1.PipelineRunTaskRunStatus{
+				PipelineTaskName: "hello-world-1",
+				Status: &v1beta1.TaskRunStatus{
+					Status: duckv1beta1.Status{Conditions: []apis.Condition{{
+						Type:   apis.ConditionSucceeded,
+						Status: corev1.ConditionTrue,
+					}}},
+					TaskRunStatusFields: v1beta1.TaskRunStatusFields{TaskSpec: taskSpec},
+				},
+			}),
+			tb.PipelineRunTaskRunsStatus("test-pipeline-run-failed-hello-world-2", &v1beta1.PipelineRunTaskRunStatus{
+				PipelineTaskName: "hello-world-2",
+				Status: &v1beta1.TaskRunStatus{
+					Status: duckv1beta1.Status{Conditions: []apis.Condition{{
+						Type:   apis.ConditionSucceeded,
+						Status: corev1.ConditionFalse,
+					}}},
+					TaskRunStatusFields: v1beta1.TaskRunStatusFields{TaskSpec: taskSpec},
+				},
+			}),
 		),
 	)
-
+	failedRun.Status.PipelineSpec = ps[0].Spec.DeepCopy()
+	prs := []*v1beta1.PipelineRun{failedRun, tb.PipelineRun("test-pipeline-run-resumed", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa"),
+			tb.PipelineRunResumeFrom("test-pipeline-run-failed"),
+		),
+	)}
 	d := test.Data{
 		PipelineRuns: prs,
 		Pipelines:    ps,
@@ -1358,92 +2343,55 @@ func TestReconcilePropagateLabels(t *testing.T) {
 	prt := NewPipelineRunTest(d, t)
 	defer prt.Cancel()
 
-	_, clients := prt.reconcileRun("foo", "test-pipeline-run-with-labels", []string{}, false)
-	actions := clients.Pipeline.Actions()
-	if len(actions) < 2 {
-		t.Fatalf("Expected client to have at least two action implementation but it has %d", len(actions))
-	}
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-resumed", nil, false)
 
-	// Check that the expected TaskRun was created
-	actual := actions[1].(ktesting.CreateAction).GetObject().(*v1beta1.TaskRun)
-	if actual == nil {
-		t.Errorf("Expected a TaskRun to be created, but it wasn't.")
+	var created []string
+	for _, a := range clients.Pipeline.Actions() {
+		if action, ok := a.(ktesting.CreateAction); ok {
+			if tr, ok := action.GetObject().(*v1beta1.TaskRun); ok {
+				created = append(created, tr.Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey])
+			}
+		}
+	}
+	if d := cmp.Diff([]string{"hello-world-2"}, created); d != "" {
+		t.Errorf("Unexpected TaskRuns created for PipelineTasks %s", diff.PrintWantGot(d))
 	}
 
-	if d := cmp.Diff(actual, expected); d != "" {
-		t.Errorf("expected to see TaskRun %v created. Diff %s", expected, diff.PrintWantGot(d))
+	var resumed *v1beta1.PipelineRunTaskRunStatus
+	for _, prtrs := range reconciledRun.Status.TaskRuns {
+		if prtrs.PipelineTaskName == "hello-world-1" {
+			resumed = prtrs
+		}
+	}
+	if resumed == nil || resumed.ResumedFrom != "test-pipeline-run-failed" || !resumed.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
+		t.Errorf("Expected hello-world-1 to be resumed from test-pipeline-run-failed, but got %v", resumed)
 	}
 }
 
-func TestReconcileWithDifferentServiceAccounts(t *testing.T) {
-	names.TestingSeed()
-
+// TestReconcileWithResumeFromMissingPipelineRun runs "Reconcile" on a PipelineRun resuming a
+// PipelineRun which doesn't exist, and verifies that it fails
+func TestReconcileWithResumeFromMissingPipelineRun(t *testing.T) {
 	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
-		tb.PipelineTask("hello-world-0", "hello-world-task"),
-		tb.PipelineTask("hello-world-1", "hello-world-task"),
+		tb.PipelineTask("hello-world-1", "hello-world"),
 	))}
-	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-different-service-accs", tb.PipelineRunNamespace("foo"),
-		tb.PipelineRunSpec("test-pipeline",
-			tb.PipelineRunServiceAccountName("test-sa-0"),
-			tb.PipelineRunServiceAccountNameTask("hello-world-1", "test-sa-1"),
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-resumed", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa"),
+			tb.PipelineRunResumeFrom("test-pipeline-run-missing"),
 		),
 	)}
-	ts := []*v1beta1.Task{
-		tb.Task("hello-world-task", tb.TaskNamespace("foo")),
-	}
-
 	d := test.Data{
 		PipelineRuns: prs,
 		Pipelines:    ps,
-		Tasks:        ts,
+		Tasks:        []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))},
 	}
 	prt := NewPipelineRunTest(d, t)
 	defer prt.Cancel()
 
-	_, clients := prt.reconcileRun("foo", "test-pipeline-run-different-service-accs", []string{}, false)
-
-	taskRunNames := []string{"test-pipeline-run-different-service-accs-hello-world-0-9l9zj", "test-pipeline-run-different-service-accs-hello-world-1-mz4c7"}
-
-	expectedTaskRuns := []*v1beta1.TaskRun{
-		tb.TaskRun(taskRunNames[0],
-			tb.TaskRunNamespace("foo"),
-			tb.TaskRunOwnerReference("PipelineRun", "test-pipeline-run-different-service-accs",
-				tb.OwnerReferenceAPIVersion("tekton.dev/v1beta1"),
-				tb.Controller, tb.BlockOwnerDeletion,
-			),
-			tb.TaskRunSpec(
-				tb.TaskRunTaskRef("hello-world-task"),
-				tb.TaskRunServiceAccountName("test-sa-0"),
-			),
-			tb.TaskRunLabel("tekton.dev/pipeline", "test-pipeline"),
-			tb.TaskRunLabel("tekton.dev/pipelineRun", "test-pipeline-run-different-service-accs"),
-			tb.TaskRunLabel("tekton.dev/pipelineTask", "hello-world-0"),
-		),
-		tb.TaskRun(taskRunNames[1],
-			tb.TaskRunNamespace("foo"),
-			tb.TaskRunOwnerReference("PipelineRun", "test-pipeline-run-different-service-accs",
-				tb.OwnerReferenceAPIVersion("tekton.dev/v1beta1"),
-				tb.Controller, tb.BlockOwnerDeletion,
-			),
-			tb.TaskRunSpec(
-				tb.TaskRunTaskRef("hello-world-task"),
-				tb.TaskRunServiceAccountName("test-sa-1"),
-			),
-			tb.TaskRunLabel("tekton.dev/pipeline", "test-pipeline"),
-			tb.TaskRunLabel("tekton.dev/pipelineRun", "test-pipeline-run-different-service-accs"),
-			tb.TaskRunLabel("tekton.dev/pipelineTask", "hello-world-1"),
-		),
-	}
-	for i := range ps[0].Spec.Tasks {
-		// Check that the expected TaskRun was created
-		actual, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").Get(taskRunNames[i], metav1.GetOptions{})
-		if err != nil {
-			t.Fatalf("Expected a TaskRun to be created, but it wasn't: %s", err)
-		}
-		if d := cmp.Diff(actual, expectedTaskRuns[i], ignoreResourceVersion); d != "" {
-			t.Errorf("expected to see TaskRun %v created. Diff %s", expectedTaskRuns[i], diff.PrintWantGot(d))
-		}
+	reconciledRun, _ := prt.reconcileRun("foo", "test-pipeline-run-resumed", nil, true)
 
+	condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+	if !condition.IsFalse() || condition.Reason != ReasonCouldntGetResumedPipelineRun {
+		t.Errorf("Expected PipelineRun to fail with reason %s, but got %v", ReasonCouldntGetResumedPipelineRun, condition)
 	}
 }
 
@@ -1673,6 +2621,54 @@ func TestGetTaskRunTimeout(t *testing.T) {
 			},
 		},
 		expected: &metav1.Duration{Duration: 2 * time.Minute},
+	}, {
+		name: "taskrun being created after tasks timeout expired",
+		pr: tb.PipelineRun(prName, tb.PipelineRunNamespace(ns),
+			tb.PipelineRunSpec(p, tb.PipelineRunTimeouts(v1beta1.TimeoutFields{
+				Pipeline: &metav1.Duration{Duration: 20 * time.Minute},
+				Tasks:    &metav1.Duration{Duration: 1 * time.Minute},
+			})),
+			tb.PipelineRunStatus(tb.PipelineRunStartTime(time.Now().Add(-2*time.Minute))),
+		),
+		rprt: &resources.ResolvedPipelineRunTask{
+			PipelineTask: &v1beta1.PipelineTask{
+				Timeout: nil,
+			},
+		},
+		expected: &metav1.Duration{Duration: 1 * time.Second},
+	}, {
+		name: "taskrun being created with timeout for PipelineTask within tasks timeout",
+		pr: tb.PipelineRun(prName, tb.PipelineRunNamespace(ns),
+			tb.PipelineRunSpec(p, tb.PipelineRunTimeouts(v1beta1.TimeoutFields{
+				Pipeline: &metav1.Duration{Duration: 20 * time.Minute},
+				Tasks:    &metav1.Duration{Duration: 10 * time.Minute},
+			})),
+			tb.PipelineRunStatus(tb.PipelineRunStartTime(time.Now())),
+		),
+		rprt: &resources.ResolvedPipelineRunTask{
+			PipelineTask: &v1beta1.PipelineTask{
+				Timeout: &metav1.Duration{Duration: 2 * time.Minute},
+			},
+		},
+		expected: &metav1.Duration{Duration: 2 * time.Minute},
+	}, {
+		name: "final taskrun being created after finally timeout expired",
+		pr: tb.PipelineRun(prName, tb.PipelineRunNamespace(ns),
+			tb.PipelineRunSpec(p, tb.PipelineRunTimeouts(v1beta1.TimeoutFields{
+				Pipeline: &metav1.Duration{Duration: 20 * time.Minute},
+				Finally:  &metav1.Duration{Duration: 1 * time.Minute},
+			})),
+			tb.PipelineRunStatus(
+				tb.PipelineRunStartTime(time.Now().Add(-5*time.Minute)),
+				tb.PipelineRunFinallyStartTime(time.Now().Add(-2*time.Minute)),
+			),
+		),
+		rprt: &resources.ResolvedPipelineRunTask{
+			PipelineTask: &v1beta1.PipelineTask{
+				Timeout: nil,
+			},
+		},
+		expected: &metav1.Duration{Duration: 1 * time.Second},
 	}}
 
 	for _, tc := range tcs {
@@ -2076,7 +3072,9 @@ func TestReconcileWithWhenExpressionsWithParameters(t *testing.T) {
 
 	actualSkippedTasks := pipelineRun.Status.SkippedTasks
 	expectedSkippedTasks := []v1beta1.SkippedTask{{
-		Name: "hello-world-2",
+		Name:    "hello-world-2",
+		Reason:  v1beta1.WhenExpressionsSkip,
+		Message: `input "yes" with operator "notin" and values ["yes"] evaluated to false`,
 	}}
 	if d := cmp.Diff(actualSkippedTasks, expectedSkippedTasks); d != "" {
 		t.Errorf("expected to find Skipped Tasks %v. Diff %s", expectedSkippedTasks, diff.PrintWantGot(d))
@@ -2197,9 +3195,12 @@ func TestReconcileWithWhenExpressionsWithTaskResults(t *testing.T) {
 
 	actualSkippedTasks := pipelineRun.Status.SkippedTasks
 	expectedSkippedTasks := []v1beta1.SkippedTask{{
-		Name: "c-task",
+		Name:    "c-task",
+		Reason:  v1beta1.WhenExpressionsSkip,
+		Message: `input "aResultValue" with operator "in" and values ["missing"] evaluated to false`,
 	}, {
-		Name: "d-task",
+		Name:   "d-task",
+		Reason: v1beta1.ParentTasksSkip,
 	}}
 	if d := cmp.Diff(actualSkippedTasks, expectedSkippedTasks); d != "" {
 		t.Errorf("expected to find Skipped Tasks %v. Diff %s", expectedSkippedTasks, diff.PrintWantGot(d))
@@ -2221,6 +3222,110 @@ func TestReconcileWithWhenExpressionsWithTaskResults(t *testing.T) {
 	}
 }
 
+func TestReconcileWithWhenExpressionsWithTaskStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		buildStatus      corev1.ConditionStatus
+		wantEvents       []string
+		wantTaskRun      string
+		wantSkippedTasks []v1beta1.SkippedTask
+	}{{
+		name:        "build failed",
+		buildStatus: corev1.ConditionFalse,
+		wantEvents: []string{
+			"Normal Started",
+			"Normal PipelineRunStopping Tasks Completed: 1 \\(Failed: 1, Cancelled 0\\), Incomplete: 1, Skipped: 1",
+		},
+		wantTaskRun: "notify",
+		wantSkippedTasks: []v1beta1.SkippedTask{{
+			Name:    "publish",
+			Reason:  v1beta1.WhenExpressionsSkip,
+			Message: `cel expression "tasks.build.status == 'Succeeded'" evaluated to false`,
+		}},
+	}, {
+		name:        "build succeeded",
+		buildStatus: corev1.ConditionTrue,
+		wantEvents: []string{
+			"Normal Started",
+			"Normal Running Tasks Completed: 1 \\(Failed: 0, Cancelled 0\\), Incomplete: 1, Skipped: 1",
+		},
+		wantTaskRun: "publish",
+		wantSkippedTasks: []v1beta1.SkippedTask{{
+			Name:    "notify",
+			Reason:  v1beta1.WhenExpressionsSkip,
+			Message: `input "Succeeded" with operator "in" and values ["Failed"] evaluated to false`,
+		}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			names.TestingSeed()
+			p := tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+				tb.PipelineTask("build", "build-task"),
+				tb.PipelineTask("notify", "notify-task",
+					tb.PipelineTaskWhenExpression("$(tasks.build.status)", selection.In, []string{v1beta1.PipelineTaskStatusFailed}),
+				),
+				tb.PipelineTask("publish", "publish-task"),
+			))
+			p.Spec.Tasks[2].WhenExpressions = v1beta1.WhenExpressions{{CEL: "tasks.build.status == 'Succeeded'"}}
+			prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run", tb.PipelineRunNamespace("foo"),
+				tb.PipelineRunSpec("test-pipeline"),
+			)}
+			ts := []*v1beta1.Task{
+				tb.Task("build-task", tb.TaskNamespace("foo")),
+				tb.Task("notify-task", tb.TaskNamespace("foo")),
+				tb.Task("publish-task", tb.TaskNamespace("foo")),
+			}
+			trs := []*v1beta1.TaskRun{
+				tb.TaskRun("test-pipeline-run-build-xxyyy",
+					tb.TaskRunNamespace("foo"),
+					tb.TaskRunOwnerReference("PipelineRun", "test-pipeline-run",
+						tb.OwnerReferenceAPIVersion("tekton.dev/v1beta1"),
+						tb.Controller, tb.BlockOwnerDeletion,
+					),
+					tb.TaskRunLabel("tekton.dev/pipeline", "test-pipeline"),
+					tb.TaskRunLabel("tekton.dev/pipelineRun", "test-pipeline-run"),
+					tb.TaskRunLabel("tekton.dev/pipelineTask", "build"),
+					tb.TaskRunSpec(tb.TaskRunTaskRef("build-task")),
+					tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+						Type:   apis.ConditionSucceeded,
+						Status: tc.buildStatus,
+					})),
+				),
+			}
+
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    []*v1beta1.Pipeline{p},
+				Tasks:        ts,
+				TaskRuns:     trs,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			pipelineRun, clients := prt.reconcileRun("foo", "test-pipeline-run", tc.wantEvents, false)
+
+			for _, pt := range []string{"notify", "publish"} {
+				actual, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").List(metav1.ListOptions{
+					LabelSelector: fmt.Sprintf("tekton.dev/pipelineTask=%s,tekton.dev/pipelineRun=test-pipeline-run", pt),
+				})
+				if err != nil {
+					t.Fatalf("Failure to list TaskRun's %s", err)
+				}
+				want := 0
+				if pt == tc.wantTaskRun {
+					want = 1
+				}
+				if len(actual.Items) != want {
+					t.Errorf("Expected %d TaskRuns for %s got %d", want, pt, len(actual.Items))
+				}
+			}
+
+			if d := cmp.Diff(tc.wantSkippedTasks, pipelineRun.Status.SkippedTasks); d != "" {
+				t.Errorf("expected to find Skipped Tasks %v. Diff %s", tc.wantSkippedTasks, diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
 // TestReconcileWithAffinityAssistantStatefulSet tests that given a pipelineRun with workspaces,
 // an Affinity Assistant StatefulSet is created for each PVC workspace and
 // that the Affinity Assistant names is propagated to TaskRuns.
@@ -2673,48 +3778,114 @@ func TestReconcileWithPipelineResults(t *testing.T) {
 			tb.TaskRunLabel("tekton.dev/pipeline", "test-pipeline"),
 			tb.TaskRunLabel("tekton.dev/pipelineRun", "test-pipeline-run-different-service-accs"),
 			tb.TaskRunLabel("tekton.dev/pipelineTask", "a-task"),
-			tb.TaskRunSpec(
-				tb.TaskRunTaskRef("hello-world"),
-				tb.TaskRunServiceAccountName("test-sa"),
-			),
-			tb.TaskRunStatus(
-				tb.StatusCondition(
-					apis.Condition{
-						Type:   apis.ConditionSucceeded,
-						Status: corev1.ConditionTrue,
-					},
-				),
-				tb.TaskRunResult("aResult", "aResultValue"),
-			),
+			tb.TaskRunSpec(
+				tb.TaskRunTaskRef("hello-world"),
+				tb.TaskRunServiceAccountName("test-sa"),
+			),
+			tb.TaskRunStatus(
+				tb.StatusCondition(
+					apis.Condition{
+						Type:   apis.ConditionSucceeded,
+						Status: corev1.ConditionTrue,
+					},
+				),
+				tb.TaskRunResult("aResult", "aResultValue"),
+			),
+		),
+	}
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-different-service-accs", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline",
+			tb.PipelineRunServiceAccountName("test-sa-0"),
+		),
+		tb.PipelineRunStatus(
+			tb.PipelineRunResult("result", "aResultValue"),
+			tb.PipelineRunStatusCondition(apis.Condition{
+				Type:    apis.ConditionSucceeded,
+				Status:  corev1.ConditionTrue,
+				Reason:  v1beta1.PipelineRunReasonSuccessful.String(),
+				Message: "All Tasks have completed executing",
+			}),
+			tb.PipelineRunTaskRunsStatus(trs[0].Name, &v1beta1.PipelineRunTaskRunStatus{
+				PipelineTaskName: "a-task",
+				Status:           &trs[0].Status,
+			}),
+			tb.PipelineRunStartTime(time.Now().AddDate(0, 0, -1)),
+			tb.PipelineRunCompletionTime(time.Now()),
+		),
+	)}
+	ts := []*v1beta1.Task{
+		tb.Task("a-task", tb.TaskNamespace("foo")),
+		tb.Task("b-task", tb.TaskNamespace("foo"),
+			tb.TaskSpec(
+				tb.TaskParam("bParam", v1beta1.ParamTypeString),
+			),
+		),
+	}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+		TaskRuns:     trs,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	reconciledRun, _ := prt.reconcileRun("foo", "test-pipeline-run-different-service-accs", []string{}, false)
+
+	if d := cmp.Diff(&reconciledRun, &prs[0], ignoreResourceVersion); d != "" {
+		t.Errorf("expected to see pipeline run results created. Diff %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestReconcileWithFinallyPipelineResults(t *testing.T) {
+	names.TestingSeed()
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("a-task", "a-task"),
+		tb.FinalPipelineTask("final-task", "final-task"),
+		tb.PipelineResult("result", "$(tasks.a-task.results.aResult)", "pipeline result"),
+		tb.PipelineResult("report", "report at $(finally.final-task.results.url)", "pipeline result from a finally task"),
+	))}
+	successful := tb.StatusCondition(apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionTrue,
+	})
+	trs := []*v1beta1.TaskRun{
+		tb.TaskRun("test-pipeline-run-finally-results-a-task",
+			tb.TaskRunNamespace("foo"),
+			tb.TaskRunLabel("tekton.dev/pipelineTask", "a-task"),
+			tb.TaskRunSpec(tb.TaskRunTaskRef("a-task")),
+			tb.TaskRunStatus(successful, tb.TaskRunResult("aResult", "aResultValue")),
 		),
-	}
-	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-different-service-accs", tb.PipelineRunNamespace("foo"),
-		tb.PipelineRunSpec("test-pipeline",
-			tb.PipelineRunServiceAccountName("test-sa-0"),
+		tb.TaskRun("test-pipeline-run-finally-results-final-task",
+			tb.TaskRunNamespace("foo"),
+			tb.TaskRunLabel("tekton.dev/pipelineTask", "final-task"),
+			tb.TaskRunSpec(tb.TaskRunTaskRef("final-task")),
+			tb.TaskRunStatus(successful, tb.TaskRunResult("url", "https://example.com/report")),
 		),
+	}
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-finally-results", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline"),
 		tb.PipelineRunStatus(
-			tb.PipelineRunResult("result", "aResultValue"),
 			tb.PipelineRunStatusCondition(apis.Condition{
-				Type:    apis.ConditionSucceeded,
-				Status:  corev1.ConditionTrue,
-				Reason:  v1beta1.PipelineRunReasonSuccessful.String(),
-				Message: "All Tasks have completed executing",
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionUnknown,
+				Reason: v1beta1.PipelineRunReasonRunning.String(),
 			}),
 			tb.PipelineRunTaskRunsStatus(trs[0].Name, &v1beta1.PipelineRunTaskRunStatus{
 				PipelineTaskName: "a-task",
 				Status:           &trs[0].Status,
 			}),
-			tb.PipelineRunStartTime(time.Now().AddDate(0, 0, -1)),
-			tb.PipelineRunCompletionTime(time.Now()),
+			tb.PipelineRunTaskRunsStatus(trs[1].Name, &v1beta1.PipelineRunTaskRunStatus{
+				PipelineTaskName: "final-task",
+				Status:           &trs[1].Status,
+			}),
+			tb.PipelineRunStartTime(time.Now()),
 		),
 	)}
 	ts := []*v1beta1.Task{
 		tb.Task("a-task", tb.TaskNamespace("foo")),
-		tb.Task("b-task", tb.TaskNamespace("foo"),
-			tb.TaskSpec(
-				tb.TaskParam("bParam", v1beta1.ParamTypeString),
-			),
-		),
+		tb.Task("final-task", tb.TaskNamespace("foo")),
 	}
 
 	d := test.Data{
@@ -2726,10 +3897,20 @@ func TestReconcileWithPipelineResults(t *testing.T) {
 	prt := NewPipelineRunTest(d, t)
 	defer prt.Cancel()
 
-	reconciledRun, _ := prt.reconcileRun("foo", "test-pipeline-run-different-service-accs", []string{}, false)
+	reconciledRun, _ := prt.reconcileRun("foo", "test-pipeline-run-finally-results", []string{}, false)
 
-	if d := cmp.Diff(&reconciledRun, &prs[0], ignoreResourceVersion); d != "" {
-		t.Errorf("expected to see pipeline run results created. Diff %s", diff.PrintWantGot(d))
+	if !reconciledRun.IsDone() || !reconciledRun.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
+		t.Fatalf("Expected the PipelineRun to succeed but got %v", reconciledRun.Status.GetCondition(apis.ConditionSucceeded))
+	}
+	want := []v1beta1.PipelineRunResult{{
+		Name:  "result",
+		Value: "aResultValue",
+	}, {
+		Name:  "report",
+		Value: "report at https://example.com/report",
+	}}
+	if d := cmp.Diff(want, reconciledRun.Status.PipelineResults); d != "" {
+		t.Errorf("expected to see pipeline run results from the finally task. Diff %s", diff.PrintWantGot(d))
 	}
 }
 
@@ -3983,3 +5164,502 @@ func getTaskRunWithTaskSpec(tr, pr, p, t string, labels, annotations map[string]
 		tb.TaskRunAnnotations(annotations),
 		tb.TaskRunSpec(tb.TaskRunTaskSpec(tb.Step("myimage", tb.StepName("mystep")))))
 }
+
+func TestReconcileWithMatrix(t *testing.T) {
+	names.TestingSeed()
+	prName := "test-pipeline-run"
+	ps := []*v1beta1.Pipeline{{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pipeline", Namespace: "foo"},
+		Spec: v1beta1.PipelineSpec{
+			Params: []v1beta1.ParamSpec{{Name: "platforms", Type: v1beta1.ParamTypeArray}},
+			Tasks: []v1beta1.PipelineTask{{
+				Name:    "build",
+				TaskRef: &v1beta1.TaskRef{Name: "build"},
+				Params: []v1beta1.Param{{
+					Name: "version", Value: *v1beta1.NewArrayOrString("v1"),
+				}},
+				Matrix: []v1beta1.Param{{
+					Name: "platform", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"$(params.platforms)"}},
+				}, {
+					Name: "browser", Value: *v1beta1.NewArrayOrString("chrome", "firefox"),
+				}},
+			}, {
+				Name:     "publish",
+				TaskRef:  &v1beta1.TaskRef{Name: "publish"},
+				RunAfter: []string{"build"},
+			}},
+		},
+	}}
+	prs := []*v1beta1.PipelineRun{{
+		ObjectMeta: metav1.ObjectMeta{Name: prName, Namespace: "foo"},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "test-pipeline"},
+			Params: []v1beta1.Param{{
+				Name: "platforms", Value: *v1beta1.NewArrayOrString("linux", "mac"),
+			}},
+		},
+	}}
+	ts := []*v1beta1.Task{{
+		ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "foo"},
+		Spec: v1beta1.TaskSpec{
+			Params: []v1beta1.ParamSpec{{Name: "version"}, {Name: "platform"}, {Name: "browser"}},
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "publish", Namespace: "foo"},
+	}}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	wantEvents := []string{
+		"Normal Started",
+		"Normal Running Tasks Completed: 0 \\(Failed: 0, Cancelled 0\\), Incomplete: 2, Skipped: 0",
+	}
+	pipelineRun, clients := prt.reconcileRun("foo", prName, wantEvents, false)
+
+	actual, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").List(metav1.ListOptions{
+		LabelSelector: "tekton.dev/pipelineRun=test-pipeline-run",
+	})
+	if err != nil {
+		t.Fatalf("Failure to list TaskRun's %s", err)
+	}
+	wantParams := map[string][]v1beta1.Param{}
+	for i, combination := range [][]string{{"linux", "chrome"}, {"linux", "firefox"}, {"mac", "chrome"}, {"mac", "firefox"}} {
+		wantParams[fmt.Sprintf("test-pipeline-run-build-9l9zj-%d", i)] = []v1beta1.Param{{
+			Name: "version", Value: *v1beta1.NewArrayOrString("v1"),
+		}, {
+			Name: "platform", Value: *v1beta1.NewArrayOrString(combination[0]),
+		}, {
+			Name: "browser", Value: *v1beta1.NewArrayOrString(combination[1]),
+		}}
+	}
+	if len(actual.Items) != len(wantParams) {
+		t.Fatalf("Expected %d TaskRuns got %d", len(wantParams), len(actual.Items))
+	}
+	for _, tr := range actual.Items {
+		want, ok := wantParams[tr.Name]
+		if !ok {
+			t.Fatalf("Unexpected TaskRun %s created", tr.Name)
+		}
+		if tr.Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey] != "build" {
+			t.Errorf("Expected TaskRun %s to be labelled with pipeline task build but got %v", tr.Name, tr.Labels)
+		}
+		if d := cmp.Diff(want, tr.Spec.Params); d != "" {
+			t.Errorf("TaskRun %s params mismatch: %s", tr.Name, diff.PrintWantGot(d))
+		}
+		if prtrs, ok := pipelineRun.Status.TaskRuns[tr.Name]; !ok || prtrs.PipelineTaskName != "build" {
+			t.Errorf("Expected PipelineRun status to include TaskRun %s for pipeline task build but was %v", tr.Name, pipelineRun.Status.TaskRuns)
+		}
+	}
+}
+
+func TestReconcileWithEmptyMatrix(t *testing.T) {
+	ps := []*v1beta1.Pipeline{{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pipeline", Namespace: "foo"},
+		Spec: v1beta1.PipelineSpec{
+			Params: []v1beta1.ParamSpec{{Name: "platforms", Type: v1beta1.ParamTypeArray}},
+			Tasks: []v1beta1.PipelineTask{{
+				Name:    "build",
+				TaskRef: &v1beta1.TaskRef{Name: "build"},
+				Matrix: []v1beta1.Param{{
+					Name: "platform", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"$(params.platforms)"}},
+				}},
+			}, {
+				Name:     "publish",
+				TaskRef:  &v1beta1.TaskRef{Name: "publish"},
+				RunAfter: []string{"build"},
+			}},
+		},
+	}}
+	prs := []*v1beta1.PipelineRun{{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pipeline-run", Namespace: "foo"},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "test-pipeline"},
+			Params: []v1beta1.Param{{
+				Name: "platforms", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{}},
+			}},
+		},
+	}}
+	ts := []*v1beta1.Task{{
+		ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "foo"},
+		Spec: v1beta1.TaskSpec{
+			Params: []v1beta1.ParamSpec{{Name: "platform"}},
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "publish", Namespace: "foo"},
+	}}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	pipelineRun, clients := prt.reconcileRun("foo", "test-pipeline-run", nil, true)
+	condition := pipelineRun.Status.GetCondition(apis.ConditionSucceeded)
+	if !condition.IsFalse() || condition.Reason != ReasonEmptyMatrixParameter {
+		t.Errorf("Expected the PipelineRun to fail with reason %s, got %v", ReasonEmptyMatrixParameter, condition)
+	}
+	if n := createdTaskRuns(clients); n != 0 {
+		t.Errorf("Expected no TaskRun to be created, got %d", n)
+	}
+}
+
+func getPipelineWithPipelineTask() []*v1beta1.Pipeline {
+	return []*v1beta1.Pipeline{{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pipeline", Namespace: "foo"},
+		Spec: v1beta1.PipelineSpec{
+			Workspaces: []v1beta1.PipelineWorkspaceDeclaration{{Name: "source"}},
+			Tasks: []v1beta1.PipelineTask{{
+				Name:        "build-test-scan",
+				PipelineRef: &v1beta1.PipelineRef{Name: "build-test-scan"},
+				Params: []v1beta1.Param{{
+					Name: "revision", Value: *v1beta1.NewArrayOrString("main"),
+				}},
+				Workspaces: []v1beta1.WorkspacePipelineTaskBinding{{
+					Name: "src", Workspace: "source", SubPath: "app",
+				}},
+			}, {
+				Name:    "publish",
+				TaskRef: &v1beta1.TaskRef{Name: "publish"},
+				Params: []v1beta1.Param{{
+					Name: "image", Value: *v1beta1.NewArrayOrString("$(tasks.build-test-scan.results.image)"),
+				}},
+			}},
+		},
+	}}
+}
+
+func TestReconcileWithPipelineTaskCreatesChildPipelineRun(t *testing.T) {
+	names.TestingSeed()
+	prs := []*v1beta1.PipelineRun{{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pipeline-run", Namespace: "foo"},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef:        &v1beta1.PipelineRef{Name: "test-pipeline"},
+			ServiceAccountName: "test-sa",
+			Workspaces: []v1beta1.WorkspaceBinding{{
+				Name: "source", EmptyDir: &corev1.EmptyDirVolumeSource{},
+			}},
+		},
+	}}
+	ts := []*v1beta1.Task{{
+		ObjectMeta: metav1.ObjectMeta{Name: "publish", Namespace: "foo"},
+		Spec: v1beta1.TaskSpec{
+			Params: []v1beta1.ParamSpec{{Name: "image"}},
+		},
+	}}
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    getPipelineWithPipelineTask(),
+		Tasks:        ts,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	wantEvents := []string{
+		"Normal Started",
+		"Normal Running Tasks Completed: 0 \\(Failed: 0, Cancelled 0\\), Incomplete: 2, Skipped: 0",
+	}
+	pipelineRun, clients := prt.reconcileRun("foo", "test-pipeline-run", wantEvents, false)
+
+	childName := "test-pipeline-run-build-test-scan-9l9zj"
+	actual, err := clients.Pipeline.TektonV1beta1().PipelineRuns("foo").Get(childName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected child PipelineRun %s to be created: %v", childName, err)
+	}
+	expected := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            childName,
+			Namespace:       "foo",
+			OwnerReferences: []metav1.OwnerReference{pipelineRun.GetOwnerReference()},
+			Labels: map[string]string{
+				pipeline.GroupName + pipeline.PipelineLabelKey:     "test-pipeline",
+				pipeline.GroupName + pipeline.PipelineRunLabelKey:  "test-pipeline-run",
+				pipeline.GroupName + pipeline.PipelineTaskLabelKey: "build-test-scan",
+			},
+			Annotations: map[string]string{
+				pipeline.GroupName + pipeline.ParentPipelineRefsAnnotationKey: `[{"name":"test-pipeline"}]`,
+			},
+		},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "build-test-scan"},
+			Params: []v1beta1.Param{{
+				Name: "revision", Value: *v1beta1.NewArrayOrString("main"),
+			}},
+			ServiceAccountName: "test-sa",
+			Timeout:            &metav1.Duration{Duration: config.DefaultTimeoutMinutes * time.Minute},
+			Workspaces: []v1beta1.WorkspaceBinding{{
+				Name: "src", SubPath: "app", EmptyDir: &corev1.EmptyDirVolumeSource{},
+			}},
+		},
+	}
+	if d := cmp.Diff(expected, actual, ignoreResourceVersion); d != "" {
+		t.Errorf("expected to see child PipelineRun %v created. Diff %s", expected, diff.PrintWantGot(d))
+	}
+
+	if prprs, ok := pipelineRun.Status.PipelineRuns[childName]; !ok || prprs.PipelineTaskName != "build-test-scan" {
+		t.Errorf("Expected PipelineRun status to include child PipelineRun %s but was %v", childName, pipelineRun.Status.PipelineRuns)
+	}
+	taskRuns, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failure to list TaskRun's %s", err)
+	}
+	if len(taskRuns.Items) != 0 {
+		t.Errorf("Expected no TaskRuns to be created until the child PipelineRun is done, got %d", len(taskRuns.Items))
+	}
+}
+
+func TestReconcileWithPipelineRefCycle(t *testing.T) {
+	ps := []*v1beta1.Pipeline{{
+		ObjectMeta: metav1.ObjectMeta{Name: "recursive", Namespace: "foo"},
+		Spec: v1beta1.PipelineSpec{
+			Tasks: []v1beta1.PipelineTask{{
+				Name:        "again",
+				PipelineRef: &v1beta1.PipelineRef{Name: "recursive"},
+			}},
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "nested", Namespace: "foo"},
+		Spec: v1beta1.PipelineSpec{
+			Tasks: []v1beta1.PipelineTask{{
+				Name:        "parent",
+				PipelineRef: &v1beta1.PipelineRef{Name: "parent"},
+			}},
+		},
+	}}
+	for _, tc := range []struct {
+		name string
+		pr   *v1beta1.PipelineRun
+	}{{
+		name: "pipeline referencing itself",
+		pr: &v1beta1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-pipeline-run-cycle", Namespace: "foo"},
+			Spec:       v1beta1.PipelineRunSpec{PipelineRef: &v1beta1.PipelineRef{Name: "recursive"}},
+		},
+	}, {
+		name: "pipeline referencing the pipeline it is nested in",
+		pr: &v1beta1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-pipeline-run-cycle",
+				Namespace: "foo",
+				Annotations: map[string]string{
+					pipeline.GroupName + pipeline.ParentPipelineRefsAnnotationKey: `[{"name":"parent"}]`,
+				},
+			},
+			Spec: v1beta1.PipelineRunSpec{PipelineRef: &v1beta1.PipelineRef{Name: "nested"}},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			d := test.Data{
+				PipelineRuns: []*v1beta1.PipelineRun{tc.pr},
+				Pipelines:    ps,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			pipelineRun, clients := prt.reconcileRun("foo", "test-pipeline-run-cycle", nil, true)
+			condition := pipelineRun.Status.GetCondition(apis.ConditionSucceeded)
+			if !condition.IsFalse() || condition.Reason != ReasonPipelineRefCycle {
+				t.Errorf("Expected the PipelineRun to fail with reason %s, got %v", ReasonPipelineRefCycle, condition)
+			}
+			prs, err := clients.Pipeline.TektonV1beta1().PipelineRuns("foo").List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(prs.Items) != 1 || createdTaskRuns(clients) != 0 {
+				t.Errorf("Expected no child PipelineRun or TaskRun to be created, got %d PipelineRuns and %d TaskRuns", len(prs.Items), createdTaskRuns(clients))
+			}
+		})
+	}
+}
+
+func TestReconcileWithPipelineTaskUsesChildPipelineRunResults(t *testing.T) {
+	names.TestingSeed()
+	childName := "test-pipeline-run-build-test-scan-abcde"
+	prs := []*v1beta1.PipelineRun{{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pipeline-run", Namespace: "foo"},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "test-pipeline"},
+			Workspaces: []v1beta1.WorkspaceBinding{{
+				Name: "source", EmptyDir: &corev1.EmptyDirVolumeSource{},
+			}},
+		},
+		Status: v1beta1.PipelineRunStatus{
+			PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+				PipelineRuns: map[string]*v1beta1.PipelineRunPipelineRunStatus{
+					childName: {PipelineTaskName: "build-test-scan"},
+				},
+			},
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      childName,
+			Namespace: "foo",
+			Labels: map[string]string{
+				pipeline.GroupName + pipeline.PipelineRunLabelKey:  "test-pipeline-run",
+				pipeline.GroupName + pipeline.PipelineTaskLabelKey: "build-test-scan",
+			},
+		},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "build-test-scan"},
+		},
+		Status: v1beta1.PipelineRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: duckv1beta1.Conditions{{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionTrue,
+					Reason: v1beta1.PipelineRunReasonSuccessful.String(),
+				}},
+			},
+			PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+				PipelineResults: []v1beta1.PipelineRunResult{{
+					Name: "image", Value: "registry.example.com/app@sha256:abc",
+				}},
+			},
+		},
+	}}
+	ts := []*v1beta1.Task{{
+		ObjectMeta: metav1.ObjectMeta{Name: "publish", Namespace: "foo"},
+		Spec: v1beta1.TaskSpec{
+			Params: []v1beta1.ParamSpec{{Name: "image"}},
+		},
+	}}
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    getPipelineWithPipelineTask(),
+		Tasks:        ts,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	wantEvents := []string{
+		"Normal Started",
+		"Normal Running Tasks Completed: 1 \\(Failed: 0, Cancelled 0\\), Incomplete: 1, Skipped: 0",
+	}
+	pipelineRun, clients := prt.reconcileRun("foo", "test-pipeline-run", wantEvents, false)
+
+	actual, err := clients.Pipeline.TektonV1beta1().TaskRuns("foo").Get("test-pipeline-run-publish-9l9zj", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected TaskRun for publish to be created: %v", err)
+	}
+	wantParams := []v1beta1.Param{{
+		Name: "image", Value: *v1beta1.NewArrayOrString("registry.example.com/app@sha256:abc"),
+	}}
+	if d := cmp.Diff(wantParams, actual.Spec.Params); d != "" {
+		t.Errorf("TaskRun params mismatch: %s", diff.PrintWantGot(d))
+	}
+	if prprs, ok := pipelineRun.Status.PipelineRuns[childName]; !ok || !prprs.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
+		t.Errorf("Expected PipelineRun status to include the succeeded child PipelineRun %s but was %v", childName, pipelineRun.Status.PipelineRuns)
+	}
+}
+
+func TestReconcileCancelledPipelineRunCancelsChildPipelineRuns(t *testing.T) {
+	childName := "test-pipeline-run-build-test-scan-abcde"
+	prs := []*v1beta1.PipelineRun{{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pipeline-run", Namespace: "foo"},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "test-pipeline"},
+			Status:      v1beta1.PipelineRunSpecStatusCancelled,
+		},
+		Status: v1beta1.PipelineRunStatus{
+			PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+				PipelineRuns: map[string]*v1beta1.PipelineRunPipelineRunStatus{
+					childName: {PipelineTaskName: "build-test-scan"},
+				},
+			},
+		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: childName, Namespace: "foo"},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "build-test-scan"},
+		},
+	}}
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    getPipelineWithPipelineTask(),
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	wantEvents := []string{
+		"Normal Started",
+		"Warning Failed PipelineRun \"test-pipeline-run\" was cancelled",
+	}
+	pipelineRun, clients := prt.reconcileRun("foo", "test-pipeline-run", wantEvents, false)
+
+	if !pipelineRun.Status.GetCondition(apis.ConditionSucceeded).IsFalse() {
+		t.Errorf("Expected PipelineRun status to be complete and false, but was %v", pipelineRun.Status.GetCondition(apis.ConditionSucceeded))
+	}
+	child, err := clients.Pipeline.TektonV1beta1().PipelineRuns("foo").Get(childName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Somehow had error getting child PipelineRun %s: %v", childName, err)
+	}
+	if !child.IsCancelled() {
+		t.Errorf("Expected child PipelineRun %s to be cancelled, but its spec status was %q", childName, child.Spec.Status)
+	}
+}
+
+func TestReconcileGracefullyCancelledOrStoppedPipelineRunPropagatesToChildPipelineRuns(t *testing.T) {
+	childName := "test-pipeline-run-build-test-scan-abcde"
+	for _, specStatus := range []v1beta1.PipelineRunSpecStatus{
+		v1beta1.PipelineRunSpecStatusCancelledRunFinally,
+		v1beta1.PipelineRunSpecStatusStoppedRunFinally,
+	} {
+		t.Run(string(specStatus), func(t *testing.T) {
+			prs := []*v1beta1.PipelineRun{{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-pipeline-run", Namespace: "foo"},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef:        &v1beta1.PipelineRef{Name: "test-pipeline"},
+					ServiceAccountName: "test-sa",
+					Status:             specStatus,
+					Workspaces: []v1beta1.WorkspaceBinding{{
+						Name: "source", EmptyDir: &corev1.EmptyDirVolumeSource{},
+					}},
+				},
+				Status: v1beta1.PipelineRunStatus{
+					PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+						StartTime: &metav1.Time{Time: time.Now()},
+						PipelineRuns: map[string]*v1beta1.PipelineRunPipelineRunStatus{
+							childName: {PipelineTaskName: "build-test-scan"},
+						},
+					},
+				},
+			}, {
+				ObjectMeta: metav1.ObjectMeta{Name: childName, Namespace: "foo"},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{Name: "build-test-scan"},
+				},
+			}}
+			ts := []*v1beta1.Task{{
+				ObjectMeta: metav1.ObjectMeta{Name: "publish", Namespace: "foo"},
+				Spec: v1beta1.TaskSpec{
+					Params: []v1beta1.ParamSpec{{Name: "image"}},
+				},
+			}}
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    getPipelineWithPipelineTask(),
+				Tasks:        ts,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			_, clients := prt.reconcileRun("foo", "test-pipeline-run", nil, false)
+
+			child, err := clients.Pipeline.TektonV1beta1().PipelineRuns("foo").Get(childName, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Somehow had error getting child PipelineRun %s: %v", childName, err)
+			}
+			if child.Spec.Status != specStatus {
+				t.Errorf("Expected the spec status of child PipelineRun %s to be %q, but was %q", childName, specStatus, child.Spec.Status)
+			}
+		})
+	}
+}
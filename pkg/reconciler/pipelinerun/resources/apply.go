@@ -20,93 +20,144 @@ import (
 	"fmt"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/substitution"
 )
 
 // ApplyParameters applies the params from a PipelineRun.Params to a PipelineSpec.
 func ApplyParameters(p *v1beta1.PipelineSpec, pr *v1beta1.PipelineRun) *v1beta1.PipelineSpec {
+	stringReplacements, arrayReplacements, objectReplacements := paramsReplacements(p, pr)
+	return ApplyReplacements(p, stringReplacements, arrayReplacements, objectReplacements)
+}
+
+// paramsReplacements returns the replacements of the params of the PipelineSpec, with the values
+// from the PipelineRun or else their defaults
+func paramsReplacements(p *v1beta1.PipelineSpec, pr *v1beta1.PipelineRun) (map[string]string, map[string][]string, map[string]map[string]string) {
 	// This assumes that the PipelineRun inputs have been validated against what the Pipeline requests.
 
-	// stringReplacements is used for standard single-string stringReplacements, while arrayReplacements contains arrays
-	// that need to be further processed.
+	// stringReplacements is used for standard single-string stringReplacements, while arrayReplacements and
+	// objectReplacements contain arrays and objects that need to be further processed. The keys of an object
+	// are also available as single-string stringReplacements.
 	stringReplacements := map[string]string{}
 	arrayReplacements := map[string][]string{}
+	objectReplacements := map[string]map[string]string{}
 
 	// Set all the default stringReplacements
 	for _, p := range p.Params {
 		if p.Default != nil {
-			if p.Default.Type == v1beta1.ParamTypeString {
-				stringReplacements[fmt.Sprintf("params.%s", p.Name)] = p.Default.StringVal
-			} else {
-				arrayReplacements[fmt.Sprintf("params.%s", p.Name)] = p.Default.ArrayVal
-			}
+			addReplacements(p.Name, *p.Default, stringReplacements, arrayReplacements, objectReplacements)
 		}
 	}
 	// Set and overwrite params with the ones from the PipelineRun
 	for _, p := range pr.Spec.Params {
-		if p.Value.Type == v1beta1.ParamTypeString {
-			stringReplacements[fmt.Sprintf("params.%s", p.Name)] = p.Value.StringVal
-		} else {
-			arrayReplacements[fmt.Sprintf("params.%s", p.Name)] = p.Value.ArrayVal
-		}
+		addReplacements(p.Name, p.Value, stringReplacements, arrayReplacements, objectReplacements)
 	}
+	return stringReplacements, arrayReplacements, objectReplacements
+}
 
-	return ApplyReplacements(p, stringReplacements, arrayReplacements)
+func addReplacements(name string, value v1beta1.ArrayOrString, stringReplacements map[string]string, arrayReplacements map[string][]string, objectReplacements map[string]map[string]string) {
+	switch value.Type {
+	case v1beta1.ParamTypeString:
+		stringReplacements[fmt.Sprintf("params.%s", name)] = value.StringVal
+	case v1beta1.ParamTypeObject:
+		objectReplacements[fmt.Sprintf("params.%s", name)] = value.ObjectVal
+		for k, v := range value.ObjectVal {
+			stringReplacements[fmt.Sprintf("params.%s.%s", name, k)] = v
+		}
+	default:
+		arrayReplacements[fmt.Sprintf("params.%s", name)] = value.ArrayVal
+	}
 }
 
 // ApplyContexts applies the substitution from $(context.(pipelineRun|pipeline).*) with the specified values.
 // Currently supports only name substitution. Uses "" as a default if name is not specified.
 func ApplyContexts(spec *v1beta1.PipelineSpec, pipelineName string, pr *v1beta1.PipelineRun) *v1beta1.PipelineSpec {
-	replacements := map[string]string{
+	return ApplyReplacements(spec, contextReplacements(pipelineName, pr), map[string][]string{}, map[string]map[string]string{})
+}
+
+func contextReplacements(pipelineName string, pr *v1beta1.PipelineRun) map[string]string {
+	return map[string]string{
 		"context.pipelineRun.name":      pr.Name,
 		"context.pipeline.name":         pipelineName,
 		"context.pipelineRun.namespace": pr.Namespace,
 		"context.pipelineRun.uid":       string(pr.ObjectMeta.UID),
 	}
-	return ApplyReplacements(spec, replacements, map[string][]string{})
 }
 
-// ApplyTaskResults applies the ResolvedResultRef to each PipelineTask.Params and Pipeline.WhenExpressions in targets
+// ApplyConcurrencyKey returns the concurrency key of the PipelineRun with its params, and the
+// $(context.(pipelineRun|pipeline).*) variables, substituted
+func ApplyConcurrencyKey(key string, p *v1beta1.PipelineSpec, pipelineName string, pr *v1beta1.PipelineRun) string {
+	stringReplacements, _, _ := paramsReplacements(p, pr)
+	for k, v := range contextReplacements(pipelineName, pr) {
+		stringReplacements[k] = v
+	}
+	return substitution.ApplyReplacements(key, stringReplacements)
+}
+
+// ApplyTaskResults applies the ResolvedResultRef to each PipelineTask.Params, PipelineTask.Matrix and Pipeline.WhenExpressions in targets.
+// Array results are expanded into array params in the same way as array params of the Pipeline.
 func ApplyTaskResults(targets PipelineRunState, resolvedResultRefs ResolvedResultRefs) {
 	stringReplacements := resolvedResultRefs.getStringReplacements()
+	arrayReplacements := resolvedResultRefs.getArrayReplacements()
 	for _, resolvedPipelineRunTask := range targets {
 		// also make substitution for resolved condition checks
 		for _, resolvedConditionCheck := range resolvedPipelineRunTask.ResolvedConditionChecks {
 			pipelineTaskCondition := resolvedConditionCheck.PipelineTaskCondition.DeepCopy()
-			pipelineTaskCondition.Params = replaceParamValues(pipelineTaskCondition.Params, stringReplacements, nil)
+			pipelineTaskCondition.Params = replaceParamValues(pipelineTaskCondition.Params, stringReplacements, nil, nil)
 			resolvedConditionCheck.PipelineTaskCondition = pipelineTaskCondition
 		}
 		if resolvedPipelineRunTask.PipelineTask != nil {
 			pipelineTask := resolvedPipelineRunTask.PipelineTask.DeepCopy()
-			pipelineTask.Params = replaceParamValues(pipelineTask.Params, stringReplacements, nil)
+			pipelineTask.Params = replaceParamValues(pipelineTask.Params, stringReplacements, arrayReplacements, nil)
+			pipelineTask.Matrix = replaceParamValues(pipelineTask.Matrix, stringReplacements, arrayReplacements, nil)
 			pipelineTask.WhenExpressions = pipelineTask.WhenExpressions.ReplaceWhenExpressionsVariables(stringReplacements)
 			resolvedPipelineRunTask.PipelineTask = pipelineTask
 		}
 	}
 }
 
+// taskStatusReplacements returns the replacements of the references to the status of each PipelineTask
+// in taskStatuses, $(tasks.<name>.status)
+func taskStatusReplacements(taskStatuses map[string]string) map[string]string {
+	replacements := make(map[string]string, len(taskStatuses))
+	for name, status := range taskStatuses {
+		replacements[fmt.Sprintf("%s.%s.status", v1beta1.ResultTaskPart, name)] = status
+	}
+	return replacements
+}
+
 // ApplyReplacements replaces placeholders for declared parameters with the specified replacements.
-func ApplyReplacements(p *v1beta1.PipelineSpec, replacements map[string]string, arrayReplacements map[string][]string) *v1beta1.PipelineSpec {
+func ApplyReplacements(p *v1beta1.PipelineSpec, replacements map[string]string, arrayReplacements map[string][]string, objectReplacements map[string]map[string]string) *v1beta1.PipelineSpec {
 	p = p.DeepCopy()
 
 	for i := range p.Tasks {
-		p.Tasks[i].Params = replaceParamValues(p.Tasks[i].Params, replacements, arrayReplacements)
+		p.Tasks[i].Params = replaceParamValues(p.Tasks[i].Params, replacements, arrayReplacements, objectReplacements)
+		p.Tasks[i].Matrix = replaceParamValues(p.Tasks[i].Matrix, replacements, arrayReplacements, objectReplacements)
 		for j := range p.Tasks[i].Conditions {
 			c := p.Tasks[i].Conditions[j]
-			c.Params = replaceParamValues(c.Params, replacements, arrayReplacements)
+			c.Params = replaceParamValues(c.Params, replacements, arrayReplacements, objectReplacements)
 		}
 		p.Tasks[i].WhenExpressions = p.Tasks[i].WhenExpressions.ReplaceWhenExpressionsVariables(replacements)
+		replaceConcurrencyKey(p.Tasks[i].Concurrency, replacements)
 	}
 
 	for i := range p.Finally {
-		p.Finally[i].Params = replaceParamValues(p.Finally[i].Params, replacements, arrayReplacements)
+		p.Finally[i].Params = replaceParamValues(p.Finally[i].Params, replacements, arrayReplacements, objectReplacements)
+		p.Finally[i].Matrix = replaceParamValues(p.Finally[i].Matrix, replacements, arrayReplacements, objectReplacements)
+		replaceConcurrencyKey(p.Finally[i].Concurrency, replacements)
 	}
 
 	return p
 }
 
-func replaceParamValues(params []v1beta1.Param, stringReplacements map[string]string, arrayReplacements map[string][]string) []v1beta1.Param {
+func replaceParamValues(params []v1beta1.Param, stringReplacements map[string]string, arrayReplacements map[string][]string, objectReplacements map[string]map[string]string) []v1beta1.Param {
 	for i := range params {
-		params[i].Value.ApplyReplacements(stringReplacements, arrayReplacements)
+		params[i].Value.ApplyReplacements(stringReplacements, arrayReplacements, objectReplacements)
 	}
 	return params
 }
+
+func replaceConcurrencyKey(c *v1beta1.Concurrency, replacements map[string]string) {
+	if c != nil {
+		c.Key = substitution.ApplyReplacements(c.Key, replacements)
+	}
+}
@@ -64,6 +64,31 @@ func TestApplyParameters(t *testing.T) {
 				},
 			}},
 		},
+	}, {
+		name: "single parameter in concurrency key",
+		original: v1beta1.PipelineSpec{
+			Params: []v1beta1.ParamSpec{
+				{Name: "env", Type: v1beta1.ParamTypeString},
+			},
+			Tasks: []v1beta1.PipelineTask{{
+				Concurrency: &v1beta1.Concurrency{Key: "deploy-$(params.env)"},
+			}},
+			Finally: []v1beta1.PipelineTask{{
+				Concurrency: &v1beta1.Concurrency{Key: "notify-$(params.env)"},
+			}},
+		},
+		params: []v1beta1.Param{{Name: "env", Value: *v1beta1.NewArrayOrString("prod")}},
+		expected: v1beta1.PipelineSpec{
+			Params: []v1beta1.ParamSpec{
+				{Name: "env", Type: v1beta1.ParamTypeString},
+			},
+			Tasks: []v1beta1.PipelineTask{{
+				Concurrency: &v1beta1.Concurrency{Key: "deploy-prod"},
+			}},
+			Finally: []v1beta1.PipelineTask{{
+				Concurrency: &v1beta1.Concurrency{Key: "notify-prod"},
+			}},
+		},
 	}, {
 		name: "single parameter with when expression",
 		original: v1beta1.PipelineSpec{
@@ -246,6 +271,37 @@ func TestApplyParameters(t *testing.T) {
 				},
 			}},
 		},
+	}, {
+		name: "object parameter",
+		original: v1beta1.PipelineSpec{
+			Params: []v1beta1.ParamSpec{{
+				Name: "image", Type: v1beta1.ParamTypeObject,
+				Properties: map[string]v1beta1.PropertySpec{"registry": {}, "digest": {}},
+				Default:    v1beta1.NewObject(map[string]string{"registry": "gcr.io", "digest": "sha256:0000"}),
+			}},
+			Tasks: []v1beta1.PipelineTask{{
+				Params: []v1beta1.Param{
+					{Name: "first-task-first-param", Value: *v1beta1.NewArrayOrString("$(params.image.registry)/app@$(params.image.digest)")},
+					{Name: "first-task-second-param", Value: *v1beta1.NewArrayOrString("$(params.image[*])")},
+					{Name: "first-task-third-param", Value: *v1beta1.NewObject(map[string]string{"url": "$(params.image.registry)/app"})},
+				},
+			}},
+		},
+		params: []v1beta1.Param{{Name: "image", Value: *v1beta1.NewObject(map[string]string{"registry": "quay.io", "digest": "sha256:1234"})}},
+		expected: v1beta1.PipelineSpec{
+			Params: []v1beta1.ParamSpec{{
+				Name: "image", Type: v1beta1.ParamTypeObject,
+				Properties: map[string]v1beta1.PropertySpec{"registry": {}, "digest": {}},
+				Default:    v1beta1.NewObject(map[string]string{"registry": "gcr.io", "digest": "sha256:0000"}),
+			}},
+			Tasks: []v1beta1.PipelineTask{{
+				Params: []v1beta1.Param{
+					{Name: "first-task-first-param", Value: *v1beta1.NewArrayOrString("quay.io/app@sha256:1234")},
+					{Name: "first-task-second-param", Value: *v1beta1.NewObject(map[string]string{"registry": "quay.io", "digest": "sha256:1234"})},
+					{Name: "first-task-third-param", Value: *v1beta1.NewObject(map[string]string{"url": "quay.io/app"})},
+				},
+			}},
+		},
 	}} {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
@@ -298,6 +354,42 @@ func TestApplyTaskResults_MinimalExpression(t *testing.T) {
 				}},
 			},
 		}},
+	}, {
+		name: "Test array result substitution on minimal variable substitution expression - params",
+		resolvedResultRefs: ResolvedResultRefs{{
+			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"module-a", "module-b"}},
+			ResultReference: v1beta1.ResultRef{
+				PipelineTask: "aTask",
+				Result:       "changed",
+			},
+			FromTaskRun: "aTaskRun",
+		}},
+		targets: PipelineRunState{{
+			PipelineTask: &v1beta1.PipelineTask{
+				Name:    "bTask",
+				TaskRef: &v1beta1.TaskRef{Name: "bTask"},
+				Params: []v1beta1.Param{{
+					Name:  "bParam",
+					Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"first", "$(tasks.aTask.results.changed[*])"}},
+				}, {
+					Name:  "cParam",
+					Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"$(tasks.aTask.results.changed)"}},
+				}},
+			},
+		}},
+		want: PipelineRunState{{
+			PipelineTask: &v1beta1.PipelineTask{
+				Name:    "bTask",
+				TaskRef: &v1beta1.TaskRef{Name: "bTask"},
+				Params: []v1beta1.Param{{
+					Name:  "bParam",
+					Value: *v1beta1.NewArrayOrString("first", "module-a", "module-b"),
+				}, {
+					Name:  "cParam",
+					Value: *v1beta1.NewArrayOrString("module-a", "module-b"),
+				}},
+			},
+		}},
 	}, {
 		name: "Test result substitution on minimal variable substitution expression - when expressions",
 		resolvedResultRefs: ResolvedResultRefs{{
@@ -558,3 +650,22 @@ func TestContext(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyConcurrencyKey(t *testing.T) {
+	spec := &v1beta1.PipelineSpec{
+		Params: []v1beta1.ParamSpec{
+			{Name: "env", Type: v1beta1.ParamTypeString},
+			{Name: "region", Type: v1beta1.ParamTypeString, Default: v1beta1.NewArrayOrString("eu")},
+		},
+	}
+	pr := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "foo"},
+		Spec: v1beta1.PipelineRunSpec{
+			Params: []v1beta1.Param{{Name: "env", Value: *v1beta1.NewArrayOrString("prod")}},
+		},
+	}
+	got := ApplyConcurrencyKey("$(context.pipeline.name)-$(params.env)-$(params.region)", spec, "deploy", pr)
+	if want := "deploy-prod-eu"; got != want {
+		t.Errorf("Expected concurrency key %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+// LimitParallel returns the tasks of next which can start without the PipelineRun running more
+// than maxParallel TaskRuns and child PipelineRuns at once, counting those of the PipelineRunState
+// which are not done yet. The TaskRuns which are held back are counted in the Queued field of their
+// tasks, and only some of the combinations of a matrixed task may start. There is no limit if
+// maxParallel is 0.
+func (state PipelineRunState) LimitParallel(next []*ResolvedPipelineRunTask, maxParallel int) []*ResolvedPipelineRunTask {
+	if maxParallel <= 0 {
+		return next
+	}
+	available := maxParallel - state.runningCount()
+	var limited []*ResolvedPipelineRunTask
+	for _, t := range next {
+		runs := 1
+		if t.IsMatrixed() {
+			runs = len(t.CombinationsToRun())
+		}
+		if available <= 0 {
+			t.Queued = runs
+			continue
+		}
+		if runs > available {
+			t.Queued = runs - available
+			runs = available
+		}
+		available -= runs
+		limited = append(limited, t)
+	}
+	return limited
+}
+
+// runningCount returns the number of TaskRuns and child PipelineRuns of the PipelineRunState which
+// were created and are not done yet
+func (state PipelineRunState) runningCount() int {
+	count := 0
+	for _, t := range state {
+		switch {
+		case t.IsPipeline():
+			if t.PipelineRun != nil && !t.PipelineRun.IsDone() {
+				count++
+			}
+		case t.IsMatrixed():
+			for _, tr := range t.TaskRuns {
+				if tr != nil && !tr.IsDone() {
+					count++
+				}
+			}
+		default:
+			if t.TaskRun != nil && !t.TaskRun.IsDone() {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// queuedCount returns the number of TaskRuns of the PipelineRunState which are held back by the
+// maxParallel limit of the PipelineRun
+func (state PipelineRunState) queuedCount() int {
+	count := 0
+	for _, t := range state {
+		count += t.Queued
+	}
+	return count
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+)
+
+func TestLimitParallel(t *testing.T) {
+	shards := v1beta1.PipelineTask{
+		Name:    "shards",
+		TaskRef: &v1beta1.TaskRef{Name: "task"},
+		Matrix:  []v1beta1.Param{{Name: "shard", Value: *v1beta1.NewArrayOrString("1", "2", "3")}},
+	}
+	for _, tc := range []struct {
+		name          string
+		maxParallel   int
+		wantNext      []string
+		wantQueued    map[string]int
+		wantShardsRun []int
+	}{{
+		name:          "no limit",
+		wantNext:      []string{"mytask2", "mytask3", "shards"},
+		wantQueued:    map[string]int{},
+		wantShardsRun: []int{0, 1, 2},
+	}, {
+		name:          "some combinations of a matrixed task queued",
+		maxParallel:   4,
+		wantNext:      []string{"mytask2", "mytask3", "shards"},
+		wantQueued:    map[string]int{"shards": 2},
+		wantShardsRun: []int{0},
+	}, {
+		name:          "tasks queued",
+		maxParallel:   2,
+		wantNext:      []string{"mytask2"},
+		wantQueued:    map[string]int{"mytask3": 1, "shards": 3},
+		wantShardsRun: []int{},
+	}, {
+		name:          "limit reached by the running tasks",
+		maxParallel:   1,
+		wantQueued:    map[string]int{"mytask2": 1, "mytask3": 1, "shards": 3},
+		wantShardsRun: []int{},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			state := PipelineRunState{{
+				PipelineTask: &pts[0],
+				TaskRunName:  "pipelinerun-mytask1",
+				TaskRun:      makeStarted(trs[0]),
+			}, {
+				PipelineTask: &pts[1],
+				TaskRunName:  "pipelinerun-mytask2",
+			}, {
+				PipelineTask: &pts[2],
+				TaskRunName:  "pipelinerun-mytask3",
+			}, {
+				PipelineTask: &shards,
+				TaskRunNames: []string{"pipelinerun-shards-0", "pipelinerun-shards-1", "pipelinerun-shards-2"},
+				TaskRuns:     make([]*v1beta1.TaskRun, 3),
+			}}
+			next := state.LimitParallel(state[1:], tc.maxParallel)
+			var gotNext []string
+			for _, rprt := range next {
+				gotNext = append(gotNext, rprt.PipelineTask.Name)
+			}
+			if d := cmp.Diff(tc.wantNext, gotNext); d != "" {
+				t.Errorf("Didn't get expected tasks to start %s", diff.PrintWantGot(d))
+			}
+			gotQueued := map[string]int{}
+			for _, rprt := range state {
+				if rprt.Queued > 0 {
+					gotQueued[rprt.PipelineTask.Name] = rprt.Queued
+				}
+			}
+			if d := cmp.Diff(tc.wantQueued, gotQueued); d != "" {
+				t.Errorf("Didn't get expected queued tasks %s", diff.PrintWantGot(d))
+			}
+			if d := cmp.Diff(tc.wantShardsRun, state[3].CombinationsToRun()); d != "" {
+				t.Errorf("Didn't get expected combinations to run %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
@@ -17,10 +17,14 @@ limitations under the License.
 package resources
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	clientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
+	"github.com/tektoncd/pipeline/pkg/remote"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -39,3 +43,45 @@ func (l *LocalPipelineRefResolver) GetPipeline(name string) (v1beta1.PipelineInt
 	}
 	return l.Tektonclient.TektonV1beta1().Pipelines(l.Namespace).Get(name, metav1.GetOptions{})
 }
+
+// RemotePipelineRefResolver uses a remote.Resolver, such as a git repository, to resolve a pipeline reference.
+type RemotePipelineRefResolver struct {
+	Resolver remote.Resolver
+}
+
+// GetPipeline will resolve a Pipeline stored in the remote location. It will return an error if it can't find an
+// appropriate Pipeline for any reason.
+func (r *RemotePipelineRefResolver) GetPipeline(name string) (v1beta1.PipelineInterface, error) {
+	obj, err := r.Resolver.Get("pipeline", name)
+	if err != nil {
+		return nil, err
+	}
+	switch p := obj.(type) {
+	case *v1beta1.Pipeline:
+		return p, nil
+	case *v1alpha1.Pipeline:
+		pipeline := &v1beta1.Pipeline{}
+		if err := p.ConvertTo(context.Background(), pipeline); err != nil {
+			return nil, err
+		}
+		return pipeline, nil
+	}
+	return nil, fmt.Errorf("failed to convert %T to a Pipeline", obj)
+}
+
+// GetPipelineFunc returns the function which resolves the given pipeline reference: from the remote location it
+// names if it uses a resolver configured by the RemoteOptions returned by getOptions, otherwise with getPipeline.
+func GetPipelineFunc(ctx context.Context, ref *v1beta1.PipelineRef, getPipeline GetPipeline, getOptions resources.GetRemoteOptions) (GetPipeline, error) {
+	if ref != nil && ref.IsRemote() {
+		opts, err := resources.LoadRemoteOptions(getOptions)
+		if err != nil {
+			return nil, err
+		}
+		resolver, err := resources.NewRemoteResolver(ctx, ref.ResolverRef, opts)
+		if err != nil {
+			return nil, err
+		}
+		return (&RemotePipelineRefResolver{Resolver: resolver}).GetPipeline, nil
+	}
+	return getPipeline, nil
+}
@@ -17,14 +17,19 @@
 package resources_test
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
 	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun/resources"
+	"github.com/tektoncd/pipeline/pkg/remote"
 	"github.com/tektoncd/pipeline/test/diff"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -81,3 +86,85 @@ func TestPipelineRef(t *testing.T) {
 		})
 	}
 }
+
+// fakeRemoteResolver returns the objects it holds, keyed by kind and name
+type fakeRemoteResolver map[string]runtime.Object
+
+func (f fakeRemoteResolver) List() ([]remote.ResolvedObject, error) {
+	return nil, nil
+}
+
+func (f fakeRemoteResolver) Get(kind, name string) (runtime.Object, error) {
+	if obj, ok := f[kind+"/"+name]; ok {
+		return obj, nil
+	}
+	return nil, fmt.Errorf("%s %s not found", kind, name)
+}
+
+func TestRemotePipelineRef(t *testing.T) {
+	resolver := fakeRemoteResolver{
+		"pipeline/simple":     tb.Pipeline("simple"),
+		"pipeline/alpha":      &v1alpha1.Pipeline{ObjectMeta: metav1.ObjectMeta{Name: "alpha"}},
+		"pipeline/not-a-task": tb.Task("not-a-task"),
+	}
+	testcases := []struct {
+		name     string
+		ref      *v1beta1.PipelineRef
+		expected runtime.Object
+		wantErr  bool
+	}{{
+		name:     "remote-pipeline",
+		ref:      &v1beta1.PipelineRef{Name: "simple"},
+		expected: tb.Pipeline("simple"),
+	}, {
+		name:     "remote-v1alpha1-pipeline",
+		ref:      &v1beta1.PipelineRef{Name: "alpha"},
+		expected: &v1beta1.Pipeline{ObjectMeta: metav1.ObjectMeta{Name: "alpha"}},
+	}, {
+		name:    "remote-pipeline-not-found",
+		ref:     &v1beta1.PipelineRef{Name: "missing"},
+		wantErr: true,
+	}, {
+		name:    "remote-object-not-a-pipeline",
+		ref:     &v1beta1.PipelineRef{Name: "not-a-task"},
+		wantErr: true,
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			rc := &resources.RemotePipelineRefResolver{Resolver: resolver}
+
+			pipeline, err := rc.GetPipeline(tc.ref.Name)
+			if tc.wantErr && err == nil {
+				t.Fatal("Expected error but found nil instead")
+			} else if !tc.wantErr && err != nil {
+				t.Fatalf("Received unexpected error ( %#v )", err)
+			}
+
+			if d := cmp.Diff(tc.expected, pipeline); tc.expected != nil && d != "" {
+				t.Error(diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestGetPipelineFunc(t *testing.T) {
+	getPipeline := func(name string) (v1beta1.PipelineInterface, error) { return tb.Pipeline(name), nil }
+
+	fn, err := resources.GetPipelineFunc(context.Background(), &v1beta1.PipelineRef{Name: "simple"}, getPipeline, nil)
+	if err != nil {
+		t.Fatalf("Received unexpected error ( %#v )", err)
+	}
+	pipeline, err := fn("simple")
+	if err != nil {
+		t.Fatalf("Received unexpected error ( %#v )", err)
+	}
+	if d := cmp.Diff(tb.Pipeline("simple"), pipeline); d != "" {
+		t.Error(diff.PrintWantGot(d))
+	}
+
+	ref := &v1beta1.PipelineRef{Name: "simple", ResolverRef: v1beta1.ResolverRef{Resolver: "bucket"}}
+	if _, err := resources.GetPipelineFunc(context.Background(), ref, getPipeline, nil); err == nil {
+		t.Error("Expected error for an unsupported resolver but found nil instead")
+	}
+}
@@ -20,9 +20,11 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -32,6 +34,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/names"
 	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
+	"github.com/tektoncd/pipeline/pkg/remote"
 )
 
 const (
@@ -44,12 +47,19 @@ const (
 type TaskNotFoundError struct {
 	Name string
 	Msg  string
+	// Err is the error which prevented the Task from being retrieved, if any
+	Err error
 }
 
 func (e *TaskNotFoundError) Error() string {
 	return fmt.Sprintf("Couldn't retrieve Task %q: %s", e.Name, e.Msg)
 }
 
+// Unwrap returns the error which prevented the Task from being retrieved.
+func (e *TaskNotFoundError) Unwrap() error {
+	return e.Err
+}
+
 // ConditionNotFoundError is used to track failures to the
 type ConditionNotFoundError struct {
 	Name string
@@ -62,76 +72,248 @@ func (e *ConditionNotFoundError) Error() string {
 
 // ResolvedPipelineRunTask contains a Task and its associated TaskRun, if it
 // exists. TaskRun can be nil to represent there being no TaskRun.
+// A matrixed PipelineTask is associated with one TaskRun per combination
+// instead, tracked in TaskRunNames and TaskRuns, and a PipelineTask running
+// a Pipeline is associated with a child PipelineRun, tracked in PipelineRunName
+// and PipelineRun.
 type ResolvedPipelineRunTask struct {
 	TaskRunName           string
 	TaskRun               *v1beta1.TaskRun
+	TaskRunNames          []string
+	TaskRuns              []*v1beta1.TaskRun
+	PipelineRunName       string
+	PipelineRun           *v1beta1.PipelineRun
 	PipelineTask          *v1beta1.PipelineTask
 	ResolvedTaskResources *resources.ResolvedTaskResources
 	// ConditionChecks ~~TaskRuns but for evaling conditions
 	ResolvedConditionChecks TaskConditionCheckState // Could also be a TaskRun or maybe just a Pod?
+	// GracefullyStopped is set when the PipelineRun was cancelled or stopped while still running its
+	// final tasks, or when its tasks timed out, in which case the PipelineTask is skipped if it is not
+	// a final task and has not started
+	GracefullyStopped bool
+	// RetriesStopped is set on the PipelineTasks of the dag once the PipelineRun is gracefully stopped,
+	// in which case their failed TaskRuns are not retried
+	RetriesStopped bool
+	// ResumedFrom is the name of the PipelineRun the status of the TaskRun was copied from, when the
+	// PipelineTask succeeded in the PipelineRun this one resumes from and is not run again
+	ResumedFrom string
+	// Queued is the number of TaskRuns of the PipelineTask which are ready to run but are held back
+	// by the maxParallel limit of the PipelineRun
+	Queued int
+	// Bundle is the bundle the Task was read from, pinned by its digest, so that the TaskRun runs the
+	// Task which was resolved even if the tag of the bundle is moved
+	Bundle string
+}
+
+// IsMatrixed returns true if the PipelineTask fans out into one TaskRun per combination of its Matrix
+func (t ResolvedPipelineRunTask) IsMatrixed() bool {
+	return t.PipelineTask != nil && t.PipelineTask.IsMatrixed()
+}
+
+// IsPipeline returns true if the PipelineTask runs a Pipeline in a child PipelineRun instead of a TaskRun
+func (t ResolvedPipelineRunTask) IsPipeline() bool {
+	return t.PipelineTask != nil && t.PipelineTask.IsPipeline()
 }
 
 func (t ResolvedPipelineRunTask) IsDone() bool {
-	if t.TaskRun == nil || t.PipelineTask == nil {
+	if t.PipelineTask == nil {
 		return false
 	}
-
-	status := t.TaskRun.Status.GetCondition(apis.ConditionSucceeded)
-	retriesDone := len(t.TaskRun.Status.RetriesStatus)
-	retries := t.PipelineTask.Retries
-	return status.IsTrue() || status.IsFalse() && retriesDone >= retries
+	if t.IsPipeline() {
+		return t.PipelineRun != nil && t.PipelineRun.IsDone()
+	}
+	if t.IsMatrixed() {
+		for _, tr := range t.TaskRuns {
+			if !isTaskRunDone(tr, t.retriedPipelineTask()) {
+				return false
+			}
+		}
+		return true
+	}
+	return isTaskRunDone(t.TaskRun, t.retriedPipelineTask())
 }
 
 // IsSuccessful returns true only if the taskrun itself has completed successfully
 func (t ResolvedPipelineRunTask) IsSuccessful() bool {
-	if t.TaskRun == nil {
-		return false
+	if t.IsPipeline() {
+		return t.PipelineRun != nil && t.PipelineRun.Status.GetCondition(apis.ConditionSucceeded).IsTrue()
 	}
-	c := t.TaskRun.Status.GetCondition(apis.ConditionSucceeded)
-	if c == nil {
-		return false
+	if t.IsMatrixed() {
+		for _, tr := range t.TaskRuns {
+			if !isTaskRunSuccessful(tr) {
+				return false
+			}
+		}
+		return true
 	}
-
-	return c.Status == corev1.ConditionTrue
+	return isTaskRunSuccessful(t.TaskRun)
 }
 
 // IsFailure returns true only if the taskrun itself has failed
+// A matrixed PipelineTask has failed once all of its TaskRuns are done and at least one of them has failed
 func (t ResolvedPipelineRunTask) IsFailure() bool {
-	if t.TaskRun == nil {
+	if t.IsPipeline() {
+		return t.PipelineRun != nil && t.PipelineRun.Status.GetCondition(apis.ConditionSucceeded).IsFalse()
+	}
+	if t.IsMatrixed() {
+		if !t.IsDone() {
+			return false
+		}
+		for _, tr := range t.TaskRuns {
+			if isTaskRunFailure(tr, t.retriedPipelineTask()) {
+				return true
+			}
+		}
 		return false
 	}
-	c := t.TaskRun.Status.GetCondition(apis.ConditionSucceeded)
-	retriesDone := len(t.TaskRun.Status.RetriesStatus)
-	retries := t.PipelineTask.Retries
-	return c.IsFalse() && retriesDone >= retries
+	return isTaskRunFailure(t.TaskRun, t.retriedPipelineTask())
 }
 
 // IsCancelled returns true only if the taskrun itself has cancelled
+// A matrixed PipelineTask is cancelled as soon as any of its TaskRuns is cancelled
 func (t ResolvedPipelineRunTask) IsCancelled() bool {
-	if t.TaskRun == nil {
+	if t.IsPipeline() {
+		return t.PipelineRun != nil && (t.PipelineRun.IsCancelled() || t.PipelineRun.IsGracefullyCancelled()) &&
+			t.PipelineRun.Status.GetCondition(apis.ConditionSucceeded).IsFalse()
+	}
+	if t.IsMatrixed() {
+		for _, tr := range t.TaskRuns {
+			if isTaskRunCancelled(tr) {
+				return true
+			}
+		}
 		return false
 	}
+	return isTaskRunCancelled(t.TaskRun)
+}
 
-	c := t.TaskRun.Status.GetCondition(apis.ConditionSucceeded)
-	if c == nil {
+// IsStarted returns true only if the PipelineRunTask itself has a TaskRun associated
+func (t ResolvedPipelineRunTask) IsStarted() bool {
+	if t.IsPipeline() {
+		return t.PipelineRun != nil && t.PipelineRun.Status.GetCondition(apis.ConditionSucceeded) != nil
+	}
+	if t.IsMatrixed() {
+		for _, tr := range t.TaskRuns {
+			if isTaskRunStarted(tr) {
+				return true
+			}
+		}
 		return false
 	}
+	return isTaskRunStarted(t.TaskRun)
+}
 
-	return c.IsFalse() && c.Reason == v1beta1.TaskRunReasonCancelled.String()
+// hasTaskRun returns true if a TaskRun has been created for the PipelineRunTask, or for any of
+// the combinations of a matrixed PipelineRunTask, or if a child PipelineRun has been created
+// for a PipelineRunTask running a Pipeline
+func (t ResolvedPipelineRunTask) hasTaskRun() bool {
+	if t.IsPipeline() {
+		return t.PipelineRun != nil
+	}
+	if t.IsMatrixed() {
+		for _, tr := range t.TaskRuns {
+			if tr != nil {
+				return true
+			}
+		}
+		return false
+	}
+	return t.TaskRun != nil
 }
 
-// IsStarted returns true only if the PipelineRunTask itself has a TaskRun associated
-func (t ResolvedPipelineRunTask) IsStarted() bool {
-	if t.TaskRun == nil {
+// CombinationsToRun returns the indices of the combinations of a matrixed PipelineRunTask that
+// still need a TaskRun to be created, either because none was created yet or because it failed
+// and is due for a retry, except those queued by the maxParallel limit of the PipelineRun
+func (t ResolvedPipelineRunTask) CombinationsToRun() []int {
+	var indices []int
+	for i, tr := range t.TaskRuns {
+		if tr == nil || isTaskRunRetryDue(tr, t.retriedPipelineTask()) {
+			indices = append(indices, i)
+		}
+	}
+	// the last combinations are held back by the maxParallel limit of the PipelineRun
+	if t.Queued > 0 && t.Queued <= len(indices) {
+		indices = indices[:len(indices)-t.Queued]
+	}
+	return indices
+}
+
+// retriedPipelineTask returns the PipelineTask whose retries apply to the TaskRuns of the
+// PipelineRunTask, which is not retried anymore once its retries are stopped
+func (t ResolvedPipelineRunTask) retriedPipelineTask() *v1beta1.PipelineTask {
+	if !t.RetriesStopped || t.PipelineTask == nil {
+		return t.PipelineTask
+	}
+	pt := t.PipelineTask.DeepCopy()
+	pt.Retries = 0
+	return pt
+}
+
+func isTaskRunDone(tr *v1beta1.TaskRun, pt *v1beta1.PipelineTask) bool {
+	if tr == nil {
 		return false
 	}
+	status := tr.Status.GetCondition(apis.ConditionSucceeded)
+	return status.IsTrue() || status.IsFalse() && !isTaskRunRetriable(tr, pt)
+}
 
-	c := t.TaskRun.Status.GetCondition(apis.ConditionSucceeded)
+func isTaskRunSuccessful(tr *v1beta1.TaskRun) bool {
+	if tr == nil {
+		return false
+	}
+	c := tr.Status.GetCondition(apis.ConditionSucceeded)
 	if c == nil {
 		return false
 	}
+	return c.Status == corev1.ConditionTrue
+}
 
-	return true
+func isTaskRunFailure(tr *v1beta1.TaskRun, pt *v1beta1.PipelineTask) bool {
+	if tr == nil {
+		return false
+	}
+	c := tr.Status.GetCondition(apis.ConditionSucceeded)
+	if !c.IsFalse() {
+		return false
+	}
+	retriesDone := len(tr.Status.RetriesStatus)
+	_, retried := RetryReason(tr, pt.RetryPolicy)
+	return retriesDone >= pt.Retries || !retried
+}
+
+func isTaskRunCancelled(tr *v1beta1.TaskRun) bool {
+	if tr == nil {
+		return false
+	}
+	c := tr.Status.GetCondition(apis.ConditionSucceeded)
+	if c == nil {
+		return false
+	}
+	return c.IsFalse() && c.Reason == v1beta1.TaskRunReasonCancelled.String()
+}
+
+func isTaskRunStarted(tr *v1beta1.TaskRun) bool {
+	if tr == nil {
+		return false
+	}
+	return tr.Status.GetCondition(apis.ConditionSucceeded) != nil
+}
+
+// isTaskRunRetriable returns true if the TaskRun failed, wasn't cancelled, hasn't exhausted its retries
+// and failed in a way the retry policy of the PipelineTask retries
+func isTaskRunRetriable(tr *v1beta1.TaskRun, pt *v1beta1.PipelineTask) bool {
+	status := tr.Status.GetCondition(apis.ConditionSucceeded)
+	if status == nil || !status.IsFalse() {
+		return false
+	}
+	if tr.IsCancelled() || status.Reason == v1beta1.TaskRunReasonCancelled.String() || status.Reason == ReasonConditionCheckFailed {
+		return false
+	}
+	if len(tr.Status.RetriesStatus) >= pt.Retries {
+		return false
+	}
+	_, retried := RetryReason(tr, pt.RetryPolicy)
+	return retried
 }
 
 func (t *ResolvedPipelineRunTask) checkParentsDone(state PipelineRunState, d *dag.Graph) bool {
@@ -150,39 +332,57 @@ func (t *ResolvedPipelineRunTask) checkParentsDone(state PipelineRunState, d *da
 	return true
 }
 
+// whenExpressions returns the when expressions of the PipelineTask, with the statuses of the PipelineTasks
+// in taskStatuses substituted
+func (t *ResolvedPipelineRunTask) whenExpressions(taskStatuses map[string]string) v1beta1.WhenExpressions {
+	return t.PipelineTask.WhenExpressions.DeepCopy().ReplaceWhenExpressionsVariables(taskStatusReplacements(taskStatuses))
+}
+
 // Skip returns true if a PipelineTask will not be run because
 // (1) its When Expressions evaluated to false
 // (2) its Condition Checks failed
 // (3) its parent task was skipped
-// (4) Pipeline is in stopping state (one of the PipelineTasks failed)
+// (4) Pipeline is in stopping state (one of the PipelineTasks failed), unless its When Expressions read
+// the status of other PipelineTasks and none of them was cancelled
 // Note that this means Skip returns false if a conditionCheck is in progress
 func (t *ResolvedPipelineRunTask) Skip(state PipelineRunState, d *dag.Graph) bool {
+	return t.skippingReason(state, d) != ""
+}
+
+// skippingReason returns the reason why a PipelineTask will not be run, as described in Skip,
+// or an empty reason if it is not skipped
+func (t *ResolvedPipelineRunTask) skippingReason(state PipelineRunState, d *dag.Graph) v1beta1.SkippingReason {
 	// it already has TaskRun associated with it - PipelineTask not skipped
 	if t.IsStarted() {
-		return false
+		return ""
 	}
 
 	// Check if conditionChecks have failed, if so task is skipped
 	if len(t.ResolvedConditionChecks) > 0 {
 		if t.ResolvedConditionChecks.IsDone() && !t.ResolvedConditionChecks.IsSuccess() {
-			return true
+			return v1beta1.ConditionChecksSkip
 		}
 	}
 
 	// Check if the when expressions are false, based on the input's relationship to the values
 	if t.checkParentsDone(state, d) {
 		if len(t.PipelineTask.WhenExpressions) > 0 {
-			if !t.PipelineTask.WhenExpressions.HaveVariables() {
-				if !t.PipelineTask.WhenExpressions.AllowsExecution() {
-					return true
+			taskStatuses := state.taskStatuses()
+			whenExpressions := t.whenExpressions(taskStatuses)
+			if !whenExpressions.HaveVariables() {
+				if !whenExpressions.AllowsExecution(taskStatuses) {
+					return v1beta1.WhenExpressionsSkip
 				}
 			}
 		}
 	}
 
-	// Skip the PipelineTask if pipeline is in stopping state
+	// Skip the PipelineTask if pipeline is in stopping state, unless its when expressions read the
+	// status of other PipelineTasks and it is only stopping because some of them failed
 	if isTaskInGraph(t.PipelineTask.Name, d) && state.IsStopping(d) {
-		return true
+		if len(t.PipelineTask.WhenExpressions.TaskStatusRefs()) == 0 || !state.isFailing(d) {
+			return v1beta1.StoppingSkip
+		}
 	}
 
 	stateMap := state.ToMap()
@@ -192,16 +392,19 @@ func (t *ResolvedPipelineRunTask) Skip(state PipelineRunState, d *dag.Graph) boo
 	if isTaskInGraph(t.PipelineTask.Name, d) {
 		for _, p := range node.Prev {
 			if stateMap[p.Task.HashKey()].Skip(state, d) {
-				return true
+				return v1beta1.ParentTasksSkip
 			}
 		}
 	}
-	return false
+	return ""
 }
 
 // GetTaskRun is a function that will retrieve the TaskRun name.
 type GetTaskRun func(name string) (*v1beta1.TaskRun, error)
 
+// GetPipelineRun is a function that will retrieve the PipelineRun name.
+type GetPipelineRun func(name string) (*v1beta1.PipelineRun, error)
+
 // GetResourcesFromBindings will retrieve all Resources bound in PipelineRun pr and return a map
 // from the declared name of the PipelineResource (which is how the PipelineResource will
 // be referred to in the PipelineRun) to the PipelineResource, obtained via getResource.
@@ -296,13 +499,21 @@ func ValidateServiceaccountMapping(p *v1beta1.PipelineSpec, pr *v1beta1.Pipeline
 // instances from getTask. If it is unable to retrieve an instance of a referenced Task, it
 // will return an error, otherwise it returns a list of all of the Tasks retrieved.
 // It will retrieve the Resources needed for the TaskRun using the mapping of providedResources.
+// Tasks running a Pipeline are not resolved further than their child PipelineRun, retrieved
+// from getPipelineRun, since the Pipeline is resolved when reconciling the child PipelineRun.
+// Tasks referenced from a bundle are read from the resolver returned by getBundle, which the
+// caller wraps with OnceEachBundle to create one resolver per bundle and service account, and
+// Tasks fetched by a resolver are retrieved with the RemoteOptions returned by getRemoteOptions.
 func ResolvePipelineRun(
 	ctx context.Context,
 	pipelineRun v1beta1.PipelineRun,
 	getTask resources.GetTask,
 	getTaskRun resources.GetTaskRun,
 	getClusterTask resources.GetClusterTask,
+	getBundle resources.GetBundle,
+	getRemoteOptions resources.GetRemoteOptions,
 	getCondition GetCondition,
+	getPipelineRun GetPipelineRun,
 	tasks []v1beta1.PipelineTask,
 	providedResources map[string]*resourcev1alpha1.PipelineResource,
 ) (PipelineRunState, error) {
@@ -312,8 +523,27 @@ func ResolvePipelineRun(
 		pt := tasks[i]
 
 		rprt := ResolvedPipelineRunTask{
-			PipelineTask: &pt,
-			TaskRunName:  GetTaskRunName(pipelineRun.Status.TaskRuns, pt.Name, pipelineRun.Name),
+			PipelineTask:      &pt,
+			GracefullyStopped: pipelineRun.IsGracefullyCancelled() || pipelineRun.IsGracefullyStopped() || pipelineRun.HaveTasksTimedOut(),
+		}
+		if pt.IsPipeline() {
+			rprt.PipelineRunName = GetPipelineRunName(pipelineRun.Status.PipelineRuns, pt.Name, pipelineRun.Name)
+			childPipelineRun, err := getPipelineRun(rprt.PipelineRunName)
+			if err != nil {
+				if !errors.IsNotFound(err) {
+					return nil, fmt.Errorf("error retrieving PipelineRun %s: %w", rprt.PipelineRunName, err)
+				}
+			}
+			if childPipelineRun != nil {
+				rprt.PipelineRun = childPipelineRun
+			}
+			state = append(state, &rprt)
+			continue
+		}
+		if pt.IsMatrixed() {
+			rprt.TaskRunNames = GetMatrixTaskRunNames(pipelineRun.Status.TaskRuns, pt.Name, pipelineRun.Name, len(pt.MatrixCombinations()))
+		} else {
+			rprt.TaskRunName = GetTaskRunName(pipelineRun.Status.TaskRuns, pt.Name, pipelineRun.Name)
 		}
 
 		// Find the Task that this PipelineTask is using
@@ -326,15 +556,19 @@ func ResolvePipelineRun(
 		)
 
 		if pt.TaskRef != nil {
-			if pt.TaskRef.Kind == v1beta1.ClusterTaskKind {
-				t, err = getClusterTask(pt.TaskRef.Name)
-			} else {
-				t, err = getTask(pt.TaskRef.Name)
+			var get resources.GetTask
+			get, err = resources.GetTaskFunc(ctx, pt.TaskRef, pipelineRun.GetServiceAccountName(pt.Name), getTask, getClusterTask, getBundle, getRemoteOptions)
+			if err == nil {
+				t, err = get(pt.TaskRef.Name)
+			}
+			if err == nil && pt.TaskRef.Bundle != "" {
+				rprt.Bundle, err = BundleDigest(pt.TaskRef.Bundle, pipelineRun.GetServiceAccountName(pt.Name), getBundle)
 			}
 			if err != nil {
 				return nil, &TaskNotFoundError{
 					Name: pt.TaskRef.Name,
 					Msg:  err.Error(),
+					Err:  err,
 				}
 			}
 			spec = t.TaskSpec()
@@ -351,14 +585,33 @@ func ResolvePipelineRun(
 
 		rprt.ResolvedTaskResources = rtr
 
-		taskRun, err := getTaskRun(rprt.TaskRunName)
-		if err != nil {
-			if !errors.IsNotFound(err) {
-				return nil, fmt.Errorf("error retrieving TaskRun %s: %w", rprt.TaskRunName, err)
+		if pt.IsMatrixed() {
+			rprt.TaskRuns = make([]*v1beta1.TaskRun, len(rprt.TaskRunNames))
+			for j, taskRunName := range rprt.TaskRunNames {
+				taskRun, err := getTaskRun(taskRunName)
+				if err != nil {
+					if !errors.IsNotFound(err) {
+						return nil, fmt.Errorf("error retrieving TaskRun %s: %w", taskRunName, err)
+					}
+				}
+				if taskRun != nil {
+					rprt.TaskRuns[j] = taskRun
+				}
+			}
+		} else if prtrs := pipelineRun.Status.TaskRuns[rprt.TaskRunName]; prtrs != nil && prtrs.ResumedFrom != "" && prtrs.Status != nil {
+			// the PipelineTask was not run again, its status was copied from the resumed PipelineRun
+			rprt.TaskRun = resumedTaskRun(rprt.TaskRunName, pipelineRun.Namespace, prtrs.Status)
+			rprt.ResumedFrom = prtrs.ResumedFrom
+		} else {
+			taskRun, err := getTaskRun(rprt.TaskRunName)
+			if err != nil {
+				if !errors.IsNotFound(err) {
+					return nil, fmt.Errorf("error retrieving TaskRun %s: %w", rprt.TaskRunName, err)
+				}
+			}
+			if taskRun != nil {
+				rprt.TaskRun = taskRun
 			}
-		}
-		if taskRun != nil {
-			rprt.TaskRun = taskRun
 		}
 
 		// Get all conditions that this pipelineTask will be using, if any
@@ -401,6 +654,37 @@ func GetTaskRunName(taskRunsStatus map[string]*v1beta1.PipelineRunTaskRunStatus,
 	return names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(fmt.Sprintf("%s-%s", prName, ptName))
 }
 
+// GetPipelineRunName should return a unique name for a child `PipelineRun` if one has not already been defined, and the existing one otherwise.
+func GetPipelineRunName(pipelineRunsStatus map[string]*v1beta1.PipelineRunPipelineRunStatus, ptName, prName string) string {
+	for k, v := range pipelineRunsStatus {
+		if v.PipelineTaskName == ptName {
+			return k
+		}
+	}
+
+	return names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(fmt.Sprintf("%s-%s", prName, ptName))
+}
+
+// GetMatrixTaskRunNames returns one name per combination of a matrixed `PipelineTask`. The names
+// share a base name obtained from GetTaskRunName, suffixed with the index of the combination, so
+// that the names of existing `TaskRuns` are found again and new ones are derived from them.
+func GetMatrixTaskRunNames(taskRunsStatus map[string]*v1beta1.PipelineRunTaskRunStatus, ptName, prName string, combinationCount int) []string {
+	base := GetTaskRunName(taskRunsStatus, ptName, prName)
+	if _, ok := taskRunsStatus[base]; ok {
+		// This is the name of the TaskRun of one of the combinations, strip its index
+		base = base[:strings.LastIndex(base, "-")]
+	} else if overflow := len(base) + len(fmt.Sprintf("-%d", combinationCount-1)) - validation.DNS1123LabelMaxLength; overflow > 0 {
+		// Make room for the index, keeping the random suffix of the generated name
+		suffix := strings.LastIndex(base, "-")
+		base = strings.TrimRight(base[:suffix-overflow], "-") + base[suffix:]
+	}
+	taskRunNames := make([]string, combinationCount)
+	for i := range taskRunNames {
+		taskRunNames[i] = fmt.Sprintf("%s-%d", base, i)
+	}
+	return taskRunNames
+}
+
 func resolveConditionChecks(pt *v1beta1.PipelineTask, taskRunStatus map[string]*v1beta1.PipelineRunTaskRunStatus, taskRunName string, getTaskRun resources.GetTaskRun, getCondition GetCondition, providedResources map[string]*resourcev1alpha1.PipelineResource) ([]*ResolvedConditionCheck, error) {
 	rccs := []*ResolvedConditionCheck{}
 	for i := range pt.Conditions {
@@ -490,3 +774,34 @@ func ResolvePipelineTaskResources(pt v1beta1.PipelineTask, ts *v1beta1.TaskSpec,
 	}
 	return &rtr, nil
 }
+
+// BundleDigest returns the reference of bundle pinned by the digest its resolver read, or bundle itself
+// if the resolver can't tell its digest.
+func BundleDigest(bundle, serviceAccountName string, getBundle resources.GetBundle) (string, error) {
+	resolver, err := getBundle(bundle, serviceAccountName)
+	if err != nil {
+		return "", err
+	}
+	if r, ok := resolver.(interface{ Digest() (string, error) }); ok {
+		return r.Digest()
+	}
+	return bundle, nil
+}
+
+// OnceEachBundle wraps getBundle so that the resolver of each bundle and service account is created once, and
+// the Pipeline and the tasks of a bundle referenced by a tag are all read from the same image.
+func OnceEachBundle(getBundle resources.GetBundle) resources.GetBundle {
+	resolvers := map[[2]string]remote.Resolver{}
+	return func(bundle, serviceAccountName string) (remote.Resolver, error) {
+		key := [2]string{bundle, serviceAccountName}
+		if resolver, ok := resolvers[key]; ok {
+			return resolver, nil
+		}
+		resolver, err := getBundle(bundle, serviceAccountName)
+		if err != nil {
+			return nil, err
+		}
+		resolvers[key] = resolver
+		return resolver, nil
+	}
+}
@@ -31,11 +31,13 @@ import (
 	resourcev1alpha1 "github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
+	"github.com/tektoncd/pipeline/pkg/remote"
 	"github.com/tektoncd/pipeline/test/diff"
 	"github.com/tektoncd/pipeline/test/names"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/selection"
 	"knative.dev/pkg/apis"
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
@@ -166,6 +168,11 @@ var conditionChecks = []v1beta1.TaskRun{{
 	Spec: v1beta1.TaskRunSpec{},
 }}
 
+// getPipelineRun is used by the tests which don't have PipelineTasks running a Pipeline
+func getPipelineRun(name string) (*v1beta1.PipelineRun, error) {
+	return nil, kerrors.NewNotFound(v1beta1.Resource("pipelinerun"), name)
+}
+
 func makeStarted(tr v1beta1.TaskRun) *v1beta1.TaskRun {
 	newTr := newTaskRun(tr)
 	newTr.Status.Conditions[0].Status = corev1.ConditionUnknown
@@ -977,7 +984,7 @@ func TestResolvePipelineRun(t *testing.T) {
 	getClusterTask := func(name string) (v1beta1.TaskInterface, error) { return nil, nil }
 	getCondition := func(name string) (*v1alpha1.Condition, error) { return nil, nil }
 
-	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, p.Spec.Tasks, providedResources)
+	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, nil, nil, getCondition, getPipelineRun, p.Spec.Tasks, providedResources)
 	if err != nil {
 		t.Fatalf("Error getting tasks for fake pipeline %s: %s", p.ObjectMeta.Name, err)
 	}
@@ -1056,7 +1063,7 @@ func TestResolvePipelineRun_PipelineTaskHasNoResources(t *testing.T) {
 			Name: "pipelinerun",
 		},
 	}
-	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, pts, providedResources)
+	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, nil, nil, getCondition, getPipelineRun, pts, providedResources)
 	if err != nil {
 		t.Fatalf("Did not expect error when resolving PipelineRun without Resources: %v", err)
 	}
@@ -1077,6 +1084,81 @@ func TestResolvePipelineRun_PipelineTaskHasNoResources(t *testing.T) {
 	}
 }
 
+// bundleResolver returns the same Task for every name
+type bundleResolver struct {
+	task *v1beta1.Task
+}
+
+func (b bundleResolver) List() ([]remote.ResolvedObject, error) {
+	return nil, nil
+}
+
+func (b bundleResolver) Get(kind, name string) (runtime.Object, error) {
+	return b.task, nil
+}
+
+func (b bundleResolver) Digest() (string, error) {
+	return "gcr.io/tekton/tasks@sha256:0123", nil
+}
+
+func TestResolvePipelineRun_TasksInBundle(t *testing.T) {
+	bundleTask := tb.Task("bundle-task", tb.TaskSpec(tb.Step("myimage", tb.StepName("mystep"))))
+	pts := []v1beta1.PipelineTask{{
+		Name:    "mytask1",
+		TaskRef: &v1beta1.TaskRef{Name: "bundle-task", Bundle: "gcr.io/tekton/tasks:v1"},
+	}, {
+		Name:    "mytask2",
+		TaskRef: &v1beta1.TaskRef{Name: "bundle-task", Bundle: "gcr.io/tekton/tasks:v1"},
+	}, {
+		Name:    "mytask3",
+		TaskRef: &v1beta1.TaskRef{Name: "bundle-task", Bundle: "gcr.io/tekton/tasks:v1"},
+	}, {
+		Name:    "mytask4",
+		TaskRef: &v1beta1.TaskRef{Name: "task"},
+	}}
+	pr := v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pipelinerun",
+		},
+		Spec: v1beta1.PipelineRunSpec{
+			ServiceAccountName: "sa",
+			ServiceAccountNames: []v1beta1.PipelineRunSpecServiceAccountName{{
+				TaskName:           "mytask3",
+				ServiceAccountName: "other-sa",
+			}},
+		},
+	}
+
+	getTask := func(name string) (v1beta1.TaskInterface, error) { return task, nil }
+	getTaskRun := func(name string) (*v1beta1.TaskRun, error) { return nil, nil }
+	getClusterTask := func(name string) (v1beta1.TaskInterface, error) { return clustertask, nil }
+	getCondition := func(name string) (*v1alpha1.Condition, error) { return nil, nil }
+	var calls []string
+	getBundle := func(bundle, serviceAccountName string) (remote.Resolver, error) {
+		calls = append(calls, bundle+" as "+serviceAccountName)
+		return bundleResolver{task: bundleTask}, nil
+	}
+
+	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, OnceEachBundle(getBundle), nil, getCondition, getPipelineRun, pts, map[string]*resourcev1alpha1.PipelineResource{})
+	if err != nil {
+		t.Fatalf("Did not expect error when resolving PipelineRun with Tasks in a bundle: %v", err)
+	}
+	wantCalls := []string{"gcr.io/tekton/tasks:v1 as sa", "gcr.io/tekton/tasks:v1 as other-sa"}
+	if d := cmp.Diff(wantCalls, calls); d != "" {
+		t.Errorf("Expected the bundle to be retrieved once for each service account %s", diff.PrintWantGot(d))
+	}
+	for i, want := range []string{"bundle-task", "bundle-task", "bundle-task", task.Name} {
+		if got := pipelineState[i].ResolvedTaskResources.TaskName; got != want {
+			t.Errorf("Expected PipelineTask %s to resolve to Task %s, got %s", pts[i].Name, want, got)
+		}
+	}
+	for i, want := range []string{"gcr.io/tekton/tasks@sha256:0123", "gcr.io/tekton/tasks@sha256:0123", "gcr.io/tekton/tasks@sha256:0123", ""} {
+		if got := pipelineState[i].Bundle; got != want {
+			t.Errorf("Expected PipelineTask %s to be pinned to bundle %q, got %q", pts[i].Name, want, got)
+		}
+	}
+}
+
 func TestResolvePipelineRun_TaskDoesntExist(t *testing.T) {
 	pts := []v1beta1.PipelineTask{{
 		Name:    "mytask1",
@@ -1103,7 +1185,7 @@ func TestResolvePipelineRun_TaskDoesntExist(t *testing.T) {
 			Name: "pipelinerun",
 		},
 	}
-	_, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, pts, providedResources)
+	_, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, nil, nil, getCondition, getPipelineRun, pts, providedResources)
 	switch err := err.(type) {
 	case nil:
 		t.Fatalf("Expected error getting non-existent Tasks for Pipeline %s but got none", p.Name)
@@ -1149,7 +1231,7 @@ func TestResolvePipelineRun_ResourceBindingsDontExist(t *testing.T) {
 					Name: "pipelinerun",
 				},
 			}
-			_, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, tt.p.Spec.Tasks, providedResources)
+			_, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, nil, nil, getCondition, getPipelineRun, tt.p.Spec.Tasks, providedResources)
 			if err == nil {
 				t.Fatalf("Expected error when bindings are in incorrect state for Pipeline %s but got none", p.Name)
 			}
@@ -1199,7 +1281,7 @@ func TestResolvePipelineRun_withExistingTaskRuns(t *testing.T) {
 	getClusterTask := func(name string) (v1beta1.TaskInterface, error) { return nil, nil }
 	getTaskRun := func(name string) (*v1beta1.TaskRun, error) { return nil, nil }
 	getCondition := func(name string) (*v1alpha1.Condition, error) { return nil, nil }
-	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, p.Spec.Tasks, providedResources)
+	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, nil, nil, getCondition, getPipelineRun, p.Spec.Tasks, providedResources)
 	if err != nil {
 		t.Fatalf("Error getting tasks for fake pipeline %s: %s", p.ObjectMeta.Name, err)
 	}
@@ -1252,7 +1334,7 @@ func TestResolvedPipelineRun_PipelineTaskHasOptionalResources(t *testing.T) {
 	getClusterTask := func(name string) (v1beta1.TaskInterface, error) { return nil, nil }
 	getCondition := func(name string) (*v1alpha1.Condition, error) { return nil, nil }
 
-	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, p.Spec.Tasks, providedResources)
+	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, nil, nil, getCondition, getPipelineRun, p.Spec.Tasks, providedResources)
 	if err != nil {
 		t.Fatalf("Error getting tasks for fake pipeline %s: %s", p.ObjectMeta.Name, err)
 	}
@@ -1355,7 +1437,7 @@ func TestResolveConditionChecks(t *testing.T) {
 
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
-			pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, tc.getTaskRun, getClusterTask, getCondition, pts, providedResources)
+			pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, tc.getTaskRun, getClusterTask, nil, nil, getCondition, getPipelineRun, pts, providedResources)
 			if err != nil {
 				t.Fatalf("Did not expect error when resolving PipelineRun without Conditions: %v", err)
 			}
@@ -1456,7 +1538,7 @@ func TestResolveConditionChecks_MultipleConditions(t *testing.T) {
 
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
-			pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, tc.getTaskRun, getClusterTask, getCondition, pts, providedResources)
+			pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, tc.getTaskRun, getClusterTask, nil, nil, getCondition, getPipelineRun, pts, providedResources)
 			if err != nil {
 				t.Fatalf("Did not expect error when resolving PipelineRun without Conditions: %v", err)
 			}
@@ -1500,7 +1582,7 @@ func TestResolveConditionChecks_ConditionDoesNotExist(t *testing.T) {
 		},
 	}
 
-	_, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, pts, providedResources)
+	_, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, nil, nil, getCondition, getPipelineRun, pts, providedResources)
 
 	switch err := err.(type) {
 	case nil:
@@ -1569,7 +1651,7 @@ func TestResolveConditionCheck_UseExistingConditionCheckName(t *testing.T) {
 		},
 	}
 
-	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, pts, providedResources)
+	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, nil, nil, getCondition, getPipelineRun, pts, providedResources)
 	if err != nil {
 		t.Fatalf("Did not expect error when resolving PipelineRun without Conditions: %v", err)
 	}
@@ -1645,7 +1727,7 @@ func TestResolvedConditionCheck_WithResources(t *testing.T) {
 
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
-			pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, pts, tc.providedResources)
+			pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, nil, nil, getCondition, getPipelineRun, pts, tc.providedResources)
 
 			if tc.wantErr {
 				if err == nil {
@@ -1780,9 +1862,335 @@ func TestResolvePipeline_WhenExpressions(t *testing.T) {
 	}
 
 	t.Run("When Expressions exist", func(t *testing.T) {
-		_, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, getCondition, pts, providedResources)
+		_, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, nil, nil, getCondition, getPipelineRun, pts, providedResources)
 		if err != nil {
 			t.Fatalf("Did not expect error when resolving PipelineRun: %v", err)
 		}
 	})
 }
+
+func TestGetMatrixTaskRunNames(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		taskRunsStatus   map[string]*v1beta1.PipelineRunTaskRunStatus
+		ptName           string
+		prName           string
+		combinationCount int
+		want             []string
+	}{{
+		name:             "new names",
+		taskRunsStatus:   map[string]*v1beta1.PipelineRunTaskRunStatus{},
+		ptName:           "mytask",
+		prName:           "pipelinerun",
+		combinationCount: 3,
+		want:             []string{"pipelinerun-mytask-9l9zj-0", "pipelinerun-mytask-9l9zj-1", "pipelinerun-mytask-9l9zj-2"},
+	}, {
+		name: "existing names",
+		taskRunsStatus: map[string]*v1beta1.PipelineRunTaskRunStatus{
+			"pipelinerun-mytask-abcde-1":  {PipelineTaskName: "mytask"},
+			"pipelinerun-othertask-xyz12": {PipelineTaskName: "othertask"},
+		},
+		ptName:           "mytask",
+		prName:           "pipelinerun",
+		combinationCount: 2,
+		want:             []string{"pipelinerun-mytask-abcde-0", "pipelinerun-mytask-abcde-1"},
+	}, {
+		name:             "new names truncated",
+		taskRunsStatus:   map[string]*v1beta1.PipelineRunTaskRunStatus{},
+		ptName:           "mytask-with-a-really-long-name-to-trigger-truncation",
+		prName:           "pipelinerun",
+		combinationCount: 11,
+		want: []string{
+			"pipelinerun-mytask-with-a-really-long-name-to-trigger-9l9zj-0",
+			"pipelinerun-mytask-with-a-really-long-name-to-trigger-9l9zj-1",
+			"pipelinerun-mytask-with-a-really-long-name-to-trigger-9l9zj-2",
+			"pipelinerun-mytask-with-a-really-long-name-to-trigger-9l9zj-3",
+			"pipelinerun-mytask-with-a-really-long-name-to-trigger-9l9zj-4",
+			"pipelinerun-mytask-with-a-really-long-name-to-trigger-9l9zj-5",
+			"pipelinerun-mytask-with-a-really-long-name-to-trigger-9l9zj-6",
+			"pipelinerun-mytask-with-a-really-long-name-to-trigger-9l9zj-7",
+			"pipelinerun-mytask-with-a-really-long-name-to-trigger-9l9zj-8",
+			"pipelinerun-mytask-with-a-really-long-name-to-trigger-9l9zj-9",
+			"pipelinerun-mytask-with-a-really-long-name-to-trigger-9l9zj-10",
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			names.TestingSeed()
+			got := GetMatrixTaskRunNames(tc.taskRunsStatus, tc.ptName, tc.prName, tc.combinationCount)
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("GetMatrixTaskRunNames: %s", diff.PrintWantGot(d))
+			}
+			for _, name := range got {
+				if len(name) > 63 {
+					t.Errorf("TaskRun name %q is longer than 63 characters", name)
+				}
+			}
+		})
+	}
+}
+
+func TestResolvePipelineRun_Matrix(t *testing.T) {
+	names.TestingSeed()
+	pts := []v1beta1.PipelineTask{{
+		Name:    "mytask",
+		TaskRef: &v1beta1.TaskRef{Name: "task"},
+		Matrix: []v1beta1.Param{{
+			Name:  "platform",
+			Value: *v1beta1.NewArrayOrString("linux", "mac"),
+		}},
+	}}
+	pr := v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pipelinerun",
+		},
+	}
+	existing := makeSucceeded(v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "pipelinerun-mytask-9l9zj-1"}})
+
+	getTask := func(name string) (v1beta1.TaskInterface, error) { return task, nil }
+	getClusterTask := func(name string) (v1beta1.TaskInterface, error) { return nil, nil }
+	getTaskRun := func(name string) (*v1beta1.TaskRun, error) {
+		if name == existing.Name {
+			return existing, nil
+		}
+		return nil, kerrors.NewNotFound(v1beta1.Resource("taskrun"), name)
+	}
+	getCondition := func(name string) (*v1alpha1.Condition, error) { return nil, nil }
+	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, nil, nil, getCondition, getPipelineRun, pts, nil)
+	if err != nil {
+		t.Fatalf("Error getting tasks for fake pipeline: %s", err)
+	}
+	expectedState := PipelineRunState{{
+		PipelineTask: &pts[0],
+		TaskRunNames: []string{"pipelinerun-mytask-9l9zj-0", "pipelinerun-mytask-9l9zj-1"},
+		TaskRuns:     []*v1beta1.TaskRun{nil, existing},
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskName: task.Name,
+			TaskSpec: &task.Spec,
+			Inputs:   map[string]*resourcev1alpha1.PipelineResource{},
+			Outputs:  map[string]*resourcev1alpha1.PipelineResource{},
+		},
+	}}
+	if d := cmp.Diff(expectedState, pipelineState, cmpopts.IgnoreUnexported(v1beta1.TaskRunSpec{})); d != "" {
+		t.Fatalf("Expected to get current pipeline state %v, but actual differed %s", expectedState, diff.PrintWantGot(d))
+	}
+	if d := cmp.Diff([]int{0}, pipelineState[0].CombinationsToRun()); d != "" {
+		t.Errorf("Unexpected combinations to run: %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestResolvedPipelineRunTask_Matrix(t *testing.T) {
+	pt := v1beta1.PipelineTask{
+		Name:    "mytask",
+		TaskRef: &v1beta1.TaskRef{Name: "task"},
+		Matrix: []v1beta1.Param{{
+			Name:  "platform",
+			Value: *v1beta1.NewArrayOrString("linux", "mac"),
+		}},
+	}
+	for _, tc := range []struct {
+		name                                                      string
+		taskRuns                                                  []*v1beta1.TaskRun
+		started, done, successful, failure, cancelled, hasTaskRun bool
+		combinationsToRun                                         []int
+	}{{
+		name:              "none started",
+		taskRuns:          []*v1beta1.TaskRun{nil, nil},
+		combinationsToRun: []int{0, 1},
+	}, {
+		name:              "one started",
+		taskRuns:          []*v1beta1.TaskRun{makeStarted(trs[0]), nil},
+		started:           true,
+		hasTaskRun:        true,
+		combinationsToRun: []int{1},
+	}, {
+		name:       "one succeeded one running",
+		taskRuns:   []*v1beta1.TaskRun{makeSucceeded(trs[0]), makeStarted(trs[1])},
+		started:    true,
+		hasTaskRun: true,
+	}, {
+		name:       "all succeeded",
+		taskRuns:   []*v1beta1.TaskRun{makeSucceeded(trs[0]), makeSucceeded(trs[1])},
+		started:    true,
+		done:       true,
+		successful: true,
+		hasTaskRun: true,
+	}, {
+		name:       "one failed one running",
+		taskRuns:   []*v1beta1.TaskRun{makeFailed(trs[0]), makeStarted(trs[1])},
+		started:    true,
+		hasTaskRun: true,
+	}, {
+		name:       "one failed one succeeded",
+		taskRuns:   []*v1beta1.TaskRun{makeFailed(trs[0]), makeSucceeded(trs[1])},
+		started:    true,
+		done:       true,
+		failure:    true,
+		hasTaskRun: true,
+	}, {
+		name:       "one cancelled",
+		taskRuns:   []*v1beta1.TaskRun{withCancelled(makeFailed(trs[0])), makeStarted(trs[1])},
+		started:    true,
+		cancelled:  true,
+		hasTaskRun: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			rprt := ResolvedPipelineRunTask{
+				PipelineTask: &pt,
+				TaskRunNames: []string{"mytask-0", "mytask-1"},
+				TaskRuns:     tc.taskRuns,
+			}
+			if !rprt.IsMatrixed() {
+				t.Errorf("expected the task to be matrixed")
+			}
+			if got := rprt.IsStarted(); got != tc.started {
+				t.Errorf("IsStarted: expected %t but got %t", tc.started, got)
+			}
+			if got := rprt.IsDone(); got != tc.done {
+				t.Errorf("IsDone: expected %t but got %t", tc.done, got)
+			}
+			if got := rprt.IsSuccessful(); got != tc.successful {
+				t.Errorf("IsSuccessful: expected %t but got %t", tc.successful, got)
+			}
+			if got := rprt.IsFailure(); got != tc.failure {
+				t.Errorf("IsFailure: expected %t but got %t", tc.failure, got)
+			}
+			if got := rprt.IsCancelled(); got != tc.cancelled {
+				t.Errorf("IsCancelled: expected %t but got %t", tc.cancelled, got)
+			}
+			if got := rprt.hasTaskRun(); got != tc.hasTaskRun {
+				t.Errorf("hasTaskRun: expected %t but got %t", tc.hasTaskRun, got)
+			}
+			if d := cmp.Diff(tc.combinationsToRun, rprt.CombinationsToRun()); d != "" {
+				t.Errorf("CombinationsToRun: %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestResolvePipelineRun_PipelineTask(t *testing.T) {
+	pts := []v1beta1.PipelineTask{{
+		Name:        "mypipeline",
+		PipelineRef: &v1beta1.PipelineRef{Name: "pipeline"},
+	}}
+	pr := v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pipelinerun",
+		},
+		Status: v1beta1.PipelineRunStatus{
+			PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+				PipelineRuns: map[string]*v1beta1.PipelineRunPipelineRunStatus{
+					"pipelinerun-mypipeline-abcde": {PipelineTaskName: "mypipeline"},
+				},
+			},
+		},
+	}
+	child := &v1beta1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "pipelinerun-mypipeline-abcde"}}
+
+	getTask := func(name string) (v1beta1.TaskInterface, error) {
+		return nil, fmt.Errorf("unexpected call to getTask for %s", name)
+	}
+	getClusterTask := func(name string) (v1beta1.TaskInterface, error) { return nil, nil }
+	getTaskRun := func(name string) (*v1beta1.TaskRun, error) {
+		return nil, fmt.Errorf("unexpected call to getTaskRun for %s", name)
+	}
+	getCondition := func(name string) (*v1alpha1.Condition, error) { return nil, nil }
+	getChildPipelineRun := func(name string) (*v1beta1.PipelineRun, error) {
+		if name == child.Name {
+			return child, nil
+		}
+		return nil, kerrors.NewNotFound(v1beta1.Resource("pipelinerun"), name)
+	}
+	pipelineState, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, nil, nil, getCondition, getChildPipelineRun, pts, nil)
+	if err != nil {
+		t.Fatalf("Error getting tasks for fake pipeline: %s", err)
+	}
+	expectedState := PipelineRunState{{
+		PipelineTask:    &pts[0],
+		PipelineRunName: "pipelinerun-mypipeline-abcde",
+		PipelineRun:     child,
+	}}
+	if d := cmp.Diff(expectedState, pipelineState); d != "" {
+		t.Fatalf("Expected to get current pipeline state %v, but actual differed %s", expectedState, diff.PrintWantGot(d))
+	}
+}
+
+func TestResolvedPipelineRunTask_PipelineTask(t *testing.T) {
+	pt := v1beta1.PipelineTask{
+		Name:        "mypipeline",
+		PipelineRef: &v1beta1.PipelineRef{Name: "pipeline"},
+	}
+	withCondition := func(status corev1.ConditionStatus) *v1beta1.PipelineRun {
+		pr := &v1beta1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "pipelinerun-mypipeline"}}
+		pr.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: status})
+		return pr
+	}
+	cancelled := withCondition(corev1.ConditionFalse)
+	cancelled.Spec.Status = v1beta1.PipelineRunSpecStatusCancelled
+	for _, tc := range []struct {
+		name                                                      string
+		pipelineRun                                               *v1beta1.PipelineRun
+		started, done, successful, failure, cancelled, hasTaskRun bool
+	}{{
+		name: "not created",
+	}, {
+		name:        "created",
+		pipelineRun: &v1beta1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "pipelinerun-mypipeline"}},
+		hasTaskRun:  true,
+	}, {
+		name:        "running",
+		pipelineRun: withCondition(corev1.ConditionUnknown),
+		started:     true,
+		hasTaskRun:  true,
+	}, {
+		name:        "succeeded",
+		pipelineRun: withCondition(corev1.ConditionTrue),
+		started:     true,
+		done:        true,
+		successful:  true,
+		hasTaskRun:  true,
+	}, {
+		name:        "failed",
+		pipelineRun: withCondition(corev1.ConditionFalse),
+		started:     true,
+		done:        true,
+		failure:     true,
+		hasTaskRun:  true,
+	}, {
+		name:        "cancelled",
+		pipelineRun: cancelled,
+		started:     true,
+		done:        true,
+		failure:     true,
+		cancelled:   true,
+		hasTaskRun:  true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			rprt := ResolvedPipelineRunTask{
+				PipelineTask:    &pt,
+				PipelineRunName: "pipelinerun-mypipeline",
+				PipelineRun:     tc.pipelineRun,
+			}
+			if !rprt.IsPipeline() {
+				t.Errorf("expected the task to run a pipeline")
+			}
+			if got := rprt.IsStarted(); got != tc.started {
+				t.Errorf("IsStarted: expected %t but got %t", tc.started, got)
+			}
+			if got := rprt.IsDone(); got != tc.done {
+				t.Errorf("IsDone: expected %t but got %t", tc.done, got)
+			}
+			if got := rprt.IsSuccessful(); got != tc.successful {
+				t.Errorf("IsSuccessful: expected %t but got %t", tc.successful, got)
+			}
+			if got := rprt.IsFailure(); got != tc.failure {
+				t.Errorf("IsFailure: expected %t but got %t", tc.failure, got)
+			}
+			if got := rprt.IsCancelled(); got != tc.cancelled {
+				t.Errorf("IsCancelled: expected %t but got %t", tc.cancelled, got)
+			}
+			if got := rprt.hasTaskRun(); got != tc.hasTaskRun {
+				t.Errorf("hasTaskRun: expected %t but got %t", tc.hasTaskRun, got)
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/apis"
 )
@@ -55,7 +56,7 @@ func (state PipelineRunState) IsDone() bool {
 // IsBeforeFirstTaskRun returns true if the PipelineRun has not yet started its first TaskRun
 func (state PipelineRunState) IsBeforeFirstTaskRun() bool {
 	for _, t := range state {
-		if t.TaskRun != nil {
+		if t.hasTaskRun() {
 			return false
 		}
 	}
@@ -63,10 +64,14 @@ func (state PipelineRunState) IsBeforeFirstTaskRun() bool {
 }
 
 // IsStopping returns true if the PipelineRun won't be scheduling any new Task because
-// at least one task already failed or was cancelled in the specified dag
+// at least one task already failed or was cancelled in the specified dag, or because
+// the PipelineRun was gracefully cancelled or stopped
 func (state PipelineRunState) IsStopping(d *dag.Graph) bool {
 	for _, t := range state {
 		if isTaskInGraph(t.PipelineTask.Name, d) {
+			if t.GracefullyStopped {
+				return true
+			}
 			if t.IsCancelled() {
 				return true
 			}
@@ -78,29 +83,99 @@ func (state PipelineRunState) IsStopping(d *dag.Graph) bool {
 	return false
 }
 
+// isFailing returns true if the PipelineRun won't be scheduling any new Task only because at least
+// one task failed in the specified dag, rather than because a task was cancelled or the PipelineRun
+// was gracefully cancelled or stopped
+func (state PipelineRunState) isFailing(d *dag.Graph) bool {
+	failing := false
+	for _, t := range state {
+		if isTaskInGraph(t.PipelineTask.Name, d) {
+			if t.GracefullyStopped || t.IsCancelled() {
+				return false
+			}
+			if t.IsFailure() {
+				failing = true
+			}
+		}
+	}
+	return failing
+}
+
+// taskStatuses returns the status of each PipelineTask of state, keyed by PipelineTask name, which the
+// when expressions read as $(tasks.<name>.status)
+func (state PipelineRunState) taskStatuses() map[string]string {
+	statuses := make(map[string]string, len(state))
+	for _, t := range state {
+		switch {
+		case t.IsSuccessful():
+			statuses[t.PipelineTask.Name] = v1beta1.PipelineTaskStatusSucceeded
+		case t.IsFailure():
+			statuses[t.PipelineTask.Name] = v1beta1.PipelineTaskStatusFailed
+		default:
+			statuses[t.PipelineTask.Name] = v1beta1.PipelineTaskStatusNone
+		}
+	}
+	return statuses
+}
+
+// StopRetries stops the retries of the PipelineTasks of the specified dag once the PipelineRun is
+// gracefully cancelled or stopped or its tasks timed out, so that no new TaskRun is created for them
+// and their failed TaskRuns are done, which lets the final tasks run
+func (state PipelineRunState) StopRetries(d *dag.Graph) {
+	for _, t := range state {
+		if t.GracefullyStopped && isTaskInGraph(t.PipelineTask.Name, d) {
+			t.RetriesStopped = true
+		}
+	}
+}
+
 // GetNextTasks returns a list of tasks which should be executed next i.e.
 // a list of tasks from candidateTasks which aren't yet indicated in state to be running and
 // a list of cancelled/failed tasks from candidateTasks which haven't exhausted their retries
 func (state PipelineRunState) GetNextTasks(candidateTasks sets.String) []*ResolvedPipelineRunTask {
 	tasks := []*ResolvedPipelineRunTask{}
 	for _, t := range state {
-		if _, ok := candidateTasks[t.PipelineTask.Name]; ok && t.TaskRun == nil {
-			tasks = append(tasks, t)
+		if _, ok := candidateTasks[t.PipelineTask.Name]; !ok {
+			continue
 		}
-		if _, ok := candidateTasks[t.PipelineTask.Name]; ok && t.TaskRun != nil {
-			status := t.TaskRun.Status.GetCondition(apis.ConditionSucceeded)
-			if status != nil && status.IsFalse() {
-				if !(t.TaskRun.IsCancelled() || status.Reason == v1beta1.TaskRunReasonCancelled.String() || status.Reason == ReasonConditionCheckFailed) {
-					if len(t.TaskRun.Status.RetriesStatus) < t.PipelineTask.Retries {
-						tasks = append(tasks, t)
-					}
-				}
+		if t.IsPipeline() {
+			// a child PipelineRun is never retried
+			if t.PipelineRun == nil {
+				tasks = append(tasks, t)
 			}
+			continue
+		}
+		if t.IsMatrixed() {
+			// a matrixed task is next as long as any of its combinations still needs a TaskRun,
+			// unless one of them was cancelled
+			if len(t.CombinationsToRun()) > 0 && !t.IsCancelled() {
+				tasks = append(tasks, t)
+			}
+			continue
+		}
+		if t.TaskRun == nil || isTaskRunRetryDue(t.TaskRun, t.retriedPipelineTask()) {
+			tasks = append(tasks, t)
 		}
 	}
 	return tasks
 }
 
+// GetStatusGuardedTasks returns the tasks of the specified dag which should be executed next while the
+// PipelineRun is failing: the tasks whose when expressions read the status of other tasks and whose
+// parent tasks are done, since they may only run once the tasks they read the status of failed
+func (state PipelineRunState) GetStatusGuardedTasks(d *dag.Graph) []*ResolvedPipelineRunTask {
+	if !state.isFailing(d) {
+		return nil
+	}
+	candidateTasks := sets.NewString()
+	for _, t := range state {
+		if isTaskInGraph(t.PipelineTask.Name, d) && len(t.PipelineTask.WhenExpressions.TaskStatusRefs()) > 0 && t.checkParentsDone(state, d) {
+			candidateTasks.Insert(t.PipelineTask.Name)
+		}
+	}
+	return state.GetNextTasks(candidateTasks)
+}
+
 // SuccessfulOrSkippedDAGTasks returns a list of the names of all of the PipelineTasks in state
 // which have successfully completed or skipped
 func (state PipelineRunState) SuccessfulOrSkippedDAGTasks(d *dag.Graph) []string {
@@ -120,7 +195,7 @@ func (state PipelineRunState) SuccessfulOrSkippedDAGTasks(d *dag.Graph) []string
 func (state PipelineRunState) checkTasksDone(d *dag.Graph) bool {
 	for _, t := range state {
 		if isTaskInGraph(t.PipelineTask.Name, d) {
-			if t.TaskRun == nil {
+			if !t.hasTaskRun() {
 				// this task might have skipped if taskRun is nil
 				// continue and ignore if this task was skipped
 				// skipped task is considered part of done
@@ -137,6 +212,51 @@ func (state PipelineRunState) checkTasksDone(d *dag.Graph) bool {
 	return true
 }
 
+// TasksCompletionTime returns the time the last TaskRun or child PipelineRun of the specified dag
+// completed, or the start time of the PipelineRun if none of its tasks ran, once all of its tasks
+// are done. It returns nil while they are not done, or when they are only done because the
+// PipelineRun was gracefully cancelled or stopped or its tasks timed out, which skips the tasks
+// which did not start.
+func (state PipelineRunState) TasksCompletionTime(pr *v1beta1.PipelineRun, d *dag.Graph) *metav1.Time {
+	running := make(PipelineRunState, 0, len(state))
+	for _, t := range state {
+		rprt := *t
+		rprt.GracefullyStopped, rprt.RetriesStopped = false, false
+		running = append(running, &rprt)
+	}
+	if !running.checkTasksDone(d) {
+		return nil
+	}
+	completionTime := pr.Status.StartTime
+	latest := func(t *metav1.Time) {
+		if t != nil && (completionTime == nil || t.After(completionTime.Time)) {
+			completionTime = t
+		}
+	}
+	for _, t := range state {
+		if !isTaskInGraph(t.PipelineTask.Name, d) {
+			continue
+		}
+		if t.TaskRun != nil {
+			latest(t.TaskRun.Status.CompletionTime)
+		}
+		for _, tr := range t.TaskRuns {
+			if tr != nil {
+				latest(tr.Status.CompletionTime)
+			}
+		}
+		if t.PipelineRun != nil {
+			latest(t.PipelineRun.Status.CompletionTime)
+		}
+		for _, c := range t.ResolvedConditionChecks {
+			if c.ConditionCheck != nil {
+				latest(c.ConditionCheck.Status.CompletionTime)
+			}
+		}
+	}
+	return completionTime
+}
+
 // GetFinalTasks returns a list of final tasks without any taskRun associated with it
 // GetFinalTasks returns final tasks only when all DAG tasks have finished executing successfully or skipped or
 // any one DAG task resulted in failure
@@ -170,7 +290,7 @@ func (state PipelineRunState) GetPipelineConditionStatus(pr *v1beta1.PipelineRun
 			Type:    apis.ConditionSucceeded,
 			Status:  corev1.ConditionFalse,
 			Reason:  v1beta1.PipelineRunReasonTimedOut.String(),
-			Message: fmt.Sprintf("PipelineRun %q failed to finish within %q", pr.Name, pr.Spec.Timeout.Duration.String()),
+			Message: fmt.Sprintf("PipelineRun %q failed to finish within %q", pr.Name, pr.PipelineTimeout().Duration.String()),
 		}
 	}
 
@@ -218,11 +338,33 @@ func (state PipelineRunState) GetPipelineConditionStatus(pr *v1beta1.PipelineRun
 		}
 	}
 
+	// a gracefully cancelled or stopped pipeline reports the mode it was cancelled or stopped with
+	// until it is done, and never succeeds
+	gracefulReason := ""
+	switch {
+	case pr.IsGracefullyCancelled():
+		gracefulReason = v1beta1.PipelineRunReasonCancelledRunFinally.String()
+	case pr.IsGracefullyStopped():
+		gracefulReason = v1beta1.PipelineRunReasonStoppedRunFinally.String()
+	}
+
 	if reflect.DeepEqual(allTasks, withStatusTasks) {
 		status := corev1.ConditionTrue
 		if failedTasks > 0 || cancelledTasks > 0 {
 			status = corev1.ConditionFalse
 		}
+		if gracefulReason != "" {
+			status = corev1.ConditionFalse
+			reason = gracefulReason
+		} else if pr.HaveTasksTimedOut() {
+			logger.Infof("All TaskRuns have finished for PipelineRun %s after its tasks timed out", pr.Name)
+			return &apis.Condition{
+				Type:    apis.ConditionSucceeded,
+				Status:  corev1.ConditionFalse,
+				Reason:  v1beta1.PipelineRunReasonTimedOut.String(),
+				Message: fmt.Sprintf("PipelineRun %q failed to finish its tasks within %q", pr.Name, pr.TasksTimeout().Duration.String()),
+			}
+		}
 		logger.Infof("All TaskRuns have finished for PipelineRun %s so it has finished", pr.Name)
 		return &apis.Condition{
 			Type:   apis.ConditionSucceeded,
@@ -237,26 +379,42 @@ func (state PipelineRunState) GetPipelineConditionStatus(pr *v1beta1.PipelineRun
 	// transition pipeline into stopping state when one of the tasks(dag/final) cancelled or one of the dag tasks failed
 	// for a pipeline with final tasks, single dag task failure does not transition to interim stopping state
 	// pipeline stays in running state until all final tasks are done before transitioning to failed state
-	if cancelledTasks > 0 || (failedTasks > 0 && state.checkTasksDone(dfinally)) {
+	// a paused pipeline does not schedule any new task until it is resumed
+	if gracefulReason != "" {
+		reason = gracefulReason
+	} else if cancelledTasks > 0 || (failedTasks > 0 && state.checkTasksDone(dfinally)) {
 		reason = v1beta1.PipelineRunReasonStopping.String()
+	} else if pr.IsPaused() {
+		reason = v1beta1.PipelineRunReasonPaused.String()
 	} else {
 		reason = v1beta1.PipelineRunReasonRunning.String()
 	}
+	message := fmt.Sprintf("Tasks Completed: %d (Failed: %d, Cancelled %d), Incomplete: %d, Skipped: %d",
+		len(withStatusTasks)-len(skipTasks), failedTasks, cancelledTasks, len(allTasks)-len(withStatusTasks), len(skipTasks))
+	if queued := state.queuedCount(); queued > 0 {
+		message = fmt.Sprintf("%s, %d tasks queued by maxParallel", message, queued)
+	}
 	return &apis.Condition{
-		Type:   apis.ConditionSucceeded,
-		Status: corev1.ConditionUnknown,
-		Reason: reason,
-		Message: fmt.Sprintf("Tasks Completed: %d (Failed: %d, Cancelled %d), Incomplete: %d, Skipped: %d",
-			len(withStatusTasks)-len(skipTasks), failedTasks, cancelledTasks, len(allTasks)-len(withStatusTasks), len(skipTasks)),
+		Type:    apis.ConditionSucceeded,
+		Status:  corev1.ConditionUnknown,
+		Reason:  reason,
+		Message: message,
 	}
 }
 
 func (state PipelineRunState) GetSkippedTasks(pr *v1beta1.PipelineRun, d *dag.Graph) []v1beta1.SkippedTask {
 	skipped := []v1beta1.SkippedTask{}
 	for _, rprt := range state {
-		if rprt.Skip(state, d) {
-			skipped = append(skipped, v1beta1.SkippedTask{Name: rprt.PipelineTask.Name})
+		reason := rprt.skippingReason(state, d)
+		if reason == "" {
+			continue
+		}
+		skippedTask := v1beta1.SkippedTask{Name: rprt.PipelineTask.Name, Reason: reason}
+		if reason == v1beta1.WhenExpressionsSkip {
+			taskStatuses := state.taskStatuses()
+			skippedTask.Message = rprt.whenExpressions(taskStatuses).SkipMessage(taskStatuses)
 		}
+		skipped = append(skipped, skippedTask)
 	}
 	return skipped
 }
@@ -264,6 +422,22 @@ func (state PipelineRunState) GetSkippedTasks(pr *v1beta1.PipelineRun, d *dag.Gr
 func (state PipelineRunState) GetTaskRunsStatus(pr *v1beta1.PipelineRun) map[string]*v1beta1.PipelineRunTaskRunStatus {
 	status := make(map[string]*v1beta1.PipelineRunTaskRunStatus)
 	for _, rprt := range state {
+		if rprt.IsMatrixed() {
+			for i, tr := range rprt.TaskRuns {
+				if tr == nil {
+					continue
+				}
+				prtrs := pr.Status.TaskRuns[tr.Name]
+				if prtrs == nil {
+					prtrs = &v1beta1.PipelineRunTaskRunStatus{
+						PipelineTaskName: rprt.PipelineTask.Name,
+					}
+				}
+				prtrs.Status = &rprt.TaskRuns[i].Status
+				status[rprt.TaskRunNames[i]] = prtrs
+			}
+			continue
+		}
 		if rprt.TaskRun == nil && rprt.ResolvedConditionChecks == nil {
 			continue
 		}
@@ -281,6 +455,9 @@ func (state PipelineRunState) GetTaskRunsStatus(pr *v1beta1.PipelineRun) map[str
 		if rprt.TaskRun != nil {
 			prtrs.Status = &rprt.TaskRun.Status
 		}
+		if rprt.ResumedFrom != "" {
+			prtrs.ResumedFrom = rprt.ResumedFrom
+		}
 
 		if len(rprt.ResolvedConditionChecks) > 0 {
 			cStatus := make(map[string]*v1beta1.PipelineRunConditionCheckStatus)
@@ -310,6 +487,25 @@ func (state PipelineRunState) GetTaskRunsStatus(pr *v1beta1.PipelineRun) map[str
 	return status
 }
 
+// GetPipelineRunsStatus returns the status of the child PipelineRuns of the PipelineTasks running a Pipeline,
+// keyed by the name of the child PipelineRun
+func (state PipelineRunState) GetPipelineRunsStatus() map[string]*v1beta1.PipelineRunPipelineRunStatus {
+	var status map[string]*v1beta1.PipelineRunPipelineRunStatus
+	for _, rprt := range state {
+		if rprt.PipelineRun == nil {
+			continue
+		}
+		if status == nil {
+			status = make(map[string]*v1beta1.PipelineRunPipelineRunStatus)
+		}
+		status[rprt.PipelineRunName] = &v1beta1.PipelineRunPipelineRunStatus{
+			PipelineTaskName: rprt.PipelineTask.Name,
+			Status:           &rprt.PipelineRun.Status,
+		}
+	}
+	return status
+}
+
 // Check if a PipelineTask belongs to the specified Graph
 func isTaskInGraph(pipelineTaskName string, d *dag.Graph) bool {
 	if _, ok := d.Nodes[pipelineTaskName]; ok {
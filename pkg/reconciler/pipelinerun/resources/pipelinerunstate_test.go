@@ -54,6 +54,15 @@ func TestIsDone(t *testing.T) {
 		},
 	}}
 
+	var taskCancelledWithRetriesLeftState = PipelineRunState{{
+		PipelineTask: &pts[4], // 2 retries needed
+		TaskRunName:  "pipelinerun-mytask1",
+		TaskRun:      withCancelled(makeFailed(trs[0])),
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}}
+
 	var taskRunningState = PipelineRunState{{
 		PipelineTask: &pts[4],
 		TaskRunName:  "pipelinerun-mytask1",
@@ -123,6 +132,11 @@ func TestIsDone(t *testing.T) {
 		state:      taskCancelledBySpecState,
 		expected:   false,
 		ptExpected: []bool{false},
+	}, {
+		name:       "tasks-cancelled-with-retries-left",
+		state:      taskCancelledWithRetriesLeftState,
+		expected:   true,
+		ptExpected: []bool{true},
 	}, {
 		name:       "tasks-running-no-candidates",
 		state:      taskRunningState,
@@ -413,6 +427,89 @@ func TestGetNextTaskWithRetries(t *testing.T) {
 	}
 }
 
+func TestGetNextTaskWithRetries_Stopped(t *testing.T) {
+	state := PipelineRunState{{
+		PipelineTask:      &pts[4], // 2 retries needed
+		TaskRunName:       "pipelinerun-mytask1",
+		TaskRun:           withRetries(makeFailed(trs[0])),
+		GracefullyStopped: true,
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}}
+	d, err := dag.Build(v1beta1.PipelineTaskList([]v1beta1.PipelineTask{pts[4]}))
+	if err != nil {
+		t.Fatalf("Unexpected error while building DAG: %v", err)
+	}
+	state.StopRetries(d)
+
+	if next := state.GetNextTasks(sets.NewString("mytask5")); len(next) != 0 {
+		t.Errorf("Expected the failed task not to be retried once the PipelineRun is stopped, got %v", next)
+	}
+	if !state[0].IsDone() || !state[0].IsFailure() {
+		t.Errorf("Expected the failed task to be done and failed once the PipelineRun is stopped")
+	}
+}
+
+func TestPipelineRunState_TasksCompletionTime(t *testing.T) {
+	startTime := time.Now().Add(-time.Hour)
+	completedAt := func(tr *v1beta1.TaskRun, d time.Duration) *v1beta1.TaskRun {
+		tr.Status.CompletionTime = &metav1.Time{Time: startTime.Add(d)}
+		return tr
+	}
+	tcs := []struct {
+		name     string
+		state    PipelineRunState
+		expected *metav1.Time
+	}{{
+		name: "all tasks done",
+		state: PipelineRunState{{
+			PipelineTask: &pts[0],
+			TaskRunName:  "pipelinerun-mytask1",
+			TaskRun:      completedAt(makeSucceeded(trs[0]), 20*time.Minute),
+		}, {
+			PipelineTask: &pts[1],
+			TaskRunName:  "pipelinerun-mytask2",
+			TaskRun:      completedAt(makeFailed(trs[1]), 10*time.Minute),
+		}},
+		expected: &metav1.Time{Time: startTime.Add(20 * time.Minute)},
+	}, {
+		name: "task not started",
+		state: PipelineRunState{{
+			PipelineTask: &pts[0],
+			TaskRunName:  "pipelinerun-mytask1",
+			TaskRun:      completedAt(makeSucceeded(trs[0]), 20*time.Minute),
+		}, {
+			PipelineTask: &pts[1],
+		}},
+	}, {
+		name: "task skipped because the tasks timed out",
+		state: PipelineRunState{{
+			PipelineTask:      &pts[0],
+			TaskRunName:       "pipelinerun-mytask1",
+			TaskRun:           completedAt(makeSucceeded(trs[0]), 20*time.Minute),
+			GracefullyStopped: true,
+		}, {
+			PipelineTask:      &pts[1],
+			GracefullyStopped: true,
+		}},
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := DagFromState(tc.state)
+			if err != nil {
+				t.Fatalf("Unexpected error while building DAG for state %v: %v", tc.state, err)
+			}
+			pr := &v1beta1.PipelineRun{Status: v1beta1.PipelineRunStatus{PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+				StartTime: &metav1.Time{Time: startTime},
+			}}}
+			if d := cmp.Diff(tc.expected, tc.state.TasksCompletionTime(pr, d)); d != "" {
+				t.Errorf("Unexpected completion time of the tasks %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
 func TestPipelineRunState_SuccessfulOrSkippedDAGTasks(t *testing.T) {
 	tcs := []struct {
 		name          string
@@ -897,6 +994,65 @@ func TestGetPipelineConditionStatus(t *testing.T) {
 	}
 }
 
+func TestGetPipelineConditionStatus_Paused(t *testing.T) {
+	tcs := []struct {
+		name               string
+		state              PipelineRunState
+		expectedStatus     corev1.ConditionStatus
+		expectedReason     string
+		expectedSucceeded  int
+		expectedIncomplete int
+		expectedFailed     int
+	}{{
+		name:               "one-task-finished",
+		state:              oneFinishedState,
+		expectedStatus:     corev1.ConditionUnknown,
+		expectedReason:     v1beta1.PipelineRunReasonPaused.String(),
+		expectedSucceeded:  1,
+		expectedIncomplete: 1,
+	}, {
+		name:              "all-finished",
+		state:             allFinishedState,
+		expectedStatus:    corev1.ConditionTrue,
+		expectedReason:    v1beta1.PipelineRunReasonSuccessful.String(),
+		expectedSucceeded: 2,
+	}, {
+		name: "one-task-failed-one-running",
+		state: PipelineRunState{{
+			TaskRunName:  "runningTaskRun",
+			PipelineTask: &pts[1],
+			TaskRun:      makeStarted(trs[1]),
+		}, {
+			TaskRunName:  "failedTaskRun",
+			PipelineTask: &pts[0],
+			TaskRun:      makeFailed(trs[0]),
+		}},
+		expectedStatus:     corev1.ConditionUnknown,
+		expectedReason:     v1beta1.PipelineRunReasonStopping.String(),
+		expectedFailed:     1,
+		expectedIncomplete: 1,
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := tb.PipelineRun("somepipelinerun", tb.PipelineRunSpec("somepipeline", tb.PipelineRunPaused))
+			d, err := DagFromState(tc.state)
+			if err != nil {
+				t.Fatalf("Unexpected error while buildig DAG for state %v: %v", tc.state, err)
+			}
+			c := tc.state.GetPipelineConditionStatus(pr, zap.NewNop().Sugar(), d, &dag.Graph{})
+			wantCondition := &apis.Condition{
+				Type:    apis.ConditionSucceeded,
+				Status:  tc.expectedStatus,
+				Reason:  tc.expectedReason,
+				Message: getExpectedMessage(tc.expectedStatus, tc.expectedSucceeded, tc.expectedIncomplete, 0, tc.expectedFailed, 0),
+			}
+			if d := cmp.Diff(wantCondition, c); d != "" {
+				t.Fatalf("Mismatch in condition %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
 func TestGetPipelineConditionStatus_WithFinalTasks(t *testing.T) {
 
 	// pipeline state with one DAG successful, one final task failed
@@ -1030,3 +1186,119 @@ func TestGetPipelineConditionStatus_PipelineTimeouts(t *testing.T) {
 		t.Fatalf("Expected to get status %s but got %s for state %v", corev1.ConditionFalse, c.Status, oneFinishedState)
 	}
 }
+
+func TestGetSkippedTasks(t *testing.T) {
+	state := PipelineRunState{{
+		PipelineTask: &pts[10], // mytask11 has a when expression which evaluates to false
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}, {
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:     "guarded-by-cel",
+			TaskRef:  &v1beta1.TaskRef{Name: "task"},
+			RunAfter: []string{"mytask11"},
+		},
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}, {
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:    "guarded-by-false-cel",
+			TaskRef: &v1beta1.TaskRef{Name: "task"},
+			WhenExpressions: []v1beta1.WhenExpression{{
+				CEL: "'prod' != 'prod'",
+			}},
+		},
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}, {
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:    "guarded-by-true-cel",
+			TaskRef: &v1beta1.TaskRef{Name: "task"},
+			WhenExpressions: []v1beta1.WhenExpression{{
+				CEL: "'dev' != 'prod'",
+			}},
+		},
+		ResolvedTaskResources: &resources.ResolvedTaskResources{
+			TaskSpec: &task.Spec,
+		},
+	}}
+	d, err := DagFromState(state)
+	if err != nil {
+		t.Fatalf("Unexpected error while buildig DAG for state %v: %v", state, err)
+	}
+	expected := []v1beta1.SkippedTask{{
+		Name:    "mytask11",
+		Reason:  v1beta1.WhenExpressionsSkip,
+		Message: `input "foo" with operator "notin" and values ["foo" "bar"] evaluated to false`,
+	}, {
+		Name:   "guarded-by-cel",
+		Reason: v1beta1.ParentTasksSkip,
+	}, {
+		Name:    "guarded-by-false-cel",
+		Reason:  v1beta1.WhenExpressionsSkip,
+		Message: `cel expression "'prod' != 'prod'" evaluated to false`,
+	}}
+	skipped := state.GetSkippedTasks(&v1beta1.PipelineRun{}, d)
+	if d := cmp.Diff(expected, skipped); d != "" {
+		t.Errorf("Didn't get expected skipped tasks %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineRunState_Matrix(t *testing.T) {
+	pt := v1beta1.PipelineTask{
+		Name:    "mytask",
+		TaskRef: &v1beta1.TaskRef{Name: "task"},
+		Matrix: []v1beta1.Param{{
+			Name:  "platform",
+			Value: *v1beta1.NewArrayOrString("linux", "mac"),
+		}},
+	}
+	succeeded := makeSucceeded(v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "pipelinerun-mytask-0"}})
+	running := makeStarted(v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "pipelinerun-mytask-1"}})
+	pr := &v1beta1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "pipelinerun"}}
+
+	for _, tc := range []struct {
+		name                   string
+		taskRuns               []*v1beta1.TaskRun
+		expectedNext           bool
+		expectedTaskRunsStatus map[string]*v1beta1.PipelineRunTaskRunStatus
+	}{{
+		name:                   "none started",
+		taskRuns:               []*v1beta1.TaskRun{nil, nil},
+		expectedNext:           true,
+		expectedTaskRunsStatus: map[string]*v1beta1.PipelineRunTaskRunStatus{},
+	}, {
+		name:         "one started",
+		taskRuns:     []*v1beta1.TaskRun{succeeded, nil},
+		expectedNext: true,
+		expectedTaskRunsStatus: map[string]*v1beta1.PipelineRunTaskRunStatus{
+			"pipelinerun-mytask-0": {PipelineTaskName: "mytask", Status: &succeeded.Status},
+		},
+	}, {
+		name:         "all started",
+		taskRuns:     []*v1beta1.TaskRun{succeeded, running},
+		expectedNext: false,
+		expectedTaskRunsStatus: map[string]*v1beta1.PipelineRunTaskRunStatus{
+			"pipelinerun-mytask-0": {PipelineTaskName: "mytask", Status: &succeeded.Status},
+			"pipelinerun-mytask-1": {PipelineTaskName: "mytask", Status: &running.Status},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			state := PipelineRunState{{
+				PipelineTask: &pt,
+				TaskRunNames: []string{"pipelinerun-mytask-0", "pipelinerun-mytask-1"},
+				TaskRuns:     tc.taskRuns,
+			}}
+			next := state.GetNextTasks(sets.NewString("mytask"))
+			if tc.expectedNext != (len(next) == 1) {
+				t.Errorf("Expected the matrixed task to be next: %t, but got %v", tc.expectedNext, next)
+			}
+			if d := cmp.Diff(tc.expectedTaskRunsStatus, state.GetTaskRunsStatus(pr)); d != "" {
+				t.Errorf("Unexpected TaskRuns status: %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
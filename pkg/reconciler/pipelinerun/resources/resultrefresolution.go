@@ -17,23 +17,32 @@ limitations under the License.
 package resources
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"knative.dev/pkg/apis"
 )
 
+// ErrInvalidArrayResultReference is returned when the value of an array result is referenced where it can't be
+// expanded into its values, which is anywhere but alone as an element of an array param.
+var ErrInvalidArrayResultReference = errors.New("array result can only be referenced alone as an element of an array param")
+
 // ResolvedResultRefs represents all of the ResolvedResultRef for a pipeline task
 type ResolvedResultRefs []*ResolvedResultRef
 
 // ResolvedResultRef represents a result ref reference that has been fully resolved (value has been populated).
 // If the value is from a Result, then the ResultReference will be populated to point to the ResultReference
-// which resulted in the value
+// which resulted in the value. The value comes either from a TaskRun or, for a PipelineTask running a
+// Pipeline, from the results of its child PipelineRun.
 type ResolvedResultRef struct {
 	Value           v1beta1.ArrayOrString
 	ResultReference v1beta1.ResultRef
 	FromTaskRun     string
+	FromPipelineRun string
 }
 
 // ResolveResultRefs resolves any ResultReference that are found in the target ResolvedPipelineRunTask
@@ -50,7 +59,7 @@ func ResolveResultRefs(pipelineRunState PipelineRunState, targets PipelineRunSta
 }
 
 // ResolvePipelineResultRefs takes a list of PipelineResults and resolves any references they
-// include to Task results in the given PipelineRunStatus
+// include to Task results, including the results of finally Tasks, in the given PipelineRunStatus
 func ResolvePipelineResultRefs(pipelineStatus v1beta1.PipelineRunStatus, pipelineResults []v1beta1.PipelineResult) ResolvedResultRefs {
 	var allResolvedResultRefs ResolvedResultRefs
 	for _, result := range pipelineResults {
@@ -83,7 +92,7 @@ func extractResultRefsForPipelineResult(pipelineStatus v1beta1.PipelineRunStatus
 }
 
 func extractResultRefsForPipelineResults(expressions []string, pipelineStatus v1beta1.PipelineRunStatus) (ResolvedResultRefs, error) {
-	resultRefs := v1beta1.NewResultRefs(expressions)
+	resultRefs := v1beta1.NewPipelineResultRefs(expressions)
 	var resolvedResultRefs ResolvedResultRefs
 	for _, resultRef := range resultRefs {
 		resolvedResultRef, err := resolveResultRefForPipelineResult(pipelineStatus, resultRef)
@@ -130,6 +139,9 @@ func removeDup(refs ResolvedResultRefs) ResolvedResultRefs {
 		if order[i].Result > order[j].Result {
 			return false
 		}
+		if order[i].Property > order[j].Property {
+			return false
+		}
 		return true
 	})
 
@@ -139,23 +151,29 @@ func removeDup(refs ResolvedResultRefs) ResolvedResultRefs {
 	return deduped
 }
 
-// convertToResultRefs replaces result references for all params and when expressions of the resolved pipeline run task
+// convertToResultRefs replaces result references for all params, matrix params and when expressions of the resolved pipeline run task
 func convertToResultRefs(pipelineRunState PipelineRunState, target *ResolvedPipelineRunTask) (ResolvedResultRefs, error) {
 	var resolvedResultRefs ResolvedResultRefs
 	for _, condition := range target.PipelineTask.Conditions {
-		condRefs, err := convertParams(condition.Params, pipelineRunState, condition.ConditionRef)
+		condRefs, err := convertParams(condition.Params, pipelineRunState, condition.ConditionRef, false)
 		if err != nil {
 			return nil, err
 		}
 		resolvedResultRefs = append(resolvedResultRefs, condRefs...)
 	}
 
-	taskParamsRefs, err := convertParams(target.PipelineTask.Params, pipelineRunState, target.PipelineTask.Name)
+	taskParamsRefs, err := convertParams(target.PipelineTask.Params, pipelineRunState, target.PipelineTask.Name, true)
 	if err != nil {
 		return nil, err
 	}
 	resolvedResultRefs = append(resolvedResultRefs, taskParamsRefs...)
 
+	taskMatrixRefs, err := convertParams(target.PipelineTask.Matrix, pipelineRunState, target.PipelineTask.Name, true)
+	if err != nil {
+		return nil, err
+	}
+	resolvedResultRefs = append(resolvedResultRefs, taskMatrixRefs...)
+
 	taskWhenExpressionsRefs, err := convertWhenExpressions(target.PipelineTask.WhenExpressions, pipelineRunState, target.PipelineTask.Name)
 	if err != nil {
 		return nil, err
@@ -165,13 +183,18 @@ func convertToResultRefs(pipelineRunState PipelineRunState, target *ResolvedPipe
 	return resolvedResultRefs, nil
 }
 
-func convertParams(params []v1beta1.Param, pipelineRunState PipelineRunState, name string) (ResolvedResultRefs, error) {
+// convertParams resolves the result references of params. The array results they reference are only expanded
+// into array params if expandArrays is true.
+func convertParams(params []v1beta1.Param, pipelineRunState PipelineRunState, name string, expandArrays bool) (ResolvedResultRefs, error) {
 	var resolvedParams ResolvedResultRefs
 	for _, param := range params {
 		resolvedResultRefs, err := extractResultRefsForParam(pipelineRunState, param)
 		if err != nil {
 			return nil, fmt.Errorf("unable to find result referenced by param %q in %q: %w", param.Name, name, err)
 		}
+		if err := validateArrayResultRefs(param, resolvedResultRefs, expandArrays); err != nil {
+			return nil, fmt.Errorf("invalid result reference in param %q in %q: %w", param.Name, name, err)
+		}
 		if resolvedResultRefs != nil {
 			resolvedParams = append(resolvedParams, resolvedResultRefs...)
 		}
@@ -179,6 +202,29 @@ func convertParams(params []v1beta1.Param, pipelineRunState PipelineRunState, na
 	return resolvedParams, nil
 }
 
+// validateArrayResultRefs returns an error if param references the value of an array result among refs anywhere but
+// alone as an element of an array, or at all if the arrays are not expanded.
+func validateArrayResultRefs(param v1beta1.Param, refs ResolvedResultRefs, expandArrays bool) error {
+	for _, r := range refs {
+		if r.Value.Type != v1beta1.ParamTypeArray {
+			continue
+		}
+		target := GetReplaceTarget(r.ResultReference)
+		if !expandArrays || param.Value.Type != v1beta1.ParamTypeArray {
+			return fmt.Errorf("%w: $(%s) is used in a string", ErrInvalidArrayResultReference, target)
+		}
+		for _, v := range param.Value.ArrayVal {
+			if v == fmt.Sprintf("$(%s)", target) || v == fmt.Sprintf("$(%s[*])", target) {
+				continue
+			}
+			if strings.Contains(v, fmt.Sprintf("$(%s)", target)) || strings.Contains(v, fmt.Sprintf("$(%s[*])", target)) {
+				return fmt.Errorf("%w: $(%s) is used in %q", ErrInvalidArrayResultReference, target, v)
+			}
+		}
+	}
+	return nil
+}
+
 func convertWhenExpressions(whenExpressions []v1beta1.WhenExpression, pipelineRunState PipelineRunState, name string) (ResolvedResultRefs, error) {
 	var resolvedWhenExpressions ResolvedResultRefs
 	for _, whenExpression := range whenExpressions {
@@ -188,6 +234,12 @@ func convertWhenExpressions(whenExpressions []v1beta1.WhenExpression, pipelineRu
 			if err != nil {
 				return nil, fmt.Errorf("unable to find result referenced by when expression with input %q in task %q: %w", whenExpression.Input, name, err)
 			}
+			for _, r := range resolvedResultRefs {
+				if r.Value.Type == v1beta1.ParamTypeArray {
+					return nil, fmt.Errorf("invalid result reference in when expression with input %q in task %q: %w: $(%s) is used in a when expression",
+						whenExpression.Input, name, ErrInvalidArrayResultReference, GetReplaceTarget(r.ResultReference))
+				}
+			}
 			if resolvedResultRefs != nil {
 				resolvedWhenExpressions = append(resolvedWhenExpressions, resolvedResultRefs...)
 			}
@@ -206,6 +258,9 @@ func convertPipelineResultToResultRefs(pipelineStatus v1beta1.PipelineRunStatus,
 }
 
 func resolveResultRef(pipelineState PipelineRunState, resultRef *v1beta1.ResultRef) (*ResolvedResultRef, error) {
+	if referencedPipelineTask := pipelineState.ToMap()[resultRef.PipelineTask]; referencedPipelineTask != nil && referencedPipelineTask.IsPipeline() {
+		return resolveResultRefFromPipelineRun(referencedPipelineTask, resultRef)
+	}
 	referencedTaskRun, err := getReferencedTaskRun(pipelineState, resultRef)
 	if err != nil {
 		return nil, err
@@ -214,14 +269,60 @@ func resolveResultRef(pipelineState PipelineRunState, resultRef *v1beta1.ResultR
 	if err != nil {
 		return nil, err
 	}
+	value, err := resultValue(result.Value, result.Type, resultRef)
+	if err != nil {
+		return nil, err
+	}
 	return &ResolvedResultRef{
-		Value:           *v1beta1.NewArrayOrString(result.Value),
+		Value:           *value,
 		FromTaskRun:     referencedTaskRun.Name,
 		ResultReference: *resultRef,
 	}, nil
 }
 
+// resolveResultRefFromPipelineRun resolves a ResultReference to a PipelineTask running a Pipeline
+// using the results of its child PipelineRun
+func resolveResultRefFromPipelineRun(referencedPipelineTask *ResolvedPipelineRunTask, resultRef *v1beta1.ResultRef) (*ResolvedResultRef, error) {
+	if referencedPipelineTask.PipelineRun == nil || !referencedPipelineTask.IsSuccessful() {
+		return nil, fmt.Errorf("could not find successful pipelinerun for task %q", referencedPipelineTask.PipelineTask.Name)
+	}
+	result, err := findPipelineRunResult(&referencedPipelineTask.PipelineRun.Status, resultRef)
+	if err != nil {
+		return nil, err
+	}
+	value, err := resultValue(result.Value, "", resultRef)
+	if err != nil {
+		return nil, err
+	}
+	return &ResolvedResultRef{
+		Value:           *value,
+		FromPipelineRun: referencedPipelineTask.PipelineRun.Name,
+		ResultReference: *resultRef,
+	}, nil
+}
+
 func resolveResultRefForPipelineResult(pipelineStatus v1beta1.PipelineRunStatus, resultRef *v1beta1.ResultRef) (*ResolvedResultRef, error) {
+	for pipelineRunName, childPipelineRun := range pipelineStatus.PipelineRuns {
+		if childPipelineRun.PipelineTaskName != resultRef.PipelineTask {
+			continue
+		}
+		if !childPipelineRun.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
+			return nil, fmt.Errorf("could not find a successful pipeline run status for task %q referenced by result", resultRef.PipelineTask)
+		}
+		result, err := findPipelineRunResult(childPipelineRun.Status, resultRef)
+		if err != nil {
+			return nil, err
+		}
+		value, err := resultValue(result.Value, "", resultRef)
+		if err != nil {
+			return nil, err
+		}
+		return &ResolvedResultRef{
+			Value:           *value,
+			FromPipelineRun: pipelineRunName,
+			ResultReference: *resultRef,
+		}, nil
+	}
 	taskRunStatus, taskRunName, err := getTaskRunStatus(pipelineStatus, resultRef.PipelineTask)
 
 	if err != nil {
@@ -231,8 +332,12 @@ func resolveResultRefForPipelineResult(pipelineStatus v1beta1.PipelineRunStatus,
 	if err != nil {
 		return nil, err
 	}
+	value, err := resultValue(result.Value, result.Type, resultRef)
+	if err != nil {
+		return nil, err
+	}
 	return &ResolvedResultRef{
-		Value:           *v1beta1.NewArrayOrString(result.Value),
+		Value:           *value,
 		FromTaskRun:     taskRunName,
 		ResultReference: *resultRef,
 	}, nil
@@ -244,6 +349,9 @@ func getReferencedTaskRun(pipelineState PipelineRunState, reference *v1beta1.Res
 	if referencedPipelineTask == nil {
 		return nil, fmt.Errorf("could not find task %q referenced by result", reference.PipelineTask)
 	}
+	if referencedPipelineTask.IsMatrixed() {
+		return nil, fmt.Errorf("results of matrixed task %q can't be referenced", reference.PipelineTask)
+	}
 	if referencedPipelineTask.TaskRun == nil || referencedPipelineTask.IsFailure() {
 		return nil, fmt.Errorf("could not find successful taskrun for task %q", referencedPipelineTask.PipelineTask.Name)
 	}
@@ -274,6 +382,15 @@ func findTaskResultForPipelineResult(taskStatus *v1beta1.TaskRunStatus, referenc
 	return nil, fmt.Errorf("Could not find result with name %s for task run %s", reference.Result, reference.PipelineTask)
 }
 
+func findPipelineRunResult(pipelineRunStatus *v1beta1.PipelineRunStatus, reference *v1beta1.ResultRef) (*v1beta1.PipelineRunResult, error) {
+	for _, result := range pipelineRunStatus.PipelineResults {
+		if result.Name == reference.Result {
+			return &result, nil
+		}
+	}
+	return nil, fmt.Errorf("Could not find result with name %s for pipeline run %s", reference.Result, reference.PipelineTask)
+}
+
 func findTaskResultForParam(taskRun *v1beta1.TaskRun, reference *v1beta1.ResultRef) (*v1beta1.TaskRunResult, error) {
 	results := taskRun.Status.TaskRunStatusFields.TaskRunResults
 	for _, result := range results {
@@ -284,15 +401,66 @@ func findTaskResultForParam(taskRun *v1beta1.TaskRun, reference *v1beta1.ResultR
 	return nil, fmt.Errorf("Could not find result with name %s for task run %s", reference.Result, reference.PipelineTask)
 }
 
+// resultValue returns the value of a result or, if the reference points to a key of an object
+// result, the value stored under that key. The value of an array result is parsed into an array.
+func resultValue(value string, resultType v1beta1.ResultsType, reference *v1beta1.ResultRef) (*v1beta1.ArrayOrString, error) {
+	if resultType == v1beta1.ResultsTypeArray {
+		if reference.Property != "" {
+			return nil, fmt.Errorf("result with name %s for task %s is an array and has no key %s", reference.Result, reference.PipelineTask, reference.Property)
+		}
+		array := []string{}
+		if err := json.Unmarshal([]byte(value), &array); err != nil {
+			return nil, fmt.Errorf("result with name %s for task %s is not an array of strings: %w", reference.Result, reference.PipelineTask, err)
+		}
+		return &v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: array}, nil
+	}
+	if reference.Property == "" {
+		return v1beta1.NewArrayOrString(value), nil
+	}
+	object := map[string]string{}
+	if err := json.Unmarshal([]byte(value), &object); err != nil {
+		return nil, fmt.Errorf("result with name %s for task %s is not an object: %w", reference.Result, reference.PipelineTask, err)
+	}
+	v, ok := object[reference.Property]
+	if !ok {
+		return nil, fmt.Errorf("Could not find key %s in result with name %s for task %s", reference.Property, reference.Result, reference.PipelineTask)
+	}
+	return v1beta1.NewArrayOrString(v), nil
+}
+
 func (rs ResolvedResultRefs) getStringReplacements() map[string]string {
 	replacements := map[string]string{}
 	for _, r := range rs {
-		replaceTarget := r.getReplaceTarget()
+		if r.Value.Type == v1beta1.ParamTypeArray {
+			continue
+		}
+		replaceTarget := GetReplaceTarget(r.ResultReference)
 		replacements[replaceTarget] = r.Value.StringVal
 	}
 	return replacements
 }
 
-func (r *ResolvedResultRef) getReplaceTarget() string {
-	return fmt.Sprintf("%s.%s.%s.%s", v1beta1.ResultTaskPart, r.ResultReference.PipelineTask, v1beta1.ResultResultPart, r.ResultReference.Result)
+func (rs ResolvedResultRefs) getArrayReplacements() map[string][]string {
+	replacements := map[string][]string{}
+	for _, r := range rs {
+		if r.Value.Type != v1beta1.ParamTypeArray {
+			continue
+		}
+		replaceTarget := GetReplaceTarget(r.ResultReference)
+		replacements[replaceTarget] = r.Value.ArrayVal
+	}
+	return replacements
+}
+
+// GetReplaceTarget returns the variable, without its "$()" delimiters, which is replaced by the
+// value of the result the ResultRef references
+func GetReplaceTarget(ref v1beta1.ResultRef) string {
+	part := v1beta1.ResultTaskPart
+	if ref.Finally {
+		part = v1beta1.ResultFinallyPart
+	}
+	if ref.Property != "" {
+		return fmt.Sprintf("%s.%s.%s.%s.%s", part, ref.PipelineTask, v1beta1.ResultResultPart, ref.Result, ref.Property)
+	}
+	return fmt.Sprintf("%s.%s.%s.%s", part, ref.PipelineTask, v1beta1.ResultResultPart, ref.Result)
 }
@@ -1,6 +1,7 @@
 package resources
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -11,6 +12,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/test/diff"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/selection"
 	"knative.dev/pkg/apis"
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
@@ -130,6 +132,125 @@ func TestTaskParamResolver_ResolveResultRefs(t *testing.T) {
 			FromTaskRun: "aTaskRun",
 		}},
 		wantErr: false,
+	}, {
+		name: "successful resolution: using object result key reference",
+		pipelineRunState: PipelineRunState{{
+			TaskRunName: "aTaskRun",
+			TaskRun: tb.TaskRun("aTaskRun", tb.TaskRunStatus(
+				tb.TaskRunResult("image", `{"url": "gcr.io/foo/bar", "digest": "sha256:1234"}`),
+			)),
+			PipelineTask: &v1beta1.PipelineTask{
+				Name:    "aTask",
+				TaskRef: &v1beta1.TaskRef{Name: "aTask"},
+			},
+		}},
+		param: v1beta1.Param{
+			Name:  "targetParam",
+			Value: *v1beta1.NewArrayOrString("$(tasks.aTask.results.image.url)"),
+		},
+		want: ResolvedResultRefs{{
+			Value: *v1beta1.NewArrayOrString("gcr.io/foo/bar"),
+			ResultReference: v1beta1.ResultRef{
+				PipelineTask: "aTask",
+				Result:       "image",
+				Property:     "url",
+			},
+			FromTaskRun: "aTaskRun",
+		}},
+		wantErr: false,
+	}, {
+		name: "successful resolution: using array result reference",
+		pipelineRunState: PipelineRunState{{
+			TaskRunName: "aTaskRun",
+			TaskRun: &v1beta1.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "aTaskRun"},
+				Status: v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+					TaskRunResults: []v1beta1.TaskRunResult{{
+						Name:  "changed",
+						Value: `["module-a", "module-b"]`,
+						Type:  v1beta1.ResultsTypeArray,
+					}},
+				}},
+			},
+			PipelineTask: &v1beta1.PipelineTask{
+				Name:    "aTask",
+				TaskRef: &v1beta1.TaskRef{Name: "aTask"},
+			},
+		}},
+		param: v1beta1.Param{
+			Name:  "targetParam",
+			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"$(tasks.aTask.results.changed[*])"}},
+		},
+		want: ResolvedResultRefs{{
+			Value: *v1beta1.NewArrayOrString("module-a", "module-b"),
+			ResultReference: v1beta1.ResultRef{
+				PipelineTask: "aTask",
+				Result:       "changed",
+			},
+			FromTaskRun: "aTaskRun",
+		}},
+		wantErr: false,
+	}, {
+		name: "unsuccessful resolution: array result is not a JSON array",
+		pipelineRunState: PipelineRunState{{
+			TaskRunName: "aTaskRun",
+			TaskRun: &v1beta1.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "aTaskRun"},
+				Status: v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+					TaskRunResults: []v1beta1.TaskRunResult{{
+						Name:  "changed",
+						Value: "module-a module-b",
+						Type:  v1beta1.ResultsTypeArray,
+					}},
+				}},
+			},
+			PipelineTask: &v1beta1.PipelineTask{
+				Name:    "aTask",
+				TaskRef: &v1beta1.TaskRef{Name: "aTask"},
+			},
+		}},
+		param: v1beta1.Param{
+			Name:  "targetParam",
+			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"$(tasks.aTask.results.changed[*])"}},
+		},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name: "unsuccessful resolution: referenced key doesn't exist in object result",
+		pipelineRunState: PipelineRunState{{
+			TaskRunName: "aTaskRun",
+			TaskRun: tb.TaskRun("aTaskRun", tb.TaskRunStatus(
+				tb.TaskRunResult("image", `{"url": "gcr.io/foo/bar"}`),
+			)),
+			PipelineTask: &v1beta1.PipelineTask{
+				Name:    "aTask",
+				TaskRef: &v1beta1.TaskRef{Name: "aTask"},
+			},
+		}},
+		param: v1beta1.Param{
+			Name:  "targetParam",
+			Value: *v1beta1.NewArrayOrString("$(tasks.aTask.results.image.digest)"),
+		},
+		want:    nil,
+		wantErr: true,
+	}, {
+		name: "unsuccessful resolution: referenced result is not an object",
+		pipelineRunState: PipelineRunState{{
+			TaskRunName: "aTaskRun",
+			TaskRun: tb.TaskRun("aTaskRun", tb.TaskRunStatus(
+				tb.TaskRunResult("image", "gcr.io/foo/bar"),
+			)),
+			PipelineTask: &v1beta1.PipelineTask{
+				Name:    "aTask",
+				TaskRef: &v1beta1.TaskRef{Name: "aTask"},
+			},
+		}},
+		param: v1beta1.Param{
+			Name:  "targetParam",
+			Value: *v1beta1.NewArrayOrString("$(tasks.aTask.results.image.url)"),
+		},
+		want:    nil,
+		wantErr: true,
 	}, {
 		name: "unsuccessful resolution: referenced result doesn't exist in referenced task",
 		pipelineRunState: PipelineRunState{{
@@ -333,6 +454,82 @@ func TestResolveResultRefs(t *testing.T) {
 	}
 }
 
+func TestResolveResultRefs_ArrayResults(t *testing.T) {
+	producer := &ResolvedPipelineRunTask{
+		TaskRunName: "aTaskRun",
+		TaskRun: &v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "aTaskRun"},
+			Status: v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				TaskRunResults: []v1beta1.TaskRunResult{{
+					Name:  "changed",
+					Value: `["module-a", "module-b"]`,
+					Type:  v1beta1.ResultsTypeArray,
+				}},
+			}},
+		},
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:    "aTask",
+			TaskRef: &v1beta1.TaskRef{Name: "aTask"},
+		},
+	}
+	arrayParam := func(values ...string) []v1beta1.Param {
+		return []v1beta1.Param{{Name: "bParam", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: values}}}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		task    v1beta1.PipelineTask
+		wantErr bool
+	}{{
+		name: "alone in an array param",
+		task: v1beta1.PipelineTask{Params: arrayParam("--verbose", "$(tasks.aTask.results.changed)")},
+	}, {
+		name: "alone in an array param with star",
+		task: v1beta1.PipelineTask{Params: arrayParam("$(tasks.aTask.results.changed[*])")},
+	}, {
+		name: "alone in a matrix param",
+		task: v1beta1.PipelineTask{Matrix: arrayParam("$(tasks.aTask.results.changed[*])")},
+	}, {
+		name:    "in a string param",
+		task:    v1beta1.PipelineTask{Params: []v1beta1.Param{{Name: "bParam", Value: *v1beta1.NewArrayOrString("$(tasks.aTask.results.changed)")}}},
+		wantErr: true,
+	}, {
+		name:    "in an element of an array param",
+		task:    v1beta1.PipelineTask{Params: arrayParam("--modules=$(tasks.aTask.results.changed)")},
+		wantErr: true,
+	}, {
+		name: "in a when expression",
+		task: v1beta1.PipelineTask{WhenExpressions: []v1beta1.WhenExpression{{
+			Input:    "$(tasks.aTask.results.changed)",
+			Operator: selection.In,
+			Values:   []string{"module-a"},
+		}}},
+		wantErr: true,
+	}, {
+		name: "in a condition param",
+		task: v1beta1.PipelineTask{Conditions: []v1beta1.PipelineTaskCondition{{
+			ConditionRef: "aCondition",
+			Params:       arrayParam("$(tasks.aTask.results.changed)"),
+		}}},
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			task := tc.task
+			task.Name = "bTask"
+			task.TaskRef = &v1beta1.TaskRef{Name: "bTask"}
+			target := &ResolvedPipelineRunTask{PipelineTask: &task}
+			_, err := ResolveResultRefs(PipelineRunState{producer, target}, PipelineRunState{target})
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidArrayResultReference) {
+					t.Errorf("Expected ErrInvalidArrayResultReference, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
 func TestResolvePipelineResultRefs(t *testing.T) {
 	taskrunStatus := map[string]*v1beta1.PipelineRunTaskRunStatus{}
 	taskrunStatus["aTaskRun"] = &v1beta1.PipelineRunTaskRunStatus{
@@ -376,6 +573,23 @@ func TestResolvePipelineResultRefs(t *testing.T) {
 			},
 		},
 	}
+	taskrunStatus["finalTaskRun"] = &v1beta1.PipelineRunTaskRunStatus{
+		PipelineTaskName: "finalTask",
+		Status: &v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionTrue,
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				TaskRunResults: []v1beta1.TaskRunResult{{
+					Name:  "url",
+					Value: "https://example.com/report",
+				}},
+			},
+		},
+	}
 	status := v1beta1.PipelineRunStatus{
 		PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
 			TaskRuns: taskrunStatus,
@@ -427,6 +641,30 @@ func TestResolvePipelineResultRefs(t *testing.T) {
 			},
 			FromTaskRun: "aTaskRun",
 		}},
+	}, {
+		name:   "Test pipeline results from a task and a finally task",
+		status: status,
+		pipelineResults: []v1beta1.PipelineResult{{
+			Name:        "report",
+			Value:       "$(tasks.aTask.results.aResult) at $(finally.finalTask.results.url)",
+			Description: "a result from a finally task",
+		}},
+		want: ResolvedResultRefs{{
+			Value: *v1beta1.NewArrayOrString("aResultValue"),
+			ResultReference: v1beta1.ResultRef{
+				PipelineTask: "aTask",
+				Result:       "aResult",
+			},
+			FromTaskRun: "aTaskRun",
+		}, {
+			Value: *v1beta1.NewArrayOrString("https://example.com/report"),
+			ResultReference: v1beta1.ResultRef{
+				PipelineTask: "finalTask",
+				Result:       "url",
+				Finally:      true,
+			},
+			FromTaskRun: "finalTaskRun",
+		}},
 	},
 	}
 	for _, tt := range tests {
@@ -441,3 +679,135 @@ func TestResolvePipelineResultRefs(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveResultRefs_PipelineTask(t *testing.T) {
+	succeeded := &v1beta1.PipelineRun{
+		Status: v1beta1.PipelineRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionTrue,
+				}},
+			},
+			PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+				PipelineResults: []v1beta1.PipelineRunResult{{
+					Name:  "aResult",
+					Value: "aResultValue",
+				}},
+			},
+		},
+	}
+	succeeded.Name = "aPipelineRun"
+	running := succeeded.DeepCopy()
+	running.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionUnknown})
+	target := &ResolvedPipelineRunTask{
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:    "bTask",
+			TaskRef: &v1beta1.TaskRef{Name: "bTask"},
+			Params: []v1beta1.Param{{
+				Name:  "bParam",
+				Value: *v1beta1.NewArrayOrString("$(tasks.aPipeline.results.aResult)"),
+			}},
+		},
+	}
+	missingTarget := &ResolvedPipelineRunTask{
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:    "bTask",
+			TaskRef: &v1beta1.TaskRef{Name: "bTask"},
+			Params: []v1beta1.Param{{
+				Name:  "bParam",
+				Value: *v1beta1.NewArrayOrString("$(tasks.aPipeline.results.missingResult)"),
+			}},
+		},
+	}
+	pipelineTask := &v1beta1.PipelineTask{
+		Name:        "aPipeline",
+		PipelineRef: &v1beta1.PipelineRef{Name: "aPipeline"},
+	}
+
+	for _, tt := range []struct {
+		name        string
+		pipelineRun *v1beta1.PipelineRun
+		target      *ResolvedPipelineRunTask
+		want        ResolvedResultRefs
+		wantErr     bool
+	}{{
+		name:        "result of a succeeded child pipelinerun",
+		pipelineRun: succeeded,
+		target:      target,
+		want: ResolvedResultRefs{{
+			Value: *v1beta1.NewArrayOrString("aResultValue"),
+			ResultReference: v1beta1.ResultRef{
+				PipelineTask: "aPipeline",
+				Result:       "aResult",
+			},
+			FromPipelineRun: "aPipelineRun",
+		}},
+	}, {
+		name:        "missing result of a succeeded child pipelinerun",
+		pipelineRun: succeeded,
+		target:      missingTarget,
+		wantErr:     true,
+	}, {
+		name:        "result of a running child pipelinerun",
+		pipelineRun: running,
+		target:      target,
+		wantErr:     true,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			pipelineRunState := PipelineRunState{{
+				PipelineTask:    pipelineTask,
+				PipelineRunName: "aPipelineRun",
+				PipelineRun:     tt.pipelineRun,
+			}, tt.target}
+			got, err := ResolveResultRefs(pipelineRunState, PipelineRunState{tt.target})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveResultRefs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Fatalf("ResolveResultRef %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestResolvePipelineResultRefs_PipelineTask(t *testing.T) {
+	status := v1beta1.PipelineRunStatus{
+		PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+			PipelineRuns: map[string]*v1beta1.PipelineRunPipelineRunStatus{
+				"aPipelineRun": {
+					PipelineTaskName: "aPipeline",
+					Status: &v1beta1.PipelineRunStatus{
+						Status: duckv1beta1.Status{
+							Conditions: []apis.Condition{{
+								Type:   apis.ConditionSucceeded,
+								Status: corev1.ConditionTrue,
+							}},
+						},
+						PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+							PipelineResults: []v1beta1.PipelineRunResult{{
+								Name:  "aResult",
+								Value: "aResultValue",
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+	got := ResolvePipelineResultRefs(status, []v1beta1.PipelineResult{{
+		Name:  "from-a",
+		Value: "$(tasks.aPipeline.results.aResult)",
+	}})
+	want := ResolvedResultRefs{{
+		Value: *v1beta1.NewArrayOrString("aResultValue"),
+		ResultReference: v1beta1.ResultRef{
+			PipelineTask: "aPipeline",
+			Result:       "aResult",
+		},
+		FromPipelineRun: "aPipelineRun",
+	}}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Fatalf("ResolvePipelineResultRefs %s", diff.PrintWantGot(d))
+	}
+}
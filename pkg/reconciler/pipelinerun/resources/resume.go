@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResumeFrom marks the PipelineTasks of the specified dag which succeeded in prev, the PipelineRun
+// resumed by pr, with the same resolved TaskSpec and params as done, by copying the status of their
+// TaskRuns instead of running them again. A PipelineTask is only resumed if all the PipelineTasks it
+// depends on were resumed too, since its params may use their results. The names of the resumed
+// PipelineTasks are returned.
+func (state PipelineRunState) ResumeFrom(pr, prev *v1beta1.PipelineRun, d *dag.Graph) []string {
+	if prev.Status.PipelineSpec == nil {
+		return nil
+	}
+	prevSpec := ApplyParameters(prev.Status.PipelineSpec, prev)
+	prevSpec = ApplyContexts(prevSpec, prev.Labels[pipeline.GroupName+pipeline.PipelineLabelKey], prev)
+	prevTasks := make(map[string]v1beta1.PipelineTask, len(prevSpec.Tasks))
+	for _, pt := range prevSpec.Tasks {
+		prevTasks[pt.Name] = pt
+	}
+	prevStatuses := make(map[string]*v1beta1.TaskRunStatus)
+	for _, prtrs := range prev.Status.TaskRuns {
+		if prtrs.Status != nil && isTaskRunSuccessful(&v1beta1.TaskRun{Status: *prtrs.Status}) {
+			prevStatuses[prtrs.PipelineTaskName] = prtrs.Status
+		}
+	}
+
+	tasks := state.ToMap()
+	resumed := make(map[string]bool)
+	var resume func(name string) bool
+	resume = func(name string) bool {
+		if r, ok := resumed[name]; ok {
+			return r
+		}
+		resumed[name] = false
+		for _, parent := range d.Nodes[name].Prev {
+			if !resume(parent.Task.HashKey()) {
+				return false
+			}
+		}
+		rprt := tasks[name]
+		if rprt == nil || rprt.IsMatrixed() || rprt.IsPipeline() || rprt.TaskRun != nil || len(rprt.PipelineTask.Conditions) > 0 {
+			return false
+		}
+		prevStatus, ok := prevStatuses[name]
+		if !ok || prevStatus.TaskSpec == nil || !equality.Semantic.DeepEqual(prevStatus.TaskSpec, rprt.ResolvedTaskResources.TaskSpec) {
+			return false
+		}
+		prevTask, ok := prevTasks[name]
+		if !ok || !equality.Semantic.DeepEqual(prevTask.Params, rprt.PipelineTask.Params) {
+			return false
+		}
+		rprt.TaskRun = resumedTaskRun(rprt.TaskRunName, pr.Namespace, prevStatus)
+		rprt.ResumedFrom = prev.Name
+		resumed[name] = true
+		return true
+	}
+
+	var names []string
+	for _, rprt := range state {
+		if _, ok := d.Nodes[rprt.PipelineTask.Name]; ok && resume(rprt.PipelineTask.Name) {
+			names = append(names, rprt.PipelineTask.Name)
+		}
+	}
+	return names
+}
+
+// resumedTaskRun returns a TaskRun standing for a PipelineTask which was not run again, whose status
+// was copied from the PipelineRun it was resumed from
+func resumedTaskRun(name, namespace string, status *v1beta1.TaskRunStatus) *v1beta1.TaskRun {
+	return &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Status: *status.DeepCopy(),
+	}
+}
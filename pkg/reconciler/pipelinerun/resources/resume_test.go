@@ -0,0 +1,190 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	resourcev1alpha1 "github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+)
+
+var resumeTaskSpec = &v1beta1.TaskSpec{
+	Results: []v1beta1.TaskResult{{Name: "result"}},
+	Steps: []v1beta1.Step{{Container: corev1.Container{
+		Name:  "step",
+		Image: "busybox",
+	}}},
+}
+
+func makeResumedStatus(pipelineTaskName string, status corev1.ConditionStatus, results ...v1beta1.TaskRunResult) *v1beta1.PipelineRunTaskRunStatus {
+	return &v1beta1.PipelineRunTaskRunStatus{
+		PipelineTaskName: pipelineTaskName,
+		Status: &v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{Conditions: []apis.Condition{{
+				Type:   apis.ConditionSucceeded,
+				Status: status,
+			}}},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				TaskSpec:       resumeTaskSpec,
+				TaskRunResults: results,
+			},
+		},
+	}
+}
+
+func TestResumeFrom(t *testing.T) {
+	// a -> b and c -> d, where b uses a result of a and c uses the param of the pipeline
+	tasks := []v1beta1.PipelineTask{{
+		Name:     "a",
+		TaskSpec: &v1beta1.EmbeddedTask{TaskSpec: resumeTaskSpec},
+	}, {
+		Name:     "b",
+		TaskSpec: &v1beta1.EmbeddedTask{TaskSpec: resumeTaskSpec},
+		Params:   []v1beta1.Param{{Name: "p", Value: *v1beta1.NewArrayOrString("$(tasks.a.results.result)")}},
+	}, {
+		Name:     "c",
+		TaskSpec: &v1beta1.EmbeddedTask{TaskSpec: resumeTaskSpec},
+		Params:   []v1beta1.Param{{Name: "p", Value: *v1beta1.NewArrayOrString("$(params.p)")}},
+	}, {
+		Name:     "d",
+		TaskSpec: &v1beta1.EmbeddedTask{TaskSpec: resumeTaskSpec},
+		RunAfter: []string{"c"},
+	}}
+	prev := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "failed-run", Namespace: "foo"},
+		Spec: v1beta1.PipelineRunSpec{
+			Params: []v1beta1.Param{{Name: "p", Value: *v1beta1.NewArrayOrString("v1")}},
+		},
+		Status: v1beta1.PipelineRunStatus{PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+			PipelineSpec: &v1beta1.PipelineSpec{
+				Params: []v1beta1.ParamSpec{{Name: "p", Type: v1beta1.ParamTypeString}},
+				Tasks:  tasks,
+			},
+			TaskRuns: map[string]*v1beta1.PipelineRunTaskRunStatus{
+				"failed-run-a": makeResumedStatus("a", corev1.ConditionTrue, v1beta1.TaskRunResult{Name: "result", Value: "aResult"}),
+				"failed-run-b": makeResumedStatus("b", corev1.ConditionFalse),
+				"failed-run-c": makeResumedStatus("c", corev1.ConditionTrue),
+				"failed-run-d": makeResumedStatus("d", corev1.ConditionTrue),
+			},
+		}},
+	}
+
+	for _, tc := range []struct {
+		name        string
+		param       string
+		taskSpec    *v1beta1.TaskSpec
+		wantResumed []string
+	}{{
+		name:        "same params",
+		param:       "v1",
+		taskSpec:    resumeTaskSpec,
+		wantResumed: []string{"a", "c", "d"},
+	}, {
+		name:        "different params",
+		param:       "v2",
+		taskSpec:    resumeTaskSpec,
+		wantResumed: []string{"a"},
+	}, {
+		name:  "different task spec",
+		param: "v1",
+		taskSpec: &v1beta1.TaskSpec{Steps: []v1beta1.Step{{Container: corev1.Container{
+			Name:  "step",
+			Image: "alpine",
+		}}}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := &v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "resumed-run", Namespace: "foo"},
+				Spec: v1beta1.PipelineRunSpec{
+					ResumeFrom: "failed-run",
+					Params:     []v1beta1.Param{{Name: "p", Value: *v1beta1.NewArrayOrString(tc.param)}},
+				},
+			}
+			spec := ApplyParameters(prev.Status.PipelineSpec, pr)
+			var state PipelineRunState
+			for i := range spec.Tasks {
+				state = append(state, &ResolvedPipelineRunTask{
+					TaskRunName:           "resumed-run-" + spec.Tasks[i].Name,
+					PipelineTask:          &spec.Tasks[i],
+					ResolvedTaskResources: &resources.ResolvedTaskResources{TaskSpec: tc.taskSpec},
+				})
+			}
+			d, err := dag.Build(v1beta1.PipelineTaskList(spec.Tasks))
+			if err != nil {
+				t.Fatalf("Unexpected error building the dag: %v", err)
+			}
+
+			got := state.ResumeFrom(pr, prev, d)
+			if d := cmp.Diff(tc.wantResumed, got); d != "" {
+				t.Errorf("Didn't get expected resumed tasks %s", diff.PrintWantGot(d))
+			}
+			for _, rprt := range state {
+				if rprt.TaskRun == nil {
+					continue
+				}
+				if rprt.ResumedFrom != "failed-run" || rprt.TaskRun.Name != rprt.TaskRunName || !rprt.IsSuccessful() {
+					t.Errorf("Expected task %s to be resumed from failed-run as a successful TaskRun %s, got %v", rprt.PipelineTask.Name, rprt.TaskRunName, rprt.TaskRun)
+				}
+			}
+		})
+	}
+}
+
+func TestResolvePipelineRun_ResumedTaskRun(t *testing.T) {
+	pts := []v1beta1.PipelineTask{{
+		Name:     "mytask",
+		TaskSpec: &v1beta1.EmbeddedTask{TaskSpec: resumeTaskSpec},
+	}}
+	prtrs := makeResumedStatus("mytask", corev1.ConditionTrue, v1beta1.TaskRunResult{Name: "result", Value: "aResult"})
+	prtrs.ResumedFrom = "failed-run"
+	pr := v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "pipelinerun", Namespace: "foo"},
+		Spec:       v1beta1.PipelineRunSpec{ResumeFrom: "failed-run"},
+		Status: v1beta1.PipelineRunStatus{PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+			TaskRuns: map[string]*v1beta1.PipelineRunTaskRunStatus{"pipelinerun-mytask": prtrs},
+		}},
+	}
+	getTask := func(name string) (v1beta1.TaskInterface, error) { return nil, nil }
+	getClusterTask := func(name string) (v1beta1.TaskInterface, error) { return nil, nil }
+	getCondition := func(name string) (*v1alpha1.Condition, error) { return nil, nil }
+	getTaskRun := func(name string) (*v1beta1.TaskRun, error) {
+		t.Errorf("Unexpected call to get the TaskRun %s of a resumed PipelineTask", name)
+		return nil, nil
+	}
+
+	state, err := ResolvePipelineRun(context.Background(), pr, getTask, getTaskRun, getClusterTask, nil, nil, getCondition, getPipelineRun, pts, map[string]*resourcev1alpha1.PipelineResource{})
+	if err != nil {
+		t.Fatalf("Unexpected error resolving the PipelineRun: %v", err)
+	}
+	if len(state) != 1 || state[0].TaskRun == nil || !state[0].IsSuccessful() {
+		t.Fatalf("Expected the resumed PipelineTask to be successful, got %v", state)
+	}
+	if d := cmp.Diff(prtrs, state.GetTaskRunsStatus(&pr)["pipelinerun-mytask"]); d != "" {
+		t.Errorf("Expected the status of the resumed PipelineTask to be kept %s", diff.PrintWantGot(d))
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/pod"
+	"knative.dev/pkg/apis"
+)
+
+const oomKilled = "OOMKilled"
+
+// RetryReason returns the kind of failure of a failed TaskRun, and whether the retry policy
+// retries the TaskRun for it. The reason of the TaskRun's condition is returned for failures
+// of no particular kind, which are only retried by a retry policy without filters.
+func RetryReason(tr *v1beta1.TaskRun, policy *v1beta1.RetryPolicy) (v1beta1.RetryReason, bool) {
+	c := tr.Status.GetCondition(apis.ConditionSucceeded)
+	if c == nil {
+		return "", false
+	}
+	switch c.Reason {
+	case pod.ReasonEvicted:
+		return v1beta1.RetryReasonEvicted, policy.RetriesOn(v1beta1.RetryReasonEvicted)
+	case pod.ReasonExceededNodeResources:
+		return v1beta1.RetryReasonExceededNodeResources, policy.RetriesOn(v1beta1.RetryReasonExceededNodeResources)
+	}
+	for _, step := range tr.Status.Steps {
+		if step.Terminated != nil && step.Terminated.Reason == oomKilled {
+			return v1beta1.RetryReasonOOMKilled, policy.RetriesOn(v1beta1.RetryReasonOOMKilled)
+		}
+	}
+	if c.Reason == v1beta1.TaskRunReasonFailed.String() {
+		// the steps following the one which failed are not run, so only its exit code matters; the
+		// steps which failed before it but whose onError is continue did not fail the TaskRun
+		for _, step := range tr.Status.Steps {
+			if step.Terminated != nil && step.Terminated.ExitCode != 0 && !step.Continued {
+				if policy.RetriesExitCode(step.Terminated.ExitCode) {
+					return v1beta1.RetryReasonExitCode, true
+				}
+				break
+			}
+		}
+	}
+	return v1beta1.RetryReason(c.Reason), !policy.HasFilters()
+}
+
+// retryDelay returns how long is left to wait before retrying a failed TaskRun according to the
+// retry policy of its PipelineTask, or 0 if it can be retried right away
+func retryDelay(tr *v1beta1.TaskRun, policy *v1beta1.RetryPolicy) time.Duration {
+	delay := policy.DelayFor(len(tr.Status.RetriesStatus))
+	if delay == 0 {
+		return 0
+	}
+	failedAt := tr.Status.GetCondition(apis.ConditionSucceeded).LastTransitionTime.Inner.Time
+	if tr.Status.CompletionTime != nil {
+		failedAt = tr.Status.CompletionTime.Time
+	}
+	if remaining := time.Until(failedAt.Add(delay)); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// isTaskRunRetryDue returns true if the TaskRun can be retried and the delay of the retry policy
+// of its PipelineTask has elapsed
+func isTaskRunRetryDue(tr *v1beta1.TaskRun, pt *v1beta1.PipelineTask) bool {
+	return isTaskRunRetriable(tr, pt) && retryDelay(tr, pt.RetryPolicy) == 0
+}
+
+// RetryDelay returns how long is left to wait before the first of the retries delayed by the
+// retry policies of the PipelineTasks in state, or 0 if no retry is waiting
+func (state PipelineRunState) RetryDelay() time.Duration {
+	var next time.Duration
+	for _, t := range state {
+		if t.PipelineTask == nil || t.IsPipeline() {
+			continue
+		}
+		trs := t.TaskRuns
+		if !t.IsMatrixed() {
+			trs = []*v1beta1.TaskRun{t.TaskRun}
+		}
+		for _, tr := range trs {
+			if tr == nil || !isTaskRunRetriable(tr, t.retriedPipelineTask()) {
+				continue
+			}
+			if d := retryDelay(tr, t.PipelineTask.RetryPolicy); d > 0 && (next == 0 || d < next) {
+				next = d
+			}
+		}
+	}
+	return next
+}
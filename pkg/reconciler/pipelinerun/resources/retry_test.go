@@ -0,0 +1,159 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/pod"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+)
+
+func makeFailedTaskRun(reason string, steps ...corev1.ContainerStateTerminated) *v1beta1.TaskRun {
+	tr := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "failed-taskrun"},
+		Status: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{Conditions: []apis.Condition{{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionFalse,
+				Reason: reason,
+			}}},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				CompletionTime: &metav1.Time{Time: time.Now()},
+			},
+		},
+	}
+	for i := range steps {
+		tr.Status.Steps = append(tr.Status.Steps, v1beta1.StepState{
+			ContainerState: corev1.ContainerState{Terminated: &steps[i]},
+		})
+	}
+	return tr
+}
+
+func TestRetryReason(t *testing.T) {
+	exitCode := func(code int32) corev1.ContainerStateTerminated {
+		return corev1.ContainerStateTerminated{ExitCode: code}
+	}
+	// the first step of the TaskRun failed with exit code 3 but its onError is continue, and the last
+	// step failed the TaskRun with exit code 137
+	continuedTaskRun := makeFailedTaskRun(v1beta1.TaskRunReasonFailed.String(), exitCode(3), exitCode(0), exitCode(137))
+	continuedTaskRun.Status.Steps[0].Continued = true
+	for _, tc := range []struct {
+		name        string
+		tr          *v1beta1.TaskRun
+		policy      *v1beta1.RetryPolicy
+		wantReason  v1beta1.RetryReason
+		wantRetried bool
+	}{{
+		name:        "failure without retry policy",
+		tr:          makeFailedTaskRun(v1beta1.TaskRunReasonTimedOut.String()),
+		wantReason:  v1beta1.RetryReason(v1beta1.TaskRunReasonTimedOut.String()),
+		wantRetried: true,
+	}, {
+		name:        "failure not filtered on",
+		tr:          makeFailedTaskRun(v1beta1.TaskRunReasonTimedOut.String()),
+		policy:      &v1beta1.RetryPolicy{On: []v1beta1.RetryReason{v1beta1.RetryReasonEvicted}},
+		wantReason:  v1beta1.RetryReason(v1beta1.TaskRunReasonTimedOut.String()),
+		wantRetried: false,
+	}, {
+		name:        "evicted",
+		tr:          makeFailedTaskRun(pod.ReasonEvicted),
+		policy:      &v1beta1.RetryPolicy{On: []v1beta1.RetryReason{v1beta1.RetryReasonEvicted}},
+		wantReason:  v1beta1.RetryReasonEvicted,
+		wantRetried: true,
+	}, {
+		name:        "exceeded node resources",
+		tr:          makeFailedTaskRun(pod.ReasonExceededNodeResources),
+		policy:      &v1beta1.RetryPolicy{On: []v1beta1.RetryReason{v1beta1.RetryReasonEvicted}},
+		wantReason:  v1beta1.RetryReasonExceededNodeResources,
+		wantRetried: false,
+	}, {
+		name:        "oom killed",
+		tr:          makeFailedTaskRun(v1beta1.TaskRunReasonFailed.String(), exitCode(0), corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}),
+		policy:      &v1beta1.RetryPolicy{On: []v1beta1.RetryReason{v1beta1.RetryReasonOOMKilled}},
+		wantReason:  v1beta1.RetryReasonOOMKilled,
+		wantRetried: true,
+	}, {
+		name:        "exit code of the failed step",
+		tr:          makeFailedTaskRun(v1beta1.TaskRunReasonFailed.String(), exitCode(0), exitCode(3), exitCode(1)),
+		policy:      &v1beta1.RetryPolicy{ExitCodes: []int32{3}},
+		wantReason:  v1beta1.RetryReasonExitCode,
+		wantRetried: true,
+	}, {
+		name:        "exit code of a step after the failed step",
+		tr:          makeFailedTaskRun(v1beta1.TaskRunReasonFailed.String(), exitCode(0), exitCode(3), exitCode(1)),
+		policy:      &v1beta1.RetryPolicy{ExitCodes: []int32{1}},
+		wantReason:  v1beta1.RetryReason(v1beta1.TaskRunReasonFailed.String()),
+		wantRetried: false,
+	}, {
+		name:        "exit code of a step whose onError is continue",
+		tr:          continuedTaskRun,
+		policy:      &v1beta1.RetryPolicy{ExitCodes: []int32{3}},
+		wantReason:  v1beta1.RetryReason(v1beta1.TaskRunReasonFailed.String()),
+		wantRetried: false,
+	}, {
+		name:        "exit code of the failed step after a step whose onError is continue",
+		tr:          continuedTaskRun,
+		policy:      &v1beta1.RetryPolicy{ExitCodes: []int32{137}},
+		wantReason:  v1beta1.RetryReasonExitCode,
+		wantRetried: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			reason, retried := RetryReason(tc.tr, tc.policy)
+			if reason != tc.wantReason || retried != tc.wantRetried {
+				t.Errorf("RetryReason() = %s, %t, want %s, %t", reason, retried, tc.wantReason, tc.wantRetried)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	pt := &v1beta1.PipelineTask{
+		Name:    "mytask",
+		TaskRef: &v1beta1.TaskRef{Name: "task"},
+		Retries: 2,
+		RetryPolicy: &v1beta1.RetryPolicy{
+			Delay: &metav1.Duration{Duration: time.Minute},
+		},
+	}
+	failedNow := makeFailedTaskRun(v1beta1.TaskRunReasonFailed.String())
+	failedBefore := makeFailedTaskRun(v1beta1.TaskRunReasonFailed.String())
+	failedBefore.Status.CompletionTime = &metav1.Time{Time: time.Now().Add(-2 * time.Minute)}
+
+	state := PipelineRunState{{PipelineTask: pt, TaskRunName: "failed-taskrun", TaskRun: failedNow}}
+	if delay := state.RetryDelay(); delay <= 0 || delay > time.Minute {
+		t.Errorf("Expected the retry to be delayed by at most a minute, but got %s", delay)
+	}
+	if next := state.GetNextTasks(sets.NewString("mytask")); len(next) != 0 {
+		t.Errorf("Expected the delayed retry not to be scheduled, but got %v", next)
+	}
+
+	state = PipelineRunState{{PipelineTask: pt, TaskRunName: "failed-taskrun", TaskRun: failedBefore}}
+	if delay := state.RetryDelay(); delay != 0 {
+		t.Errorf("Expected no retry to be waiting, but got %s", delay)
+	}
+	if next := state.GetNextTasks(sets.NewString("mytask")); len(next) != 1 {
+		t.Errorf("Expected the retry to be scheduled once its delay elapsed, but got %v", next)
+	}
+}
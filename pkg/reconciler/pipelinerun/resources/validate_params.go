@@ -73,3 +73,17 @@ func ValidateRequiredParametersProvided(pipelineParameters *[]v1beta1.ParamSpec,
 	}
 	return nil
 }
+
+// ValidateMatrixNotEmpty validates that the Matrix parameters of the PipelineTasks still hold values once
+// the parameters and results they reference are substituted, since a PipelineTask fanned out over no
+// combination would be done without running any TaskRun.
+func ValidateMatrixNotEmpty(tasks []v1beta1.PipelineTask) error {
+	for _, pt := range tasks {
+		for _, mp := range pt.Matrix {
+			if len(mp.Value.ArrayVal) == 0 {
+				return fmt.Errorf("matrix parameter %q of pipeline task %q has no values", mp.Name, pt.Name)
+			}
+		}
+	}
+	return nil
+}
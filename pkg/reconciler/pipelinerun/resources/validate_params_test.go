@@ -222,3 +222,20 @@ func TestValidateRequiredParametersProvided_Invalid(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateMatrixNotEmpty(t *testing.T) {
+	tasks := []v1beta1.PipelineTask{{
+		Name:   "build",
+		Matrix: []v1beta1.Param{{Name: "platform", Value: *v1beta1.NewArrayOrString("linux", "mac")}},
+	}}
+	if err := ValidateMatrixNotEmpty(tasks); err != nil {
+		t.Errorf("Didn't expect to see error when validating a matrix with values but saw %v", err)
+	}
+	tasks = append(tasks, v1beta1.PipelineTask{
+		Name:   "test",
+		Matrix: []v1beta1.Param{{Name: "browser", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{}}}},
+	})
+	if err := ValidateMatrixNotEmpty(tasks); err == nil {
+		t.Errorf("Expected to see error when validating an empty matrix but saw none")
+	}
+}
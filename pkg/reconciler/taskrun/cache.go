@@ -0,0 +1,225 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	resourcev1alpha1 "github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1"
+	podconvert "github.com/tektoncd/pipeline/pkg/pod"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/logging"
+)
+
+// maxCacheConfigMapSize is the size of the data of the ConfigMap of a cache above which the oldest
+// results are evicted from it, well below the 1 MiB limit of the size of an object
+const maxCacheConfigMapSize = 512 * 1024
+
+// cachedResults are the results stored under a cache key in the ConfigMap of a cache
+type cachedResults struct {
+	Results  []v1beta1.TaskRunResult `json:"results"`
+	StoredAt metav1.Time             `json:"storedAt"`
+}
+
+// cacheKeyData holds everything the results of a TaskRun depend on
+type cacheKeyData struct {
+	TaskSpec *v1beta1.TaskSpec                                `json:"taskSpec"`
+	Params   []v1beta1.Param                                  `json:"params,omitempty"`
+	Inputs   map[string]resourcev1alpha1.PipelineResourceSpec `json:"inputs,omitempty"`
+	Images   []string                                         `json:"images"`
+}
+
+// cacheKey returns the key the results of the TaskRun are cached under: a hash of its TaskSpec
+// with its params substituted, of its params, of the specs of its input resources, which hold
+// their revisions, and of the digests of the images of its steps
+func (c *Reconciler) cacheKey(tr *v1beta1.TaskRun, rtr *resources.ResolvedTaskResources) (string, error) {
+	ts := resources.ApplyParameters(rtr.TaskSpec.DeepCopy(), tr, rtr.TaskSpec.Params...)
+	steps := make([]corev1.Container, len(ts.Steps))
+	for i, s := range ts.Steps {
+		steps[i] = s.Container
+	}
+	images, err := podconvert.ResolveImageDigests(c.entrypointCache, tr.Namespace, tr.Spec.ServiceAccountName, steps)
+	if err != nil {
+		return "", fmt.Errorf("couldn't resolve the image digests of the steps: %w", err)
+	}
+
+	params := append([]v1beta1.Param{}, tr.Spec.Params...)
+	sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+	inputs := make(map[string]resourcev1alpha1.PipelineResourceSpec, len(rtr.Inputs))
+	for name, r := range rtr.Inputs {
+		if r != nil {
+			inputs[name] = r.Spec
+		}
+	}
+	b, err := json.Marshal(cacheKeyData{TaskSpec: ts, Params: params, Inputs: inputs, Images: images})
+	if err != nil {
+		return "", err
+	}
+	// label values are limited to 63 characters, which fits the 56 of a SHA-224 hash
+	return fmt.Sprintf("%x", sha256.Sum224(b)), nil
+}
+
+// reuseCachedResults looks for a previous successful TaskRun with the same cache key as the TaskRun,
+// in its namespace and then in the ConfigMap of its cache. If one is found, the TaskRun is marked
+// successful with its results, and true is returned. A TaskRun with workspaces is never cached,
+// since the contents of its workspaces aren't known and it may write to them.
+func (c *Reconciler) reuseCachedResults(ctx context.Context, tr *v1beta1.TaskRun, rtr *resources.ResolvedTaskResources) (bool, error) {
+	logger := logging.FromContext(ctx)
+	if len(rtr.TaskSpec.Workspaces) != 0 || len(tr.Spec.Workspaces) != 0 {
+		logger.Infof("TaskRun %s isn't cached since it has workspaces", tr.Name)
+		return false, nil
+	}
+	key, err := c.cacheKey(tr, rtr)
+	if err != nil {
+		return false, err
+	}
+	if tr.ObjectMeta.Labels == nil {
+		tr.ObjectMeta.Labels = make(map[string]string)
+	}
+	tr.ObjectMeta.Labels[pipeline.GroupName+pipeline.CacheKeyLabelKey] = key
+
+	trs, err := c.taskRunLister.TaskRuns(tr.Namespace).List(labels.SelectorFromSet(labels.Set{
+		pipeline.GroupName + pipeline.CacheKeyLabelKey: key,
+	}))
+	if err != nil {
+		return false, err
+	}
+	for _, cached := range trs {
+		if cached.Name != tr.Name && cached.IsSuccessful() {
+			logger.Infof("TaskRun %s reuses the results of TaskRun %s", tr.Name, cached.Name)
+			markCacheHit(tr, cached.Status.TaskRunResults, fmt.Sprintf("TaskRun %s", cached.Name))
+			return true, nil
+		}
+	}
+
+	if tr.Spec.Cache.ConfigMap == "" {
+		return false, nil
+	}
+	cm, err := c.KubeClientSet.CoreV1().ConfigMaps(tr.Namespace).Get(tr.Spec.Cache.ConfigMap, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	value, ok := cm.Data[key]
+	if !ok {
+		return false, nil
+	}
+	var cached cachedResults
+	if err := json.Unmarshal([]byte(value), &cached); err != nil {
+		logger.Warnf("Ignoring the invalid results cached under %s in ConfigMap %s: %v", key, cm.Name, err)
+		return false, nil
+	}
+	logger.Infof("TaskRun %s reuses the results cached in ConfigMap %s", tr.Name, cm.Name)
+	markCacheHit(tr, cached.Results, fmt.Sprintf("ConfigMap %s", cm.Name))
+	return true, nil
+}
+
+// markCacheHit marks the TaskRun successful with the results cached by the specified source,
+// without it having run
+func markCacheHit(tr *v1beta1.TaskRun, results []v1beta1.TaskRunResult, source string) {
+	tr.Status.TaskRunResults = results
+	tr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	tr.Status.SetCondition(&apis.Condition{
+		Type:    apis.ConditionSucceeded,
+		Status:  corev1.ConditionTrue,
+		Reason:  v1beta1.TaskRunReasonCacheHit.String(),
+		Message: fmt.Sprintf("TaskRun %q reused the results cached by %s", tr.Name, source),
+	})
+}
+
+// storeCachedResults stores the results of a successful TaskRun in the ConfigMap of its cache,
+// under its cache key, so that they can still be reused once the TaskRun is deleted. The oldest
+// results are evicted to keep the ConfigMap under maxCacheConfigMapSize. The cache is best effort,
+// so failures are only logged.
+func (c *Reconciler) storeCachedResults(ctx context.Context, tr *v1beta1.TaskRun) {
+	logger := logging.FromContext(ctx)
+	key := tr.ObjectMeta.Labels[pipeline.GroupName+pipeline.CacheKeyLabelKey]
+	if tr.Spec.Cache == nil || tr.Spec.Cache.ConfigMap == "" || key == "" || !tr.IsSuccessful() {
+		return
+	}
+	b, err := json.Marshal(cachedResults{Results: tr.Status.TaskRunResults, StoredAt: metav1.Now()})
+	if err != nil {
+		logger.Warnf("Failed to cache the results of TaskRun %s: %v", tr.Name, err)
+		return
+	}
+
+	configMaps := c.KubeClientSet.CoreV1().ConfigMaps(tr.Namespace)
+	cm, err := configMaps.Get(tr.Spec.Cache.ConfigMap, metav1.GetOptions{})
+	switch {
+	case k8serrors.IsNotFound(err):
+		_, err = configMaps.Create(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      tr.Spec.Cache.ConfigMap,
+				Namespace: tr.Namespace,
+			},
+			Data: map[string]string{key: string(b)},
+		})
+	case err == nil:
+		cm = cm.DeepCopy()
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		cm.Data[key] = string(b)
+		evictCachedResults(cm.Data, maxCacheConfigMapSize)
+		_, err = configMaps.Update(cm)
+	}
+	if err != nil {
+		logger.Warnf("Failed to cache the results of TaskRun %s in ConfigMap %s: %v", tr.Name, tr.Spec.Cache.ConfigMap, err)
+	}
+}
+
+// evictCachedResults removes the oldest results of the data of the ConfigMap of a cache, starting
+// with the invalid ones, until its size is at most maxSize
+func evictCachedResults(data map[string]string, maxSize int) {
+	size := 0
+	for k, v := range data {
+		size += len(k) + len(v)
+	}
+	if size <= maxSize {
+		return
+	}
+	keys := make([]string, 0, len(data))
+	storedAt := make(map[string]time.Time, len(data))
+	for k, v := range data {
+		keys = append(keys, k)
+		var cached cachedResults
+		if err := json.Unmarshal([]byte(v), &cached); err == nil {
+			storedAt[k] = cached.StoredAt.Time
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return storedAt[keys[i]].Before(storedAt[keys[j]]) })
+	for _, k := range keys {
+		if size <= maxSize {
+			return
+		}
+		size -= len(k) + len(data[k])
+		delete(data, k)
+	}
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	test "github.com/tektoncd/pipeline/test"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+	logtesting "knative.dev/pkg/logging/testing"
+)
+
+const (
+	cacheKeyLabelKey = pipeline.GroupName + pipeline.CacheKeyLabelKey
+	cachedImage      = "gcr.io/foo/bar@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+)
+
+var cachedTask = tb.Task("cached-task", tb.TaskNamespace("foo"), tb.TaskSpec(
+	tb.TaskParam("lint", v1beta1.ParamTypeString),
+	tb.TaskResults("out", "the output"),
+	tb.Step(cachedImage, tb.StepCommand("/mycmd"), tb.StepArgs("$(params.lint)")),
+))
+
+func cachedTaskRun(name, lint string) *v1beta1.TaskRun {
+	tr := tb.TaskRun(name, tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
+		tb.TaskRunTaskRef(cachedTask.Name),
+		tb.TaskRunParam("lint", lint),
+	))
+	tr.Spec.Cache = &v1beta1.TaskCache{ConfigMap: "task-cache"}
+	return tr
+}
+
+// reconcileCachedTaskRun reconciles the TaskRun and returns it, along with the number of pods created
+func reconcileCachedTaskRun(t *testing.T, testAssets test.Assets, tr *v1beta1.TaskRun) (*v1beta1.TaskRun, int) {
+	t.Helper()
+	if err := testAssets.Controller.Reconciler.Reconcile(context.Background(), getRunName(tr)); err != nil {
+		t.Fatalf("Unexpected error when reconciling TaskRun %s: %v", tr.Name, err)
+	}
+	newTr, err := testAssets.Clients.Pipeline.TektonV1beta1().TaskRuns(tr.Namespace).Get(tr.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", tr.Name, err)
+	}
+	pods, err := testAssets.Clients.Kube.CoreV1().Pods(tr.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error listing pods: %v", err)
+	}
+	return newTr, len(pods.Items)
+}
+
+func TestReconcile_CacheHit(t *testing.T) {
+	results := []v1beta1.TaskRunResult{{Name: "out", Value: "ok"}}
+	for _, tc := range []struct {
+		name       string
+		lint       string
+		cachedInCM bool
+		wantHit    bool
+	}{{
+		name:    "same params as a successful taskrun",
+		lint:    "all",
+		wantHit: true,
+	}, {
+		name:       "same params as results cached in the configmap",
+		lint:       "all",
+		cachedInCM: true,
+		wantHit:    true,
+	}, {
+		name: "different params",
+		lint: "some",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			first := cachedTaskRun("test-taskrun-first", "all")
+			second := cachedTaskRun("test-taskrun-second", tc.lint)
+			d := test.Data{
+				TaskRuns: []*v1beta1.TaskRun{first, second},
+				Tasks:    []*v1beta1.Task{cachedTask},
+				ServiceAccounts: []*corev1.ServiceAccount{{
+					ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+				}},
+			}
+			testAssets, cancel := getTaskRunController(t, d)
+			defer cancel()
+
+			// the first TaskRun misses the cache and runs
+			first, pods := reconcileCachedTaskRun(t, testAssets, first)
+			key := first.Labels[cacheKeyLabelKey]
+			if key == "" || pods != 1 {
+				t.Fatalf("Expected the first TaskRun to get a cache key and a pod, got key %q and %d pods", key, pods)
+			}
+
+			if tc.cachedInCM {
+				if _, err := testAssets.Clients.Kube.CoreV1().ConfigMaps("foo").Create(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "task-cache", Namespace: "foo"},
+					Data:       map[string]string{key: `{"results":[{"name":"out","value":"ok"}],"storedAt":"2020-06-01T00:00:00Z"}`},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				first.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue})
+				first.Status.TaskRunResults = results
+			}
+			if err := testAssets.Informers.TaskRun.Informer().GetIndexer().Update(first); err != nil {
+				t.Fatal(err)
+			}
+
+			second, pods = reconcileCachedTaskRun(t, testAssets, second)
+			condition := second.Status.GetCondition(apis.ConditionSucceeded)
+			if !tc.wantHit {
+				if pods != 2 || condition.Reason == v1beta1.TaskRunReasonCacheHit.String() {
+					t.Errorf("Expected the second TaskRun to miss the cache and run, got %d pods and condition %v", pods, condition)
+				}
+				return
+			}
+			if pods != 1 || !condition.IsTrue() || condition.Reason != v1beta1.TaskRunReasonCacheHit.String() {
+				t.Errorf("Expected the second TaskRun to succeed with reason %s without a pod, got %d pods and condition %v", v1beta1.TaskRunReasonCacheHit, pods, condition)
+			}
+			if d := cmp.Diff(results, second.Status.TaskRunResults); d != "" {
+				t.Errorf("Expected the cached results to be reused %s", diff.PrintWantGot(d))
+			}
+			if second.Labels[cacheKeyLabelKey] != key {
+				t.Errorf("Expected the second TaskRun to have the cache key %q, got %q", key, second.Labels[cacheKeyLabelKey])
+			}
+		})
+	}
+}
+
+func TestStoreCachedResults(t *testing.T) {
+	tr := cachedTaskRun("test-taskrun", "all")
+	tr.Labels = map[string]string{cacheKeyLabelKey: "abc"}
+	tr.Status = v1beta1.TaskRunStatus{
+		Status: duckv1beta1.Status{Conditions: []apis.Condition{{
+			Type:   apis.ConditionSucceeded,
+			Status: corev1.ConditionTrue,
+		}}},
+		TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+			TaskRunResults: []v1beta1.TaskRunResult{{Name: "out", Value: "ok"}},
+		},
+	}
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	c := &Reconciler{KubeClientSet: kubeClient}
+	ctx := logtesting.TestContextWithLogger(t)
+
+	c.storeCachedResults(ctx, tr)
+	tr.Labels[cacheKeyLabelKey] = "def"
+	c.storeCachedResults(ctx, tr)
+
+	cm, err := kubeClient.CoreV1().ConfigMaps("foo").Get("task-cache", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the results to be cached in ConfigMap task-cache: %v", err)
+	}
+	got := map[string][]v1beta1.TaskRunResult{}
+	for key, value := range cm.Data {
+		var cached cachedResults
+		if err := json.Unmarshal([]byte(value), &cached); err != nil {
+			t.Fatalf("Invalid results cached under %s: %v", key, err)
+		}
+		got[key] = cached.Results
+	}
+	want := map[string][]v1beta1.TaskRunResult{
+		"abc": {{Name: "out", Value: "ok"}},
+		"def": {{Name: "out", Value: "ok"}},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Unexpected cached results %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestReconcile_CacheWithWorkspaces(t *testing.T) {
+	task := cachedTask.DeepCopy()
+	task.Spec.Workspaces = []v1beta1.WorkspaceDeclaration{{Name: "source"}}
+	first := cachedTaskRun("test-taskrun-first", "all")
+	first.Spec.Workspaces = []v1beta1.WorkspaceBinding{{Name: "source", EmptyDir: &corev1.EmptyDirVolumeSource{}}}
+	first.Labels = map[string]string{cacheKeyLabelKey: "abc"}
+	first.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue})
+	second := cachedTaskRun("test-taskrun-second", "all")
+	second.Spec.Workspaces = first.Spec.Workspaces
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{first, second},
+		Tasks:    []*v1beta1.Task{task},
+		ServiceAccounts: []*corev1.ServiceAccount{{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+		}},
+	}
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+
+	second, pods := reconcileCachedTaskRun(t, testAssets, second)
+	if pods != 1 || second.Labels[cacheKeyLabelKey] != "" {
+		t.Errorf("Expected the TaskRun with workspaces to run without a cache key, got %d pods and labels %v", pods, second.Labels)
+	}
+}
+
+func TestEvictCachedResults(t *testing.T) {
+	entry := func(storedAt string) string {
+		return `{"results":[{"name":"out","value":"ok"}],"storedAt":"` + storedAt + `"}`
+	}
+	data := map[string]string{
+		"old":     entry("2020-06-01T00:00:00Z"),
+		"new":     entry("2020-06-03T00:00:00Z"),
+		"middle":  entry("2020-06-02T00:00:00Z"),
+		"invalid": "[",
+	}
+	size := len("new") + len(data["new"]) + len("middle") + len(data["middle"])
+
+	evictCachedResults(data, size)
+	want := map[string]string{
+		"new":    entry("2020-06-03T00:00:00Z"),
+		"middle": entry("2020-06-02T00:00:00Z"),
+	}
+	if d := cmp.Diff(want, data); d != "" {
+		t.Errorf("Unexpected cached results after eviction %s", diff.PrintWantGot(d))
+	}
+
+	evictCachedResults(data, size)
+	if d := cmp.Diff(want, data); d != "" {
+		t.Errorf("Expected no eviction under the maximum size %s", diff.PrintWantGot(d))
+	}
+}
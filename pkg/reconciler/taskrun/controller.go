@@ -30,6 +30,7 @@ import (
 	resourceinformer "github.com/tektoncd/pipeline/pkg/client/resource/injection/informers/resource/v1alpha1/pipelineresource"
 	"github.com/tektoncd/pipeline/pkg/pod"
 	cloudeventclient "github.com/tektoncd/pipeline/pkg/reconciler/events/cloudevent"
+	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
 	"github.com/tektoncd/pipeline/pkg/reconciler/volumeclaim"
 	"github.com/tektoncd/pipeline/pkg/timeout"
 	"k8s.io/client-go/tools/cache"
@@ -76,10 +77,12 @@ func NewController(namespace string, images pipeline.Images) func(context.Contex
 			metrics:           metrics,
 			entrypointCache:   entrypointCache,
 			pvcHandler:        volumeclaim.NewPVCHandler(kubeclientset, logger),
+			remoteConfigStore: resources.NewRemoteConfigStore(kubeclientset),
 		}
 		impl := taskrunreconciler.NewImpl(ctx, c, func(impl *controller.Impl) controller.Options {
 			configStore := config.NewStore(logger.Named("config-store"))
 			configStore.WatchConfigs(cmw)
+			c.remoteConfigStore.WatchConfigs(cmw)
 
 			return controller.Options{
 				AgentName:   pipeline.TaskRunControllerName,
@@ -87,6 +90,7 @@ func NewController(namespace string, images pipeline.Images) func(context.Contex
 			}
 		})
 
+		c.enqueueAfter = impl.EnqueueKeyAfter
 		timeoutHandler.SetCallbackFunc(impl.EnqueueKey)
 		timeoutHandler.CheckTimeouts(ctx, namespace, kubeclientset, pipelineclientset)
 
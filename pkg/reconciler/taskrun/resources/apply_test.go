@@ -572,6 +572,38 @@ func TestApplyParameters(t *testing.T) {
 	}
 }
 
+func TestApplyObjectParameters(t *testing.T) {
+	ts := &v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{Container: corev1.Container{
+			Name:  "deploy",
+			Image: "$(params.image.registry)/app@$(params.image.digest)",
+			Args:  []string{"--target=$(params.target.cluster)"},
+		}}},
+	}
+	tr := &v1beta1.TaskRun{
+		Spec: v1beta1.TaskRunSpec{
+			Params: []v1beta1.Param{{
+				Name:  "image",
+				Value: *v1beta1.NewObject(map[string]string{"registry": "gcr.io", "digest": "sha256:1234"}),
+			}},
+		},
+	}
+	dp := []v1beta1.ParamSpec{{
+		Name:       "target",
+		Type:       v1beta1.ParamTypeObject,
+		Properties: map[string]v1beta1.PropertySpec{"cluster": {}},
+		Default:    v1beta1.NewObject(map[string]string{"cluster": "staging"}),
+	}}
+	want := applyMutation(ts, func(spec *v1beta1.TaskSpec) {
+		spec.Steps[0].Image = "gcr.io/app@sha256:1234"
+		spec.Steps[0].Args = []string{"--target=staging"}
+	})
+	got := resources.ApplyParameters(ts, tr, dp...)
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("ApplyParameters() got diff %s", diff.PrintWantGot(d))
+	}
+}
+
 func TestApplyResources(t *testing.T) {
 	tests := []struct {
 		name string
@@ -17,11 +17,25 @@ limitations under the License.
 package resources
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"sync"
 
+	"github.com/google/go-containerregistry/pkg/authn/k8schain"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	clientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	"github.com/tektoncd/pipeline/pkg/remote"
+	gitresolver "github.com/tektoncd/pipeline/pkg/remote/git"
+	httpresolver "github.com/tektoncd/pipeline/pkg/remote/http"
+	"github.com/tektoncd/pipeline/pkg/remote/oci"
+	"github.com/tektoncd/pipeline/pkg/system"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/logging"
 )
 
 // LocalTaskRefResolver uses the current cluster to resolve a task reference.
@@ -48,3 +62,203 @@ func (l *LocalTaskRefResolver) GetTask(name string) (v1beta1.TaskInterface, erro
 	}
 	return l.Tektonclient.TektonV1beta1().Tasks(l.Namespace).Get(name, metav1.GetOptions{})
 }
+
+// RemoteTaskRefResolver uses a remote.Resolver, such as a git repository, to resolve a task reference.
+type RemoteTaskRefResolver struct {
+	Resolver remote.Resolver
+	Kind     v1beta1.TaskKind
+}
+
+// GetTask will resolve either a Task or ClusterTask stored in the remote location. It will return an error if it
+// can't find an appropriate Task for any reason.
+func (r *RemoteTaskRefResolver) GetTask(name string) (v1beta1.TaskInterface, error) {
+	kind := v1beta1.NamespacedTaskKind
+	if r.Kind != "" {
+		kind = r.Kind
+	}
+	obj, err := r.Resolver.Get(strings.ToLower(string(kind)), name)
+	if err != nil {
+		return nil, err
+	}
+	switch t := obj.(type) {
+	case *v1beta1.Task:
+		return t, nil
+	case *v1beta1.ClusterTask:
+		return t, nil
+	case *v1alpha1.Task:
+		task := &v1beta1.Task{}
+		if err := t.ConvertTo(context.Background(), task); err != nil {
+			return nil, err
+		}
+		return task, nil
+	case *v1alpha1.ClusterTask:
+		task := &v1beta1.ClusterTask{}
+		if err := t.ConvertTo(context.Background(), task); err != nil {
+			return nil, err
+		}
+		return task, nil
+	}
+	return nil, fmt.Errorf("failed to convert %T to a %s", obj, kind)
+}
+
+// RemoteOptions configure the remote.Resolvers of the Tasks and Pipelines referenced by the runs of a namespace.
+type RemoteOptions struct {
+	// Verifier checks the signatures of the remote resources
+	Verifier *remote.Verifier
+	// AllowedHosts are the hosts the http resolver may fetch from
+	AllowedHosts []string
+	// GitAllowedHosts are the hosts the git resolver may fetch from
+	GitAllowedHosts []string
+}
+
+// GetRemoteOptions is a function that returns the RemoteOptions of the runs of a namespace.
+type GetRemoteOptions func() (*RemoteOptions, error)
+
+// NewRemoteResolver returns the remote.Resolver which retrieves the resources described by the given reference,
+// configured by opts, which may be nil.
+func NewRemoteResolver(ctx context.Context, ref v1beta1.ResolverRef, opts *RemoteOptions) (remote.Resolver, error) {
+	if opts == nil {
+		opts = &RemoteOptions{}
+	}
+	switch ref.Resolver {
+	case v1beta1.GitResolver:
+		return gitresolver.NewResolver(ref.ParamValue(v1beta1.GitURLParam), ref.ParamValue(v1beta1.GitRevisionParam),
+			ref.ParamValue(v1beta1.GitPathParam), opts.GitAllowedHosts, opts.Verifier, logging.FromContext(ctx)), nil
+	case v1beta1.HTTPResolver:
+		return httpresolver.NewResolver(ref.ParamValue(v1beta1.HTTPURLParam), ref.ParamValue(v1beta1.HTTPSHA256Param),
+			opts.AllowedHosts, opts.Verifier), nil
+	}
+	return nil, fmt.Errorf("unsupported resolver %q", ref.Resolver)
+}
+
+// LoadRemoteOptions returns the RemoteOptions returned by getOptions, or empty RemoteOptions if it is nil.
+func LoadRemoteOptions(getOptions GetRemoteOptions) (*RemoteOptions, error) {
+	if getOptions == nil {
+		return &RemoteOptions{}, nil
+	}
+	return getOptions()
+}
+
+// NewBundleResolver returns the resolver retrieving the contents of bundle with the image pull secrets of the
+// service account serviceAccountName in namespace, checking its signatures with verifier.
+func NewBundleResolver(kubeclient kubernetes.Interface, namespace, serviceAccountName, bundle string, verifier *remote.Verifier) (*oci.Resolver, error) {
+	kc, err := k8schain.New(kubeclient, k8schain.Options{
+		Namespace:          namespace,
+		ServiceAccountName: serviceAccountName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating k8schain: %w", err)
+	}
+	return oci.NewResolver(bundle, kc, verifier), nil
+}
+
+// RemoteConfigStore keeps the ConfigMaps of the namespace of the controller which configure the remote.Resolvers:
+// the verification policies and the hosts allowed for the http and git resolvers.
+type RemoteConfigStore struct {
+	kubeclient kubernetes.Interface
+
+	mu         sync.RWMutex
+	configMaps map[string]*corev1.ConfigMap
+}
+
+// NewRemoteConfigStore returns a RemoteConfigStore which reads the Secrets holding the keys of the verification
+// policies with kubeclient.
+func NewRemoteConfigStore(kubeclient kubernetes.Interface) *RemoteConfigStore {
+	return &RemoteConfigStore{
+		kubeclient: kubeclient,
+		configMaps: map[string]*corev1.ConfigMap{},
+	}
+}
+
+// WatchConfigs keeps the ConfigMaps of the store up to date with cmw. A ConfigMap which doesn't exist configures
+// nothing if cmw is a configmap.DefaultingWatcher.
+func (s *RemoteConfigStore) WatchConfigs(cmw configmap.Watcher) {
+	for _, name := range []string{remote.VerificationPolicyConfigName, httpresolver.ConfigName, gitresolver.ConfigName} {
+		if dw, ok := cmw.(configmap.DefaultingWatcher); ok {
+			dw.WatchWithDefault(corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: system.GetNamespace()},
+			}, s.setConfigMap)
+		} else {
+			cmw.Watch(name, s.setConfigMap)
+		}
+	}
+}
+
+func (s *RemoteConfigStore) setConfigMap(cm *corev1.ConfigMap) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.configMaps[cm.Name] = cm.DeepCopy()
+}
+
+// configMap returns the ConfigMap name of the store, which is empty if it wasn't observed.
+func (s *RemoteConfigStore) configMap(name string) *corev1.ConfigMap {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if cm, ok := s.configMaps[name]; ok {
+		return cm
+	}
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+// Load returns the RemoteOptions of the runs of namespace: the Verifier enforcing the verification policies, and
+// the hosts allowed for namespace by the ConfigMaps of the http and git resolvers. The keys of the policies which
+// are in Secrets are read from the namespace of the controller at each call, so it is only called to resolve a
+// reference to a bundle or to a remote location.
+func (s *RemoteConfigStore) Load(namespace string) (*RemoteOptions, error) {
+	verifier, err := remote.NewVerifierFromConfigMap(s.configMap(remote.VerificationPolicyConfigName), func(name string) (*corev1.Secret, error) {
+		return s.kubeclient.CoreV1().Secrets(system.GetNamespace()).Get(name, metav1.GetOptions{})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the verification policies: %w", err)
+	}
+	return &RemoteOptions{
+		Verifier:        verifier,
+		AllowedHosts:    remote.AllowedHosts(s.configMap(httpresolver.ConfigName), namespace),
+		GitAllowedHosts: remote.AllowedHosts(s.configMap(gitresolver.ConfigName), namespace),
+	}, nil
+}
+
+// GetRemoteOptionsFunc returns the function which loads the RemoteOptions of the runs of namespace the first time
+// it is called, and returns the same RemoteOptions afterwards.
+func (s *RemoteConfigStore) GetRemoteOptionsFunc(namespace string) GetRemoteOptions {
+	var once sync.Once
+	var opts *RemoteOptions
+	var err error
+	return func() (*RemoteOptions, error) {
+		once.Do(func() {
+			opts, err = s.Load(namespace)
+		})
+		return opts, err
+	}
+}
+
+// GetTaskFunc returns the function which resolves the given task reference: from its bundle, retrieved by getBundle
+// with the credentials of serviceAccountName, or from the remote location it names if it uses a resolver configured
+// by the RemoteOptions returned by getOptions, otherwise with getTask or getClusterTask depending on its kind.
+func GetTaskFunc(ctx context.Context, ref *v1beta1.TaskRef, serviceAccountName string, getTask GetTask, getClusterTask GetClusterTask, getBundle GetBundle, getOptions GetRemoteOptions) (GetTask, error) {
+	if ref != nil && ref.Bundle != "" {
+		if getBundle == nil {
+			return nil, fmt.Errorf("cannot retrieve task %s from bundle %s", ref.Name, ref.Bundle)
+		}
+		resolver, err := getBundle(ref.Bundle, serviceAccountName)
+		if err != nil {
+			return nil, err
+		}
+		return (&RemoteTaskRefResolver{Resolver: resolver, Kind: ref.Kind}).GetTask, nil
+	}
+	if ref != nil && ref.IsRemote() {
+		opts, err := LoadRemoteOptions(getOptions)
+		if err != nil {
+			return nil, err
+		}
+		resolver, err := NewRemoteResolver(ctx, ref.ResolverRef, opts)
+		if err != nil {
+			return nil, err
+		}
+		return (&RemoteTaskRefResolver{Resolver: resolver, Kind: ref.Kind}).GetTask, nil
+	}
+	if ref != nil && ref.Kind == v1beta1.ClusterTaskKind {
+		return GetTask(getClusterTask), nil
+	}
+	return getTask, nil
+}
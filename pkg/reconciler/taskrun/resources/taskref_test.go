@@ -17,15 +17,26 @@
 package resources_test
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+	"knative.dev/pkg/configmap"
 
 	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
+	"github.com/tektoncd/pipeline/pkg/remote"
+	gitresolver "github.com/tektoncd/pipeline/pkg/remote/git"
+	httpresolver "github.com/tektoncd/pipeline/pkg/remote/http"
+	"github.com/tektoncd/pipeline/pkg/system"
 	"github.com/tektoncd/pipeline/test/diff"
 )
 
@@ -96,3 +107,194 @@ func TestTaskRef(t *testing.T) {
 		})
 	}
 }
+
+// fakeRemoteResolver returns the objects it holds, keyed by kind and name
+type fakeRemoteResolver map[string]runtime.Object
+
+func (f fakeRemoteResolver) List() ([]remote.ResolvedObject, error) {
+	return nil, nil
+}
+
+func (f fakeRemoteResolver) Get(kind, name string) (runtime.Object, error) {
+	if obj, ok := f[kind+"/"+name]; ok {
+		return obj, nil
+	}
+	return nil, fmt.Errorf("%s %s not found", kind, name)
+}
+
+func TestRemoteTaskRef(t *testing.T) {
+	resolver := fakeRemoteResolver{
+		"task/simple":         tb.Task("simple"),
+		"clustertask/cluster": tb.ClusterTask("cluster"),
+		"task/alpha":          &v1alpha1.Task{ObjectMeta: metav1.ObjectMeta{Name: "alpha"}},
+		"task/not-a-task":     tb.Pipeline("not-a-task"),
+	}
+	testcases := []struct {
+		name     string
+		ref      *v1beta1.TaskRef
+		expected runtime.Object
+		wantErr  bool
+	}{{
+		name:     "remote-task",
+		ref:      &v1beta1.TaskRef{Name: "simple"},
+		expected: tb.Task("simple"),
+	}, {
+		name:     "remote-clustertask",
+		ref:      &v1beta1.TaskRef{Name: "cluster", Kind: v1beta1.ClusterTaskKind},
+		expected: tb.ClusterTask("cluster"),
+	}, {
+		name:     "remote-v1alpha1-task",
+		ref:      &v1beta1.TaskRef{Name: "alpha"},
+		expected: &v1beta1.Task{ObjectMeta: metav1.ObjectMeta{Name: "alpha"}},
+	}, {
+		name:    "remote-task-not-found",
+		ref:     &v1beta1.TaskRef{Name: "missing"},
+		wantErr: true,
+	}, {
+		name:    "remote-object-not-a-task",
+		ref:     &v1beta1.TaskRef{Name: "not-a-task"},
+		wantErr: true,
+	}}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			rc := &resources.RemoteTaskRefResolver{
+				Resolver: resolver,
+				Kind:     tc.ref.Kind,
+			}
+
+			task, err := rc.GetTask(tc.ref.Name)
+			if tc.wantErr && err == nil {
+				t.Fatal("Expected error but found nil instead")
+			} else if !tc.wantErr && err != nil {
+				t.Fatalf("Received unexpected error ( %#v )", err)
+			}
+
+			if d := cmp.Diff(tc.expected, task); tc.expected != nil && d != "" {
+				t.Error(diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestGetTaskFunc(t *testing.T) {
+	getTask := func(name string) (v1beta1.TaskInterface, error) { return tb.Task(name), nil }
+	getClusterTask := func(name string) (v1beta1.TaskInterface, error) { return tb.ClusterTask(name), nil }
+	getBundle := func(bundle, serviceAccountName string) (remote.Resolver, error) {
+		return fakeRemoteResolver{"task/bundled": tb.Task("bundled", tb.TaskNamespace(bundle+" as "+serviceAccountName))}, nil
+	}
+
+	for _, tc := range []struct {
+		name     string
+		ref      *v1beta1.TaskRef
+		expected runtime.Object
+	}{{
+		name:     "task",
+		ref:      &v1beta1.TaskRef{Name: "simple"},
+		expected: tb.Task("simple"),
+	}, {
+		name:     "clustertask",
+		ref:      &v1beta1.TaskRef{Name: "simple", Kind: v1beta1.ClusterTaskKind},
+		expected: tb.ClusterTask("simple"),
+	}, {
+		name:     "task in bundle",
+		ref:      &v1beta1.TaskRef{Name: "bundled", Bundle: "gcr.io/tekton/tasks:v1"},
+		expected: tb.Task("bundled", tb.TaskNamespace("gcr.io/tekton/tasks:v1 as default")),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			fn, err := resources.GetTaskFunc(context.Background(), tc.ref, "default", getTask, getClusterTask, getBundle, nil)
+			if err != nil {
+				t.Fatalf("Received unexpected error ( %#v )", err)
+			}
+			task, err := fn(tc.ref.Name)
+			if err != nil {
+				t.Fatalf("Received unexpected error ( %#v )", err)
+			}
+			if d := cmp.Diff(tc.expected, task); d != "" {
+				t.Error(diff.PrintWantGot(d))
+			}
+		})
+	}
+
+	ref := &v1beta1.TaskRef{Name: "simple", ResolverRef: v1beta1.ResolverRef{Resolver: "bucket"}}
+	if _, err := resources.GetTaskFunc(context.Background(), ref, "default", getTask, getClusterTask, getBundle, nil); err == nil {
+		t.Error("Expected error for an unsupported resolver but found nil instead")
+	}
+}
+
+func TestRemoteConfigStore(t *testing.T) {
+	kubeclient := fakek8s.NewSimpleClientset()
+	cmw := configmap.NewInformedWatcher(kubeclient, system.GetNamespace())
+	store := resources.NewRemoteConfigStore(kubeclient)
+	store.WatchConfigs(cmw)
+	if err := cmw.Start(make(chan struct{})); err != nil {
+		t.Fatalf("Failed to start the ConfigMap watcher: %v", err)
+	}
+	opts, err := store.Load("ci")
+	if err != nil {
+		t.Fatalf("Received unexpected error ( %#v )", err)
+	}
+	if opts.Verifier.Enforced("gcr.io/tekton/tasks") || len(opts.AllowedHosts) != 0 || len(opts.GitAllowedHosts) != 0 {
+		t.Errorf("Expected no verification and no allowed hosts without configuration, got %+v", opts)
+	}
+
+	kubeclient = fakek8s.NewSimpleClientset()
+	store = resources.NewRemoteConfigStore(kubeclient)
+	store.WatchConfigs(configmap.NewStaticWatcher(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: httpresolver.ConfigName, Namespace: system.GetNamespace()},
+		Data:       map[string]string{"ci": "artifacts.example.com"},
+	}, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: gitresolver.ConfigName, Namespace: system.GetNamespace()},
+		Data:       map[string]string{"ci": "github.com"},
+	}, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: remote.VerificationPolicyConfigName, Namespace: system.GetNamespace()},
+	}))
+	opts, err = store.Load("ci")
+	if err != nil {
+		t.Fatalf("Received unexpected error ( %#v )", err)
+	}
+	if d := cmp.Diff([]string{"artifacts.example.com"}, opts.AllowedHosts); d != "" {
+		t.Error(diff.PrintWantGot(d))
+	}
+	if d := cmp.Diff([]string{"github.com"}, opts.GitAllowedHosts); d != "" {
+		t.Error(diff.PrintWantGot(d))
+	}
+	if opts, err = store.Load("default"); err != nil || len(opts.AllowedHosts) != 0 || len(opts.GitAllowedHosts) != 0 {
+		t.Errorf("Expected no allowed hosts for namespace default, got %+v (%v)", opts, err)
+	}
+	if actions := kubeclient.Actions(); len(actions) != 0 {
+		t.Errorf("Expected the configuration to be read from the watched ConfigMaps, got requests %v", actions)
+	}
+}
+
+func TestRemoteConfigStoreSecrets(t *testing.T) {
+	kubeclient := fakek8s.NewSimpleClientset()
+	store := resources.NewRemoteConfigStore(kubeclient)
+	store.WatchConfigs(configmap.NewStaticWatcher(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: httpresolver.ConfigName, Namespace: system.GetNamespace()},
+	}, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: gitresolver.ConfigName, Namespace: system.GetNamespace()},
+	}, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: remote.VerificationPolicyConfigName, Namespace: system.GetNamespace()},
+		Data: map[string]string{remote.VerificationPoliciesKey: `
+- pattern: "gcr.io/tekton/*"
+  keys:
+  - secretRef:
+      name: signing-keys
+      key: cosign.pub
+`},
+	}))
+
+	getOptions := store.GetRemoteOptionsFunc("ci")
+	if actions := kubeclient.Actions(); len(actions) != 0 {
+		t.Fatalf("Expected no request before the options are needed, got %v", actions)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := getOptions(); err == nil {
+			t.Error("Expected error for a missing Secret but found nil instead")
+		}
+	}
+	if actions := kubeclient.Actions(); len(actions) != 1 || !actions[0].Matches("get", "secrets") {
+		t.Errorf("Expected the Secret to be read once, got requests %v", actions)
+	}
+}
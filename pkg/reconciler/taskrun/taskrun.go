@@ -41,12 +41,14 @@ import (
 	"github.com/tektoncd/pipeline/pkg/reconciler/events/cloudevent"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
 	"github.com/tektoncd/pipeline/pkg/reconciler/volumeclaim"
+	"github.com/tektoncd/pipeline/pkg/remote"
 	"github.com/tektoncd/pipeline/pkg/termination"
 	"github.com/tektoncd/pipeline/pkg/timeout"
 	"github.com/tektoncd/pipeline/pkg/workspace"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/controller"
@@ -55,6 +57,10 @@ import (
 	"knative.dev/pkg/tracker"
 )
 
+// breakpointTimeoutGracePeriod is how long the step of a TaskRun which timed out can still be held
+// at a breakpoint, after which the TaskRun fails for its timeout like any other
+const breakpointTimeoutGracePeriod = time.Hour
+
 // Reconciler implements controller.Reconciler for Configuration resources.
 type Reconciler struct {
 	KubeClientSet     kubernetes.Interface
@@ -72,6 +78,9 @@ type Reconciler struct {
 	timeoutHandler    *timeout.Handler
 	metrics           *Recorder
 	pvcHandler        volumeclaim.PvcHandler
+	remoteConfigStore *resources.RemoteConfigStore
+	// enqueueAfter reconciles a TaskRun again after a delay
+	enqueueAfter func(types.NamespacedName, time.Duration)
 }
 
 // Check that our Reconciler implements taskrunreconciler.Interface
@@ -121,6 +130,10 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, tr *v1beta1.TaskRun) pkg
 			return merr.ErrorOrNil()
 		}
 		c.timeoutHandler.Release(tr.GetNamespacedName())
+		if tr.Status.PodName == "" {
+			// no pod was created for the TaskRun, e.g. when it reused cached results
+			return merr.ErrorOrNil()
+		}
 		pod, err := c.KubeClientSet.CoreV1().Pods(tr.Namespace).Get(tr.Status.PodName, metav1.GetOptions{})
 		if err == nil {
 			err = podconvert.StopSidecars(c.Images.NopImage, c.KubeClientSet, *pod)
@@ -165,10 +178,22 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, tr *v1beta1.TaskRun) pkg
 	}
 
 	// Check if the TaskRun has timed out; if it is, this will set its status
-	// accordingly.
-	if tr.HasTimedOut() {
+	// accordingly. A TaskRun whose step is held at a breakpoint keeps its pod
+	// until the step is resumed, so that it can still be debugged, for at most
+	// breakpointTimeoutGracePeriod after its timeout.
+	if tr.HasTimedOut() && tr.Status.Debug != nil && time.Since(tr.Status.StartTime.Time) < tr.GetTimeout()+breakpointTimeoutGracePeriod {
+		logger.Infof("TaskRun %q timed out but its step %q is held for debugging", tr.Name, tr.Status.Debug.ContainerName)
+		c.enqueueAfter(tr.GetNamespacedName(), tr.GetTimeout()+breakpointTimeoutGracePeriod-time.Since(tr.Status.StartTime.Time))
+	} else if tr.HasTimedOut() {
 		message := fmt.Sprintf("TaskRun %q failed to finish within %q", tr.Name, tr.GetTimeout())
-		err := c.failTaskRun(ctx, tr, v1beta1.TaskRunReasonTimedOut, message)
+		reason := v1beta1.TaskRunReasonTimedOut
+		// a TaskRun whose pod could never be scheduled keeps reporting it if the retry policy of its
+		// PipelineTask can retry it for it
+		if cond := tr.Status.GetCondition(apis.ConditionSucceeded); cond != nil && cond.Reason == podconvert.ReasonExceededNodeResources &&
+			tr.Annotations[pipeline.GroupName+pipeline.RetryPolicyAnnotationKey] == "true" {
+			reason = podconvert.ReasonExceededNodeResources
+		}
+		err := c.failTaskRun(ctx, tr, reason, message)
 		return c.finishReconcileUpdateEmitEvents(ctx, tr, before, err)
 	}
 
@@ -211,17 +236,52 @@ func (c *Reconciler) finishReconcileUpdateEmitEvents(ctx context.Context, tr *v1
 	return multierror.Append(previousError, err).ErrorOrNil()
 }
 
-func (c *Reconciler) getTaskResolver(tr *v1beta1.TaskRun) (*resources.LocalTaskRefResolver, v1beta1.TaskKind) {
-	resolver := &resources.LocalTaskRefResolver{
-		Namespace:    tr.Namespace,
-		Tektonclient: c.PipelineClientSet,
-	}
+// getTaskFunc returns the function which retrieves the Task referenced by tr, either from the local cluster, from its
+// bundle or from the remote location named by its resolver, and the kind of the Task. A Task from a bundle or a
+// remote location is verified against the verification policies, and one fetched by the http resolver must be
+// served by a host allowed for the namespace of tr. For a Task in a bundle, it records the bundle
+// pinned by its digest in the status of tr, and keeps reading the same image in the following reconciles.
+func (c *Reconciler) getTaskFunc(ctx context.Context, tr *v1beta1.TaskRun) (resources.GetTask, v1beta1.TaskKind, error) {
 	kind := v1beta1.NamespacedTaskKind
 	if tr.Spec.TaskRef != nil && tr.Spec.TaskRef.Kind == v1beta1.ClusterTaskKind {
 		kind = v1beta1.ClusterTaskKind
 	}
-	resolver.Kind = kind
-	return resolver, kind
+	opts := &resources.RemoteOptions{}
+	if tr.Spec.TaskRef != nil && (tr.Spec.TaskRef.Bundle != "" || tr.Spec.TaskRef.IsRemote()) {
+		var err error
+		if opts, err = c.remoteConfigStore.Load(tr.Namespace); err != nil {
+			return nil, kind, err
+		}
+	}
+	if tr.Spec.TaskRef != nil && tr.Spec.TaskRef.Bundle != "" {
+		bundle := tr.Spec.TaskRef.Bundle
+		if tr.Status.Bundle != "" {
+			bundle = tr.Status.Bundle
+		}
+		resolver, err := resources.NewBundleResolver(c.KubeClientSet, tr.Namespace, tr.Spec.ServiceAccountName, bundle, opts.Verifier)
+		if err != nil {
+			return nil, kind, err
+		}
+		digest, err := resolver.Digest()
+		if err != nil {
+			return nil, kind, err
+		}
+		tr.Status.Bundle = digest
+		return (&resources.RemoteTaskRefResolver{Resolver: resolver, Kind: kind}).GetTask, kind, nil
+	}
+	if tr.Spec.TaskRef != nil && tr.Spec.TaskRef.IsRemote() {
+		resolver, err := resources.NewRemoteResolver(ctx, tr.Spec.TaskRef.ResolverRef, opts)
+		if err != nil {
+			return nil, kind, err
+		}
+		return (&resources.RemoteTaskRefResolver{Resolver: resolver, Kind: kind}).GetTask, kind, nil
+	}
+	resolver := &resources.LocalTaskRefResolver{
+		Namespace:    tr.Namespace,
+		Kind:         kind,
+		Tektonclient: c.PipelineClientSet,
+	}
+	return resolver.GetTask, kind, nil
 }
 
 // `prepare` fetches resources the taskrun depends on, runs validation and conversion
@@ -240,11 +300,19 @@ func (c *Reconciler) prepare(ctx context.Context, tr *v1beta1.TaskRun) (*v1beta1
 	// and may not have had all of the assumed default specified.
 	tr.SetDefaults(contexts.WithUpgradeViaDefaulting(ctx))
 
-	resolver, kind := c.getTaskResolver(tr)
-	taskMeta, taskSpec, err := resources.GetTaskData(ctx, tr, resolver.GetTask)
+	var taskMeta *metav1.ObjectMeta
+	var taskSpec *v1beta1.TaskSpec
+	getTask, kind, err := c.getTaskFunc(ctx, tr)
+	if err == nil {
+		taskMeta, taskSpec, err = resources.GetTaskData(ctx, tr, getTask)
+	}
 	if err != nil {
 		logger.Errorf("Failed to determine Task spec to use for taskrun %s: %v", tr.Name, err)
-		tr.Status.MarkResourceFailed(podconvert.ReasonFailedResolution, err)
+		if errors.Is(err, remote.ErrResourceVerificationFailed) {
+			tr.Status.MarkResourceFailed(podconvert.ReasonResourceVerificationFailed, err)
+		} else {
+			tr.Status.MarkResourceFailed(podconvert.ReasonFailedResolution, err)
+		}
 		return nil, nil, controller.NewPermanentError(err)
 	}
 
@@ -365,6 +433,17 @@ func (c *Reconciler) reconcile(ctx context.Context, tr *v1beta1.TaskRun,
 	}
 
 	if pod == nil {
+		if tr.Spec.Cache != nil {
+			hit, err := c.reuseCachedResults(ctx, tr, rtr)
+			if err != nil {
+				logger.Errorf("Failed to look up the cached results of taskrun %q: %v", tr.Name, err)
+				return err
+			}
+			if hit {
+				return nil
+			}
+		}
+
 		if tr.HasVolumeClaimTemplate() {
 			if err := c.pvcHandler.CreatePersistentVolumeClaimsForWorkspaces(tr.Spec.Workspaces, tr.GetOwnerReference(), tr.Namespace); err != nil {
 				logger.Errorf("Failed to create PVC for TaskRun %s: %v", tr.Name, err)
@@ -408,6 +487,8 @@ func (c *Reconciler) reconcile(ctx context.Context, tr *v1beta1.TaskRun,
 	if err := updateTaskRunResourceResult(tr, *pod); err != nil {
 		return err
 	}
+	updateTaskRunResultTypes(tr, taskSpec)
+	c.storeCachedResults(ctx, tr)
 
 	logger.Infof("Successfully reconciled taskrun %s/%s with status: %#v", tr.Name, tr.Namespace, tr.Status.GetCondition(apis.ConditionSucceeded))
 	return nil
@@ -654,10 +735,26 @@ func updateTaskRunResourceResult(taskRun *v1beta1.TaskRun, pod corev1.Pod) error
 	return nil
 }
 
+// updateTaskRunResultTypes records the type the Task declares for each of the TaskRun's results
+// so that array and object results can be decoded when they are consumed.
+func updateTaskRunResultTypes(taskRun *v1beta1.TaskRun, taskSpec *v1beta1.TaskSpec) {
+	resultTypes := make(map[string]v1beta1.ResultsType, len(taskSpec.Results))
+	for _, r := range taskSpec.Results {
+		resultTypes[r.Name] = r.Type
+	}
+	for i, r := range taskRun.Status.TaskRunResults {
+		taskRun.Status.TaskRunResults[i].Type = resultTypes[r.Name]
+	}
+}
+
 func getResults(results []v1beta1.PipelineResourceResult) ([]v1beta1.TaskRunResult, []v1beta1.PipelineResourceResult) {
 	var taskResults []v1beta1.TaskRunResult
 	var pipelineResourceResults []v1beta1.PipelineResourceResult
 	for _, r := range results {
+		if podconvert.IsInternalResult(r) {
+			// The state of the step reported by the entrypoint is already in the step's state
+			continue
+		}
 		switch r.ResultType {
 		case v1beta1.TaskRunResultType:
 			taskRunResult := v1beta1.TaskRunResult{
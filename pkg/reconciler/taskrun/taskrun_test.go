@@ -18,8 +18,15 @@ package taskrun
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"net/http/httptest"
+	"net/url"
 	"regexp"
 	"strings"
 	"testing"
@@ -27,6 +34,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-containerregistry/pkg/registry"
 	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
@@ -36,6 +44,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/reconciler/events/cloudevent"
 	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
 	"github.com/tektoncd/pipeline/pkg/reconciler/volumeclaim"
+	"github.com/tektoncd/pipeline/pkg/remote"
 	"github.com/tektoncd/pipeline/pkg/system"
 	"github.com/tektoncd/pipeline/pkg/timeout"
 	"github.com/tektoncd/pipeline/pkg/workspace"
@@ -2028,6 +2037,53 @@ func TestReconcileTimeouts(t *testing.T) {
 			wantEvents: []string{
 				"Warning Failed ",
 			},
+		}, {
+			name: "taskrun with timeout exceeding node resources",
+			taskRun: tb.TaskRun("test-taskrun-timeout-exceeded-node-resources",
+				tb.TaskRunNamespace("foo"),
+				tb.TaskRunAnnotation(pipeline.GroupName+pipeline.RetryPolicyAnnotationKey, "true"),
+				tb.TaskRunSpec(
+					tb.TaskRunTaskRef(simpleTask.Name),
+					tb.TaskRunTimeout(10*time.Second),
+				),
+				tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionUnknown,
+					Reason: podconvert.ReasonExceededNodeResources}),
+					tb.TaskRunStartTime(time.Now().Add(-15*time.Second)))),
+
+			expectedStatus: &apis.Condition{
+				Type:    apis.ConditionSucceeded,
+				Status:  corev1.ConditionFalse,
+				Reason:  podconvert.ReasonExceededNodeResources,
+				Message: `TaskRun "test-taskrun-timeout-exceeded-node-resources" failed to finish within "10s"`,
+			},
+			wantEvents: []string{
+				"Warning Failed ",
+			},
+		}, {
+			name: "taskrun without retry policy with timeout exceeding node resources",
+			taskRun: tb.TaskRun("test-taskrun-timeout-exceeded-node-resources-no-retry",
+				tb.TaskRunNamespace("foo"),
+				tb.TaskRunSpec(
+					tb.TaskRunTaskRef(simpleTask.Name),
+					tb.TaskRunTimeout(10*time.Second),
+				),
+				tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionUnknown,
+					Reason: podconvert.ReasonExceededNodeResources}),
+					tb.TaskRunStartTime(time.Now().Add(-15*time.Second)))),
+
+			expectedStatus: &apis.Condition{
+				Type:    apis.ConditionSucceeded,
+				Status:  corev1.ConditionFalse,
+				Reason:  "TaskRunTimeout",
+				Message: `TaskRun "test-taskrun-timeout-exceeded-node-resources-no-retry" failed to finish within "10s"`,
+			},
+			wantEvents: []string{
+				"Warning Failed ",
+			},
 		}}
 
 	for _, tc := range testcases {
@@ -2060,6 +2116,203 @@ func TestReconcileTimeouts(t *testing.T) {
 	}
 }
 
+func TestReconcileWithTaskInBundle(t *testing.T) {
+	// Set up a fake registry to push the bundle to.
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := fmt.Sprintf("%s/test-bundle/tasks:latest", u.Host)
+	task := tb.Task("test-task-in-bundle", tb.TaskType(), tb.TaskSpec(simpleStep))
+	digest, err := test.CreateImage(bundle, task)
+	if err != nil {
+		t.Fatalf("could not push bundle: %v", err)
+	}
+
+	taskRun := tb.TaskRun("test-taskrun-with-bundle",
+		tb.TaskRunNamespace("foo"),
+		tb.TaskRunSpec(tb.TaskRunTaskRef(task.Name)))
+	taskRun.Spec.TaskRef.Bundle = bundle
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		ServiceAccounts: []*corev1.ServiceAccount{{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+		}},
+	}
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	c := testAssets.Controller
+	clients := testAssets.Clients
+
+	if err := c.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+		t.Fatalf("Unexpected error when reconciling TaskRun: %v", err)
+	}
+	newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+	}
+	if newTr.Status.Bundle != digest {
+		t.Errorf("Expected the bundle %s to be recorded in the status, got %q", digest, newTr.Status.Bundle)
+	}
+	if !newTr.Status.GetCondition(apis.ConditionSucceeded).IsUnknown() {
+		t.Errorf("Expected the TaskRun to be running, got %v", newTr.Status.GetCondition(apis.ConditionSucceeded))
+	}
+	if d := cmp.Diff(&task.Spec, newTr.Status.TaskSpec); d != "" {
+		t.Errorf("TaskSpec diff %s", diff.PrintWantGot(d))
+	}
+	if newTr.Status.PodName == "" {
+		t.Error("Expected a pod to be created for the Task in the bundle")
+	}
+}
+
+func TestReconcileWithUnverifiedTaskInBundle(t *testing.T) {
+	// Set up a fake registry to push the bundle to, without signing it.
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := fmt.Sprintf("%s/test-bundle/tasks:latest", u.Host)
+	task := tb.Task("test-task-in-bundle", tb.TaskType(), tb.TaskSpec(simpleStep))
+	if _, err := test.CreateImage(bundle, task); err != nil {
+		t.Fatalf("could not push bundle: %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	taskRun := tb.TaskRun("test-taskrun-with-unverified-bundle",
+		tb.TaskRunNamespace("foo"),
+		tb.TaskRunSpec(tb.TaskRunTaskRef(task.Name)))
+	taskRun.Spec.TaskRef.Bundle = bundle
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		ServiceAccounts: []*corev1.ServiceAccount{{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+		}},
+		ConfigMaps: []*corev1.ConfigMap{{
+			ObjectMeta: metav1.ObjectMeta{Name: remote.VerificationPolicyConfigName, Namespace: system.GetNamespace()},
+			Data: map[string]string{
+				remote.VerificationPoliciesKey: fmt.Sprintf("- pattern: %s/test-bundle/*\n  keys:\n  - data: %q\n",
+					u.Host, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+			},
+		}},
+	}
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	c := testAssets.Controller
+	clients := testAssets.Clients
+
+	reconcileErr := c.Reconciler.Reconcile(context.Background(), getRunName(taskRun))
+	if !controller.IsPermanentError(reconcileErr) {
+		t.Fatalf("Expected to see a permanent error when reconciling the TaskRun, got %v instead", reconcileErr)
+	}
+	newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+	}
+	condition := newTr.Status.GetCondition(apis.ConditionSucceeded)
+	if condition == nil || condition.Status != corev1.ConditionFalse || condition.Reason != podconvert.ReasonResourceVerificationFailed {
+		t.Errorf("Expected the TaskRun to fail with reason %s, got %v", podconvert.ReasonResourceVerificationFailed, condition)
+	}
+	if newTr.Status.PodName != "" {
+		t.Errorf("Expected no pod to be created for the unverified Task, got %s", newTr.Status.PodName)
+	}
+}
+
+func TestReconcileTimeoutWithStepHeldAtBreakpoint(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		startedAgo  time.Duration
+		wantRunning bool
+	}{{
+		name:        "within grace period",
+		startedAgo:  15 * time.Second,
+		wantRunning: true,
+	}, {
+		name:       "after grace period",
+		startedAgo: 10*time.Second + breakpointTimeoutGracePeriod + time.Minute,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			taskRun := tb.TaskRun("test-taskrun-held-at-breakpoint",
+				tb.TaskRunNamespace("foo"),
+				tb.TaskRunSpec(
+					tb.TaskRunTaskRef(simpleTask.Name),
+					tb.TaskRunTimeout(10*time.Second),
+				),
+				tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionUnknown}),
+					tb.TaskRunStartTime(time.Now().Add(-tc.startedAgo)),
+					tb.PodName("test-taskrun-held-at-breakpoint-pod")))
+			taskRun.Spec.Debug = &v1beta1.TaskRunDebug{Breakpoint: []string{v1beta1.BreakpointOnFailure}}
+			wantDebug := &v1beta1.TaskRunDebugStatus{
+				PodName:       "test-taskrun-held-at-breakpoint-pod",
+				ContainerName: "step-simple-step",
+			}
+			taskRun.Status.Debug = wantDebug
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-taskrun-held-at-breakpoint-pod",
+					Namespace: "foo",
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "step-simple-step"}},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{{
+						Name:  "step-simple-step",
+						Ready: true,
+						State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+					}},
+				},
+			}
+			d := test.Data{
+				TaskRuns: []*v1beta1.TaskRun{taskRun},
+				Tasks:    []*v1beta1.Task{simpleTask},
+				Pods:     []*corev1.Pod{pod},
+			}
+			testAssets, cancel := getTaskRunController(t, d)
+			defer cancel()
+			c := testAssets.Controller
+			clients := testAssets.Clients
+
+			if err := c.Reconciler.Reconcile(context.Background(), getRunName(taskRun)); err != nil {
+				t.Fatalf("Unexpected error when reconciling TaskRun: %v", err)
+			}
+			newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(taskRun.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+			}
+			condition := newTr.Status.GetCondition(apis.ConditionSucceeded)
+			if !tc.wantRunning {
+				if !condition.IsFalse() || condition.Reason != v1beta1.TaskRunReasonTimedOut.String() {
+					t.Errorf("Expected the TaskRun held past the grace period to fail for its timeout, got %v", condition)
+				}
+				return
+			}
+			if !condition.IsUnknown() {
+				t.Errorf("Expected the TaskRun held at a breakpoint to keep running despite its timeout, got %v", condition)
+			}
+			if d := cmp.Diff(wantDebug, newTr.Status.Debug); d != "" {
+				t.Errorf("Debug status diff %s", diff.PrintWantGot(d))
+			}
+			if _, err := clients.Kube.CoreV1().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{}); err != nil {
+				t.Errorf("Expected the pod held at a breakpoint to be left alone, got error getting it: %v", err)
+			}
+		})
+	}
+}
+
 func TestHandlePodCreationError(t *testing.T) {
 	taskRun := tb.TaskRun("test-taskrun-pod-creation-failed", tb.TaskRunSpec(
 		tb.TaskRunTaskRef(simpleTask.Name),
@@ -2339,6 +2592,32 @@ func TestUpdateTaskRunResourceResult(t *testing.T) {
 			Value:       "sha256:1234",
 			ResourceRef: resourcev1alpha1.PipelineResourceRef{Name: "source-image"},
 		}},
+	}, {
+		desc: "exit code of a step continuing on error",
+		pod: corev1.Pod{
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							Message: `[{"key":"StartedAt","value":"2020-01-01T00:00:00.000Z"},{"key":"ExitCode","value":"3"}]`,
+						},
+					},
+				}},
+			},
+		},
+	}, {
+		desc: "timeout of a step which timed out",
+		pod: corev1.Pod{
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							Message: `[{"key":"StartedAt","value":"2020-01-01T00:00:00.000Z"},{"key":"TimedOut","value":"1m0s"}]`,
+						},
+					},
+				}},
+			},
+		},
 	}} {
 		t.Run(c.desc, func(t *testing.T) {
 			names.TestingSeed()
@@ -2458,6 +2737,42 @@ func TestUpdateTaskRunResult2(t *testing.T) {
 	}
 }
 
+func TestUpdateTaskRunResultTypes(t *testing.T) {
+	tr := &v1beta1.TaskRun{
+		Status: v1beta1.TaskRunStatus{
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				TaskRunResults: []v1beta1.TaskRunResult{{
+					Name:  "commit",
+					Value: "abc123",
+				}, {
+					Name:  "modules",
+					Value: `["api","ui"]`,
+				}},
+			},
+		},
+	}
+	ts := &v1beta1.TaskSpec{
+		Results: []v1beta1.TaskResult{{
+			Name: "commit",
+		}, {
+			Name: "modules",
+			Type: v1beta1.ResultsTypeArray,
+		}},
+	}
+	updateTaskRunResultTypes(tr, ts)
+	want := []v1beta1.TaskRunResult{{
+		Name:  "commit",
+		Value: "abc123",
+	}, {
+		Name:  "modules",
+		Value: `["api","ui"]`,
+		Type:  v1beta1.ResultsTypeArray,
+	}}
+	if d := cmp.Diff(want, tr.Status.TaskRunResults); d != "" {
+		t.Errorf("updateTaskRunResultTypes %s", diff.PrintWantGot(d))
+	}
+}
+
 func TestUpdateTaskRunResultTwoResults(t *testing.T) {
 	for _, c := range []struct {
 		desc          string
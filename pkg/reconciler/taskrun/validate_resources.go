@@ -105,6 +105,28 @@ func validateParams(paramSpecs []v1beta1.ParamSpec, params []v1beta1.Param) erro
 		return fmt.Errorf("param types don't match the user-specified type: %s", wrongTypeParamNames)
 	}
 
+	// Make sure object params provide a value for each of the keys declared in the properties.
+	var missingKeysParamNames []string
+	for _, param := range params {
+		if param.Value.Type != v1beta1.ParamTypeObject {
+			continue
+		}
+		for _, paramSpec := range paramSpecs {
+			if paramSpec.Name != param.Name {
+				continue
+			}
+			for key := range paramSpec.Properties {
+				if _, ok := param.Value.ObjectVal[key]; !ok {
+					missingKeysParamNames = append(missingKeysParamNames, param.Name)
+					break
+				}
+			}
+		}
+	}
+	if len(missingKeysParamNames) != 0 {
+		return fmt.Errorf("missing keys for these object params: %s", missingKeysParamNames)
+	}
+
 	return nil
 }
 
@@ -111,6 +111,22 @@ func TestValidateResolvedTaskResources_InvalidParams(t *testing.T) {
 			Name:  "extra",
 			Value: *v1beta1.NewArrayOrString("i am an extra param"),
 		}},
+	}, {
+		name: "missing-object-param-keys",
+		rtr: &resources.ResolvedTaskResources{
+			TaskSpec: &v1beta1.TaskSpec{
+				Steps: task.Spec.Steps,
+				Params: []v1beta1.ParamSpec{{
+					Name:       "foo",
+					Type:       v1beta1.ParamTypeObject,
+					Properties: map[string]v1beta1.PropertySpec{"url": {}, "digest": {}},
+				}},
+			},
+		},
+		params: []v1beta1.Param{{
+			Name:  "foo",
+			Value: *v1beta1.NewObject(map[string]string{"url": "gcr.io/foo"}),
+		}},
 	}}
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
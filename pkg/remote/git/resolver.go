@@ -0,0 +1,358 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned/scheme"
+	"github.com/tektoncd/pipeline/pkg/git"
+	"github.com/tektoncd/pipeline/pkg/remote"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// ConfigName is the name of the ConfigMap, in the namespace of the controller, holding the hosts the
+	// resolver may fetch from for the runs of each namespace, read by remote.AllowedHosts.
+	ConfigName = "config-git-resolver"
+
+	// cacheTTL is how long the objects fetched from a repository are reused before it is fetched again
+	cacheTTL = time.Minute
+)
+
+var (
+	// fetchTimeout bounds the time taken by a fetch, during which the other fetches wait
+	fetchTimeout = time.Minute
+
+	// fetchMu serializes fetches since git.Fetch writes the global git configuration
+	fetchMu sync.Mutex
+
+	cacheMu sync.Mutex
+	cache   = map[string]*cacheEntry{}
+)
+
+// cacheEntry holds the objects fetched from a repository, or the fetch in progress which every resolver of
+// the same objects waits for rather than fetching the repository again.
+type cacheEntry struct {
+	// done is closed once the fetch is over
+	done      chan struct{}
+	objects   []object
+	err       error
+	fetchedAt time.Time
+}
+
+// object is a Tekton object read from a file of the repository
+type object struct {
+	runtime.Object
+	// file is the path of the file in the repository
+	file string
+	// contents and signatures are the contents of the file and of its signature file, if any
+	contents   []byte
+	signatures []byte
+}
+
+var _ remote.Resolver = (*Resolver)(nil)
+
+// Resolver implements the Resolver interface using the YAML files of a git repository.
+type Resolver struct {
+	url          string
+	revision     string
+	path         string
+	allowedHosts []string
+	verifier     *remote.Verifier
+	logger       *zap.SugaredLogger
+}
+
+// NewResolver returns a Resolver for the Tekton objects in the YAML files found under path in the
+// repository at url, at the given revision: a branch, a tag or a commit. The default branch is used
+// if revision is empty, and the whole repository if path is empty. The repository is fetched with git
+// over HTTP or HTTPS, and only from allowedHosts. If verifier enforces a policy for url, the files of the
+// objects retrieved must be signed by a trusted key: the detached signatures of a file are stored
+// next to it, in a file with the SignatureSuffix, encoded in base64 one per line.
+func NewResolver(url, revision, path string, allowedHosts []string, verifier *remote.Verifier, logger *zap.SugaredLogger) *Resolver {
+	return &Resolver{
+		url:          url,
+		revision:     revision,
+		path:         path,
+		allowedHosts: allowedHosts,
+		verifier:     verifier,
+		logger:       logger,
+	}
+}
+
+func (g *Resolver) List() ([]remote.ResolvedObject, error) {
+	objs, err := g.retrieveObjects()
+	if err != nil {
+		return nil, err
+	}
+	contents := make([]remote.ResolvedObject, 0, len(objs))
+	for _, obj := range objs {
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		contents = append(contents, remote.ResolvedObject{
+			Kind:       strings.ToLower(gvk.Kind),
+			APIVersion: gvk.Version,
+			Name:       objectName(obj.Object),
+		})
+	}
+	return contents, nil
+}
+
+func (g *Resolver) Get(kind, name string) (runtime.Object, error) {
+	objs, err := g.retrieveObjects()
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objs {
+		if strings.EqualFold(kind, obj.GetObjectKind().GroupVersionKind().Kind) && name == objectName(obj.Object) {
+			if err := g.verify(obj); err != nil {
+				return nil, err
+			}
+			return obj.Object.DeepCopyObject(), nil
+		}
+	}
+	return nil, fmt.Errorf("Could not find object in repository %s with kind: %s and name: %s", g.url, kind, name)
+}
+
+// verify checks the detached signatures of the file of obj against the keys trusted for the repository.
+func (g *Resolver) verify(obj object) error {
+	location := remote.NormalizeURL(g.url)
+	if !g.verifier.Enforced(location) {
+		return nil
+	}
+	signatures, err := remote.ParseSignatures(obj.signatures)
+	if err == nil {
+		err = g.verifier.Verify(location, obj.contents, signatures)
+	} else {
+		err = fmt.Errorf("%w: %v", remote.ErrResourceVerificationFailed, err)
+	}
+	if err != nil {
+		return fmt.Errorf("Could not verify %s%s: %w", obj.file, remote.SignatureSuffix, err)
+	}
+	return nil
+}
+
+// checkHost returns an error unless u is an HTTP or HTTPS URL of an allowed host.
+func (g *Resolver) checkHost(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%s is not an http or https URL", u)
+	}
+	for _, host := range g.allowedHosts {
+		if strings.EqualFold(host, u.Host) || strings.EqualFold(host, u.Hostname()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("host %s is not allowed by %s", u.Host, ConfigName)
+}
+
+// retrieveObjects returns the objects fetched from the repository, which are reused for cacheTTL. Only one
+// fetch of the same objects runs at a time.
+func (g *Resolver) retrieveObjects() ([]object, error) {
+	u, err := url.Parse(g.url)
+	if err != nil {
+		return nil, fmt.Errorf("%s is an unparseable URL: %w", g.url, err)
+	}
+	if err := g.checkHost(u); err != nil {
+		return nil, err
+	}
+
+	key := strings.Join([]string{g.url, g.revision, g.path}, "@")
+	cacheMu.Lock()
+	entry, ok := cache[key]
+	if ok && (!isDone(entry) || (entry.err == nil && time.Since(entry.fetchedAt) < cacheTTL)) {
+		cacheMu.Unlock()
+		<-entry.done
+		return entry.objects, entry.err
+	}
+	for k, e := range cache {
+		if isDone(e) && time.Since(e.fetchedAt) >= cacheTTL {
+			delete(cache, k)
+		}
+	}
+	entry = &cacheEntry{done: make(chan struct{})}
+	cache[key] = entry
+	cacheMu.Unlock()
+
+	entry.objects, entry.err = g.fetchObjects()
+	entry.fetchedAt = time.Now()
+	close(entry.done)
+	return entry.objects, entry.err
+}
+
+func isDone(entry *cacheEntry) bool {
+	select {
+	case <-entry.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// fetchObjects fetches the revision of the repository in a temporary directory and reads the objects of the
+// files under path.
+func (g *Resolver) fetchObjects() ([]object, error) {
+	p := filepath.Clean(filepath.FromSlash(g.path))
+	if p == ".." || strings.HasPrefix(p, ".."+string(filepath.Separator)) || filepath.IsAbs(p) {
+		return nil, fmt.Errorf("Path %q is outside of the repository %s", g.path, g.url)
+	}
+
+	dir, err := ioutil.TempDir("", "git-resolver-")
+	if err != nil {
+		return nil, fmt.Errorf("Could not create a directory to fetch %s: %w", g.url, err)
+	}
+	defer os.RemoveAll(dir)
+	if err := g.fetch(dir); err != nil {
+		return nil, fmt.Errorf("Could not fetch %s at revision %q: %w", g.url, g.revision, err)
+	}
+
+	// The directories of path must not be symbolic links, which could point out of the repository.
+	root := dir
+	if p != "." {
+		for _, name := range strings.Split(p, string(filepath.Separator)) {
+			root = filepath.Join(root, name)
+			if err := checkNotSymlink(dir, root); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var objs []object
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		file := filepath.ToSlash(strings.TrimPrefix(path, dir+string(filepath.Separator)))
+		contents, err := readFile(dir, path)
+		if err != nil || contents == nil {
+			return err
+		}
+		signatures, err := readFile(dir, path+remote.SignatureSuffix)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		fileObjs, err := readObjects(contents)
+		if err != nil {
+			return fmt.Errorf("Could not read %s: %w", file, err)
+		}
+		for _, obj := range fileObjs {
+			objs = append(objs, object{Object: obj, file: file, contents: contents, signatures: signatures})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objs, nil
+}
+
+// fetch fetches the commit of the revision, without its history, into dir. The redirects of the remote are
+// not followed, since they could lead to a host which isn't allowed.
+func (g *Resolver) fetch(dir string) error {
+	fetchMu.Lock()
+	defer fetchMu.Unlock()
+	return git.Fetch(g.logger, git.FetchSpec{
+		URL:              g.url,
+		Revision:         g.revision,
+		Path:             dir,
+		Depth:            1,
+		SSLVerify:        true,
+		Timeout:          fetchTimeout,
+		DisableRedirects: true,
+	})
+}
+
+// checkNotSymlink returns an error if the file at path, in the repository fetched in dir, is a symbolic link.
+// A symbolic link is an error rather than followed, so that the objects and their signatures are always the
+// contents of the files of the repository.
+func checkNotSymlink(dir, path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("Could not read %s: symbolic links are not supported", strings.TrimPrefix(path, dir+string(filepath.Separator)))
+	}
+	return nil
+}
+
+// readFile returns the contents of the file at path, in the repository fetched in dir, or nil if it isn't a
+// regular file. A symbolic link is an error.
+func readFile(dir, path string) ([]byte, error) {
+	if err := checkNotSymlink(dir, path); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, err
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read %s: %w", strings.TrimPrefix(path, dir+string(filepath.Separator)), err)
+	}
+	return contents, nil
+}
+
+// Utility function to read out the contents of a YAML file as parsed Tekton resources, skipping the
+// documents which are not Tekton resources.
+func readObjects(contents []byte) ([]runtime.Object, error) {
+	var objs []runtime.Object
+	reader := yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(contents)))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return objs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(doc, nil, nil)
+		if runtime.IsNotRegisteredError(err) || runtime.IsMissingKind(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+}
+
+func objectName(obj runtime.Object) string {
+	if o, ok := obj.(interface{ GetName() string }); ok {
+		return o.GetName()
+	}
+	return ""
+}
@@ -0,0 +1,428 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package git
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/remote"
+	"github.com/tektoncd/pipeline/test/diff"
+	"go.uber.org/zap/zaptest"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	buildTask = `apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: build
+spec:
+  steps:
+  - name: build
+    image: golang
+    script: go build ./...
+`
+	tasks = `# the tasks which test and release
+apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: test
+spec:
+  steps:
+  - name: test
+    image: golang
+    script: go test ./...
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-task
+---
+apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: release
+spec:
+  tasks:
+  - name: build
+    taskRef:
+      name: build
+`
+)
+
+// createRepository creates a git repository with the given files, committed in order, and returns its
+// directory and the commit of each file
+func createRepository(t *testing.T, files ...[2]string) (string, []string) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "git-resolver-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	runGit(t, dir, "init")
+	var commits []string
+	for _, f := range files {
+		path := filepath.Join(dir, f[0])
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(f[1]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		commits = append(commits, commit(t, dir, "add "+f[0]))
+	}
+	return dir, commits
+}
+
+// commit commits all the changes of the repository in dir and returns the commit
+func commit(t *testing.T, dir, message string) string {
+	t.Helper()
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-m", message)
+	return runGit(t, dir, "rev-parse", "HEAD")
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// setHome sets HOME to a temporary directory for the duration of the test, since git.Fetch writes to the
+// global git configuration.
+func setHome(t *testing.T) {
+	t.Helper()
+	home, err := ioutil.TempDir("", "git-resolver-home-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	t.Cleanup(func() {
+		os.Setenv("HOME", previous)
+		os.RemoveAll(home)
+	})
+}
+
+// serveRepository serves the repository in dir over HTTP with git http-backend, and returns its URL and host.
+func serveRepository(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	execPath := runGit(t, dir, "--exec-path")
+	server := httptest.NewServer(&cgi.Handler{
+		Path: filepath.Join(execPath, "git-http-backend"),
+		Env:  []string{"GIT_PROJECT_ROOT=" + filepath.Dir(dir), "GIT_HTTP_EXPORT_ALL=1"},
+	})
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server.URL + "/" + filepath.Base(dir), u.Host
+}
+
+func TestGitResolver(t *testing.T) {
+	setHome(t)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, commits := createRepository(t,
+		[2]string{"tekton/build.yaml", buildTask},
+		[2]string{"tekton/ci/tasks.yml", tasks},
+		[2]string{"README.md", "# catalog"},
+	)
+	runGit(t, dir, "tag", "-a", "v1", "-m", "v1", commits[1])
+	runGit(t, dir, "checkout", "-b", "docs")
+	if err := ioutil.WriteFile(filepath.Join(dir, "tekton", "ci", "tasks.yml"), []byte("# moved"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	commit(t, dir, "move the ci tasks")
+	runGit(t, dir, "checkout", "-")
+	url, host := serveRepository(t, dir)
+	allowedHosts := []string{host}
+	logger := zaptest.NewLogger(t).Sugar()
+
+	testcases := []struct {
+		name         string
+		revision     string
+		path         string
+		listExpected []remote.ResolvedObject
+	}{{
+		name: "whole repository",
+		listExpected: []remote.ResolvedObject{
+			{Kind: "task", APIVersion: "v1beta1", Name: "build"},
+			{Kind: "task", APIVersion: "v1beta1", Name: "test"},
+			{Kind: "pipeline", APIVersion: "v1beta1", Name: "release"},
+		},
+	}, {
+		name: "directory",
+		path: "tekton/ci",
+		listExpected: []remote.ResolvedObject{
+			{Kind: "task", APIVersion: "v1beta1", Name: "test"},
+			{Kind: "pipeline", APIVersion: "v1beta1", Name: "release"},
+		},
+	}, {
+		name: "file",
+		path: "tekton/build.yaml",
+		listExpected: []remote.ResolvedObject{
+			{Kind: "task", APIVersion: "v1beta1", Name: "build"},
+		},
+	}, {
+		name:     "revision",
+		revision: commits[0],
+		listExpected: []remote.ResolvedObject{
+			{Kind: "task", APIVersion: "v1beta1", Name: "build"},
+		},
+	}, {
+		name:     "branch",
+		revision: "docs",
+		listExpected: []remote.ResolvedObject{
+			{Kind: "task", APIVersion: "v1beta1", Name: "build"},
+		},
+	}, {
+		name:     "tag",
+		revision: "v1",
+		path:     "tekton/ci",
+		listExpected: []remote.ResolvedObject{
+			{Kind: "task", APIVersion: "v1beta1", Name: "test"},
+			{Kind: "pipeline", APIVersion: "v1beta1", Name: "release"},
+		},
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolver := NewResolver(url, tc.revision, tc.path, allowedHosts, nil, logger)
+			listActual, err := resolver.List()
+			if err != nil {
+				t.Fatalf("unexpected error listing contents of repository: %v", err)
+			}
+			if d := cmp.Diff(tc.listExpected, listActual); d != "" {
+				t.Error(diff.PrintWantGot(d))
+			}
+		})
+	}
+
+	resolver := NewResolver(url, "", "", allowedHosts, nil, logger)
+	obj, err := resolver.Get("task", "build")
+	if err != nil {
+		t.Fatalf("could not retrieve task from repository: %v", err)
+	}
+	task, ok := obj.(*v1beta1.Task)
+	if !ok {
+		t.Fatalf("expected a Task, got %T", obj)
+	}
+	if task.Name != "build" || len(task.Spec.Steps) != 1 || task.Spec.Steps[0].Script != "go build ./..." {
+		t.Errorf("unexpected Task retrieved from repository: %v", task)
+	}
+	if _, err := resolver.Get("pipeline", "build"); err == nil {
+		t.Error("expected an error retrieving a pipeline which does not exist")
+	}
+
+	for _, tc := range []struct {
+		name     string
+		url      string
+		revision string
+		path     string
+		hosts    []string
+	}{{
+		name:  "path outside of the repository",
+		url:   url,
+		path:  "../outside",
+		hosts: allowedHosts,
+	}, {
+		name:     "unknown revision",
+		url:      url,
+		revision: "main",
+		hosts:    allowedHosts,
+	}, {
+		name: "host not allowed",
+		url:  url,
+		// The host without its port is allowed
+		hosts: []string{"example.com"},
+	}, {
+		name: "no allowed hosts",
+		url:  url,
+	}, {
+		name:  "file URL",
+		url:   "file://" + dir,
+		hosts: allowedHosts,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewResolver(tc.url, tc.revision, tc.path, tc.hosts, nil, logger).List(); err == nil {
+				t.Error("expected an error listing the objects")
+			}
+		})
+	}
+	if after, _ := os.Getwd(); after != wd {
+		t.Errorf("expected the working directory %s to be unchanged, got %s", wd, after)
+	}
+}
+
+func TestGitResolverRedirect(t *testing.T) {
+	setHome(t)
+	dir, _ := createRepository(t, [2]string{"tekton/build.yaml", buildTask})
+	repositoryURL, _ := serveRepository(t, dir)
+	// the redirect leads to the repository, on a host which isn't allowed
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, repositoryURL+strings.TrimPrefix(r.URL.RequestURI(), "/"+filepath.Base(dir)), http.StatusFound)
+	}))
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver := NewResolver(server.URL+"/"+filepath.Base(dir), "", "", []string{u.Host}, nil, zaptest.NewLogger(t).Sugar())
+	if _, err := resolver.List(); err == nil {
+		t.Error("expected an error listing the objects of a repository redirected to another host")
+	}
+}
+
+func TestGitResolverTimeout(t *testing.T) {
+	setHome(t)
+	previous := fetchTimeout
+	fetchTimeout = time.Second
+	t.Cleanup(func() { fetchTimeout = previous })
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	_, err = NewResolver(server.URL+"/catalog", "", "", []string{u.Host}, nil, zaptest.NewLogger(t).Sugar()).List()
+	if err == nil {
+		t.Fatal("expected an error listing the objects of a repository which doesn't answer")
+	}
+	if elapsed := time.Since(start); elapsed > 30*time.Second {
+		t.Errorf("expected the fetch to give up after %s, took %s", fetchTimeout, elapsed)
+	}
+	if !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("expected the error %q to report the timeout", err)
+	}
+}
+
+func TestGitResolverSymlinks(t *testing.T) {
+	setHome(t)
+	dir, _ := createRepository(t, [2]string{"tekton/build.yaml", buildTask})
+	if err := os.Symlink("/etc/passwd", filepath.Join(dir, "tekton", "passwd.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("tekton", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	commit(t, dir, "add symbolic links")
+	url, host := serveRepository(t, dir)
+	logger := zaptest.NewLogger(t).Sugar()
+
+	for _, path := range []string{"", "tekton", "tekton/passwd.yaml", "link", "link/build.yaml"} {
+		if _, err := NewResolver(url, "", path, []string{host}, nil, logger).List(); err == nil {
+			t.Errorf("expected an error listing the objects of %q with a symbolic link", path)
+		}
+	}
+	if _, err := NewResolver(url, "", "tekton/build.yaml", []string{host}, nil, logger).List(); err != nil {
+		t.Errorf("unexpected error listing the objects of a file next to a symbolic link: %v", err)
+	}
+}
+
+func TestGitResolverVerification(t *testing.T) {
+	setHome(t)
+	public, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lintTask := strings.Replace(buildTask, "build", "lint", -1)
+	sign := func(key ed25519.PrivateKey, contents string) string {
+		return base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(contents))) + "\n"
+	}
+	dir, _ := createRepository(t,
+		[2]string{"tekton/build.yaml", buildTask},
+		[2]string{"tekton/build.yaml.sig", sign(key, buildTask)},
+		[2]string{"tekton/lint.yaml", lintTask},
+		[2]string{"tekton/lint.yaml.sig", sign(otherKey, lintTask)},
+		[2]string{"tekton/ci/tasks.yml", tasks},
+	)
+	// the repository is served with the .git suffix, which the policy omits
+	if err := os.Rename(dir, dir+".git"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir + ".git") })
+	url, host := serveRepository(t, dir+".git")
+	logger := zaptest.NewLogger(t).Sugar()
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm := &corev1.ConfigMap{Data: map[string]string{
+		remote.VerificationPoliciesKey: fmt.Sprintf("- pattern: %s\n  keys:\n  - data: %q\n",
+			strings.TrimSuffix(url, ".git"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+	}}
+	verifier, err := remote.NewVerifierFromConfigMap(cm, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating the verifier: %v", err)
+	}
+
+	resolver := NewResolver(url, "", "", []string{host}, verifier, logger)
+	if _, err := resolver.Get("task", "build"); err != nil {
+		t.Errorf("unexpected error retrieving a signed task: %v", err)
+	}
+	for _, name := range []string{"lint", "test"} {
+		if _, err := resolver.Get("task", name); !errors.Is(err, remote.ErrResourceVerificationFailed) {
+			t.Errorf("expected ErrResourceVerificationFailed retrieving task %s, got %v", name, err)
+		}
+	}
+	// Another spelling of the URL of the repository is verified too.
+	if _, err := NewResolver(url+"/", "", "", []string{host}, verifier, logger).Get("task", "test"); !errors.Is(err, remote.ErrResourceVerificationFailed) {
+		t.Errorf("expected ErrResourceVerificationFailed retrieving task test from %s/, got %v", url, err)
+	}
+
+	// Without a policy for the repository, the objects are not verified.
+	if _, err := NewResolver(url, "", "", []string{host}, nil, logger).Get("task", "test"); err != nil {
+		t.Errorf("unexpected error retrieving an unsigned task: %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AllowedHosts returns the hosts the runs of namespace may fetch from, listed in the ConfigMap cm of a resolver.
+// Each key of the ConfigMap is a namespace, and its value the comma or whitespace separated list of the hosts
+// allowed for the runs of that namespace.
+func AllowedHosts(cm *corev1.ConfigMap, namespace string) []string {
+	return strings.FieldsFunc(cm.Data[namespace], func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestAllowedHosts(t *testing.T) {
+	cm := &corev1.ConfigMap{Data: map[string]string{
+		"ci":      "artifacts.example.com, mirror.example.com:8443\ncdn.example.com",
+		"default": "",
+	}}
+	if d := cmp.Diff([]string{"artifacts.example.com", "mirror.example.com:8443", "cdn.example.com"}, AllowedHosts(cm, "ci")); d != "" {
+		t.Error(diff.PrintWantGot(d))
+	}
+	for _, namespace := range []string{"default", "other"} {
+		if hosts := AllowedHosts(cm, namespace); len(hosts) != 0 {
+			t.Errorf("expected no hosts allowed for namespace %s, got %v", namespace, hosts)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned/scheme"
+	"github.com/tektoncd/pipeline/pkg/remote"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// ConfigName is the name of the ConfigMap, in the namespace of the controller, holding the hosts the
+	// resolver may fetch from for the runs of each namespace, read by remote.AllowedHosts.
+	ConfigName = "config-http-resolver"
+
+	// cacheSize is the number of responses kept in memory
+	cacheSize = 128
+	// maxSize is the maximum size of a fetched file
+	maxSize = 1 << 20
+	// timeout bounds the time taken by a request, including redirects and reading the response
+	timeout = 30 * time.Second
+)
+
+// cache holds the last response for each URL, which is revalidated with its ETag before being reused.
+var cache *lru.Cache
+
+func init() {
+	var err error
+	if cache, err = lru.New(cacheSize); err != nil {
+		panic(err)
+	}
+}
+
+// response is a response cached for a URL
+type response struct {
+	etag   string
+	sha256 string
+	body   []byte
+}
+
+var _ remote.Resolver = (*Resolver)(nil)
+
+// Resolver implements the Resolver interface using the YAML file at an HTTP or HTTPS URL.
+type Resolver struct {
+	url          string
+	sha256       string
+	allowedHosts []string
+	verifier     *remote.Verifier
+	client       *nethttp.Client
+	// object is the object decoded from the file, kept so that every call of the Resolver reads the same
+	// object.
+	object runtime.Object
+}
+
+// NewResolver returns a Resolver for the Tekton object in the YAML file at url, which must be served by one
+// of allowedHosts. If digest is not empty, the contents of the file must have this SHA-256 digest, hex
+// encoded. If verifier enforces a policy for url, the file must be signed by a trusted key: its detached
+// signatures are served at the same URL with the remote.SignatureSuffix, encoded in base64 one per line.
+func NewResolver(url, digest string, allowedHosts []string, verifier *remote.Verifier) *Resolver {
+	h := &Resolver{
+		url:          url,
+		sha256:       strings.ToLower(digest),
+		allowedHosts: allowedHosts,
+		verifier:     verifier,
+	}
+	h.client = &nethttp.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *nethttp.Request, via []*nethttp.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return h.checkHost(req.URL)
+		},
+	}
+	return h
+}
+
+func (h *Resolver) List() ([]remote.ResolvedObject, error) {
+	obj, err := h.retrieveObject()
+	if err != nil {
+		return nil, err
+	}
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	return []remote.ResolvedObject{{
+		Kind:       strings.ToLower(gvk.Kind),
+		APIVersion: gvk.Version,
+		Name:       objectName(obj),
+	}}, nil
+}
+
+func (h *Resolver) Get(kind, name string) (runtime.Object, error) {
+	obj, err := h.retrieveObject()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(kind, obj.GetObjectKind().GroupVersionKind().Kind) || name != objectName(obj) {
+		return nil, fmt.Errorf("Could not find object at %s with kind: %s and name: %s", h.url, kind, name)
+	}
+	return obj.DeepCopyObject(), nil
+}
+
+// checkHost returns an error unless u is an HTTP or HTTPS URL of an allowed host.
+func (h *Resolver) checkHost(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%s is not an http or https URL", u)
+	}
+	for _, host := range h.allowedHosts {
+		if strings.EqualFold(host, u.Host) || strings.EqualFold(host, u.Hostname()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("host %s is not allowed by %s", u.Host, ConfigName)
+}
+
+// retrieveObject fetches the file, unless it was already fetched by the Resolver, and decodes its object after
+// checking its digest and its signatures.
+func (h *Resolver) retrieveObject() (runtime.Object, error) {
+	if h.object != nil {
+		return h.object, nil
+	}
+	u, err := url.Parse(h.url)
+	if err != nil {
+		return nil, fmt.Errorf("%s is an unparseable URL: %w", h.url, err)
+	}
+	if err := h.checkHost(u); err != nil {
+		return nil, err
+	}
+	body, err := h.fetch()
+	if err != nil {
+		return nil, err
+	}
+	if h.sha256 != "" {
+		if actual := digest(body); actual != h.sha256 {
+			return nil, fmt.Errorf("Contents of %s have the digest sha256:%s instead of sha256:%s", h.url, actual, h.sha256)
+		}
+	}
+	if err := h.verify(body); err != nil {
+		return nil, err
+	}
+	obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(body, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Could not decode the object at %s: %w", h.url, err)
+	}
+	h.object = obj
+	return obj, nil
+}
+
+// fetch returns the contents of the file. A cached response is reused without a request if it has the pinned
+// digest, and otherwise if the server confirms that its ETag still matches.
+func (h *Resolver) fetch() ([]byte, error) {
+	var cached *response
+	if c, ok := cache.Get(h.url); ok {
+		cached = c.(*response)
+		if h.sha256 != "" && cached.sha256 == h.sha256 {
+			return cached.body, nil
+		}
+	}
+	req, err := nethttp.NewRequest(nethttp.MethodGet, h.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil && cached.etag != "" {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Could not fetch %s: %w", h.url, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == nethttp.StatusNotModified && cached != nil:
+		return cached.body, nil
+	case resp.StatusCode != nethttp.StatusOK:
+		return nil, fmt.Errorf("Could not fetch %s: %s", h.url, resp.Status)
+	}
+	body, err := readBody(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read %s: %w", h.url, err)
+	}
+	cache.Add(h.url, &response{
+		etag:   resp.Header.Get("ETag"),
+		sha256: digest(body),
+		body:   body,
+	})
+	return body, nil
+}
+
+// verify checks the detached signatures of the file against the keys trusted for its URL.
+func (h *Resolver) verify(body []byte) error {
+	location := remote.NormalizeURL(h.url)
+	if !h.verifier.Enforced(location) {
+		return nil
+	}
+	signatures, err := h.retrieveSignatures()
+	if err != nil {
+		return err
+	}
+	return h.verifier.Verify(location, body, signatures)
+}
+
+// retrieveSignatures returns the signatures served next to the file, if any.
+func (h *Resolver) retrieveSignatures() ([][]byte, error) {
+	resp, err := h.client.Get(h.url + remote.SignatureSuffix)
+	if err != nil {
+		return nil, fmt.Errorf("Could not fetch the signatures of %s: %w", h.url, err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case nethttp.StatusNotFound:
+		return nil, nil
+	case nethttp.StatusOK:
+	default:
+		return nil, fmt.Errorf("Could not fetch the signatures of %s: %s", h.url, resp.Status)
+	}
+	body, err := readBody(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read the signatures of %s: %w", h.url, err)
+	}
+	signatures, err := remote.ParseSignatures(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s%s: %v", remote.ErrResourceVerificationFailed, h.url, remote.SignatureSuffix, err)
+	}
+	return signatures, nil
+}
+
+// readBody reads a response body of at most maxSize bytes.
+func readBody(r io.Reader) ([]byte, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxSize {
+		return nil, fmt.Errorf("response is larger than %d bytes", maxSize)
+	}
+	return body, nil
+}
+
+func digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+func objectName(obj runtime.Object) string {
+	if o, ok := obj.(interface{ GetName() string }); ok {
+		return o.GetName()
+	}
+	return ""
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/remote"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const buildTask = `apiVersion: tekton.dev/v1beta1
+kind: Task
+metadata:
+  name: build
+spec:
+  steps:
+  - name: build
+    image: golang
+    script: go build ./...
+`
+
+// server serves files, with an ETag, and counts the requests it receives and the bodies it sends.
+type server struct {
+	*httptest.Server
+	files    map[string]string
+	requests int32
+	bodies   int32
+}
+
+func newServer(t *testing.T, files map[string]string) *server {
+	t.Helper()
+	s := &server{files: files}
+	s.Server = httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		atomic.AddInt32(&s.requests, 1)
+		if target, ok := s.files[r.URL.Path+"#redirect"]; ok {
+			nethttp.Redirect(w, r, target, nethttp.StatusFound)
+			return
+		}
+		contents, ok := s.files[r.URL.Path]
+		if !ok {
+			nethttp.NotFound(w, r)
+			return
+		}
+		etag := fmt.Sprintf("%q", digest([]byte(contents)))
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(nethttp.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&s.bodies, 1)
+		fmt.Fprint(w, contents)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestHTTPResolver(t *testing.T) {
+	s := newServer(t, map[string]string{"/tasks/build.yaml": buildTask})
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	taskURL := s.URL + "/tasks/build.yaml"
+
+	resolver := NewResolver(taskURL, "", []string{u.Host}, nil)
+	listActual, err := resolver.List()
+	if err != nil {
+		t.Fatalf("unexpected error listing the object at %s: %v", taskURL, err)
+	}
+	if d := cmp.Diff([]remote.ResolvedObject{{Kind: "task", APIVersion: "v1beta1", Name: "build"}}, listActual); d != "" {
+		t.Error(diff.PrintWantGot(d))
+	}
+	obj, err := resolver.Get("task", "build")
+	if err != nil {
+		t.Fatalf("could not retrieve task: %v", err)
+	}
+	task, ok := obj.(*v1beta1.Task)
+	if !ok {
+		t.Fatalf("expected a Task, got %T", obj)
+	}
+	if task.Name != "build" || len(task.Spec.Steps) != 1 || task.Spec.Steps[0].Script != "go build ./..." {
+		t.Errorf("unexpected Task retrieved: %v", task)
+	}
+	if _, err := resolver.Get("pipeline", "build"); err == nil {
+		t.Error("expected an error retrieving a pipeline which does not exist")
+	}
+	if requests := atomic.LoadInt32(&s.requests); requests != 1 {
+		t.Errorf("expected the resolver to fetch the file once, got %d requests", requests)
+	}
+
+	// Another resolver revalidates the cached response with its ETag.
+	if _, err := NewResolver(taskURL, "", []string{u.Hostname()}, nil).Get("task", "build"); err != nil {
+		t.Fatalf("could not retrieve task: %v", err)
+	}
+	if requests, bodies := atomic.LoadInt32(&s.requests), atomic.LoadInt32(&s.bodies); requests != 2 || bodies != 1 {
+		t.Errorf("expected the cached response to be revalidated, got %d requests and %d bodies", requests, bodies)
+	}
+
+	// A resolver pinning the digest of the cached response doesn't send any request.
+	if _, err := NewResolver(taskURL, digest([]byte(buildTask)), []string{u.Host}, nil).Get("task", "build"); err != nil {
+		t.Fatalf("could not retrieve task: %v", err)
+	}
+	if requests := atomic.LoadInt32(&s.requests); requests != 2 {
+		t.Errorf("expected the pinned response to be read from the cache, got %d requests", requests)
+	}
+}
+
+func TestHTTPResolver_Errors(t *testing.T) {
+	other := newServer(t, map[string]string{"/tasks/build.yaml": buildTask})
+	s := newServer(t, map[string]string{
+		"/tasks/build.yaml":             buildTask,
+		"/tasks/redirect.yaml#redirect": other.URL + "/tasks/build.yaml",
+		"/tasks/configmap.yaml":         "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n",
+	})
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("other contents"))
+
+	for _, tc := range []struct {
+		name         string
+		path         string
+		sha256       string
+		allowedHosts []string
+	}{{
+		name: "host not allowed",
+		path: "/tasks/build.yaml",
+	}, {
+		name:         "digest mismatch",
+		path:         "/tasks/build.yaml",
+		sha256:       hex.EncodeToString(sum[:]),
+		allowedHosts: []string{u.Host},
+	}, {
+		name:         "not found",
+		path:         "/tasks/missing.yaml",
+		allowedHosts: []string{u.Host},
+	}, {
+		name:         "redirect to a host not allowed",
+		path:         "/tasks/redirect.yaml",
+		allowedHosts: []string{u.Host},
+	}, {
+		name:         "not a Tekton object",
+		path:         "/tasks/configmap.yaml",
+		allowedHosts: []string{u.Host},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewResolver(s.URL+tc.path, tc.sha256, tc.allowedHosts, nil).Get("task", "build"); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+	if requests := atomic.LoadInt32(&other.requests); requests != 0 {
+		t.Errorf("expected the redirect to a host not allowed not to be followed, got %d requests", requests)
+	}
+}
+
+func TestHTTPResolverVerification(t *testing.T) {
+	public, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(buildTask))) + "\n"
+	s := newServer(t, map[string]string{
+		"/signed/build.yaml":     buildTask,
+		"/signed/build.yaml.sig": signature,
+		"/unsigned/build.yaml":   buildTask,
+		"/unsigned/build.yaml/":  buildTask,
+	})
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm := &corev1.ConfigMap{Data: map[string]string{
+		remote.VerificationPoliciesKey: fmt.Sprintf("- pattern: %s/signed/*\n  keys:\n  - data: %[2]q\n- pattern: %[1]s/unsigned/build.yaml\n  keys:\n  - data: %[2]q\n",
+			s.URL, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+	}}
+	verifier, err := remote.NewVerifierFromConfigMap(cm, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating the verifier: %v", err)
+	}
+
+	if _, err := NewResolver(s.URL+"/signed/build.yaml", "", []string{u.Host}, verifier).Get("task", "build"); err != nil {
+		t.Errorf("unexpected error retrieving a signed task: %v", err)
+	}
+	// The other spellings of the URL of the unsigned task are verified too.
+	for _, location := range []string{
+		s.URL + "/unsigned/build.yaml",
+		s.URL + "/unsigned/build.yaml/",
+		strings.ToUpper(u.Scheme) + "://" + u.Host + "/unsigned/build.yaml",
+	} {
+		if _, err := NewResolver(location, "", []string{u.Host}, verifier).Get("task", "build"); !errors.Is(err, remote.ErrResourceVerificationFailed) {
+			t.Errorf("expected ErrResourceVerificationFailed retrieving an unsigned task from %s, got %v", location, err)
+		}
+	}
+}
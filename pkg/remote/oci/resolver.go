@@ -17,24 +17,79 @@ limitations under the License.
 package oci
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	imgname "github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	ociremote "github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/tektoncd/pipeline/pkg/client/clientset/versioned/scheme"
 	"github.com/tektoncd/pipeline/pkg/remote"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// cacheSize is the number of images whose contents are kept in memory
+const cacheSize = 128
+
+// acceptableMediaTypes are the media types of the manifests whose digest is requested from the registry
+var acceptableMediaTypes = []string{
+	string(types.DockerManifestSchema2),
+	string(types.OCIManifestSchema1),
+	string(types.DockerManifestList),
+	string(types.OCIImageIndex),
+}
+
+// cache holds the contents of the images retrieved, keyed by digest. Since the digest of an image identifies its
+// contents, an entry never needs to be invalidated. The cache is shared by all the credentials: a Resolver asks the
+// registry for the manifest of the image with its own credentials before reading the cache.
+var cache *lru.Cache
+
+func init() {
+	var err error
+	if cache, err = lru.New(cacheSize); err != nil {
+		panic(err)
+	}
+}
+
 var _ remote.Resolver = (*Resolver)(nil)
 
 // Resolver implements the Resolver interface using OCI images.
 type Resolver struct {
 	imageReference string
 	keychain       authn.Keychain
+	verifier       *remote.Verifier
+	// digest pins the image the first time a tag is resolved, so that every call of the Resolver reads the same
+	// image even if the tag is moved.
+	digest *imgname.Digest
+	// verified is set once the signatures of the image have been verified
+	verified bool
+}
+
+// image holds the contents of an image: the objects described by the annotations of its layers, and the contents
+// of each layer.
+type image struct {
+	contents []remote.ResolvedObject
+	layers   [][]byte
+}
+
+// NewResolver returns a Resolver which retrieves the image imageReference with the credentials of keychain. If
+// verifier enforces a policy for the repository of the image, the image must be signed by a trusted key: its
+// detached signatures are the layers of the image tagged sha256-<hex>.sig in the same repository, each holding
+// signatures of the digest of the image ("sha256:<hex>") encoded in base64.
+func NewResolver(imageReference string, keychain authn.Keychain, verifier *remote.Verifier) *Resolver {
+	return &Resolver{
+		imageReference: imageReference,
+		keychain:       keychain,
+		verifier:       verifier,
+	}
 }
 
 func (o *Resolver) List() ([]remote.ResolvedObject, error) {
@@ -42,61 +97,195 @@ func (o *Resolver) List() ([]remote.ResolvedObject, error) {
 	if err != nil {
 		return nil, err
 	}
+	return img.contents, nil
+}
 
-	manifest, err := img.Manifest()
+func (o *Resolver) Get(kind, name string) (runtime.Object, error) {
+	img, err := o.retrieveImage()
 	if err != nil {
-		return nil, fmt.Errorf("Could not parse image manifest: %w", err)
+		return nil, err
 	}
-	contents := make([]remote.ResolvedObject, 0, len(manifest.Layers))
-	for _, l := range manifest.Layers {
-		contents = append(contents, remote.ResolvedObject{
-			Kind:       l.Annotations["cdf.tekton.image.kind"],
-			APIVersion: l.Annotations["cdf.tekton.image.apiVersion"],
-			Name:       l.Annotations["org.opencontainers.image.title"],
-		})
+
+	for idx, c := range img.contents {
+		if kind == c.Kind && name == c.Name {
+			obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(img.layers[idx], nil, nil)
+			return obj, err
+		}
 	}
+	return nil, fmt.Errorf("Could not find object in image with kind: %s and name: %s", kind, name)
+}
 
-	return contents, nil
+// Digest returns the reference of the image pinned by its digest.
+func (o *Resolver) Digest() (string, error) {
+	digest, err := o.resolveDigest()
+	if err != nil {
+		return "", err
+	}
+	return digest.String(), nil
 }
 
-func (o *Resolver) Get(kind, name string) (runtime.Object, error) {
-	img, err := o.retrieveImage()
+// resolveDigest returns the reference of the image pinned by its digest, asking the registry for the digest of the
+// manifest of the image. The registry is asked even if the image is referenced by its digest, so that the image is
+// only read from the cache if the credentials of the Resolver allow pulling it.
+func (o *Resolver) resolveDigest() (imgname.Digest, error) {
+	if o.digest != nil {
+		return *o.digest, nil
+	}
+	imgRef, err := imgname.ParseReference(o.imageReference)
+	if err != nil {
+		return imgname.Digest{}, fmt.Errorf("%s is an unparseable image reference: %w", o.imageReference, err)
+	}
+	hash, err := headDigest(imgRef, o.keychain)
+	if err != nil {
+		return imgname.Digest{}, fmt.Errorf("Could not resolve the digest of image %s: %w", o.imageReference, err)
+	}
+	digest := imgRef.Context().Digest(hash.String())
+	o.digest = &digest
+	return digest, nil
+}
+
+// headDigest returns the digest of the manifest of the image with a HEAD request, like remote.Head does in later
+// versions of go-containerregistry, so that the manifest is only pulled when the image isn't cached. It falls back
+// on pulling the manifest if the registry doesn't report its digest.
+func headDigest(ref imgname.Reference, keychain authn.Keychain) (v1.Hash, error) {
+	auth, err := keychain.Resolve(ref.Context())
+	if err != nil {
+		return v1.Hash{}, err
+	}
+	tr, err := transport.New(ref.Context().Registry, auth, transport.NewRetry(http.DefaultTransport), []string{ref.Scope(transport.PullScope)})
+	if err != nil {
+		return v1.Hash{}, err
+	}
+	u := url.URL{
+		Scheme: ref.Context().Registry.Scheme(),
+		Host:   ref.Context().RegistryStr(),
+		Path:   fmt.Sprintf("/v2/%s/manifests/%s", ref.Context().RepositoryStr(), ref.Identifier()),
+	}
+	req, err := http.NewRequest(http.MethodHead, u.String(), nil)
+	if err != nil {
+		return v1.Hash{}, err
+	}
+	req.Header.Set("Accept", strings.Join(acceptableMediaTypes, ","))
+	resp, err := (&http.Client{Transport: tr}).Do(req)
+	if err != nil {
+		return v1.Hash{}, err
+	}
+	defer resp.Body.Close()
+	if err := transport.CheckError(resp, http.StatusOK); err != nil {
+		return v1.Hash{}, err
+	}
+
+	hash, err := v1.NewHash(resp.Header.Get("Docker-Content-Digest"))
+	if err != nil {
+		desc, err := ociremote.Get(ref, ociremote.WithAuthFromKeychain(keychain))
+		if err != nil {
+			return v1.Hash{}, err
+		}
+		return desc.Digest, nil
+	}
+	if d, ok := ref.(imgname.Digest); ok && hash.String() != d.DigestStr() {
+		return v1.Hash{}, fmt.Errorf("manifest digest %s does not match requested digest %s", hash, d.DigestStr())
+	}
+	return hash, nil
+}
+
+// retrieveImage will fetch the image's contents and manifest, unless they are already cached, after verifying
+// its signatures.
+func (o *Resolver) retrieveImage() (*image, error) {
+	digest, err := o.resolveDigest()
 	if err != nil {
 		return nil, err
 	}
+	if !o.verified {
+		if err := o.verify(digest); err != nil {
+			return nil, err
+		}
+		o.verified = true
+	}
+	if cached, ok := cache.Get(digest.String()); ok {
+		return cached.(*image), nil
+	}
 
+	img, err := ociremote.Image(digest, ociremote.WithAuthFromKeychain(o.keychain))
+	if err != nil {
+		return nil, err
+	}
 	manifest, err := img.Manifest()
 	if err != nil {
 		return nil, fmt.Errorf("Could not parse image manifest: %w", err)
 	}
-
 	layers, err := img.Layers()
 	if err != nil {
 		return nil, fmt.Errorf("Could not read image layers: %w", err)
 	}
+	if len(layers) != len(manifest.Layers) {
+		return nil, fmt.Errorf("Image manifest lists %d layers but the image has %d", len(manifest.Layers), len(layers))
+	}
 
+	contents := &image{
+		contents: make([]remote.ResolvedObject, 0, len(manifest.Layers)),
+		layers:   make([][]byte, 0, len(layers)),
+	}
 	for idx, l := range manifest.Layers {
-		lKind := l.Annotations["cdf.tekton.image.kind"]
-		lName := l.Annotations["org.opencontainers.image.title"]
-
-		if kind == lKind && name == lName {
-			return readLayer(layers[idx])
+		contents.contents = append(contents.contents, remote.ResolvedObject{
+			Kind:       l.Annotations["cdf.tekton.image.kind"],
+			APIVersion: l.Annotations["cdf.tekton.image.apiVersion"],
+			Name:       l.Annotations["org.opencontainers.image.title"],
+		})
+		layer, err := readLayer(layers[idx])
+		if err != nil {
+			return nil, err
 		}
+		contents.layers = append(contents.layers, layer)
 	}
-	return nil, fmt.Errorf("Could not find object in image with kind: %s and name: %s", kind, name)
+	cache.Add(digest.String(), contents)
+	return contents, nil
 }
 
-// retrieveImage will fetch the image's contents and manifest.
-func (o *Resolver) retrieveImage() (v1.Image, error) {
-	imgRef, err := imgname.ParseReference(o.imageReference)
+// verify checks the detached signatures of the image against the keys trusted for its repository.
+func (o *Resolver) verify(digest imgname.Digest) error {
+	repository := digest.Context().Name()
+	if !o.verifier.Enforced(repository) {
+		return nil
+	}
+	signatures, err := o.retrieveSignatures(digest)
 	if err != nil {
-		return nil, fmt.Errorf("%s is an unparseable image reference: %w", o.imageReference, err)
+		return err
 	}
-	return ociremote.Image(imgRef, ociremote.WithAuthFromKeychain(o.keychain))
+	return o.verifier.Verify(repository, []byte(digest.DigestStr()), signatures)
 }
 
-// Utility function to read out the contents of an image layer as a parsed Tekton resource.
-func readLayer(layer v1.Layer) (runtime.Object, error) {
+// retrieveSignatures returns the signatures held in the layers of the signature image of the image, if any.
+func (o *Resolver) retrieveSignatures(digest imgname.Digest) ([][]byte, error) {
+	tag := digest.Context().Tag(strings.Replace(digest.DigestStr(), ":", "-", 1) + remote.SignatureSuffix)
+	img, err := ociremote.Image(tag, ociremote.WithAuthFromKeychain(o.keychain))
+	var terr *transport.Error
+	if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Could not retrieve the signatures of image %s: %w", digest, err)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("Could not read signature layers: %w", err)
+	}
+	var signatures [][]byte
+	for _, l := range layers {
+		contents, err := readLayer(l)
+		if err != nil {
+			return nil, err
+		}
+		layerSignatures, err := remote.ParseSignatures(contents)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", remote.ErrResourceVerificationFailed, tag, err)
+		}
+		signatures = append(signatures, layerSignatures...)
+	}
+	return signatures, nil
+}
+
+// Utility function to read out the contents of an image layer.
+func readLayer(layer v1.Layer) ([]byte, error) {
 	rc, err := layer.Uncompressed()
 	if err != nil {
 		return nil, fmt.Errorf("Failed to read image layer: %w", err)
@@ -107,7 +296,5 @@ func readLayer(layer v1.Layer) (runtime.Object, error) {
 	if err != nil {
 		return nil, fmt.Errorf("Could not read contents of image layer: %w", err)
 	}
-
-	obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(contents, nil, nil)
-	return obj, err
+	return contents, nil
 }
@@ -17,20 +17,31 @@ limitations under the License.
 package oci
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-containerregistry/pkg/authn"
+	imgname "github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/registry"
 	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/remote"
 	"github.com/tektoncd/pipeline/test"
 	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -114,6 +125,207 @@ func TestOCIResolver(t *testing.T) {
 	}
 }
 
+func TestOCIResolverDigestAndCache(t *testing.T) {
+	// Set up a fake registry counting the layers pulled from it.
+	var blobs int32
+	reg := registry.New()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/blobs/") {
+			atomic.AddInt32(&blobs, 1)
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag := fmt.Sprintf("%s/testociresolve/cache:latest", u.Host)
+	task := tb.Task("simple-task", tb.TaskType())
+	digest, err := test.CreateImage(tag, task)
+	if err != nil {
+		t.Fatalf("could not push image: %#v", err)
+	}
+
+	resolver := NewResolver(tag, authn.DefaultKeychain, nil)
+	actual, err := resolver.Digest()
+	if err != nil {
+		t.Fatalf("unexpected error resolving the digest of the image: %v", err)
+	}
+	if actual != digest {
+		t.Errorf("expected digest %s, got %s", digest, actual)
+	}
+
+	// Moving the tag doesn't change the image read by the resolver.
+	if _, err := test.CreateImage(tag, tb.Task("other-task", tb.TaskType())); err != nil {
+		t.Fatalf("could not push image: %#v", err)
+	}
+	if _, err := resolver.Get("task", "simple-task"); err != nil {
+		t.Fatalf("could not retrieve object from image: %v", err)
+	}
+	pulled := atomic.LoadInt32(&blobs)
+	if pulled == 0 {
+		t.Fatal("expected the layers of the image to be pulled")
+	}
+
+	// Other resolvers of the same digest read the image from the cache.
+	for i := 0; i < 3; i++ {
+		actual, err := NewResolver(digest, authn.DefaultKeychain, nil).Get("task", "simple-task")
+		if err != nil {
+			t.Fatalf("could not retrieve object from image: %v", err)
+		}
+		if d := cmp.Diff(task, actual); d != "" {
+			t.Error(diff.PrintWantGot(d))
+		}
+	}
+	if after := atomic.LoadInt32(&blobs); after != pulled {
+		t.Errorf("expected the image to be pulled once, %d more blobs were pulled", after-pulled)
+	}
+}
+
+func TestOCIResolverVerification(t *testing.T) {
+	// Set up a fake registry to push images and their signatures to.
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm := &corev1.ConfigMap{Data: map[string]string{
+		remote.VerificationPoliciesKey: fmt.Sprintf("- pattern: %s/signed/*\n  keys:\n  - data: %q\n",
+			u.Host, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+	}}
+	verifier, err := remote.NewVerifierFromConfigMap(cm, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating the verifier: %v", err)
+	}
+
+	sign := func(key *ecdsa.PrivateKey, ref string) []byte {
+		digest, err := imgname.NewDigest(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum := sha256.Sum256([]byte(digest.DigestStr()))
+		signature, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signature
+	}
+
+	for _, tc := range []struct {
+		name       string
+		repository string
+		signingKey *ecdsa.PrivateKey
+		wantErr    bool
+	}{{
+		name:       "signed by a trusted key",
+		repository: "signed/trusted",
+		signingKey: key,
+	}, {
+		name:       "signed by an untrusted key",
+		repository: "signed/untrusted",
+		signingKey: otherKey,
+		wantErr:    true,
+	}, {
+		name:       "not signed",
+		repository: "signed/missing",
+		wantErr:    true,
+	}, {
+		name:       "repository without policy",
+		repository: "unsigned/task",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ref, err := test.CreateImage(fmt.Sprintf("%s/%s:latest", u.Host, tc.repository), tb.Task("simple-task", tb.TaskType()))
+			if err != nil {
+				t.Fatalf("could not push image: %#v", err)
+			}
+			if tc.signingKey != nil {
+				if err := test.CreateSignatureImage(ref, sign(tc.signingKey, ref)); err != nil {
+					t.Fatalf("could not push signature image: %v", err)
+				}
+			}
+
+			resolver := NewResolver(ref, authn.DefaultKeychain, verifier)
+			_, err = resolver.Get("task", "simple-task")
+			if tc.wantErr {
+				if !errors.Is(err, remote.ErrResourceVerificationFailed) {
+					t.Errorf("expected ErrResourceVerificationFailed, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if _, err := resolver.List(); (err != nil) != tc.wantErr {
+				t.Errorf("expected List to fail as Get, got %v", err)
+			}
+		})
+	}
+}
+
 func getObjectName(obj runtime.Object) string {
 	return reflect.Indirect(reflect.ValueOf(obj)).FieldByName("ObjectMeta").FieldByName("Name").String()
 }
+
+// basicKeychain holds the credentials of every registry
+type basicKeychain struct {
+	username, password string
+}
+
+func (k basicKeychain) Resolve(authn.Resource) (authn.Authenticator, error) {
+	return &authn.Basic{Username: k.username, Password: k.password}, nil
+}
+
+func TestOCIResolverCacheRequiresCredentials(t *testing.T) {
+	// Set up a fake registry which requires credentials once the image is pushed.
+	var private int32
+	reg := registry.New()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); atomic.LoadInt32(&private) == 1 && (!ok || username != "user" || password != "secret") {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task := tb.Task("private-task", tb.TaskType())
+	digest, err := test.CreateImage(fmt.Sprintf("%s/testociresolve/private:latest", u.Host), task)
+	if err != nil {
+		t.Fatalf("could not push image: %#v", err)
+	}
+	atomic.StoreInt32(&private, 1)
+
+	// The image is cached once it is read with the right credentials.
+	if _, err := NewResolver(digest, basicKeychain{username: "user", password: "secret"}, nil).Get("task", "private-task"); err != nil {
+		t.Fatalf("could not retrieve object from image: %v", err)
+	}
+	if _, ok := cache.Get(digest); !ok {
+		t.Fatalf("expected image %s to be cached", digest)
+	}
+
+	// The cached image isn't read without credentials allowing to pull it.
+	for _, keychain := range []authn.Keychain{authn.DefaultKeychain, basicKeychain{username: "user", password: "wrong"}} {
+		if _, err := NewResolver(digest, keychain, nil).Get("task", "private-task"); err == nil {
+			t.Errorf("expected an error reading image %s from the cache without the right credentials", digest)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// VerificationPolicyConfigName is the name of the ConfigMap, in the namespace of the controller, holding
+	// the verification policies of remote Tasks and Pipelines.
+	VerificationPolicyConfigName = "config-verification-policies"
+
+	// VerificationPoliciesKey is the key of the ConfigMap holding the list of policies in YAML.
+	VerificationPoliciesKey = "policies"
+
+	// SignatureSuffix is appended to the name of a file of a git repository to name the file holding its
+	// detached signatures.
+	SignatureSuffix = ".sig"
+)
+
+// ErrResourceVerificationFailed is wrapped by the errors of resolvers when a remote object is not signed by
+// a key trusted for its location.
+var ErrResourceVerificationFailed = errors.New("resource verification failed")
+
+// VerificationPolicy trusts public keys to sign the Tasks and Pipelines of the locations matching a pattern.
+type VerificationPolicy struct {
+	// Pattern matches the locations the policy applies to: the repository of a bundle, such as
+	// gcr.io/myorg/catalog, or the URL of a git repository or of a file, matched in the form returned by
+	// NormalizeURL. "*" matches any sequence of characters.
+	Pattern string `json:"pattern"`
+	// Keys are the public keys trusted for the locations.
+	Keys []PublicKeySource `json:"keys"`
+}
+
+// PublicKeySource holds a PEM encoded public key, or references the Secret holding it.
+type PublicKeySource struct {
+	// Data is the PEM encoded public key
+	Data string `json:"data,omitempty"`
+	// SecretRef selects the key of a Secret, in the namespace of the controller, holding the PEM encoded
+	// public key
+	SecretRef *corev1.SecretKeySelector `json:"secretRef,omitempty"`
+}
+
+// Verifier checks the detached signatures of remote objects against the public keys trusted for their
+// location. Objects whose location matches no policy are not verified, and neither are the objects checked
+// by a nil Verifier.
+type Verifier struct {
+	policies []policy
+}
+
+type policy struct {
+	pattern *regexp.Regexp
+	keys    []crypto.PublicKey
+}
+
+// NewVerifierFromConfigMap returns the Verifier enforcing the policies of the ConfigMap, reading the keys
+// held in Secrets with getSecret.
+func NewVerifierFromConfigMap(cm *corev1.ConfigMap, getSecret func(name string) (*corev1.Secret, error)) (*Verifier, error) {
+	var policies []VerificationPolicy
+	if err := yaml.Unmarshal([]byte(cm.Data[VerificationPoliciesKey]), &policies); err != nil {
+		return nil, fmt.Errorf("failed to parse the verification policies of ConfigMap %s: %w", cm.Name, err)
+	}
+	v := &Verifier{}
+	for i, p := range policies {
+		if p.Pattern == "" {
+			return nil, fmt.Errorf("verification policy %d has no pattern", i)
+		}
+		if len(p.Keys) == 0 {
+			return nil, fmt.Errorf("verification policy %q has no keys", p.Pattern)
+		}
+		parsed := policy{pattern: compilePattern(NormalizeURL(p.Pattern))}
+		for _, k := range p.Keys {
+			data := []byte(k.Data)
+			if k.SecretRef != nil {
+				secret, err := getSecret(k.SecretRef.Name)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get the key of verification policy %q: %w", p.Pattern, err)
+				}
+				var ok bool
+				if data, ok = secret.Data[k.SecretRef.Key]; !ok {
+					return nil, fmt.Errorf("Secret %s of verification policy %q has no key %s", k.SecretRef.Name, p.Pattern, k.SecretRef.Key)
+				}
+			}
+			key, err := ParsePublicKey(data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid key for verification policy %q: %w", p.Pattern, err)
+			}
+			parsed.keys = append(parsed.keys, key)
+		}
+		v.policies = append(v.policies, parsed)
+	}
+	return v, nil
+}
+
+// compilePattern turns a pattern where "*" matches any sequence of characters into a regular expression.
+func compilePattern(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
+
+// NormalizeURL returns the form of the URL of a git repository or of a file under which it is matched against
+// the verification policies, so that the spellings of the same location match the same policies: the scheme
+// and the host are lower-cased, and the user, the fragment, the default port of the scheme, the trailing slashes
+// and a .git suffix are removed. rawURL is returned unchanged if it isn't an absolute URL, like the repository of a bundle.
+func NormalizeURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return rawURL
+	}
+	scheme, host := strings.ToLower(u.Scheme), strings.ToLower(u.Host)
+	if port := u.Port(); (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		host = strings.TrimSuffix(host, ":"+port)
+	}
+	p := u.Path
+	if p != "" {
+		p = strings.TrimSuffix(strings.TrimSuffix(path.Clean(p), "/"), ".git")
+		p = strings.TrimSuffix(p, "/")
+	}
+	// the path is kept unescaped, so that the "*" of the patterns are left as is
+	normalized := scheme + "://" + host + p
+	if u.RawQuery != "" {
+		normalized += "?" + u.RawQuery
+	}
+	return normalized
+}
+
+// ParsePublicKey parses a PEM encoded ECDSA, RSA or Ed25519 public key.
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported public key of type %T", key)
+}
+
+// ParseSignatures decodes detached signatures, encoded in base64 one per line.
+func ParseSignatures(data []byte) ([][]byte, error) {
+	var signatures [][]byte
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		signature, err := base64.StdEncoding.DecodeString(string(line))
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature: %w", err)
+		}
+		signatures = append(signatures, signature)
+	}
+	return signatures, nil
+}
+
+// Enforced returns true if the objects of location have to be verified.
+func (v *Verifier) Enforced(location string) bool {
+	return len(v.keys(location)) != 0
+}
+
+// Verify checks that one of the signatures of payload, found at location, was made by a key trusted for
+// location. The payload is hashed with SHA-256 for ECDSA and RSA (PKCS #1 v1.5) keys, and signed as is for
+// Ed25519 keys.
+func (v *Verifier) Verify(location string, payload []byte, signatures [][]byte) error {
+	keys := v.keys(location)
+	if len(keys) == 0 {
+		return nil
+	}
+	if len(signatures) == 0 {
+		return fmt.Errorf("%w: %s is not signed", ErrResourceVerificationFailed, location)
+	}
+	digest := sha256.Sum256(payload)
+	for _, signature := range signatures {
+		for _, key := range keys {
+			if verifySignature(key, payload, digest[:], signature) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: no signature of %s was made by a key trusted for it", ErrResourceVerificationFailed, location)
+}
+
+// keys returns the keys trusted by the policies matching location.
+func (v *Verifier) keys(location string) []crypto.PublicKey {
+	if v == nil {
+		return nil
+	}
+	var keys []crypto.PublicKey
+	for _, p := range v.policies {
+		if p.pattern.MatchString(location) {
+			keys = append(keys, p.keys...)
+		}
+	}
+	return keys
+}
+
+func verifySignature(key crypto.PublicKey, payload, digest, signature []byte) bool {
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest, signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, signature) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, payload, signature)
+	}
+	return false
+}
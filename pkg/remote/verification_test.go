@@ -0,0 +1,286 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVerify(t *testing.T) {
+	payload := []byte("sha256:4e3b6f8b5b5e2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4")
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed25519Public, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest := sha256.Sum256(payload)
+	ecdsaSignature, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaSignature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed25519Signature := ed25519.Sign(ed25519Key, payload)
+	otherSignature, err := ecdsa.SignASN1(rand.Reader, otherKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: VerificationPolicyConfigName},
+		Data: map[string]string{
+			VerificationPoliciesKey: fmt.Sprintf(`
+- pattern: gcr.io/tekton/*
+  keys:
+  - data: %q
+  - secretRef:
+      name: signing-keys
+      key: rsa.pub
+- pattern: https://github.com/tektoncd/catalog
+  keys:
+  - data: %q
+- pattern: HTTPS://GitHub.com:443/tektoncd/pipeline.git/
+  keys:
+  - data: %q
+`, encodePublicKey(t, &ecdsaKey.PublicKey), encodePublicKey(t, ed25519Public), encodePublicKey(t, ed25519Public)),
+		},
+	}
+	getSecret := func(name string) (*corev1.Secret, error) {
+		if name != "signing-keys" {
+			return nil, fmt.Errorf("secret %s not found", name)
+		}
+		return &corev1.Secret{Data: map[string][]byte{"rsa.pub": encodePublicKey(t, &rsaKey.PublicKey)}}, nil
+	}
+	verifier, err := NewVerifierFromConfigMap(cm, getSecret)
+	if err != nil {
+		t.Fatalf("unexpected error creating the verifier: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name       string
+		verifier   *Verifier
+		location   string
+		signatures [][]byte
+		enforced   bool
+		wantErr    bool
+	}{{
+		name:       "ecdsa signature",
+		verifier:   verifier,
+		location:   "gcr.io/tekton/catalog",
+		signatures: [][]byte{ecdsaSignature},
+		enforced:   true,
+	}, {
+		name:       "rsa signature from a secret",
+		verifier:   verifier,
+		location:   "gcr.io/tekton/catalog",
+		signatures: [][]byte{rsaSignature},
+		enforced:   true,
+	}, {
+		name:       "ed25519 signature",
+		verifier:   verifier,
+		location:   "https://github.com/tektoncd/catalog",
+		signatures: [][]byte{ed25519Signature},
+		enforced:   true,
+	}, {
+		name:       "one of the signatures is trusted",
+		verifier:   verifier,
+		location:   "gcr.io/tekton/catalog",
+		signatures: [][]byte{otherSignature, ecdsaSignature},
+		enforced:   true,
+	}, {
+		name:       "untrusted key",
+		verifier:   verifier,
+		location:   "gcr.io/tekton/catalog",
+		signatures: [][]byte{otherSignature},
+		enforced:   true,
+		wantErr:    true,
+	}, {
+		name:       "key trusted for another location",
+		verifier:   verifier,
+		location:   "https://github.com/tektoncd/catalog",
+		signatures: [][]byte{ecdsaSignature},
+		enforced:   true,
+		wantErr:    true,
+	}, {
+		name:     "not signed",
+		verifier: verifier,
+		location: "gcr.io/tekton/catalog",
+		enforced: true,
+		wantErr:  true,
+	}, {
+		name:     "location without policy",
+		verifier: verifier,
+		location: "gcr.io/other/catalog",
+	}, {
+		name:     "pattern matches the whole location",
+		verifier: verifier,
+		location: "https://github.com/tektoncd/catalog-fork",
+	}, {
+		name:       "location matching a pattern written in another form",
+		verifier:   verifier,
+		location:   "https://github.com/tektoncd/pipeline",
+		signatures: [][]byte{otherSignature},
+		enforced:   true,
+		wantErr:    true,
+	}, {
+		name:     "nil verifier",
+		location: "gcr.io/tekton/catalog",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if enforced := tc.verifier.Enforced(tc.location); enforced != tc.enforced {
+				t.Errorf("expected Enforced to return %t, got %t", tc.enforced, enforced)
+			}
+			err := tc.verifier.Verify(tc.location, payload, tc.signatures)
+			if tc.wantErr {
+				if !errors.Is(err, ErrResourceVerificationFailed) {
+					t.Errorf("expected ErrResourceVerificationFailed, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewVerifierFromConfigMap_Invalid(t *testing.T) {
+	getSecret := func(name string) (*corev1.Secret, error) {
+		return &corev1.Secret{Data: map[string][]byte{"key": []byte("not a key")}}, nil
+	}
+	for _, tc := range []struct {
+		name     string
+		policies string
+	}{{
+		name:     "invalid yaml",
+		policies: "pattern: gcr.io/*",
+	}, {
+		name:     "missing pattern",
+		policies: "- keys: [{data: foo}]",
+	}, {
+		name:     "missing keys",
+		policies: "- pattern: gcr.io/*",
+	}, {
+		name:     "invalid key",
+		policies: "- pattern: gcr.io/*\n  keys: [{data: foo}]",
+	}, {
+		name:     "invalid key in secret",
+		policies: "- pattern: gcr.io/*\n  keys: [{secretRef: {name: keys, key: key}}]",
+	}, {
+		name:     "missing key in secret",
+		policies: "- pattern: gcr.io/*\n  keys: [{secretRef: {name: keys, key: other}}]",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			cm := &corev1.ConfigMap{Data: map[string]string{VerificationPoliciesKey: tc.policies}}
+			if _, err := NewVerifierFromConfigMap(cm, getSecret); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestParseSignatures(t *testing.T) {
+	data := base64.StdEncoding.EncodeToString([]byte("first")) + "\n\n" + base64.StdEncoding.EncodeToString([]byte("second")) + "\n"
+	signatures, err := ParseSignatures([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(signatures) != 2 || string(signatures[0]) != "first" || string(signatures[1]) != "second" {
+		t.Errorf("unexpected signatures %q", signatures)
+	}
+	if _, err := ParseSignatures([]byte("not base64!")); err == nil {
+		t.Error("expected an error parsing invalid signatures")
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	for _, tc := range []struct {
+		url  string
+		want string
+	}{{
+		url:  "https://github.com/my-org/catalog",
+		want: "https://github.com/my-org/catalog",
+	}, {
+		url:  "https://github.com/my-org/catalog.git",
+		want: "https://github.com/my-org/catalog",
+	}, {
+		url:  "https://github.com/my-org/catalog.git/",
+		want: "https://github.com/my-org/catalog",
+	}, {
+		url:  "https://github.com:443/my-org/catalog",
+		want: "https://github.com/my-org/catalog",
+	}, {
+		url:  "HTTPS://GitHub.com/my-org//catalog/",
+		want: "https://github.com/my-org/catalog",
+	}, {
+		url:  "https://user@github.com/my-org/catalog#main",
+		want: "https://github.com/my-org/catalog",
+	}, {
+		url:  "http://example.com:80/tasks/build.yaml",
+		want: "http://example.com/tasks/build.yaml",
+	}, {
+		url:  "http://example.com:443/tasks/build.yaml?ref=main",
+		want: "http://example.com:443/tasks/build.yaml?ref=main",
+	}, {
+		url:  "https://github.com/my-org/*",
+		want: "https://github.com/my-org/*",
+	}, {
+		url:  "gcr.io/my-org/catalog",
+		want: "gcr.io/my-org/catalog",
+	}} {
+		if got := NormalizeURL(tc.url); got != tc.want {
+			t.Errorf("NormalizeURL(%q) = %q, want %q", tc.url, got, tc.want)
+		}
+	}
+}
+
+func encodePublicKey(t *testing.T, key crypto.PublicKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
@@ -130,6 +130,67 @@ func ValidateVariableIsolatedP(value, prefix string, vars sets.String) *apis.Fie
 	return nil
 }
 
+// Verifies that variables referencing one of the objects in objectKeys use one of the object's keys, e.g.
+// "$(params.foo.key)".
+func ValidateVariableObjectKeys(name, value, prefix, locationName, path string, objectKeys map[string]sets.String) *apis.FieldError {
+	if msg, ok := validateObjectKeys(value, prefix, objectKeys, false); !ok {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("%s in %q for %s %s", msg, value, locationName, name),
+			Paths:   []string{path + "." + name},
+		}
+	}
+	return nil
+}
+
+func ValidateVariableObjectKeysP(value, prefix string, objectKeys map[string]sets.String) *apis.FieldError {
+	if msg, ok := validateObjectKeys(value, prefix, objectKeys, false); !ok {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("%s in %q", msg, value),
+			// Empty path is required to make the `ViaField`, … work
+			Paths: []string{""},
+		}
+	}
+	return nil
+}
+
+// Verifies that variables referencing one of the objects in objectKeys either use one of the object's keys,
+// e.g. "$(params.foo.key)", or reference the whole object, e.g. "$(params.foo[*])", completely isolated.
+func ValidateVariableObjectKeysOrIsolated(name, value, prefix, locationName, path string, objectKeys map[string]sets.String) *apis.FieldError {
+	if msg, ok := validateObjectKeys(value, prefix, objectKeys, true); !ok {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("%s in %q for %s %s", msg, value, locationName, name),
+			Paths:   []string{path + "." + name},
+		}
+	}
+	return nil
+}
+
+func validateObjectKeys(value, prefix string, objectKeys map[string]sets.String, allowIsolatedWholeObject bool) (string, bool) {
+	pattern := fmt.Sprintf(braceMatchingRegex, prefix, parameterSubstitution)
+	re := regexp.MustCompile(pattern)
+	for _, match := range re.FindAllStringSubmatch(value, -1) {
+		v := matchGroups(match, re)["var"]
+		parts := strings.SplitN(strings.TrimSuffix(v, "[*]"), ".", 2)
+		keys, isObject := objectKeys[parts[0]]
+		if !isObject {
+			continue
+		}
+		if len(parts) == 1 {
+			if !allowIsolatedWholeObject {
+				return "object variable must be referenced by key", false
+			}
+			if strings.HasSuffix(v, "[*]") && len(value) == len(match[0]) {
+				continue
+			}
+			return "object variable must be referenced by key or be properly isolated", false
+		}
+		if !keys.Has(parts[1]) {
+			return fmt.Sprintf("non-existent key %q of object variable", parts[1]), false
+		}
+	}
+	return "", true
+}
+
 // Extract a the first full string expressions found (e.g "$(input.params.foo)"). Return
 // "" and false if nothing is found.
 func extractExpressionFromString(s, prefix string) (string, bool) {
@@ -116,6 +116,53 @@ func TestValidateVariables(t *testing.T) {
 	}
 }
 
+func TestValidateVariableObjectKeysOrIsolated(t *testing.T) {
+	objectKeys := map[string]sets.String{"image": sets.NewString("url", "digest")}
+	for _, tc := range []struct {
+		name          string
+		input         string
+		expectedError *apis.FieldError
+	}{{
+		name:  "valid key reference",
+		input: "$(params.image.url)@$(params.image.digest)",
+	}, {
+		name:  "isolated whole object reference",
+		input: "$(params.image[*])",
+	}, {
+		name:  "not an object",
+		input: "$(params.other)",
+	}, {
+		name:  "undeclared key",
+		input: "$(params.image.tag)",
+		expectedError: &apis.FieldError{
+			Message: `non-existent key "tag" of object variable in "$(params.image.tag)" for task parameter somefield`,
+			Paths:   []string{"pipelinespec.params.somefield"},
+		},
+	}, {
+		name:  "missing key",
+		input: "$(params.image)",
+		expectedError: &apis.FieldError{
+			Message: `object variable must be referenced by key or be properly isolated in "$(params.image)" for task parameter somefield`,
+			Paths:   []string{"pipelinespec.params.somefield"},
+		},
+	}, {
+		name:  "whole object reference not isolated",
+		input: "image: $(params.image[*])",
+		expectedError: &apis.FieldError{
+			Message: `object variable must be referenced by key or be properly isolated in "image: $(params.image[*])" for task parameter somefield`,
+			Paths:   []string{"pipelinespec.params.somefield"},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := substitution.ValidateVariableObjectKeysOrIsolated("somefield", tc.input, "params", "task parameter", "pipelinespec.params", objectKeys)
+
+			if d := cmp.Diff(got, tc.expectedError, cmp.AllowUnexported(apis.FieldError{})); d != "" {
+				t.Errorf("ValidateVariableObjectKeysOrIsolated() error did not match expected error %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
 func TestApplyReplacements(t *testing.T) {
 	type args struct {
 		input        string
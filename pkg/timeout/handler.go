@@ -24,6 +24,7 @@ import (
 
 	"time"
 
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	clientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	"github.com/tektoncd/pipeline/pkg/contexts"
 	"go.uber.org/zap"
@@ -181,8 +182,13 @@ func (t *Handler) checkPipelineRunTimeouts(ctx context.Context, namespace string
 		if pipelineRun.IsDone() || pipelineRun.IsCancelled() {
 			continue
 		}
+		// Paused PipelineRuns are still waited on: the time spent paused counts toward the timeout
 		if pipelineRun.HasStarted() {
-			go t.Wait(pipelineRun.GetNamespacedName(), *pipelineRun.Status.StartTime, *pipelineRun.Spec.Timeout)
+			go t.Wait(pipelineRun.GetNamespacedName(), *pipelineRun.Status.StartTime, *pipelineRun.PipelineTimeout())
+			// PipelineRuns whose tasks can time out are also reconciled then, to start their finally tasks
+			if tasksTimeout := pipelineRun.TasksTimeout(); tasksTimeout != nil && tasksTimeout.Duration != config.NoTimeoutDuration && pipelineRun.Status.FinallyStartTime == nil && pipelineRun.Status.TasksCompletionTime == nil {
+				go t.SetTimer(pipelineRun.GetNamespacedName(), time.Until(pipelineRun.Status.StartTime.Add(tasksTimeout.Duration)))
+			}
 		}
 	}
 }
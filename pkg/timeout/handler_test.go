@@ -273,6 +273,20 @@ func TestPipelinRunCheckTimeouts(t *testing.T) {
 			tb.PipelineRunStartTime(time.Now()),
 		),
 	)
+	prTasksTimeout := tb.PipelineRun("test-pipeline-tasks-timeout", tb.PipelineRunNamespace(testNs),
+		tb.PipelineRunSpec("test-pipeline",
+			tb.PipelineRunNilTimeout,
+		),
+		tb.PipelineRunStatus(tb.PipelineRunStatusCondition(apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: corev1.ConditionUnknown}),
+			tb.PipelineRunStartTime(time.Now().Add(-2*time.Second)),
+		),
+	)
+	prTasksTimeout.Spec.Timeouts = &v1beta1.TimeoutFields{
+		Pipeline: &metav1.Duration{Duration: config.DefaultTimeoutMinutes * time.Minute},
+		Tasks:    &metav1.Duration{Duration: 1 * time.Second},
+	}
 	prDone := tb.PipelineRun("test-pipeline-done", tb.PipelineRunNamespace(testNs),
 		tb.PipelineRunSpec("test-pipeline",
 			tb.PipelineRunTimeout(config.DefaultTimeoutMinutes*time.Minute),
@@ -293,7 +307,7 @@ func TestPipelinRunCheckTimeouts(t *testing.T) {
 		),
 	)
 	d := test.Data{
-		PipelineRuns: []*v1beta1.PipelineRun{prTimeout, prRunning, prDone, prCancelled, prRunningNilTimeout},
+		PipelineRuns: []*v1beta1.PipelineRun{prTimeout, prRunning, prDone, prCancelled, prRunningNilTimeout, prTasksTimeout},
 		Pipelines:    []*v1beta1.Pipeline{simplePipeline},
 		Tasks:        []*v1beta1.Task{ts},
 		Namespaces: []*corev1.Namespace{{
@@ -333,6 +347,10 @@ func TestPipelinRunCheckTimeouts(t *testing.T) {
 		name:           "pr-timedout",
 		pr:             prTimeout,
 		expectCallback: true,
+	}, {
+		name:           "pr-tasks-timedout",
+		pr:             prTasksTimeout,
+		expectCallback: true,
 	}, {
 		name:           "pr-completed",
 		pr:             prDone,
@@ -18,6 +18,7 @@ package test
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"reflect"
 	"strings"
@@ -78,6 +79,33 @@ func CreateImage(ref string, objs ...runtime.Object) (string, error) {
 	return imgRef.Context().Digest(digest.String()).String(), nil
 }
 
+// CreateSignatureImage pushes the image holding the detached signatures of the image with the given digest
+// reference, tagged sha256-<hex>.sig in its repository, with one layer holding the signatures encoded in base64.
+func CreateSignatureImage(digestRef string, signatures ...[]byte) error {
+	digest, err := name.NewDigest(digestRef)
+	if err != nil {
+		return fmt.Errorf("undexpected error producing image reference %w", err)
+	}
+	tag := digest.Context().Tag(strings.Replace(digest.DigestStr(), ":", "-", 1) + ".sig")
+
+	var data bytes.Buffer
+	for _, signature := range signatures {
+		data.WriteString(base64.StdEncoding.EncodeToString(signature) + "\n")
+	}
+	layer, err := tarball.LayerFromReader(bytes.NewReader(data.Bytes()))
+	if err != nil {
+		return fmt.Errorf("unexpected error adding layer to image %w", err)
+	}
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	if err != nil {
+		return fmt.Errorf("could not add layer to image %w", err)
+	}
+	if err := remoteimg.Write(tag, img); err != nil {
+		return fmt.Errorf("could not push signature image to registry: %w", err)
+	}
+	return nil
+}
+
 // Return the ObjectMetadata.Name field which every resource should have.
 func getObjectName(obj runtime.Object) string {
 	return reflect.Indirect(reflect.ValueOf(obj)).FieldByName("ObjectMeta").FieldByName("Name").String()